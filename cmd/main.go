@@ -5,15 +5,20 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"net/http"
 
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/nooble/task/audio-short-api/pkg/api"
-	"github.com/nooble/task/audio-short-api/pkg/api/generated"
 	"github.com/nooble/task/audio-short-api/pkg/config"
 	"github.com/nooble/task/audio-short-api/pkg/db"
+	"github.com/nooble/task/audio-short-api/pkg/hls"
 	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/loudness"
+	"github.com/nooble/task/audio-short-api/pkg/playback"
+	"github.com/nooble/task/audio-short-api/pkg/purger"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
 	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/nooble/task/audio-short-api/pkg/stream"
 	"github.com/nooble/task/audio-short-api/pkg/util"
+	"github.com/nooble/task/audio-short-api/pkg/waveform"
 )
 
 func main() {
@@ -52,14 +57,105 @@ func main() {
 	cStore, err := store.NewCreatorsStore(pgDB)
 	util.ExitOnErr(ctx, err)
 
+	wStore, err := store.NewWaveformsStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	hStore, err := store.NewHLSStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	lStore, err := store.NewLoudnessStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	fStore, err := store.NewFingerprintsStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	tStore, err := store.NewTranscriptsStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	chStore, err := store.NewChaptersStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	iStore, err := store.NewImagesStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	tgStore, err := store.NewTagsStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	cgStore, err := store.NewCategoriesStore(pgDB)
+	util.ExitOnErr(ctx, err)
+
+	// =========== blob store ============= //
+	blobStore, err := storage.New(cfg)
+	util.ExitOnErr(ctx, err)
+
+	// =========== purger ============= //
+	if cfg.Purger.Enabled {
+		p, err := purger.New(asStore, blobStore, cfg)
+		util.ExitOnErr(ctx, err)
+		go p.Run(ctx)
+	}
+
+	// =========== waveform generator ============= //
+	if cfg.Waveform.Enabled {
+		g, err := waveform.New(wStore, blobStore, cfg)
+		util.ExitOnErr(ctx, err)
+		go g.Run(ctx)
+	}
+
+	// =========== HLS packager ============= //
+	if cfg.HLS.Enabled {
+		p, err := hls.New(hStore, blobStore, cfg)
+		util.ExitOnErr(ctx, err)
+		go p.Run(ctx)
+	}
+
+	// =========== loudness analyzer ============= //
+	if cfg.Loudness.Enabled {
+		a, err := loudness.New(lStore, blobStore, cfg)
+		util.ExitOnErr(ctx, err)
+		go a.Run(ctx)
+	}
+
+	// =========== playback ============= //
+	signer, err := playback.New(cfg)
+	util.ExitOnErr(ctx, err)
+
 	// =========== resolver ============= //
-	resolver, err := api.New(asStore, cStore)
+	opts := []api.Option{
+		api.WithBlobStore(blobStore),
+		api.WithUploadLimits(cfg.Upload.MaxSize, cfg.Upload.AllowedTypes),
+		api.WithWaveforms(wStore),
+		api.WithHLS(hStore),
+		api.WithLoudness(lStore),
+		api.WithTranscripts(tStore),
+		api.WithChapters(chStore),
+		api.WithArtwork(iStore),
+		api.WithTags(tgStore),
+		api.WithCategories(cgStore),
+		api.WithPlayback(signer),
+		api.WithStreaming(cfg.Playback.BaseURL),
+	}
+	if cfg.Audio.Validate {
+		opts = append(opts, api.WithAudioLimits(cfg.Audio.MinDuration, cfg.Audio.MaxDuration, cfg.Audio.AllowedCodecs))
+	}
+	if cfg.Duplicates.Enabled {
+		opts = append(opts, api.WithDuplicateDetection(fStore, cfg.Duplicates.Reject, cfg.Duplicates.Similarity))
+	}
+	resolver, err := api.New(asStore, cStore, opts...)
 	util.ExitOnErr(ctx, err)
 
 	// =========== server ============= //
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+	srv := api.NewServer(resolver)
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", playback.Middleware(srv))
+	audio, err := stream.New(asStore, blobStore, stream.WithSigner(signer), stream.WithHLS(hStore))
+	util.ExitOnErr(ctx, err)
+	http.Handle("/audio/", http.StripPrefix("/audio/", audio))
+	// the images of the local store are served by the API itself; S3 buckets are served by S3 or a CDN in front of
+	// them. Audio is only streamed from /audio/, so that deleted and banned shorts cannot be played from stored paths
+	if localStore, ok := blobStore.(*storage.LocalStore); ok {
+		http.Handle("/files/", http.StripPrefix("/files/", localStore.Handler(api.ImageKeyPrefixes()...)))
+	}
 
 	logging.WithContext(ctx).Info("connected for GraphQL playground")
 	err = http.ListenAndServe(cfg.Server.Host+":"+cfg.Server.Port, nil)
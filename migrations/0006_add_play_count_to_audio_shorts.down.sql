@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS audio_shorts_created_at_id_idx;
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "play_count";
+
+COMMIT;
\ No newline at end of file
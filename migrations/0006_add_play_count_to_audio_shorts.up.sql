@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "play_count" bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS audio_shorts_created_at_id_idx ON audio_shorts ("created_at", "id");
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS audio_shorts_search_vector_idx;
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "search_vector";
+
+COMMIT;
\ No newline at end of file
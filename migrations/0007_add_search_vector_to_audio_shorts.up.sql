@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "search_vector" tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce("title", '')), 'A') ||
+        setweight(to_tsvector('english', coalesce("description", '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS audio_shorts_search_vector_idx ON audio_shorts USING GIN ("search_vector");
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE creators DROP CONSTRAINT IF EXISTS creators_username_key;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE creators ADD CONSTRAINT creators_username_key UNIQUE ("username");
\ No newline at end of file
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audio_shorts_version ON audio_shorts;
+DROP FUNCTION IF EXISTS increment_version_column;
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "version";
+
+COMMIT;
\ No newline at end of file
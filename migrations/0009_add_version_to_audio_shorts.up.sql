@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "version" int NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION increment_version_column()
+    RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_version BEFORE UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE increment_version_column();
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS audio_shorts_deleted_at_idx;
+DROP TRIGGER IF EXISTS audio_shorts_deleted_at ON audio_shorts;
+DROP FUNCTION IF EXISTS change_deleted_at_column;
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "deleted_at";
+
+COMMIT;
\ No newline at end of file
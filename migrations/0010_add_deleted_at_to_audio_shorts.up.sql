@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "deleted_at" timestamp with time zone;
+
+UPDATE audio_shorts SET deleted_at = updated_at WHERE status = 'deleted';
+
+CREATE OR REPLACE FUNCTION change_deleted_at_column()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status <> 'deleted' THEN
+        NEW.deleted_at = NULL;
+    ELSIF OLD.status <> 'deleted' THEN
+        NEW.deleted_at = now();
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_deleted_at BEFORE UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE change_deleted_at_column();
+
+CREATE INDEX IF NOT EXISTS audio_shorts_deleted_at_idx ON audio_shorts ("deleted_at") WHERE status = 'deleted';
+
+COMMIT;
\ No newline at end of file
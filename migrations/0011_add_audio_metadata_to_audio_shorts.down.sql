@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audio_shorts_audio_metadata ON audio_shorts;
+DROP FUNCTION IF EXISTS clear_audio_metadata_columns;
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "codec";
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "bitrate";
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "channels";
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "sample_rate";
+ALTER TABLE audio_shorts DROP COLUMN IF EXISTS "duration_ms";
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,26 @@
+BEGIN;
+
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "duration_ms" int;
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "sample_rate" int;
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "channels" smallint;
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "bitrate" int;
+ALTER TABLE audio_shorts ADD COLUMN IF NOT EXISTS "codec" varchar(20);
+
+CREATE OR REPLACE FUNCTION clear_audio_metadata_columns()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.audio_file <> OLD.audio_file AND NEW.codec IS NOT DISTINCT FROM OLD.codec
+        AND NEW.duration_ms IS NOT DISTINCT FROM OLD.duration_ms THEN
+        NEW.duration_ms = NULL;
+        NEW.sample_rate = NULL;
+        NEW.channels = NULL;
+        NEW.bitrate = NULL;
+        NEW.codec = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_audio_metadata BEFORE UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE clear_audio_metadata_columns();
+
+COMMIT;
\ No newline at end of file
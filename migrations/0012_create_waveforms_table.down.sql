@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audio_shorts_waveform ON audio_shorts;
+DROP FUNCTION IF EXISTS drop_stale_waveform;
+DROP TABLE IF EXISTS waveforms;
+DROP TYPE IF EXISTS waveform_status;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,39 @@
+BEGIN;
+
+CREATE TYPE waveform_status AS ENUM (
+    'pending',
+    'processing',
+    'ready',
+    'failed',
+    'unsupported'
+);
+
+CREATE TABLE IF NOT EXISTS waveforms (
+    "short_id" int PRIMARY KEY,
+    "status" waveform_status NOT NULL,
+    "resolution" int NOT NULL DEFAULT 0,
+    "min_peaks" smallint[] NOT NULL DEFAULT '{}',
+    "max_peaks" smallint[] NOT NULL DEFAULT '{}',
+    "error" text,
+    "created_at" timestamp with time zone DEFAULT now(),
+    "updated_at" timestamp with time zone DEFAULT now(),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE
+);
+
+CREATE TRIGGER waveforms_updated_at BEFORE UPDATE ON waveforms FOR EACH ROW EXECUTE PROCEDURE change_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS waveforms_claimable_idx ON waveforms ("updated_at") WHERE status IN ('pending', 'processing');
+
+CREATE OR REPLACE FUNCTION drop_stale_waveform()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.audio_file <> OLD.audio_file THEN
+        DELETE FROM waveforms WHERE short_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_waveform AFTER UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE drop_stale_waveform();
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audio_shorts_hls_package ON audio_shorts;
+DROP FUNCTION IF EXISTS drop_stale_hls_package;
+DROP TABLE IF EXISTS hls_packages;
+DROP TYPE IF EXISTS hls_status;
+
+COMMIT;
\ No newline at end of file
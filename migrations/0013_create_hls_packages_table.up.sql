@@ -0,0 +1,38 @@
+BEGIN;
+
+CREATE TYPE hls_status AS ENUM (
+    'pending',
+    'processing',
+    'ready',
+    'failed',
+    'unsupported'
+);
+
+CREATE TABLE IF NOT EXISTS hls_packages (
+    "short_id" int PRIMARY KEY,
+    "status" hls_status NOT NULL,
+    "playlist_url" text,
+    "segments" int NOT NULL DEFAULT 0,
+    "error" text,
+    "created_at" timestamp with time zone DEFAULT now(),
+    "updated_at" timestamp with time zone DEFAULT now(),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE
+);
+
+CREATE TRIGGER hls_packages_updated_at BEFORE UPDATE ON hls_packages FOR EACH ROW EXECUTE PROCEDURE change_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS hls_packages_claimable_idx ON hls_packages ("updated_at") WHERE status IN ('pending', 'processing');
+
+CREATE OR REPLACE FUNCTION drop_stale_hls_package()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.audio_file <> OLD.audio_file THEN
+        DELETE FROM hls_packages WHERE short_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_hls_package AFTER UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE drop_stale_hls_package();
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audio_shorts_audio_fingerprint ON audio_shorts;
+DROP FUNCTION IF EXISTS drop_stale_audio_fingerprint;
+DROP TABLE IF EXISTS audio_fingerprints;
+
+COMMIT;
\ No newline at end of file
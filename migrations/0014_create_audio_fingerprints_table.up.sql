@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audio_fingerprints (
+    "short_id" int PRIMARY KEY,
+    "sha256" char(64) NOT NULL,
+    "acoustic" bytea,
+    "flagged" boolean NOT NULL DEFAULT false,
+    "created_at" timestamp with time zone DEFAULT now(),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS audio_fingerprints_sha256_idx ON audio_fingerprints ("sha256");
+
+CREATE INDEX IF NOT EXISTS audio_fingerprints_length_idx ON audio_fingerprints (octet_length("acoustic")) WHERE "acoustic" IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION drop_stale_audio_fingerprint()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.audio_file <> OLD.audio_file THEN
+        DELETE FROM audio_fingerprints WHERE short_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_audio_fingerprint AFTER UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE drop_stale_audio_fingerprint();
+
+COMMIT;
\ No newline at end of file
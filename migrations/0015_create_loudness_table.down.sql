@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audio_shorts_loudness ON audio_shorts;
+DROP FUNCTION IF EXISTS drop_stale_loudness;
+DROP TABLE IF EXISTS loudness;
+DROP TYPE IF EXISTS loudness_status;
+
+COMMIT;
\ No newline at end of file
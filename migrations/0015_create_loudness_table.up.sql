@@ -0,0 +1,43 @@
+BEGIN;
+
+CREATE TYPE loudness_status AS ENUM (
+    'pending',
+    'processing',
+    'ready',
+    'failed',
+    'unsupported'
+);
+
+CREATE TABLE IF NOT EXISTS loudness (
+    "short_id" int PRIMARY KEY,
+    "status" loudness_status NOT NULL,
+    "integrated" double precision,
+    "true_peak" double precision,
+    "silence_ratio" double precision NOT NULL DEFAULT 0,
+    "mostly_silent" boolean NOT NULL DEFAULT false,
+    "replay_gain" double precision NOT NULL DEFAULT 0,
+    "error" text,
+    "created_at" timestamp with time zone DEFAULT now(),
+    "updated_at" timestamp with time zone DEFAULT now(),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE
+);
+
+CREATE TRIGGER loudness_updated_at BEFORE UPDATE ON loudness FOR EACH ROW EXECUTE PROCEDURE change_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS loudness_claimable_idx ON loudness ("updated_at") WHERE status IN ('pending', 'processing');
+
+CREATE INDEX IF NOT EXISTS loudness_mostly_silent_idx ON loudness ("short_id") WHERE mostly_silent;
+
+CREATE OR REPLACE FUNCTION drop_stale_loudness()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.audio_file <> OLD.audio_file THEN
+        DELETE FROM loudness WHERE short_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audio_shorts_loudness AFTER UPDATE ON audio_shorts FOR EACH ROW EXECUTE PROCEDURE drop_stale_loudness();
+
+COMMIT;
\ No newline at end of file
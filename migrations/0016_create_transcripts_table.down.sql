@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS transcripts;
+
+COMMIT;
\ No newline at end of file
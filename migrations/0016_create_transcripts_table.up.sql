@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS transcripts (
+    "short_id" int PRIMARY KEY,
+    "cue_starts" int[] NOT NULL DEFAULT '{}',
+    "cue_ends" int[] NOT NULL DEFAULT '{}',
+    "cue_texts" text[] NOT NULL DEFAULT '{}',
+    "created_at" timestamp with time zone DEFAULT now(),
+    "updated_at" timestamp with time zone DEFAULT now(),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE,
+    CONSTRAINT cues_aligned CHECK (cardinality(cue_starts) = cardinality(cue_ends) AND cardinality(cue_starts) = cardinality(cue_texts))
+);
+
+CREATE TRIGGER transcripts_updated_at BEFORE UPDATE ON transcripts FOR EACH ROW EXECUTE PROCEDURE change_updated_at_column();
+
+COMMIT;
\ No newline at end of file
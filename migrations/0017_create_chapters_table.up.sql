@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS chapters (
+    "short_id" int NOT NULL,
+    "position" int NOT NULL,
+    "start_ms" int NOT NULL,
+    "end_ms" int NOT NULL,
+    "title" varchar(200) NOT NULL,
+    "image_url" varchar(2048),
+    "created_at" timestamp with time zone DEFAULT now(),
+    PRIMARY KEY ("short_id", "position"),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE
+);
+
+COMMIT;
\ No newline at end of file
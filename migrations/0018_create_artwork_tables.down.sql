@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS avatars;
+DROP TABLE IF EXISTS cover_art;
+DROP TYPE IF EXISTS image_size;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TYPE image_size AS ENUM (
+    'small',
+    'medium',
+    'large',
+    'original'
+);
+
+CREATE TABLE IF NOT EXISTS cover_art (
+    "short_id" int NOT NULL,
+    "size" image_size NOT NULL,
+    "url" varchar(300) NOT NULL,
+    "width" int NOT NULL,
+    "height" int NOT NULL,
+    "content_type" varchar(100) NOT NULL,
+    "created_at" timestamp with time zone DEFAULT now(),
+    PRIMARY KEY ("short_id", "size"),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS avatars (
+    "creator_id" int NOT NULL,
+    "size" image_size NOT NULL,
+    "url" varchar(300) NOT NULL,
+    "width" int NOT NULL,
+    "height" int NOT NULL,
+    "content_type" varchar(100) NOT NULL,
+    "created_at" timestamp with time zone DEFAULT now(),
+    PRIMARY KEY ("creator_id", "size"),
+    CONSTRAINT fk_creator FOREIGN KEY("creator_id") references creators("id") ON DELETE CASCADE
+);
+
+COMMIT;
\ No newline at end of file
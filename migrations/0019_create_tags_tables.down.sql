@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS audio_short_tags;
+DROP TABLE IF EXISTS tags;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS tags (
+    "id" SERIAL PRIMARY KEY,
+    "name" varchar(50) NOT NULL UNIQUE,
+    "created_at" timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS tags_name_prefix_idx ON tags ("name" text_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS audio_short_tags (
+    "short_id" int NOT NULL,
+    "tag_id" int NOT NULL,
+    "created_at" timestamp with time zone DEFAULT now(),
+    PRIMARY KEY ("short_id", "tag_id"),
+    CONSTRAINT fk_short FOREIGN KEY("short_id") references audio_shorts("id") ON DELETE CASCADE,
+    CONSTRAINT fk_tag FOREIGN KEY("tag_id") references tags("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS audio_short_tags_tag_id_idx ON audio_short_tags ("tag_id");
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TYPE category AS ENUM (
+    'news',
+    'gossip',
+    'review',
+    'story'
+);
+
+DROP INDEX IF EXISTS audio_shorts_category_idx;
+ALTER TABLE audio_shorts DROP CONSTRAINT IF EXISTS fk_category;
+ALTER TABLE audio_shorts ALTER COLUMN "category" TYPE category USING "category"::category;
+
+DROP TABLE IF EXISTS category_names;
+DROP TABLE IF EXISTS categories;
+
+COMMIT;
\ No newline at end of file
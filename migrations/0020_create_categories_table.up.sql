@@ -0,0 +1,36 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS categories (
+    "id" SERIAL PRIMARY KEY,
+    "slug" varchar(50) NOT NULL UNIQUE,
+    "name" varchar(100) NOT NULL,
+    "position" int NOT NULL,
+    "archived" boolean NOT NULL DEFAULT false,
+    "created_at" timestamp with time zone DEFAULT now(),
+    "updated_at" timestamp with time zone DEFAULT now()
+);
+
+CREATE TRIGGER categories_updated_at BEFORE UPDATE ON categories FOR EACH ROW EXECUTE PROCEDURE change_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS category_names (
+    "category_id" int NOT NULL,
+    "locale" varchar(35) NOT NULL,
+    "name" varchar(100) NOT NULL,
+    PRIMARY KEY ("category_id", "locale"),
+    CONSTRAINT fk_category FOREIGN KEY("category_id") references categories("id") ON DELETE CASCADE
+);
+
+INSERT INTO categories ("slug", "name", "position") VALUES
+    ('news', 'News', 1),
+    ('gossip', 'Gossip', 2),
+    ('review', 'Review', 3),
+    ('story', 'Story', 4);
+
+ALTER TABLE audio_shorts ALTER COLUMN "category" TYPE varchar(50) USING "category"::text;
+ALTER TABLE audio_shorts ADD CONSTRAINT fk_category FOREIGN KEY("category") references categories("slug");
+
+CREATE INDEX IF NOT EXISTS audio_shorts_category_idx ON audio_shorts ("category");
+
+DROP TYPE IF EXISTS category;
+
+COMMIT;
\ No newline at end of file
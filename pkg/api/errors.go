@@ -1,5 +1,15 @@
 package api
 
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
 const (
 	ErrorMessageBadRequest       = "Bad request"
 	ErrorMessageCreateFailed     = "Failed to create resource"
@@ -7,4 +17,168 @@ const (
 	ErrorMessageUpdateFailed     = "Failed to update resource"
 	ErrorMessageDeleteFailed     = "Failed to delete resource"
 	ErrorMessageHardDeleteFailed = "Failed to hard delete resource"
+	ErrorMessageRestoreFailed    = "Failed to restore resource"
+	ErrorMessageUploadFailed     = "Failed to upload file"
+	ErrorMessageValidationFailed = "Audio file failed validation"
+
+	ErrorMessageInvalidPageSize        = "Page size must be between 1 and 100"
+	ErrorMessageInvalidCreatorDetails  = "Username, name and email must be given, up to 100 characters each"
+	ErrorMessageCreatorNotActive       = "Creator is banned or suspended"
+	ErrorMessageEmptyPatch             = "Patch must set at least one field"
+	ErrorMessageNullPatchField         = "Patch cannot set a required field to null"
+	ErrorMessageUploadsDisabled        = "Uploads are not enabled"
+	ErrorMessageFileTooLarge           = "File is too large"
+	ErrorMessageTranscriptsDisabled    = "Transcripts are not enabled"
+	ErrorMessageInvalidCaptions        = "Captions failed validation"
+	ErrorMessageChaptersDisabled       = "Chapters are not enabled"
+	ErrorMessageInvalidChapters        = "Chapters failed validation"
+	ErrorMessageArtworkDisabled        = "Cover art and avatars are not enabled"
+	ErrorMessageInvalidImage           = "Image failed validation"
+	ErrorMessageTagsDisabled           = "Tags are not enabled"
+	ErrorMessageInvalidTags            = "Up to 10 tags of 1 to 50 letters, digits, spaces, hyphens or underscores can be set"
+	ErrorMessageCategoriesDisabled     = "Categories are not enabled"
+	ErrorMessageInvalidCategory        = "Slugs must be 1 to 50 lowercase letters, digits and hyphens, names 1 to 100 characters and locales distinct language tags"
+	ErrorMessageInvalidCategoryOrder   = "IDs must list every category exactly once"
+	ErrorMessageUnsupportedFileType    = "File type is not supported"
+	ErrorMessageUploadCleanupFailed    = "Failed to delete unused upload"
+	ErrorMessageAudioFileCleanupFailed = "Failed to delete the audio file of a hard deleted short"
+	ErrorMessageHLSCleanupFailed       = "Failed to delete the HLS package of a hard deleted short"
+	ErrorMessageCoverArtCleanupFailed  = "Failed to delete the cover art of a hard deleted short"
+	ErrorMessageProbeFailed            = "Failed to parse the audio file"
+	ErrorMessageInvalidResolution      = "Waveform resolution must be at least 1"
+	ErrorMessageWaveformRequestFailed  = "Failed to request the waveform of an uploaded short"
+	ErrorMessageHLSRequestFailed       = "Failed to request the HLS package of an uploaded short"
+	ErrorMessageLoudnessRequestFailed  = "Failed to request the loudness analysis of an uploaded short"
+	ErrorMessageFingerprintFailed      = "Failed to fingerprint the audio file"
+	ErrorMessageFingerprintSaveFailed  = "Failed to save the fingerprint of a created short"
+	ErrorMessageImageCleanupFailed     = "Failed to delete an unused image"
+)
+
+// error codes are set in the extensions of GraphQL errors, for clients to tell errors apart
+const (
+	ErrorCodeCreatorNotActive = "CREATOR_NOT_ACTIVE"
+	ErrorCodeCategoryArchived = "CATEGORY_ARCHIVED"
+	ErrorCodeNotFound         = "NOT_FOUND"
+	ErrorCodeConflict         = "CONFLICT"
+	ErrorCodeInvalidReference = "INVALID_REFERENCE"
+	ErrorCodeUnavailable      = "UNAVAILABLE"
+	ErrorCodeValidationFailed = "VALIDATION_FAILED"
+)
+
+// problem codes tell apart the problems listed by a ValidationError
+const (
+	ProblemCodeUnreadable        = "UNREADABLE"
+	ProblemCodeTooLarge          = "TOO_LARGE"
+	ProblemCodeUnsupportedFormat = "UNSUPPORTED_FORMAT"
+	ProblemCodeCorrupt           = "CORRUPT"
+	ProblemCodeUnsupportedCodec  = "UNSUPPORTED_CODEC"
+	ProblemCodeTooLong           = "TOO_LONG"
+	ProblemCodeTooShort          = "TOO_SHORT"
+	ProblemCodeDuplicate         = "DUPLICATE"
+	ProblemCodeInvalidCaptions   = "INVALID_CAPTIONS"
+	ProblemCodeNoCues            = "NO_CUES"
+	ProblemCodeInvalidCueTiming  = "INVALID_CUE_TIMING"
+	ProblemCodeInvalidCueText    = "INVALID_CUE_TEXT"
+	ProblemCodeCueOutOfOrder     = "CUE_OUT_OF_ORDER"
+	ProblemCodeCueOverlap        = "CUE_OVERLAP"
+	ProblemCodeCuePastEnd        = "CUE_PAST_END"
+	ProblemCodeDurationUnknown   = "DURATION_UNKNOWN"
+	ProblemCodeNoChapters        = "NO_CHAPTERS"
+	ProblemCodeTooManyChapters   = "TOO_MANY_CHAPTERS"
+	ProblemCodeInvalidStart      = "INVALID_CHAPTER_START"
+	ProblemCodeChapterOutOfOrder = "CHAPTER_OUT_OF_ORDER"
+	ProblemCodeInvalidTitle      = "INVALID_CHAPTER_TITLE"
+	ProblemCodeInvalidImageURL   = "INVALID_CHAPTER_IMAGE_URL"
+	ProblemCodeUnsupportedImage  = "UNSUPPORTED_IMAGE_FORMAT"
+	ProblemCodeCorruptImage      = "CORRUPT_IMAGE"
+	ProblemCodeImageTooSmall     = "IMAGE_TOO_SMALL"
+	ProblemCodeImageTooLarge     = "IMAGE_TOO_LARGE"
 )
+
+// ValidationError lists every problem found in an audio file, in captions, in chapters or in an image, so that clients
+// can show them all at once
+type ValidationError struct {
+	Problems []*ValidationProblem
+}
+
+// ValidationProblem is a problem of an audio file, of captions, of chapters or of an image, with a code and a message
+// for people; problems of a caption cue or of a chapter have its index, counted from 0
+type ValidationProblem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Cue     *int   `json:"cue,omitempty"`
+	Chapter *int   `json:"chapter,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// resolverError shows only the message to clients, while keeping the cause for the error presenter
+type resolverError struct {
+	message string
+	cause   error
+}
+
+func (e *resolverError) Error() string { return e.message }
+func (e *resolverError) Unwrap() error { return e.cause }
+
+// wrapError returns an error with the given message, caused by err
+func wrapError(err error, message string) error {
+	return &resolverError{message: message, cause: err}
+}
+
+// ErrorPresenter sets the code of the store error causing err in the extensions of the GraphQL error, along with the
+// problems of a failed validation
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	code := errorCode(err)
+	if code == "" {
+		return gqlErr
+	}
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	gqlErr.Extensions["code"] = code
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		gqlErr.Extensions["problems"] = validation.Problems
+	}
+	return gqlErr
+}
+
+// errorCode returns the error code of the store or validation error causing err, if any
+func errorCode(err error) string {
+	var (
+		notActive        *store.CreatorNotActiveError
+		archived         *store.CategoryArchivedError
+		notFound         *store.NotFoundError
+		conflict         *store.ConflictError
+		versionConflict  *store.VersionConflictError
+		notDeleted       *store.NotDeletedError
+		invalidReference *store.InvalidReferenceError
+		unavailable      *store.UnavailableError
+		validation       *ValidationError
+	)
+	switch {
+	case errors.As(err, &notActive):
+		return ErrorCodeCreatorNotActive
+	case errors.As(err, &archived):
+		return ErrorCodeCategoryArchived
+	case errors.As(err, &notFound):
+		return ErrorCodeNotFound
+	case errors.As(err, &conflict), errors.As(err, &versionConflict), errors.As(err, &notDeleted):
+		return ErrorCodeConflict
+	case errors.As(err, &invalidReference):
+		return ErrorCodeInvalidReference
+	case errors.As(err, &unavailable):
+		return ErrorCodeUnavailable
+	case errors.As(err, &validation):
+		return ErrorCodeValidationFailed
+	}
+	return ""
+}
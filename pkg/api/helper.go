@@ -0,0 +1,876 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/artwork"
+	"github.com/nooble/task/audio-short-api/pkg/audio"
+	"github.com/nooble/task/audio-short-api/pkg/captions"
+	"github.com/nooble/task/audio-short-api/pkg/chapters"
+	"github.com/nooble/task/audio-short-api/pkg/hls"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxPageSize is the maximum number of entries that can be requested in a single page
+	MaxPageSize = 100
+	// maxCreatorFieldLength is the column size of the creator details
+	maxCreatorFieldLength = 100
+	// maxCaptionsSize is the maximum size in bytes of uploaded caption files
+	maxCaptionsSize = 1 << 20
+	// maxImageSize is the maximum size in bytes of uploaded cover art and avatars
+	maxImageSize = 10 << 20
+	// maxTags is the maximum number of tags of a short
+	maxTags = 10
+	// maxTagLength is the column size of tags, in characters
+	maxTagLength = 50
+	// maxCategorySlugLength and maxCategoryNameLength are the column sizes of category slugs and names, in characters
+	maxCategorySlugLength = 50
+	maxCategoryNameLength = 100
+	// maxLocaleLength is the column size of locales, the longest language tag BCP 47 requires to be supported
+	maxLocaleLength = 35
+)
+
+var (
+	// categorySlugPattern matches lowercase words of letters and digits joined by single hyphens
+	categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// localePattern matches lowercased BCP 47 language tags, a language followed by subtags such as a region
+	localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+// parsePagination validates the page size and decodes the optional cursor of the given ordering
+func parsePagination(first *int, after *string, key string) (*store.Cursor, error) {
+	if first == nil || *first < 1 || *first > MaxPageSize {
+		return nil, errors.New(ErrorMessageInvalidPageSize)
+	}
+	if after == nil {
+		return nil, nil
+	}
+	return store.DecodeCursor(*after, key)
+}
+
+// validateCreatorDetails trims the creator details and checks they fit the creators table
+func validateCreatorDetails(input *model.CreatorDetailsInput) error {
+	input.Username = strings.TrimSpace(input.Username)
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.TrimSpace(input.Email)
+	for _, field := range []string{input.Username, input.Name, input.Email} {
+		if field == "" || utf8.RuneCountInString(field) > maxCreatorFieldLength {
+			return errors.New(ErrorMessageInvalidCreatorDetails)
+		}
+	}
+	if !strings.Contains(input.Email, "@") {
+		return errors.New(ErrorMessageInvalidCreatorDetails)
+	}
+	return nil
+}
+
+// normalizeTags lowercases the tags and collapses their whitespace, drops the duplicates, and checks they are made of
+// letters, digits, spaces, hyphens and underscores and fit the tags table
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, errors.New(ErrorMessageInvalidTags)
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' && r != '_' {
+				return nil, errors.New(ErrorMessageInvalidTags)
+			}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxTags {
+		return nil, errors.New(ErrorMessageInvalidTags)
+	}
+	return normalized, nil
+}
+
+// normalizeTag lowercases the tag and collapses its whitespace, so that tags differing only in case or spacing match
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// validateCategory trims the names of the category and checks its slug, names and locales, see validateCategoryNames
+func validateCategory(input *model.CategoryInput) error {
+	if len(input.Slug) > maxCategorySlugLength || !categorySlugPattern.MatchString(input.Slug) {
+		return errors.New(ErrorMessageInvalidCategory)
+	}
+	return validateCategoryNames(&input.Name, input.Names)
+}
+
+// validateCategoryNames trims the name and the localized names of a category and lowercases their locales, so that
+// "pt-BR" and "pt-br" match, and checks they fit the categories and category_names tables with no locale repeated
+func validateCategoryNames(name *string, names []*model.LocalizedNameInput) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" || utf8.RuneCountInString(*name) > maxCategoryNameLength {
+		return errors.New(ErrorMessageInvalidCategory)
+	}
+	seen := map[string]bool{}
+	for _, localized := range names {
+		localized.Locale = strings.ToLower(strings.TrimSpace(localized.Locale))
+		localized.Name = strings.TrimSpace(localized.Name)
+		if len(localized.Locale) > maxLocaleLength || !localePattern.MatchString(localized.Locale) || seen[localized.Locale] {
+			return errors.New(ErrorMessageInvalidCategory)
+		}
+		if localized.Name == "" || utf8.RuneCountInString(localized.Name) > maxCategoryNameLength {
+			return errors.New(ErrorMessageInvalidCategory)
+		}
+		seen[localized.Locale] = true
+	}
+	return nil
+}
+
+// pickName returns the localized name of the exact locale, else that of its language, else the fallback
+func pickName(names []*model.LocalizedName, locale, fallback string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	language := strings.SplitN(locale, "-", 2)[0]
+	picked := fallback
+	for _, name := range names {
+		if name.Locale == locale {
+			return name.Name
+		}
+		if name.Locale == language {
+			picked = name.Name
+		}
+	}
+	return picked
+}
+
+// sameIDs tells whether the ids list every one of the categories exactly once
+func sameIDs(ids []string, categories []*model.Category) bool {
+	if len(ids) != len(categories) {
+		return false
+	}
+	listed := map[string]bool{}
+	for _, id := range ids {
+		listed[id] = true
+	}
+	for _, category := range categories {
+		if !listed[category.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePatch checks that the patch argument of the current field sets at least one field, and none to null
+func validatePatch(ctx context.Context, patch *model.AudioShortPatch) error {
+	if patch.Title == nil && patch.Description == nil && patch.Category == nil && patch.AudioFile == nil && patch.Creator == nil {
+		return errors.New(ErrorMessageEmptyPatch)
+	}
+	// explicit nulls are dropped when unmarshalling the patch, so they are looked up in the raw arguments
+	args := graphql.GetFieldContext(ctx).Field.ArgumentMap(graphql.GetOperationContext(ctx).Variables)
+	raw, _ := args["patch"].(map[string]interface{})
+	for field, value := range raw {
+		if value == nil {
+			return errors.New(ErrorMessageNullPatchField + ": " + field)
+		}
+	}
+	return nil
+}
+
+// validateUpload checks the size and the sniffed content type of the uploaded file, ignoring the content type
+// declared by the client; it returns the body of the file with its content type
+func (r *Resolver) validateUpload(file *graphql.Upload) (body io.Reader, contentType string, err error) {
+	if r.blobStore == nil {
+		return nil, "", errors.New(ErrorMessageUploadsDisabled)
+	}
+	if file.Size > r.maxUploadSize {
+		return nil, "", errors.New(ErrorMessageFileTooLarge)
+	}
+	buffered := bufio.NewReader(io.LimitReader(file.File, r.maxUploadSize))
+	head, _ := buffered.Peek(audio.SniffLength)
+	contentType = audio.DetectContentType(head)
+	if !contains(r.uploadTypes, contentType) {
+		return nil, "", errors.New(ErrorMessageUnsupportedFileType)
+	}
+	return buffered, contentType, nil
+}
+
+// storeUpload streams the body of an uploaded file to the blob store under a random key
+func (r *Resolver) storeUpload(ctx context.Context, body io.Reader, contentType string) (key, url string, err error) {
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	if err != nil {
+		return "", "", err
+	}
+	key = "shorts/" + hex.EncodeToString(id) + audio.Extension(contentType)
+	url, err = r.blobStore.Put(ctx, key, body, contentType)
+	if err != nil {
+		return "", "", err
+	}
+	return key, url, nil
+}
+
+// openAudioFile returns a reader of the audio file at the URL, from the blob store when it is kept there, or else with
+// HTTP range requests of the public client, for files up to the upload size
+func (r *Resolver) openAudioFile(ctx context.Context, url string) (*storage.ReaderAt, error) {
+	if r.blobStore != nil {
+		key, ok := r.blobStore.Key(url)
+		if ok {
+			return storage.NewReaderAt(ctx, r.blobStore, key)
+		}
+	}
+	return storage.NewURLReaderAt(ctx, r.httpClient, url, r.maxUploadSize)
+}
+
+// probeAudioFile parses the headers of the audio file at the URL to describe it; with audio limits, it returns a
+// ValidationError listing every problem of the file
+func (r *Resolver) probeAudioFile(ctx context.Context, url string) (*model.AudioMetadata, error) {
+	reader, err := r.openAudioFile(ctx, url)
+	if err == storage.ErrTooLarge {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageProbeFailed+" URL:"+url).Error())
+		return nil, &ValidationError{Problems: []*ValidationProblem{{
+			Code:    ProblemCodeTooLarge,
+			Message: ErrorMessageFileTooLarge,
+		}}}
+	}
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageProbeFailed+" URL:"+url).Error())
+		return nil, &ValidationError{Problems: []*ValidationProblem{{
+			Code:    ProblemCodeUnreadable,
+			Message: "Audio file cannot be read",
+		}}}
+	}
+	metadata, err := audio.Probe(reader, reader.Size())
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageProbeFailed+" URL:"+url).Error())
+		problem := &ValidationProblem{Code: ProblemCodeUnreadable, Message: "Audio file cannot be read"}
+		switch errors.Cause(err) {
+		case audio.ErrUnsupported:
+			problem = &ValidationProblem{Code: ProblemCodeUnsupportedFormat, Message: err.Error()}
+		case audio.ErrCorrupt:
+			problem = &ValidationProblem{Code: ProblemCodeCorrupt, Message: err.Error()}
+		}
+		return nil, &ValidationError{Problems: []*ValidationProblem{problem}}
+	}
+	if r.validateAudio {
+		problems := r.checkAudioLimits(metadata)
+		if len(problems) > 0 {
+			return nil, &ValidationError{Problems: problems}
+		}
+	}
+	return &model.AudioMetadata{
+		Duration:   metadata.Duration.Seconds(),
+		SampleRate: metadata.SampleRate,
+		Channels:   metadata.Channels,
+		Bitrate:    metadata.Bitrate,
+		Codec:      metadata.Codec,
+	}, nil
+}
+
+// checkAudioLimits returns the problems of audio with the metadata, if any
+func (r *Resolver) checkAudioLimits(metadata *audio.Metadata) []*ValidationProblem {
+	var problems []*ValidationProblem
+	if !contains(r.audioCodecs, metadata.Codec) {
+		problems = append(problems, &ValidationProblem{
+			Code:    ProblemCodeUnsupportedCodec,
+			Message: "Codec " + metadata.Codec + " is not supported, use one of " + strings.Join(r.audioCodecs, ", "),
+		})
+	}
+	if r.maxDuration > 0 && metadata.Duration > r.maxDuration {
+		problems = append(problems, &ValidationProblem{
+			Code:    ProblemCodeTooLong,
+			Message: "Audio lasts " + metadata.Duration.Round(time.Millisecond).String() + ", longer than the maximum of " + r.maxDuration.String(),
+		})
+	}
+	if metadata.Duration < r.minDuration {
+		problems = append(problems, &ValidationProblem{
+			Code:    ProblemCodeTooShort,
+			Message: "Audio lasts " + metadata.Duration.Round(time.Millisecond).String() + ", shorter than the minimum of " + r.minDuration.String(),
+		})
+	}
+	return problems
+}
+
+// validateAudioFile probes the audio file at the URL when audio limits are set, and returns its metadata or a
+// ValidationError; without limits, files are not read and no metadata is returned
+func (r *Resolver) validateAudioFile(ctx context.Context, url string) (*model.AudioMetadata, error) {
+	if !r.validateAudio {
+		return nil, nil
+	}
+	return r.probeAudioFile(ctx, url)
+}
+
+// replacedAudioFile returns the short when an update replaces its audio file with the one at the URL, and nil when it
+// keeps its audio file. The short is only looked up when the work done for new audio files depends on the answer
+func (r *Resolver) replacedAudioFile(ctx context.Context, id, url string) (*model.AudioShort, error) {
+	if !r.validateAudio && r.waveformsStore == nil && r.hlsStore == nil && r.loudnessStore == nil && r.fingerprintsStore == nil {
+		return nil, nil
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if short.AudioFile == url {
+		return nil, nil
+	}
+	return short, nil
+}
+
+// duplicate is a short whose audio duplicates that of another
+type duplicate struct {
+	shortID    string
+	exact      bool
+	similarity float64
+}
+
+// checkDuplicates fingerprints the new audio file at the URL of a short of the creator, and looks for shorts of other
+// creators with the same audio, leaving out the short itself when its ID is given; duplicates are rejected with a
+// ValidationError, or flagged on the returned fingerprint when they are not rejected. Without duplicate detection, or
+// for audio files that cannot be read, no fingerprint is returned
+func (r *Resolver) checkDuplicates(ctx context.Context, url, creatorID, shortID string) (*store.Fingerprint, error) {
+	if r.fingerprintsStore == nil {
+		return nil, nil
+	}
+	reader, err := r.openAudioFile(ctx, url)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageFingerprintFailed+" URL:"+url).Error())
+		return nil, nil
+	}
+	computed, err := audio.NewFingerprint(reader, reader.Size())
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageFingerprintFailed+" URL:"+url).Error())
+		return nil, nil
+	}
+	fingerprint := &store.Fingerprint{SHA256: computed.SHA256, Acoustic: computed.Acoustic}
+	duplicates, err := r.findDuplicates(ctx, fingerprint, creatorID, shortID)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) == 0 {
+		return fingerprint, nil
+	}
+	logging.WithContext(ctx).Info("Audio file duplicates short ID:" + duplicates[0].shortID + " URL:" + url)
+	if !r.rejectDuplicates {
+		fingerprint.Flagged = true
+		return fingerprint, nil
+	}
+	message := "Audio sounds like that of short " + duplicates[0].shortID + " of another creator"
+	if duplicates[0].exact {
+		message = "Audio file is the same as that of short " + duplicates[0].shortID + " of another creator"
+	}
+	return nil, &ValidationError{Problems: []*ValidationProblem{{Code: ProblemCodeDuplicate, Message: message}}}
+}
+
+// findDuplicates returns the shorts whose audio is the same file as that of the fingerprint, or sounds at least as
+// similar as the duplicate similarity, most similar first; the shorts of the creator and the short itself are left out
+// when their IDs are given
+func (r *Resolver) findDuplicates(ctx context.Context, fingerprint *store.Fingerprint, creatorID, shortID string) ([]*duplicate, error) {
+	// acoustic fingerprints of too different lengths cannot be similar enough, see audio.Similarity
+	minLength, maxLength := 1, 0
+	if fingerprint.Acoustic != nil && r.duplicateSimilarity > 0 {
+		n := float64(len(fingerprint.Acoustic))
+		minLength, maxLength = int(math.Ceil(n*r.duplicateSimilarity)), int(n/r.duplicateSimilarity)
+	}
+	candidates, err := r.fingerprintsStore.FindCandidates(ctx, fingerprint.SHA256, minLength, maxLength, creatorID, shortID)
+	if err != nil {
+		return nil, err
+	}
+	var duplicates []*duplicate
+	for _, candidate := range candidates {
+		if candidate.SHA256 == fingerprint.SHA256 {
+			duplicates = append(duplicates, &duplicate{shortID: candidate.ShortID, exact: true, similarity: 1})
+			continue
+		}
+		if fingerprint.Acoustic == nil || candidate.Acoustic == nil {
+			continue
+		}
+		similarity := audio.Similarity(fingerprint.Acoustic, candidate.Acoustic)
+		if similarity >= r.duplicateSimilarity {
+			duplicates = append(duplicates, &duplicate{shortID: candidate.ShortID, similarity: similarity})
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].similarity > duplicates[j].similarity
+	})
+	return duplicates, nil
+}
+
+// The helpers below follow up on a short once it is saved or gone, when failing the mutation would not undo it;
+// their failures are only logged
+
+// saveFingerprint stores the fingerprint of the new audio file of a short, if any
+func (r *Resolver) saveFingerprint(ctx context.Context, id string, fingerprint *store.Fingerprint) {
+	if fingerprint == nil {
+		return
+	}
+	fingerprint.ShortID = id
+	err := r.fingerprintsStore.Save(ctx, fingerprint)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageFingerprintSaveFailed+" ID:"+id).Error())
+	}
+}
+
+// deleteUpload removes an uploaded file that ended up unused
+func (r *Resolver) deleteUpload(ctx context.Context, key string) {
+	err := r.blobStore.Delete(ctx, key)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUploadCleanupFailed+" key:"+key).Error())
+	}
+}
+
+// deleteShortFiles removes the audio file of a hard deleted short when it is kept in the blob store, along with its
+// HLS package and cover art, so that none is left orphaned at its URL
+func (r *Resolver) deleteShortFiles(ctx context.Context, short *model.AudioShort) {
+	if r.blobStore == nil {
+		return
+	}
+	err := r.blobStore.DeletePrefix(ctx, hls.KeyPrefix(short.ID))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageHLSCleanupFailed+" ID:"+short.ID).Error())
+	}
+	err = r.blobStore.DeletePrefix(ctx, store.CoverArt.KeyPrefix(short.ID))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCoverArtCleanupFailed+" ID:"+short.ID).Error())
+	}
+	key, ok := r.blobStore.Key(short.AudioFile)
+	if !ok {
+		return
+	}
+	err = r.blobStore.Delete(ctx, key)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageAudioFileCleanupFailed+" ID:"+short.ID).Error())
+	}
+}
+
+// deleteImages removes the blobs of the URLs of the sizes of an image that was replaced or deleted
+func (r *Resolver) deleteImages(ctx context.Context, urls []string) {
+	for _, url := range urls {
+		key, ok := r.blobStore.Key(url)
+		if !ok {
+			continue
+		}
+		err := r.blobStore.Delete(ctx, key)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageImageCleanupFailed+" key:"+key).Error())
+		}
+	}
+}
+
+// requestWaveform has the waveform of a short with a new audio file generated in the background
+func (r *Resolver) requestWaveform(ctx context.Context, id string) {
+	if r.waveformsStore == nil {
+		return
+	}
+	err := r.waveformsStore.Request(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageWaveformRequestFailed+" ID:"+id).Error())
+	}
+}
+
+// requestHLS has the HLS package of a short with a new audio file packaged in the background; until it is ready, the
+// short plays from its audio file
+func (r *Resolver) requestHLS(ctx context.Context, id string) {
+	if r.hlsStore == nil {
+		return
+	}
+	err := r.hlsStore.Request(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageHLSRequestFailed+" ID:"+id).Error())
+	}
+}
+
+// requestLoudness has the loudness of a short with a new audio file measured in the background; until it is measured,
+// the short plays without normalization
+func (r *Resolver) requestLoudness(ctx context.Context, id string) {
+	if r.loudnessStore == nil {
+		return
+	}
+	err := r.loudnessStore.Request(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageLoudnessRequestFailed+" ID:"+id).Error())
+	}
+}
+
+// cueProblemCodes are the problem codes of the errors of captions.Validate
+var cueProblemCodes = map[error]string{
+	captions.ErrNoCues:        ProblemCodeNoCues,
+	captions.ErrInvalidTiming: ProblemCodeInvalidCueTiming,
+	captions.ErrInvalidText:   ProblemCodeInvalidCueText,
+	captions.ErrOutOfOrder:    ProblemCodeCueOutOfOrder,
+	captions.ErrOverlap:       ProblemCodeCueOverlap,
+	captions.ErrPastEnd:       ProblemCodeCuePastEnd,
+}
+
+// saveTranscript replaces the transcript of the short with the cues, once they are validated against the duration of
+// its audio, and returns the short; invalid cues are rejected with a ValidationError listing every problem
+func (r *Resolver) saveTranscript(ctx context.Context, id string, cues []captions.Cue) (*model.AudioShort, error) {
+	if r.transcriptsStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageTranscriptsDisabled)
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	var duration time.Duration
+	if short.Metadata != nil {
+		// the duration is rounded up to the millisecond of the cues
+		duration = time.Duration(math.Ceil(short.Metadata.Duration*1000)) * time.Millisecond
+	}
+	var problems []*ValidationProblem
+	for _, err := range captions.Validate(cues, duration) {
+		problem := &ValidationProblem{Code: cueProblemCodes[err], Message: err.Error()}
+		var cueErr *captions.CueError
+		if errors.As(err, &cueErr) {
+			problem.Code = cueProblemCodes[cueErr.Err]
+			problem.Cue = &cueErr.Index
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		err := &ValidationError{Problems: problems}
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageInvalidCaptions).Error())
+		return nil, wrapError(err, ErrorMessageInvalidCaptions+": "+err.Error())
+	}
+	err = r.transcriptsStore.Save(ctx, id, toCaptionCues(cues))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return short, nil
+}
+
+// parseCaptions reads the cues of an uploaded WebVTT or SubRip file; files that cannot be parsed are rejected with a
+// ValidationError
+func parseCaptions(file *graphql.Upload) ([]captions.Cue, error) {
+	if file.Size > maxCaptionsSize {
+		return nil, errors.New(ErrorMessageFileTooLarge)
+	}
+	cues, err := captions.Parse(io.LimitReader(file.File, maxCaptionsSize))
+	if err != nil {
+		return nil, &ValidationError{Problems: []*ValidationProblem{{Code: ProblemCodeInvalidCaptions, Message: err.Error()}}}
+	}
+	return cues, nil
+}
+
+// fromCueInputs returns the cues of the input, rounded to the millisecond; times too large to be converted are clamped
+// past captions.MaxTime, and rejected as such when validated
+func fromCueInputs(inputs []*model.CaptionCueInput) []captions.Cue {
+	toTime := func(seconds float64) time.Duration {
+		seconds = math.Max(math.Min(seconds, captions.MaxTime.Seconds()+1), -1)
+		return time.Duration(math.Round(seconds*1000)) * time.Millisecond
+	}
+	cues := make([]captions.Cue, len(inputs))
+	for i, input := range inputs {
+		cues[i] = captions.Cue{Start: toTime(input.Start), End: toTime(input.End), Text: strings.TrimSpace(input.Text)}
+	}
+	return cues
+}
+
+// toCaptionCues returns the cues with their times in seconds
+func toCaptionCues(cues []captions.Cue) []*model.CaptionCue {
+	result := make([]*model.CaptionCue, len(cues))
+	for i, cue := range cues {
+		result[i] = &model.CaptionCue{Start: cue.Start.Seconds(), End: cue.End.Seconds(), Text: cue.Text}
+	}
+	return result
+}
+
+// fromCaptionCues returns the cues of a transcript, with their times in seconds rounded to the millisecond
+func fromCaptionCues(cues []*model.CaptionCue) []captions.Cue {
+	result := make([]captions.Cue, len(cues))
+	for i, cue := range cues {
+		result[i] = captions.Cue{
+			Start: time.Duration(math.Round(cue.Start*1000)) * time.Millisecond,
+			End:   time.Duration(math.Round(cue.End*1000)) * time.Millisecond,
+			Text:  cue.Text,
+		}
+	}
+	return result
+}
+
+// chapterProblemCodes are the problem codes of the errors of chapters.Validate
+var chapterProblemCodes = map[error]string{
+	chapters.ErrDurationUnknown: ProblemCodeDurationUnknown,
+	chapters.ErrNoChapters:      ProblemCodeNoChapters,
+	chapters.ErrTooMany:         ProblemCodeTooManyChapters,
+	chapters.ErrInvalidStart:    ProblemCodeInvalidStart,
+	chapters.ErrOutOfOrder:      ProblemCodeChapterOutOfOrder,
+	chapters.ErrInvalidTitle:    ProblemCodeInvalidTitle,
+	chapters.ErrInvalidImageURL: ProblemCodeInvalidImageURL,
+}
+
+// saveChapters replaces the chapters of the short, once they are validated against the duration of its audio, and
+// returns the short; the chapters are saved with their ends, the last one ending with the audio. Invalid chapters are
+// rejected with a ValidationError listing every problem
+func (r *Resolver) saveChapters(ctx context.Context, id string, inputs []chapters.Chapter) (*model.AudioShort, error) {
+	if r.chaptersStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageChaptersDisabled)
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	var duration time.Duration
+	if short.Metadata != nil {
+		// the duration is rounded up to the millisecond of the chapters
+		duration = time.Duration(math.Ceil(short.Metadata.Duration*1000)) * time.Millisecond
+	}
+	var problems []*ValidationProblem
+	for _, err := range chapters.Validate(inputs, duration) {
+		problem := &ValidationProblem{Code: chapterProblemCodes[err], Message: err.Error()}
+		var chapterErr *chapters.ChapterError
+		if errors.As(err, &chapterErr) {
+			problem.Code = chapterProblemCodes[chapterErr.Err]
+			problem.Chapter = &chapterErr.Index
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		err := &ValidationError{Problems: problems}
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageInvalidChapters).Error())
+		return nil, wrapError(err, ErrorMessageInvalidChapters+": "+err.Error())
+	}
+	err = r.chaptersStore.Save(ctx, id, toModelChapters(chapters.WithEnds(inputs, duration)))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return short, nil
+}
+
+// fromChapterInputs returns the chapters of the input, with their starts rounded to the millisecond and their titles
+// and image URLs trimmed; starts too large to be converted are clamped, and rejected as such when validated
+func fromChapterInputs(inputs []*model.ChapterInput) []chapters.Chapter {
+	result := make([]chapters.Chapter, len(inputs))
+	for i, input := range inputs {
+		start := math.Max(math.Min(input.Start, math.MaxInt32/1000), -1)
+		result[i] = chapters.Chapter{
+			Start: time.Duration(math.Round(start*1000)) * time.Millisecond,
+			Title: strings.TrimSpace(input.Title),
+		}
+		if input.ImageURL != nil {
+			result[i].ImageURL = strings.TrimSpace(*input.ImageURL)
+		}
+	}
+	return result
+}
+
+// toModelChapters returns the chapters with their times in seconds
+func toModelChapters(ended []chapters.Chapter) []*model.Chapter {
+	result := make([]*model.Chapter, len(ended))
+	for i, chapter := range ended {
+		result[i] = &model.Chapter{Start: chapter.Start.Seconds(), End: chapter.End.Seconds(), Title: chapter.Title}
+		if chapter.ImageURL != "" {
+			imageURL := chapter.ImageURL
+			result[i].ImageURL = &imageURL
+		}
+	}
+	return result
+}
+
+// fromModelChapters returns the chapters of a short, with their times in seconds rounded to the millisecond
+func fromModelChapters(saved []*model.Chapter) []chapters.Chapter {
+	result := make([]chapters.Chapter, len(saved))
+	for i, chapter := range saved {
+		result[i] = chapters.Chapter{
+			Start: time.Duration(math.Round(chapter.Start*1000)) * time.Millisecond,
+			End:   time.Duration(math.Round(chapter.End*1000)) * time.Millisecond,
+			Title: chapter.Title,
+		}
+		if chapter.ImageURL != nil {
+			result[i].ImageURL = *chapter.ImageURL
+		}
+	}
+	return result
+}
+
+// thumbnailSizes are the sizes in pixels of the squares thumbnails fit within
+var thumbnailSizes = map[model.ImageSize]int{
+	model.ImageSizeSmall:  64,
+	model.ImageSizeMedium: 256,
+	model.ImageSizeLarge:  1024,
+}
+
+// imageProblemCodes are the problem codes of the errors of artwork.Probe and artwork.Validate
+var imageProblemCodes = map[error]string{
+	artwork.ErrUnsupported: ProblemCodeUnsupportedImage,
+	artwork.ErrCorrupt:     ProblemCodeCorruptImage,
+	artwork.ErrTooSmall:    ProblemCodeImageTooSmall,
+	artwork.ErrTooLarge:    ProblemCodeImageTooLarge,
+}
+
+// readImage reads and validates an uploaded image; invalid images are rejected with a ValidationError listing every
+// problem
+func readImage(file *graphql.Upload) (*artwork.Image, error) {
+	if file.Size > maxImageSize {
+		return nil, errors.New(ErrorMessageFileTooLarge)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(file.File, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageSize {
+		return nil, errors.New(ErrorMessageFileTooLarge)
+	}
+	img, err := artwork.Probe(data)
+	if err != nil {
+		cause := errors.Cause(err)
+		return nil, &ValidationError{Problems: []*ValidationProblem{{Code: imageProblemCodes[cause], Message: cause.Error()}}}
+	}
+	var problems []*ValidationProblem
+	for _, err := range artwork.Validate(img) {
+		problems = append(problems, &ValidationProblem{Code: imageProblemCodes[err], Message: err.Error()})
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return img, nil
+}
+
+// uploadImage reads, validates and saves an uploaded image as the image of the owner, and returns the error to show
+// to clients when it fails
+func (r *Resolver) uploadImage(ctx context.Context, owner store.ImageOwner, ownerID string, file *graphql.Upload) error {
+	img, err := readImage(file)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageInvalidImage).Error())
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			return wrapError(err, ErrorMessageInvalidImage+": "+err.Error())
+		}
+		return errors.New(ErrorMessageBadRequest + ": " + err.Error())
+	}
+	err = r.saveImage(ctx, owner, ownerID, img)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUploadFailed).Error())
+		return wrapError(err, ErrorMessageUploadFailed)
+	}
+	return nil
+}
+
+// saveImage stores the image and its thumbnails in the blob store under a random prefix, and replaces the image of
+// the owner with them; the blobs of the replaced image are deleted afterwards, and those of the new one when it cannot
+// be saved
+func (r *Resolver) saveImage(ctx context.Context, owner store.ImageOwner, ownerID string, img *artwork.Image) error {
+	sizes := make([]int, 0, len(thumbnailSizes))
+	for _, size := range thumbnailSizes {
+		sizes = append(sizes, size)
+	}
+	thumbnails, err := artwork.Thumbnails(img, sizes)
+	if err != nil {
+		return err
+	}
+
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	if err != nil {
+		return err
+	}
+	prefix := owner.KeyPrefix(ownerID) + hex.EncodeToString(id)
+	sized := map[model.ImageSize]*artwork.Image{model.ImageSizeOriginal: img}
+	for size, pixels := range thumbnailSizes {
+		if thumbnail, ok := thumbnails[pixels]; ok {
+			sized[size] = thumbnail
+		}
+	}
+
+	var images []*model.Image
+	var keys []string
+	for _, size := range model.AllImageSize {
+		resized, ok := sized[size]
+		if !ok {
+			continue
+		}
+		key := prefix + "-" + size.String() + artwork.Extension(img.ContentType)
+		if size == model.ImageSizeOriginal {
+			key = prefix + artwork.Extension(img.ContentType)
+		}
+		var url string
+		url, err = r.blobStore.Put(ctx, key, bytes.NewReader(resized.Data), resized.ContentType)
+		if err != nil {
+			break
+		}
+		keys = append(keys, key)
+		images = append(images, &model.Image{
+			Size:        size,
+			URL:         url,
+			Width:       resized.Width,
+			Height:      resized.Height,
+			ContentType: resized.ContentType,
+		})
+	}
+	if err == nil {
+		var replaced []string
+		replaced, err = r.imagesStore.Save(ctx, owner, ownerID, images)
+		if err == nil {
+			r.deleteImages(ctx, replaced)
+			return nil
+		}
+	}
+	for _, key := range keys {
+		r.deleteUpload(ctx, key)
+	}
+	return err
+}
+
+// pickImage returns the image of the size among the sizes of an image, or the original when it was not resized to
+// the size
+func pickImage(images []*model.Image, size *model.ImageSize) *model.Image {
+	var original *model.Image
+	for _, img := range images {
+		if size != nil && img.Size == *size {
+			return img
+		}
+		if img.Size == model.ImageSizeOriginal {
+			original = img
+		}
+	}
+	return original
+}
+
+// downsample reduces the peaks of the waveform to the resolution, keeping the lowest and highest peak of each part;
+// waveforms are never upsampled
+func downsample(waveform *model.Waveform, resolution int) {
+	n := waveform.Resolution
+	if resolution >= n {
+		return
+	}
+	min := make([]float64, resolution)
+	max := make([]float64, resolution)
+	for j := 0; j < resolution; j++ {
+		from, to := j*n/resolution, (j+1)*n/resolution
+		min[j], max[j] = waveform.Min[from], waveform.Max[from]
+		for i := from + 1; i < to; i++ {
+			min[j] = math.Min(min[j], waveform.Min[i])
+			max[j] = math.Max(max[j], waveform.Max[i])
+		}
+	}
+	waveform.Resolution, waveform.Min, waveform.Max = resolution, min, max
+}
+
+// contains reports whether the value is in the list
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -6,78 +6,450 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
+type AudioMetadata struct {
+	Duration   float64 `json:"duration"`
+	SampleRate int     `json:"sample_rate"`
+	Channels   int     `json:"channels"`
+	Bitrate    int     `json:"bitrate"`
+	Codec      string  `json:"codec"`
+}
+
 type AudioShort struct {
-	ID          string   `json:"id"`
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Status      Status   `json:"status"`
-	Category    Category `json:"category"`
-	AudioFile   string   `json:"audio_file"`
-	Creator     *Creator `json:"creator"`
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Status      Status         `json:"status"`
+	Category    *Category      `json:"category"`
+	AudioFile   string         `json:"audio_file"`
+	Creator     *Creator       `json:"creator"`
+	Version     int            `json:"version"`
+	Metadata    *AudioMetadata `json:"metadata"`
+	Waveform    *Waveform      `json:"waveform"`
+	HlsURL      *string        `json:"hls_url"`
+	Loudness    *Loudness      `json:"loudness"`
+	Transcript  *Transcript    `json:"transcript"`
+	Captions    *string        `json:"captions"`
+	Chapters    []*Chapter     `json:"chapters"`
+	ChaptersID3 *string        `json:"chapters_id3"`
+	CoverArt    *Image         `json:"cover_art"`
+	Tags        []string       `json:"tags"`
+}
+
+type AudioShortConnection struct {
+	Edges      []*AudioShortEdge `json:"edges"`
+	PageInfo   *PageInfo         `json:"pageInfo"`
+	TotalCount int               `json:"totalCount"`
+}
+
+type AudioShortDuplicate struct {
+	Node       *AudioShort `json:"node"`
+	Exact      bool        `json:"exact"`
+	Similarity float64     `json:"similarity"`
+}
+
+type AudioShortEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *AudioShort `json:"node"`
+}
+
+type AudioShortFilter struct {
+	Categories       []string   `json:"categories"`
+	Statuses         []Status   `json:"statuses"`
+	CreatorIds       []string   `json:"creator_ids"`
+	CreatedAfter     *time.Time `json:"created_after"`
+	CreatedBefore    *time.Time `json:"created_before"`
+	TitleContains    *string    `json:"title_contains"`
+	DuplicateFlagged *bool      `json:"duplicate_flagged"`
+	MostlySilent     *bool      `json:"mostly_silent"`
+	Tags             []string   `json:"tags"`
 }
 
 type AudioShortInput struct {
 	Title       string        `json:"title"`
 	Description string        `json:"description"`
-	Category    Category      `json:"category"`
+	Category    string        `json:"category"`
 	AudioFile   string        `json:"audio_file"`
 	Creator     *CreatorInput `json:"creator"`
 }
 
+type AudioShortOrder struct {
+	Field     AudioShortOrderField `json:"field"`
+	Direction OrderDirection       `json:"direction"`
+}
+
+type AudioShortPatch struct {
+	Title       *string       `json:"title"`
+	Description *string       `json:"description"`
+	Category    *string       `json:"category"`
+	AudioFile   *string       `json:"audio_file"`
+	Creator     *CreatorInput `json:"creator"`
+}
+
+type AudioShortSearchConnection struct {
+	Edges      []*AudioShortSearchEdge `json:"edges"`
+	PageInfo   *PageInfo               `json:"pageInfo"`
+	TotalCount int                     `json:"totalCount"`
+}
+
+type AudioShortSearchEdge struct {
+	Cursor               string      `json:"cursor"`
+	Node                 *AudioShort `json:"node"`
+	Rank                 float64     `json:"rank"`
+	TitleHighlight       string      `json:"title_highlight"`
+	DescriptionHighlight string      `json:"description_highlight"`
+}
+
+type AudioShortUploadInput struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	Creator     *CreatorInput `json:"creator"`
+}
+
+type CaptionCue struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+type CaptionCueInput struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+type Category struct {
+	ID       string           `json:"id"`
+	Slug     string           `json:"slug"`
+	Name     string           `json:"name"`
+	Names    []*LocalizedName `json:"names"`
+	Position int              `json:"position"`
+	Archived bool             `json:"archived"`
+}
+
+type CategoryInput struct {
+	Slug  string                `json:"slug"`
+	Name  string                `json:"name"`
+	Names []*LocalizedNameInput `json:"names"`
+}
+
+type Chapter struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Title    string  `json:"title"`
+	ImageURL *string `json:"image_url"`
+}
+
+type ChapterInput struct {
+	Start    float64 `json:"start"`
+	Title    string  `json:"title"`
+	ImageURL *string `json:"image_url"`
+}
+
 type Creator struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID       string        `json:"id"`
+	Username string        `json:"username"`
+	Name     string        `json:"name"`
+	Email    string        `json:"email"`
+	Status   CreatorStatus `json:"status"`
+	Avatar   *Image        `json:"avatar"`
+}
+
+type CreatorConnection struct {
+	Edges      []*CreatorEdge `json:"edges"`
+	PageInfo   *PageInfo      `json:"pageInfo"`
+	TotalCount int            `json:"totalCount"`
+}
+
+type CreatorDetailsInput struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+}
+
+type CreatorEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Creator `json:"node"`
 }
 
 type CreatorInput struct {
 	ID string `json:"id"`
 }
 
-type Category string
+type Image struct {
+	Size        ImageSize `json:"size"`
+	URL         string    `json:"url"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	ContentType string    `json:"content_type"`
+}
+
+type LocalizedName struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+}
+
+type LocalizedNameInput struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+}
+
+type Loudness struct {
+	Integrated   *float64 `json:"integrated"`
+	TruePeak     *float64 `json:"true_peak"`
+	SilenceRatio float64  `json:"silence_ratio"`
+	MostlySilent bool     `json:"mostly_silent"`
+	ReplayGain   float64  `json:"replay_gain"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+type Tag struct {
+	Name       string `json:"name"`
+	UsageCount int    `json:"usage_count"`
+}
+
+type TagConnection struct {
+	Edges      []*TagEdge `json:"edges"`
+	PageInfo   *PageInfo  `json:"pageInfo"`
+	TotalCount int        `json:"totalCount"`
+}
+
+type TagEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Tag   `json:"node"`
+}
+
+type Transcript struct {
+	Text string        `json:"text"`
+	Cues []*CaptionCue `json:"cues"`
+}
+
+type Waveform struct {
+	Status     WaveformStatus `json:"status"`
+	Resolution int            `json:"resolution"`
+	Min        []float64      `json:"min"`
+	Max        []float64      `json:"max"`
+}
+
+type AudioShortOrderField string
+
+const (
+	AudioShortOrderFieldCreatedAt AudioShortOrderField = "created_at"
+	AudioShortOrderFieldUpdatedAt AudioShortOrderField = "updated_at"
+	AudioShortOrderFieldTitle     AudioShortOrderField = "title"
+	AudioShortOrderFieldPlayCount AudioShortOrderField = "play_count"
+)
+
+var AllAudioShortOrderField = []AudioShortOrderField{
+	AudioShortOrderFieldCreatedAt,
+	AudioShortOrderFieldUpdatedAt,
+	AudioShortOrderFieldTitle,
+	AudioShortOrderFieldPlayCount,
+}
+
+func (e AudioShortOrderField) IsValid() bool {
+	switch e {
+	case AudioShortOrderFieldCreatedAt, AudioShortOrderFieldUpdatedAt, AudioShortOrderFieldTitle, AudioShortOrderFieldPlayCount:
+		return true
+	}
+	return false
+}
+
+func (e AudioShortOrderField) String() string {
+	return string(e)
+}
+
+func (e *AudioShortOrderField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AudioShortOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AudioShortOrderField", str)
+	}
+	return nil
+}
+
+func (e AudioShortOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CaptionFormat string
+
+const (
+	CaptionFormatVtt CaptionFormat = "vtt"
+	CaptionFormatSrt CaptionFormat = "srt"
+)
+
+var AllCaptionFormat = []CaptionFormat{
+	CaptionFormatVtt,
+	CaptionFormatSrt,
+}
+
+func (e CaptionFormat) IsValid() bool {
+	switch e {
+	case CaptionFormatVtt, CaptionFormatSrt:
+		return true
+	}
+	return false
+}
+
+func (e CaptionFormat) String() string {
+	return string(e)
+}
+
+func (e *CaptionFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CaptionFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CaptionFormat", str)
+	}
+	return nil
+}
+
+func (e CaptionFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CreatorStatus string
 
 const (
-	CategoryNews   Category = "news"
-	CategoryGossip Category = "gossip"
-	CategoryReview Category = "review"
-	CategoryStory  Category = "story"
+	CreatorStatusActive    CreatorStatus = "active"
+	CreatorStatusBanned    CreatorStatus = "banned"
+	CreatorStatusSuspended CreatorStatus = "suspended"
 )
 
-var AllCategory = []Category{
-	CategoryNews,
-	CategoryGossip,
-	CategoryReview,
-	CategoryStory,
+var AllCreatorStatus = []CreatorStatus{
+	CreatorStatusActive,
+	CreatorStatusBanned,
+	CreatorStatusSuspended,
 }
 
-func (e Category) IsValid() bool {
+func (e CreatorStatus) IsValid() bool {
 	switch e {
-	case CategoryNews, CategoryGossip, CategoryReview, CategoryStory:
+	case CreatorStatusActive, CreatorStatusBanned, CreatorStatusSuspended:
 		return true
 	}
 	return false
 }
 
-func (e Category) String() string {
+func (e CreatorStatus) String() string {
 	return string(e)
 }
 
-func (e *Category) UnmarshalGQL(v interface{}) error {
+func (e *CreatorStatus) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = Category(str)
+	*e = CreatorStatus(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid Category", str)
+		return fmt.Errorf("%s is not a valid CreatorStatus", str)
 	}
 	return nil
 }
 
-func (e Category) MarshalGQL(w io.Writer) {
+func (e CreatorStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImageSize string
+
+const (
+	ImageSizeSmall    ImageSize = "small"
+	ImageSizeMedium   ImageSize = "medium"
+	ImageSizeLarge    ImageSize = "large"
+	ImageSizeOriginal ImageSize = "original"
+)
+
+var AllImageSize = []ImageSize{
+	ImageSizeSmall,
+	ImageSizeMedium,
+	ImageSizeLarge,
+	ImageSizeOriginal,
+}
+
+func (e ImageSize) IsValid() bool {
+	switch e {
+	case ImageSizeSmall, ImageSizeMedium, ImageSizeLarge, ImageSizeOriginal:
+		return true
+	}
+	return false
+}
+
+func (e ImageSize) String() string {
+	return string(e)
+}
+
+func (e *ImageSize) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImageSize(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImageSize", str)
+	}
+	return nil
+}
+
+func (e ImageSize) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type OrderDirection string
+
+const (
+	OrderDirectionAsc  OrderDirection = "asc"
+	OrderDirectionDesc OrderDirection = "desc"
+)
+
+var AllOrderDirection = []OrderDirection{
+	OrderDirectionAsc,
+	OrderDirectionDesc,
+}
+
+func (e OrderDirection) IsValid() bool {
+	switch e {
+	case OrderDirectionAsc, OrderDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e OrderDirection) String() string {
+	return string(e)
+}
+
+func (e *OrderDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderDirection", str)
+	}
+	return nil
+}
+
+func (e OrderDirection) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
@@ -123,3 +495,50 @@ func (e *Status) UnmarshalGQL(v interface{}) error {
 func (e Status) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type WaveformStatus string
+
+const (
+	WaveformStatusPending     WaveformStatus = "pending"
+	WaveformStatusProcessing  WaveformStatus = "processing"
+	WaveformStatusReady       WaveformStatus = "ready"
+	WaveformStatusFailed      WaveformStatus = "failed"
+	WaveformStatusUnsupported WaveformStatus = "unsupported"
+)
+
+var AllWaveformStatus = []WaveformStatus{
+	WaveformStatusPending,
+	WaveformStatusProcessing,
+	WaveformStatusReady,
+	WaveformStatusFailed,
+	WaveformStatusUnsupported,
+}
+
+func (e WaveformStatus) IsValid() bool {
+	switch e {
+	case WaveformStatusPending, WaveformStatusProcessing, WaveformStatusReady, WaveformStatusFailed, WaveformStatusUnsupported:
+		return true
+	}
+	return false
+}
+
+func (e WaveformStatus) String() string {
+	return string(e)
+}
+
+func (e *WaveformStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WaveformStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WaveformStatus", str)
+	}
+	return nil
+}
+
+func (e WaveformStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
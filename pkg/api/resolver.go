@@ -1,15 +1,224 @@
 package api
 
-import "github.com/nooble/task/audio-short-api/pkg/store"
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/nooble/task/audio-short-api/pkg/api/generated"
+	"github.com/nooble/task/audio-short-api/pkg/playback"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+)
 
 //go:generate go run github.com/99designs/gqlgen
 
-// Resolver has reference to shortsStore and creatorsStore
+const (
+	// DefaultMaxUploadSize is the maximum size of uploaded files in bytes, unless set with WithUploadLimits
+	DefaultMaxUploadSize = 20 << 20
+	// uploadMemory is the part of multipart requests kept in memory, the rest is buffered to temporary files
+	uploadMemory = 1 << 20
+	// uploadOverhead is the size allowed for the other parts of multipart requests, e.g. the operations
+	uploadOverhead = 1 << 20
+	// fetchTimeout bounds the requests reading audio files that are referenced by URL rather than uploaded
+	fetchTimeout = 10 * time.Second
+)
+
+// Resolver has reference to shortsStore and creatorsStore, and to the optional dependencies set by its options
 type Resolver struct {
-	shortsStore   store.AudioShortsStore
-	creatorsStore store.CreatorsStore
+	shortsStore      store.AudioShortsStore
+	creatorsStore    store.CreatorsStore
+	blobStore        storage.BlobStore
+	waveformsStore   store.WaveformsStore
+	hlsStore         store.HLSStore
+	loudnessStore    store.LoudnessStore
+	transcriptsStore store.TranscriptsStore
+	chaptersStore    store.ChaptersStore
+	imagesStore      store.ImagesStore
+	tagsStore        store.TagsStore
+	categoriesStore  store.CategoriesStore
+	signer           *playback.Signer
+	streamingURL     string
+
+	maxUploadSize int64
+	uploadTypes   []string
+
+	// audio files are only validated with audio limits
+	validateAudio bool
+	minDuration   time.Duration
+	maxDuration   time.Duration
+	audioCodecs   []string
+	// httpClient reads the audio files referenced by URL, only from public addresses
+	httpClient *http.Client
+
+	// duplicates are only detected with a fingerprints store
+	fingerprintsStore   store.FingerprintsStore
+	rejectDuplicates    bool
+	duplicateSimilarity float64
+}
+
+// Option sets an optional dependency or setting of the resolver
+type Option func(r *Resolver)
+
+// WithBlobStore enables uploads, storing the files in the blob store
+func WithBlobStore(blobStore storage.BlobStore) Option {
+	return func(r *Resolver) {
+		r.blobStore = blobStore
+	}
+}
+
+// WithWaveforms makes uploads and updates of the audio file request the waveform of the short, generated in the
+// background, and exposes waveforms
+func WithWaveforms(waveformsStore store.WaveformsStore) Option {
+	return func(r *Resolver) {
+		r.waveformsStore = waveformsStore
+	}
+}
+
+// WithHLS makes uploads and updates of the audio file request the HLS package of the short, packaged in the
+// background, and exposes its playlist
+func WithHLS(hlsStore store.HLSStore) Option {
+	return func(r *Resolver) {
+		r.hlsStore = hlsStore
+	}
+}
+
+// WithLoudness makes uploads and updates of the audio file request the loudness analysis of the short, measured in the
+// background, and exposes its loudness
+func WithLoudness(loudnessStore store.LoudnessStore) Option {
+	return func(r *Resolver) {
+		r.loudnessStore = loudnessStore
+	}
+}
+
+// WithTranscripts enables transcripts, whose cues are set by mutations and rendered as captions
+func WithTranscripts(transcriptsStore store.TranscriptsStore) Option {
+	return func(r *Resolver) {
+		r.transcriptsStore = transcriptsStore
+	}
+}
+
+// WithChapters enables chapters, which are set by mutations and exposed as ID3 chapter frames as well
+func WithChapters(chaptersStore store.ChaptersStore) Option {
+	return func(r *Resolver) {
+		r.chaptersStore = chaptersStore
+	}
+}
+
+// WithArtwork enables cover art for shorts and avatars for creators, uploaded to the blob store with their thumbnails
+func WithArtwork(imagesStore store.ImagesStore) Option {
+	return func(r *Resolver) {
+		r.imagesStore = imagesStore
+	}
+}
+
+// WithTags enables tags, which are set by mutations, counted by usage and autocompleted
+func WithTags(tagsStore store.TagsStore) Option {
+	return func(r *Resolver) {
+		r.tagsStore = tagsStore
+	}
+}
+
+// WithCategories enables the admin mutations of categories and their localized names
+func WithCategories(categoriesStore store.CategoriesStore) Option {
+	return func(r *Resolver) {
+		r.categoriesStore = categoriesStore
+	}
+}
+
+// WithPlayback makes the audio file and HLS playlist of shorts in the blob store resolve to playback URLs signed for
+// the listener of the request, kept in its context by playback.Middleware
+func WithPlayback(signer *playback.Signer) Option {
+	return func(r *Resolver) {
+		r.signer = signer
+	}
+}
+
+// WithStreaming makes the audio file and HLS playlist of shorts in the blob store resolve to their unsigned URLs under
+// the base URL of the streaming handler, e.g. http://localhost:8080/audio, rather than where they are stored, so that
+// the visibility of shorts applies to their audio; signed playback URLs take precedence
+func WithStreaming(baseURL string) Option {
+	return func(r *Resolver) {
+		r.streamingURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// ImageKeyPrefixes returns the prefixes of the blob keys of cover art and avatars, which are served as stored, unlike
+// audio files
+func ImageKeyPrefixes() []string {
+	return []string{store.CoverArt.KeyPrefix(""), store.Avatar.KeyPrefix("")}
+}
+
+// WithUploadLimits sets the maximum size in bytes and the allowed content types of uploaded files
+func WithUploadLimits(maxSize int64, contentTypes []string) Option {
+	return func(r *Resolver) {
+		r.maxUploadSize = maxSize
+		r.uploadTypes = contentTypes
+	}
 }
 
-func New(shortsStore store.AudioShortsStore, creatorsStore store.CreatorsStore) (*Resolver, error) {
-	return &Resolver{shortsStore: shortsStore, creatorsStore: creatorsStore}, nil
+// WithAudioLimits makes creates, updates and uploads probe the audio file of the short, and reject it when it cannot be
+// read or parsed, uses a codec other than the given ones, or lasts less than minDuration or more than maxDuration
+func WithAudioLimits(minDuration, maxDuration time.Duration, codecs []string) Option {
+	return func(r *Resolver) {
+		r.validateAudio = true
+		r.minDuration = minDuration
+		r.maxDuration = maxDuration
+		r.audioCodecs = codecs
+	}
+}
+
+// WithDuplicateDetection makes creates, uploads and updates of the audio file fingerprint the audio file of the short,
+// and reject it, or flag it for moderators when reject is false, when it is the same file as that of a short of another
+// creator or sounds at least as similar as the similarity, from 0 to 1
+func WithDuplicateDetection(fingerprintsStore store.FingerprintsStore, reject bool, similarity float64) Option {
+	return func(r *Resolver) {
+		r.fingerprintsStore = fingerprintsStore
+		r.rejectDuplicates = reject
+		r.duplicateSimilarity = similarity
+	}
+}
+
+func New(shortsStore store.AudioShortsStore, creatorsStore store.CreatorsStore, opts ...Option) (*Resolver, error) {
+	r := &Resolver{
+		shortsStore:   shortsStore,
+		creatorsStore: creatorsStore,
+		maxUploadSize: DefaultMaxUploadSize,
+		httpClient:    storage.NewPublicClient(fetchTimeout),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// NewServer returns the GraphQL server for the resolver, presenting store errors with their error code;
+// it is set up like handler.NewDefaultServer, with multipart requests limited to the upload size
+func NewServer(resolver *Resolver) *handler.Server {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{
+		MaxUploadSize: resolver.maxUploadSize + uploadOverhead,
+		MaxMemory:     uploadMemory,
+	})
+
+	srv.SetQueryCache(lru.New(1000))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+
+	srv.SetErrorPresenter(ErrorPresenter)
+	return srv
 }
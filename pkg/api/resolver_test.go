@@ -1,11 +1,30 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/99designs/gqlgen/client"
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/golang/mock/gomock"
-	"github.com/nooble/task/audio-short-api/pkg/api/generated"
 	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/audio"
+	"github.com/nooble/task/audio-short-api/pkg/playback"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
 	"github.com/nooble/task/audio-short-api/pkg/store"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -17,32 +36,38 @@ func TestQueryResolver_GetAudioShort(t *testing.T) {
 	mockStore := store.NewMockAudioShortsStore(ctrl)
 	resolver, err := New(mockStore, nil)
 	assert.NoError(t, err)
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
+	c := client.New(NewServer(resolver))
 
 	short := &model.AudioShort{
 		ID:          "1",
 		Title:       "abc",
 		Description: "abcs",
-		Category:    model.CategoryNews,
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
 		AudioFile:   "a",
 		Creator:     &model.Creator{},
+		Version:     2,
 	}
 
 	t.Run("happy path", func(t *testing.T) {
 		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
 		var resp struct {
-			GetAudioShort struct{ Title, Description string }
+			GetAudioShort struct {
+				Title, Description string
+				Version            int
+			}
 		}
 		q := `
 		query {
 			getAudioShort(id: "1") {
 				title,
-				description
+				description,
+				version
 			}
 		}`
 		c.MustPost(q, &resp)
 		assert.Equal(t, "abc", resp.GetAudioShort.Title)
 		assert.Equal(t, "abcs", resp.GetAudioShort.Description)
+		assert.Equal(t, 2, resp.GetAudioShort.Version)
 	})
 
 	t.Run("sad path - no args", func(t *testing.T) {
@@ -61,6 +86,41 @@ func TestQueryResolver_GetAudioShort(t *testing.T) {
 		})
 	})
 
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+		var resp struct {
+			GetAudioShort struct{ Title, Description string }
+		}
+		q := `
+		query {
+			getAudioShort(id: "1") {
+				title,
+				description
+			}
+		}`
+		err := c.Post(q, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+		assert.NotContains(t, err.Error(), "some error")
+	})
+
+	t.Run("sad path - unavailable", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, errors.Wrap(&store.UnavailableError{Err: errors.New("some error")}, "wrapped"))
+		var resp struct {
+			GetAudioShort struct{ Title, Description string }
+		}
+		q := `
+		query {
+			getAudioShort(id: "1") {
+				title,
+				description
+			}
+		}`
+		err := c.Post(q, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+
 	t.Run("sad path - error", func(t *testing.T) {
 		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, errors.New("some error"))
 		var resp struct {
@@ -79,228 +139,3322 @@ func TestQueryResolver_GetAudioShort(t *testing.T) {
 	})
 }
 
+func TestAudioShortResolver_Waveform(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockWaveforms := store.NewMockWaveformsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithWaveforms(mockWaveforms))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	waveform := func() *model.Waveform {
+		return &model.Waveform{
+			Status:     model.WaveformStatusReady,
+			Resolution: 5,
+			Min:        []float64{-0.5, -0.25, -1, 0, -0.75},
+			Max:        []float64{0.5, 0.75, 0.25, 1, 0},
+		}
+	}
+	type response struct {
+		GetAudioShort struct {
+			Waveform *model.Waveform
+		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockWaveforms.EXPECT().Get(gomock.Any(), "1").Return(waveform(), nil)
+		var resp response
+		c.MustPost(`query { getAudioShort(id: "1") { waveform { status, resolution, min, max } } }`, &resp)
+		assert.Equal(t, waveform(), resp.GetAudioShort.Waveform)
+	})
+
+	t.Run("happy path - downsampled", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockWaveforms.EXPECT().Get(gomock.Any(), "1").Return(waveform(), nil)
+		var resp response
+		c.MustPost(`query { getAudioShort(id: "1") { waveform(resolution: 2) { status, resolution, min, max } } }`, &resp)
+		assert.Equal(t, &model.Waveform{
+			Status:     model.WaveformStatusReady,
+			Resolution: 2,
+			Min:        []float64{-0.5, -1},
+			Max:        []float64{0.75, 1},
+		}, resp.GetAudioShort.Waveform)
+	})
+
+	t.Run("happy path - not upsampled", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockWaveforms.EXPECT().Get(gomock.Any(), "1").Return(waveform(), nil)
+		var resp response
+		c.MustPost(`query { getAudioShort(id: "1") { waveform(resolution: 10) { status, resolution, min, max } } }`, &resp)
+		assert.Equal(t, waveform(), resp.GetAudioShort.Waveform)
+	})
+
+	t.Run("happy path - not requested", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockWaveforms.EXPECT().Get(gomock.Any(), "1").Return(nil, nil)
+		var resp response
+		c.MustPost(`query { getAudioShort(id: "1") { waveform { status } } }`, &resp)
+		assert.Nil(t, resp.GetAudioShort.Waveform)
+	})
+
+	t.Run("sad path - invalid resolution", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		var resp response
+		err := c.Post(`query { getAudioShort(id: "1") { waveform(resolution: 0) { status } } }`, &resp)
+		assert.Contains(t, err.Error(), ErrorMessageBadRequest+": "+ErrorMessageInvalidResolution)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockWaveforms.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+		var resp response
+		err := c.Post(`query { getAudioShort(id: "1") { waveform { status } } }`, &resp)
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestAudioShortResolver_AudioFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	keys, err := playback.ParseKeys([]string{"1:secret"})
+	assert.NoError(t, err)
+	signer, err := playback.NewSigner("http://localhost:8080/audio", keys, time.Hour, true)
+	assert.NoError(t, err)
+	resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithPlayback(signer))
+	assert.NoError(t, err)
+	c := client.New(playback.Middleware(NewServer(resolver)))
+
+	var resp struct {
+		GetAudioShort struct {
+			AudioFile string `json:"audio_file"`
+		}
+	}
+	q := `query { getAudioShort(id: "1") { audio_file } }`
+
+	t.Run("happy path - signed", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(
+			&model.AudioShort{ID: "1", AudioFile: "http://localhost:8080/files/shorts/abc.mp3", Creator: &model.Creator{}}, nil)
+
+		c.MustPost(q, &resp)
+
+		u, err := url.Parse(resp.GetAudioShort.AudioFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "/audio/1", u.Path)
+		// httptest requests come from 192.0.2.1
+		assert.NoError(t, signer.Verify("1", u.Query(), "192.0.2.1"))
+	})
+
+	t.Run("happy path - external URL", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(
+			&model.AudioShort{ID: "1", AudioFile: "https://example.com/a.mp3", Creator: &model.Creator{}}, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, "https://example.com/a.mp3", resp.GetAudioShort.AudioFile)
+	})
+
+	t.Run("happy path - streamed", func(t *testing.T) {
+		resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithStreaming("http://localhost:8080/audio/"))
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(
+			&model.AudioShort{ID: "1", AudioFile: "http://localhost:8080/files/shorts/abc.mp3", Creator: &model.Creator{}}, nil)
+
+		client.New(NewServer(resolver)).MustPost(q, &resp)
+
+		assert.Equal(t, "http://localhost:8080/audio/1", resp.GetAudioShort.AudioFile)
+	})
+}
+
+func TestAudioShortResolver_HlsURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockHLS := store.NewMockHLSStore(ctrl)
+	resolver, err := New(mockStore, nil, WithHLS(mockHLS))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	var resp struct {
+		GetAudioShort struct {
+			HlsURL *string `json:"hls_url"`
+		}
+	}
+	q := `query { getAudioShort(id: "1") { hls_url } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(
+			&store.HLSPackage{Status: store.HLSStatusReady, PlaylistURL: "http://localhost:8080/files/hls/1/index.m3u8", Segments: 2}, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, "http://localhost:8080/files/hls/1/index.m3u8", *resp.GetAudioShort.HlsURL)
+	})
+
+	t.Run("happy path - signed", func(t *testing.T) {
+		keys, err := playback.ParseKeys([]string{"1:secret"})
+		assert.NoError(t, err)
+		signer, err := playback.NewSigner("http://localhost:8080/audio", keys, time.Hour, false)
+		assert.NoError(t, err)
+		resolver, err := New(mockStore, nil, WithHLS(mockHLS), WithPlayback(signer))
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(
+			&store.HLSPackage{Status: store.HLSStatusReady, PlaylistURL: "http://localhost:8080/files/hls/1/index.m3u8", Segments: 2}, nil)
+
+		client.New(NewServer(resolver)).MustPost(q, &resp)
+
+		u, err := url.Parse(*resp.GetAudioShort.HlsURL)
+		assert.NoError(t, err)
+		assert.Equal(t, "/audio/1/hls/index.m3u8", u.Path)
+		assert.NoError(t, signer.Verify("1", u.Query(), ""))
+	})
+
+	t.Run("happy path - streamed", func(t *testing.T) {
+		resolver, err := New(mockStore, nil, WithHLS(mockHLS), WithStreaming("http://localhost:8080/audio"))
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(
+			&store.HLSPackage{Status: store.HLSStatusReady, PlaylistURL: "http://localhost:8080/files/hls/1/index.m3u8", Segments: 2}, nil)
+
+		client.New(NewServer(resolver)).MustPost(q, &resp)
+
+		assert.Equal(t, "http://localhost:8080/audio/1/hls/index.m3u8", *resp.GetAudioShort.HlsURL)
+	})
+
+	t.Run("happy path - not ready", func(t *testing.T) {
+		for _, pkg := range []*store.HLSPackage{nil, {Status: store.HLSStatusPending}, {Status: store.HLSStatusUnsupported}} {
+			mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+			mockHLS.EXPECT().Get(gomock.Any(), "1").Return(pkg, nil)
+
+			c.MustPost(q, &resp)
+
+			assert.Nil(t, resp.GetAudioShort.HlsURL)
+		}
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestAudioShortResolver_Loudness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockLoudness := store.NewMockLoudnessStore(ctrl)
+	resolver, err := New(mockStore, nil, WithLoudness(mockLoudness))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	var resp struct {
+		GetAudioShort struct {
+			Loudness *model.Loudness
+		}
+	}
+	q := `query { getAudioShort(id: "1") { loudness { integrated, true_peak, silence_ratio, mostly_silent, replay_gain } } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		integrated, truePeak := -23.0, -3.5
+		loudness := &model.Loudness{Integrated: &integrated, TruePeak: &truePeak, SilenceRatio: 0.1, ReplayGain: 2.5}
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockLoudness.EXPECT().Get(gomock.Any(), "1").Return(loudness, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, loudness, resp.GetAudioShort.Loudness)
+	})
+
+	t.Run("happy path - silence", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockLoudness.EXPECT().Get(gomock.Any(), "1").Return(&model.Loudness{SilenceRatio: 1, MostlySilent: true}, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, &model.Loudness{SilenceRatio: 1, MostlySilent: true}, resp.GetAudioShort.Loudness)
+	})
+
+	t.Run("happy path - not measured", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockLoudness.EXPECT().Get(gomock.Any(), "1").Return(nil, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Nil(t, resp.GetAudioShort.Loudness)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockLoudness.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestAudioShortResolver_Transcript(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockTranscripts := store.NewMockTranscriptsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithTranscripts(mockTranscripts))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	transcript := &model.Transcript{
+		Text: "Hello\nworld",
+		Cues: []*model.CaptionCue{{Start: 0.5, End: 1.5, Text: "Hello"}, {Start: 1.5, End: 2.25, Text: "world"}},
+	}
+	var resp struct {
+		GetAudioShort struct {
+			Transcript *model.Transcript
+			VTT        *string
+			SRT        *string
+		}
+	}
+	q := `query { getAudioShort(id: "1") { transcript { text, cues { start, end, text } }, vtt: captions, srt: captions(format: srt) } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Get(gomock.Any(), "1").Return(transcript, nil).Times(3)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, transcript, resp.GetAudioShort.Transcript)
+		assert.Equal(t, "WEBVTT\n\n00:00:00.500 --> 00:00:01.500\nHello\n\n00:00:01.500 --> 00:00:02.250\nworld\n", *resp.GetAudioShort.VTT)
+		assert.Equal(t, "1\n00:00:00,500 --> 00:00:01,500\nHello\n\n2\n00:00:01,500 --> 00:00:02,250\nworld\n", *resp.GetAudioShort.SRT)
+	})
+
+	t.Run("happy path - not set", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Get(gomock.Any(), "1").Return(nil, nil).Times(3)
+
+		c.MustPost(q, &resp)
+
+		assert.Nil(t, resp.GetAudioShort.Transcript)
+		assert.Nil(t, resp.GetAudioShort.VTT)
+		assert.Nil(t, resp.GetAudioShort.SRT)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(`query { getAudioShort(id: "1") { captions } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestAudioShortResolver_Chapters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockChapters := store.NewMockChaptersStore(ctrl)
+	resolver, err := New(mockStore, nil, WithChapters(mockChapters))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	imageURL := "https://example.com/outro.png"
+	saved := []*model.Chapter{
+		{Start: 0, End: 1.5, Title: "Intro"},
+		{Start: 1.5, End: 2.25, Title: "Outro", ImageURL: &imageURL},
+	}
+	var resp struct {
+		GetAudioShort struct {
+			Chapters    []*model.Chapter
+			ChaptersID3 *string `json:"chapters_id3"`
+		}
+	}
+	q := `query { getAudioShort(id: "1") { chapters { start, end, title, image_url }, chapters_id3 } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockChapters.EXPECT().Get(gomock.Any(), "1").Return(saved, nil).Times(2)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, saved, resp.GetAudioShort.Chapters)
+		assert.JSONEq(t, `{
+			"CTOC": {"element_id": "toc", "top_level": true, "ordered": true, "child_element_ids": ["chp1", "chp2"]},
+			"CHAP": [
+				{
+					"element_id": "chp1", "start_time": 0, "end_time": 1500,
+					"start_offset": 4294967295, "end_offset": 4294967295,
+					"sub_frames": {"TIT2": "Intro"}
+				},
+				{
+					"element_id": "chp2", "start_time": 1500, "end_time": 2250,
+					"start_offset": 4294967295, "end_offset": 4294967295,
+					"sub_frames": {
+						"TIT2": "Outro",
+						"APIC": {"mime_type": "-->", "picture_type": 0, "description": "", "data": "https://example.com/outro.png"}
+					}
+				}
+			]
+		}`, *resp.GetAudioShort.ChaptersID3)
+	})
+
+	t.Run("happy path - not set", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockChapters.EXPECT().Get(gomock.Any(), "1").Return([]*model.Chapter{}, nil).Times(2)
+
+		c.MustPost(q, &resp)
+
+		assert.Empty(t, resp.GetAudioShort.Chapters)
+		assert.Nil(t, resp.GetAudioShort.ChaptersID3)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockChapters.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(`query { getAudioShort(id: "1") { chapters { title } } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestAudioShortResolver_CoverArt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockImages := store.NewMockImagesStore(ctrl)
+	resolver, err := New(mockStore, nil, WithArtwork(mockImages))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	original := &model.Image{Size: model.ImageSizeOriginal, URL: "http://localhost:8080/files/covers/1/a.png", Width: 300,
+		Height: 150, ContentType: "image/png"}
+	small := &model.Image{Size: model.ImageSizeSmall, URL: "http://localhost:8080/files/covers/1/a-small.png", Width: 64,
+		Height: 32, ContentType: "image/png"}
+	var resp struct {
+		GetAudioShort struct {
+			Original *model.Image
+			Small    *model.Image
+			Large    *model.Image
+		}
+	}
+	q := `
+	query {
+		getAudioShort(id: "1") {
+			original: cover_art { size, url, width, height, content_type }
+			small: cover_art(size: small) { size, url, width, height, content_type }
+			large: cover_art(size: large) { size, url, width, height, content_type }
+		}
+	}`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Get(gomock.Any(), store.CoverArt, "1").Return([]*model.Image{small, original}, nil).Times(3)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, original, resp.GetAudioShort.Original)
+		assert.Equal(t, small, resp.GetAudioShort.Small)
+		// images smaller than the size are not resized to it
+		assert.Equal(t, original, resp.GetAudioShort.Large)
+	})
+
+	t.Run("happy path - not set", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Get(gomock.Any(), store.CoverArt, "1").Return([]*model.Image{}, nil).Times(3)
+
+		c.MustPost(q, &resp)
+
+		assert.Nil(t, resp.GetAudioShort.Original)
+		assert.Nil(t, resp.GetAudioShort.Small)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Get(gomock.Any(), store.CoverArt, "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(`query { getAudioShort(id: "1") { cover_art { url } } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestAudioShortResolver_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockTags := store.NewMockTagsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithTags(mockTags))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	var resp struct {
+		GetAudioShort struct{ Tags []string }
+	}
+	q := `query { getAudioShort(id: "1") { tags } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTags.EXPECT().Get(gomock.Any(), "1").Return([]string{"covid", "health"}, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, []string{"covid", "health"}, resp.GetAudioShort.Tags)
+	})
+
+	t.Run("happy path - tags disabled", func(t *testing.T) {
+		resolver, err := New(mockStore, nil)
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		client.New(NewServer(resolver)).MustPost(q, &resp)
+
+		assert.Equal(t, []string{}, resp.GetAudioShort.Tags)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTags.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
 func TestQueryResolver_GetAudioShorts(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockStore := store.NewMockAudioShortsStore(ctrl)
 	resolver, err := New(mockStore, nil)
 	assert.NoError(t, err)
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
+	c := client.New(NewServer(resolver))
 
 	short := &model.AudioShort{
 		ID:          "1",
 		Title:       "abc",
 		Description: "abcs",
-		Category:    model.CategoryNews,
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
 		AudioFile:   "a",
 		Creator:     &model.Creator{},
 	}
+	cursor := store.Cursor{Key: "created_at_desc", Value: "2021-01-02 03:04:05+00", ID: "1"}
+	endCursor := store.EncodeCursor(cursor)
+	conn := &model.AudioShortConnection{
+		Edges:      []*model.AudioShortEdge{{Cursor: endCursor, Node: short}},
+		PageInfo:   &model.PageInfo{HasNextPage: true, EndCursor: &endCursor},
+		TotalCount: 2,
+	}
 
-	t.Run("happy path", func(t *testing.T) {
-		mockStore.EXPECT().GetAll(gomock.Any(), uint16(0), uint16(1)).Return([]*model.AudioShort{short}, nil)
-		var resp struct {
-			GetAudioShorts []struct{ Title, Description string }
+	type response struct {
+		GetAudioShorts struct {
+			Edges []struct {
+				Cursor string
+				Node   struct{ Title, Description string }
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   string
+			}
+			TotalCount int
 		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), nil, nil, nil, nil).Return(conn, nil)
+		var resp response
 		q := `
 		query {
-			getAudioShorts(page: 1, limit: 1) {
-				title,
-				description
+			getAudioShorts(first: 1) {
+				edges { cursor, node { title, description } }
+				pageInfo { hasNextPage, endCursor }
+				totalCount
 			}
 		}`
 		c.MustPost(q, &resp)
-		assert.Equal(t, "abc", resp.GetAudioShorts[0].Title)
-		assert.Equal(t, "abcs", resp.GetAudioShorts[0].Description)
+		assert.Equal(t, "abc", resp.GetAudioShorts.Edges[0].Node.Title)
+		assert.Equal(t, "abcs", resp.GetAudioShorts.Edges[0].Node.Description)
+		assert.Equal(t, endCursor, resp.GetAudioShorts.Edges[0].Cursor)
+		assert.True(t, resp.GetAudioShorts.PageInfo.HasNextPage)
+		assert.Equal(t, endCursor, resp.GetAudioShorts.PageInfo.EndCursor)
+		assert.Equal(t, 2, resp.GetAudioShorts.TotalCount)
 	})
 
 	t.Run("happy path - default args", func(t *testing.T) {
-		mockStore.EXPECT().GetAll(gomock.Any(), uint16(0), uint16(10)).Return([]*model.AudioShort{short}, nil)
-		var resp struct {
-			GetAudioShorts []struct{ Title, Description string }
-		}
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(10), nil, nil, nil, nil).Return(conn, nil)
+		var resp response
 		q := `
 		query {
 			getAudioShorts {
+				edges { node { title, description } }
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "abc", resp.GetAudioShorts.Edges[0].Node.Title)
+	})
+
+	t.Run("happy path - after cursor", func(t *testing.T) {
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), &cursor, nil, nil, nil).Return(conn, nil)
+		var resp response
+		q := `
+		query($after: String) {
+			getAudioShorts(first: 1, after: $after) {
+				edges { node { title, description } }
+			}
+		}`
+		c.MustPost(q, &resp, client.Var("after", endCursor))
+		assert.Equal(t, "abc", resp.GetAudioShorts.Edges[0].Node.Title)
+	})
+
+	t.Run("happy path - filter and order", func(t *testing.T) {
+		titleContains := "covid"
+		filter := &model.AudioShortFilter{
+			Categories:    []string{"news"},
+			CreatorIds:    []string{"1", "2", "3"},
+			TitleContains: &titleContains,
+		}
+		orderBy := &model.AudioShortOrder{
+			Field:     model.AudioShortOrderFieldPlayCount,
+			Direction: model.OrderDirectionDesc,
+		}
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(3), nil, filter, orderBy, nil).Return(conn, nil)
+		var resp response
+		q := `
+		query {
+			getAudioShorts(
+				first: 3,
+				filter: { categories: ["news"], creator_ids: ["1", "2", "3"], title_contains: "covid" },
+				orderBy: { field: play_count, direction: desc }
+			) {
+				edges { node { title, description } }
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "abc", resp.GetAudioShorts.Edges[0].Node.Title)
+	})
+
+	t.Run("happy path - filter by tags", func(t *testing.T) {
+		filter := &model.AudioShortFilter{Tags: []string{"new york", "covid"}}
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), nil, filter, nil, nil).Return(conn, nil)
+		var resp response
+		q := `
+		query {
+			getAudioShorts(first: 1, filter: { tags: ["New  York", "covid", "COVID"] }) {
+				edges { node { title, description } }
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "abc", resp.GetAudioShorts.Edges[0].Node.Title)
+	})
+
+	t.Run("sad path - invalid tag filter", func(t *testing.T) {
+		var resp response
+		q := `
+		query {
+			getAudioShorts(first: 1, filter: { tags: ["#covid"] }) {
+				totalCount
+			}
+		}`
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorMessageInvalidTags)
+	})
+
+	t.Run("happy path - include statuses", func(t *testing.T) {
+		includeStatuses := []model.Status{model.StatusDeleted, model.StatusBanned}
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), nil, nil, nil, includeStatuses).Return(conn, nil)
+		var resp response
+		q := `
+		query {
+			getAudioShorts(first: 1, includeStatuses: [deleted, banned]) {
+				edges { node { title, description } }
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "abc", resp.GetAudioShorts.Edges[0].Node.Title)
+	})
+
+	t.Run("sad path - cursor of another ordering", func(t *testing.T) {
+		var resp response
+		q := `
+		query($after: String) {
+			getAudioShorts(first: 1, after: $after, orderBy: { field: title, direction: asc }) {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp, client.Var("after", endCursor))
+		})
+	})
+
+	t.Run("sad path - first is 0", func(t *testing.T) {
+		var resp response
+		q := `
+		query {
+			getAudioShorts(first: 0) {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+
+	t.Run("sad path - invalid cursor", func(t *testing.T) {
+		var resp response
+		q := `
+		query {
+			getAudioShorts(first: 1, after: "not a cursor") {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), nil, nil, nil, nil).Return(nil, errors.New("some error"))
+		var resp response
+		q := `
+		query {
+			getAudioShorts(first: 1) {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+}
+
+func TestQueryResolver_SearchAudioShorts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "covid news",
+		Description: "abcs",
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+	endCursor := store.EncodeCursor(store.Cursor{Key: store.SearchOrderKey, Value: "0.5", ID: "1"})
+	results := &model.AudioShortSearchConnection{
+		Edges: []*model.AudioShortSearchEdge{{
+			Cursor:               endCursor,
+			Node:                 short,
+			Rank:                 0.5,
+			TitleHighlight:       "<mark>covid</mark> news",
+			DescriptionHighlight: "abcs",
+		}},
+		PageInfo:   &model.PageInfo{EndCursor: &endCursor},
+		TotalCount: 1,
+	}
+
+	type response struct {
+		SearchAudioShorts struct {
+			Edges []struct {
+				Node           struct{ Title string }
+				Rank           float64
+				TitleHighlight string `json:"title_highlight"`
+			}
+			TotalCount int
+		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Search(gomock.Any(), "covid", uint16(10), nil).Return(results, nil)
+		var resp response
+		q := `
+		query {
+			searchAudioShorts(query: "covid") {
+				edges { node { title }, rank, title_highlight }
+				totalCount
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "covid news", resp.SearchAudioShorts.Edges[0].Node.Title)
+		assert.Equal(t, 0.5, resp.SearchAudioShorts.Edges[0].Rank)
+		assert.Equal(t, "<mark>covid</mark> news", resp.SearchAudioShorts.Edges[0].TitleHighlight)
+		assert.Equal(t, 1, resp.SearchAudioShorts.TotalCount)
+	})
+
+	t.Run("sad path - blank query", func(t *testing.T) {
+		var resp response
+		q := `
+		query {
+			searchAudioShorts(query: "  ") {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().Search(gomock.Any(), "covid", uint16(10), nil).Return(nil, errors.New("some error"))
+		var resp response
+		q := `
+		query {
+			searchAudioShorts(query: "covid") {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+}
+
+func TestQueryResolver_GetCreators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockCreatorsStore(ctrl)
+	resolver, err := New(nil, mockStore)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	creator := &model.Creator{
+		ID:    "1",
+		Name:  "hi",
+		Email: "mockemail@gmail.com",
+	}
+	endCursor := store.EncodeCursor(store.Cursor{Key: store.CreatorsOrderKey, Value: "2021-01-02 03:04:05+00", ID: "1"})
+	conn := &model.CreatorConnection{
+		Edges:      []*model.CreatorEdge{{Cursor: endCursor, Node: creator}},
+		PageInfo:   &model.PageInfo{EndCursor: &endCursor},
+		TotalCount: 1,
+	}
+
+	type response struct {
+		GetCreators struct {
+			Edges []struct {
+				Node struct{ ID, Name string }
+			}
+			PageInfo struct {
+				HasNextPage bool
+			}
+			TotalCount int
+		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), nil).Return(conn, nil)
+		var resp response
+		q := `
+		query {
+			getCreators(first: 1) {
+				edges { node { id, name } }
+				pageInfo { hasNextPage }
+				totalCount
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "1", resp.GetCreators.Edges[0].Node.ID)
+		assert.Equal(t, "hi", resp.GetCreators.Edges[0].Node.Name)
+		assert.False(t, resp.GetCreators.PageInfo.HasNextPage)
+		assert.Equal(t, 1, resp.GetCreators.TotalCount)
+	})
+
+	t.Run("happy path - default args", func(t *testing.T) {
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(10), nil).Return(conn, nil)
+		var resp response
+		q := `
+		query {
+			getCreators {
+				edges { node { id, name } }
+			}
+		}`
+		c.MustPost(q, &resp)
+		assert.Equal(t, "1", resp.GetCreators.Edges[0].Node.ID)
+		assert.Equal(t, "hi", resp.GetCreators.Edges[0].Node.Name)
+	})
+
+	t.Run("sad path - first is 0", func(t *testing.T) {
+		var resp response
+		q := `
+		query {
+			getCreators(first: 0) {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().GetAll(gomock.Any(), uint16(1), nil).Return(nil, errors.New("some error"))
+		var resp response
+		q := `
+		query {
+			getCreators(first: 1) {
+				totalCount
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(q, &resp)
+		})
+	})
+}
+
+func TestQueryResolver_GetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTags := store.NewMockTagsStore(ctrl)
+	resolver, err := New(nil, nil, WithTags(mockTags))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	cursor := store.EncodeCursor(store.Cursor{Key: store.TagsOrderKey, Value: "5", ID: "3"})
+	conn := &model.TagConnection{
+		Edges:      []*model.TagEdge{{Cursor: cursor, Node: &model.Tag{Name: "covid", UsageCount: 5}}},
+		PageInfo:   &model.PageInfo{EndCursor: &cursor, HasNextPage: true},
+		TotalCount: 4,
+	}
+	type response struct {
+		GetTags struct {
+			Edges []struct {
+				Node struct {
+					Name       string
+					UsageCount int `json:"usage_count"`
+				}
+			}
+			PageInfo struct {
+				HasNextPage bool
+			}
+			TotalCount int
+		}
+	}
+	q := `
+	query ($after: String) {
+		getTags(first: 1, after: $after) {
+			edges { node { name, usage_count } }
+			pageInfo { hasNextPage }
+			totalCount
+		}
+	}`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockTags.EXPECT().GetAll(gomock.Any(), uint16(1), nil).Return(conn, nil)
+		var resp response
+
+		c.MustPost(q, &resp)
+
+		assert.Equal(t, "covid", resp.GetTags.Edges[0].Node.Name)
+		assert.Equal(t, 5, resp.GetTags.Edges[0].Node.UsageCount)
+		assert.True(t, resp.GetTags.PageInfo.HasNextPage)
+		assert.Equal(t, 4, resp.GetTags.TotalCount)
+	})
+
+	t.Run("happy path - after cursor", func(t *testing.T) {
+		after := &store.Cursor{Key: store.TagsOrderKey, Value: "5", ID: "3"}
+		mockTags.EXPECT().GetAll(gomock.Any(), uint16(1), after).Return(conn, nil)
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("after", cursor))
+
+		assert.Equal(t, "covid", resp.GetTags.Edges[0].Node.Name)
+	})
+
+	t.Run("sad path - cursor of creators", func(t *testing.T) {
+		var resp response
+		creators := store.EncodeCursor(store.Cursor{Key: store.CreatorsOrderKey, Value: "2021-01-02 03:04:05+00", ID: "1"})
+
+		err := c.Post(q, &resp, client.Var("after", creators))
+
+		assert.Contains(t, err.Error(), ErrorMessageBadRequest)
+	})
+
+	t.Run("sad path - tags disabled", func(t *testing.T) {
+		resolver, err := New(nil, nil)
+		assert.NoError(t, err)
+		var resp response
+
+		err = client.New(NewServer(resolver)).Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorMessageTagsDisabled)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockTags.EXPECT().GetAll(gomock.Any(), uint16(1), nil).Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+		var resp response
+
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestQueryResolver_AutocompleteTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTags := store.NewMockTagsStore(ctrl)
+	resolver, err := New(nil, nil, WithTags(mockTags))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	var resp struct {
+		AutocompleteTags []struct {
+			Name       string
+			UsageCount int `json:"usage_count"`
+		}
+	}
+	q := `query ($prefix: String!) { autocompleteTags(prefix: $prefix) { name, usage_count } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockTags.EXPECT().Autocomplete(gomock.Any(), "new y", uint16(10)).
+			Return([]*model.Tag{{Name: "new york", UsageCount: 3}}, nil)
+
+		c.MustPost(q, &resp, client.Var("prefix", " New  Y"))
+
+		assert.Len(t, resp.AutocompleteTags, 1)
+		assert.Equal(t, "new york", resp.AutocompleteTags[0].Name)
+		assert.Equal(t, 3, resp.AutocompleteTags[0].UsageCount)
+	})
+
+	t.Run("sad path - empty prefix", func(t *testing.T) {
+		err := c.Post(q, &resp, client.Var("prefix", "  "))
+
+		assert.Contains(t, err.Error(), ErrorMessageBadRequest)
+	})
+
+	t.Run("sad path - first is 0", func(t *testing.T) {
+		err := c.Post(`query { autocompleteTags(prefix: "co", first: 0) { name } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorMessageBadRequest)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockTags.EXPECT().Autocomplete(gomock.Any(), "co", uint16(10)).
+			Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(q, &resp, client.Var("prefix", "co"))
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestQueryResolver_GetCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCategories := store.NewMockCategoriesStore(ctrl)
+	resolver, err := New(nil, nil, WithCategories(mockCategories))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	categories := []*model.Category{
+		{ID: "1", Slug: "news", Name: "News", Position: 1},
+		{ID: "2", Slug: "gossip", Name: "Gossip", Position: 2, Archived: true},
+	}
+	type response struct {
+		GetCategories []struct {
+			ID       string
+			Slug     string
+			Name     string
+			Archived bool
+		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockCategories.EXPECT().GetAll(gomock.Any(), false).Return(categories[:1], nil)
+		var resp response
+
+		c.MustPost(`query { getCategories { id, slug, name, archived } }`, &resp)
+
+		assert.Len(t, resp.GetCategories, 1)
+		assert.Equal(t, "news", resp.GetCategories[0].Slug)
+		assert.Equal(t, "News", resp.GetCategories[0].Name)
+	})
+
+	t.Run("happy path - include archived", func(t *testing.T) {
+		mockCategories.EXPECT().GetAll(gomock.Any(), true).Return(categories, nil)
+		var resp response
+
+		c.MustPost(`query { getCategories(includeArchived: true) { id, slug, name, archived } }`, &resp)
+
+		assert.Len(t, resp.GetCategories, 2)
+		assert.True(t, resp.GetCategories[1].Archived)
+	})
+
+	t.Run("happy path - localized names", func(t *testing.T) {
+		names := []*model.LocalizedName{{Locale: "pt", Name: "Notícias"}, {Locale: "pt-br", Name: "Novidades"}}
+		mockCategories.EXPECT().GetAll(gomock.Any(), false).Return(categories[:1], nil)
+		mockCategories.EXPECT().GetNames(gomock.Any(), "1").Return(names, nil).Times(4)
+		var resp struct {
+			GetCategories []struct {
+				Brazil   string
+				Portugal string
+				German   string
+				Names    []struct{ Locale, Name string }
+			}
+		}
+
+		c.MustPost(`query {
+			getCategories {
+				brazil: name(locale: "pt-BR"), portugal: name(locale: "pt-PT"), german: name(locale: "de")
+				names { locale, name }
+			}
+		}`, &resp)
+
+		assert.Equal(t, "Novidades", resp.GetCategories[0].Brazil)
+		assert.Equal(t, "Notícias", resp.GetCategories[0].Portugal)
+		assert.Equal(t, "News", resp.GetCategories[0].German)
+		assert.Len(t, resp.GetCategories[0].Names, 2)
+	})
+
+	t.Run("sad path - categories disabled", func(t *testing.T) {
+		resolver, err := New(nil, nil)
+		assert.NoError(t, err)
+		var resp response
+
+		err = client.New(NewServer(resolver)).Post(`query { getCategories { id } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorMessageCategoriesDisabled)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockCategories.EXPECT().GetAll(gomock.Any(), false).Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+		var resp response
+
+		err := c.Post(`query { getCategories { id } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestMutationResolver_UpdateAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "abc",
+		Description: "abcs",
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+	input := &model.AudioShortInput{
+		Title:       "abc",
+		Description: "abcs",
+		Category:    "news",
+		AudioFile:   "a",
+		Creator:     &model.CreatorInput{ID: "1"},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Update(gomock.Any(), "1", input, nil, nil).Return(short, nil)
+		var resp struct {
+			UpdateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			updateAudioShort(id: "1", input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
+		assert.Equal(t, "abcs", resp.UpdateAudioShort.Description)
+	})
+
+	t.Run("sad path - no args", func(t *testing.T) {
+		var resp struct {
+			UpdateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			updateAudioShort {
+				title,
+				description
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(m, &resp)
+		})
+	})
+
+	t.Run("sad path - creator not active", func(t *testing.T) {
+		mockStore.EXPECT().Update(gomock.Any(), "1", input, nil, nil).
+			Return(nil, errors.Wrap(&store.CreatorNotActiveError{CreatorID: "1", Status: model.CreatorStatusSuspended}, "some error"))
+		var resp struct {
+			UpdateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			updateAudioShort(id: "1", input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeCreatorNotActive)
+	})
+
+	t.Run("sad path - version conflict", func(t *testing.T) {
+		version := 3
+		mockStore.EXPECT().Update(gomock.Any(), "1", input, nil, &version).
+			Return(nil, &store.VersionConflictError{ID: "1", Expected: 3, Actual: 4})
+		var resp struct {
+			UpdateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			updateAudioShort(id: "1", expectedVersion: 3, input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeConflict)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().Update(gomock.Any(), "1", input, nil, nil).Return(nil, errors.New("some error"))
+		var resp struct {
+			UpdateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			updateAudioShort(id: "1", input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(m, &resp)
+		})
+	})
+}
+
+func TestMutationResolver_PatchAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "abc",
+		Description: "abcs",
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+	title := "abc"
+	patch := &model.AudioShortPatch{Title: &title}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Patch(gomock.Any(), "1", patch, nil, nil).Return(short, nil)
+		var resp struct {
+			PatchAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			patchAudioShort(id: "1", patch: { title: "abc" }) {
+				title,
+				description
+			}
+		}`
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.PatchAudioShort.Title)
+		assert.Equal(t, "abcs", resp.PatchAudioShort.Description)
+	})
+
+	t.Run("happy path - patch variable", func(t *testing.T) {
+		mockStore.EXPECT().Patch(gomock.Any(), "1", patch, nil, nil).Return(short, nil)
+		var resp struct {
+			PatchAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation($patch: AudioShortPatch!) {
+			patchAudioShort(id: "1", patch: $patch) {
+				title
+			}
+		}`
+		c.MustPost(m, &resp, client.Var("patch", map[string]interface{}{"title": "abc"}))
+		assert.Equal(t, "abc", resp.PatchAudioShort.Title)
+	})
+
+	t.Run("sad path - empty patch", func(t *testing.T) {
+		var resp struct {
+			PatchAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			patchAudioShort(id: "1", patch: {}) {
+				title
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorMessageBadRequest+": "+ErrorMessageEmptyPatch)
+	})
+
+	t.Run("sad path - explicit null", func(t *testing.T) {
+		var resp struct {
+			PatchAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			patchAudioShort(id: "1", patch: { title: "abc", description: null }) {
+				title
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorMessageNullPatchField+": description")
+	})
+
+	t.Run("sad path - explicit null in variable", func(t *testing.T) {
+		var resp struct {
+			PatchAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation($patch: AudioShortPatch!) {
+			patchAudioShort(id: "1", patch: $patch) {
+				title
+			}
+		}`
+		err := c.Post(m, &resp, client.Var("patch", map[string]interface{}{"title": "abc", "audio_file": nil}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorMessageNullPatchField+": audio_file")
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().Patch(gomock.Any(), "1", patch, nil, nil).Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+		var resp struct {
+			PatchAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			patchAudioShort(id: "1", patch: { title: "abc" }) {
+				title
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+}
+
+func TestMutationResolver_CreateAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "abc",
+		Description: "abcs",
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+	input := &model.AudioShortInput{
+		Title:       "abc",
+		Description: "abcs",
+		Category:    "news",
+		AudioFile:   "a",
+		Creator:     &model.CreatorInput{ID: "1"},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input, nil).Return(short, nil)
+		var resp struct {
+			CreateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			createAudioShort(input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.CreateAudioShort.Title)
+		assert.Equal(t, "abcs", resp.CreateAudioShort.Description)
+	})
+
+	t.Run("sad path - creator not active", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input, nil).
+			Return(nil, errors.Wrap(&store.CreatorNotActiveError{CreatorID: "1", Status: model.CreatorStatusBanned}, "some error"))
+		var resp struct {
+			CreateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			createAudioShort(input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeCreatorNotActive)
+	})
+
+	t.Run("sad path - category archived", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input, nil).
+			Return(nil, errors.Wrap(&store.CategoryArchivedError{Slug: "news"}, "some error"))
+		var resp struct {
+			CreateAudioShort struct{ Title string }
+		}
+		m := `mutation { createAudioShort(input: {title: "abc", description: "abcs", category: "news", audio_file: "a", creator: {id: "1"}}) { title } }`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeCategoryArchived)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input, nil).Return(nil, errors.New("some error"))
+		var resp struct {
+			CreateAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			createAudioShort(input: {
+				title: "abc",
+				description: "abcs",
+				category: "news",
+				audio_file: "a",
+				creator: {
+					id: "1"
+				}
+			}) {
+				title,
+				description
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(m, &resp)
+		})
+	})
+}
+
+// newMP3 returns the frames of an MP3 file of silence, each 26ms of 128 kbit/s, 44.1 kHz stereo MPEG-1 layer III
+func newMP3(frames int) string {
+	return strings.Repeat("\xFF\xFB\x90\x00"+strings.Repeat("\x00", 413), frames)
+}
+
+func TestMutationResolver_UploadAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	dir := t.TempDir()
+	blobStore, err := storage.NewLocalStore(dir, "http://localhost:8080/files")
+	assert.NoError(t, err)
+	mockWaveforms := store.NewMockWaveformsStore(ctrl)
+	mockHLS := store.NewMockHLSStore(ctrl)
+	mockLoudness := store.NewMockLoudnessStore(ctrl)
+	resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithUploadLimits(4096, []string{"audio/mpeg"}),
+		WithWaveforms(mockWaveforms), WithHLS(mockHLS), WithLoudness(mockLoudness))
+	assert.NoError(t, err)
+	srv := NewServer(resolver)
+
+	q := `
+	mutation ($file: Upload!) {
+		uploadAudioShort(file: $file, input: {title: "abc", description: "abcs", category: "news", creator: {id: "1"}}) {
+			title,
+			audio_file
+		}
+	}`
+
+	// upload posts the file as a GraphQL multipart request and returns the decoded response
+	upload := func(content string) (resp struct {
+		Data struct {
+			UploadAudioShort *struct {
+				Title     string
+				AudioFile string `json:"audio_file"`
+			}
+		}
+		Errors []struct{ Message string }
+	}) {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		operations, _ := json.Marshal(map[string]interface{}{"query": q, "variables": map[string]interface{}{"file": nil}})
+		assert.NoError(t, w.WriteField("operations", string(operations)))
+		assert.NoError(t, w.WriteField("map", `{"0": ["variables.file"]}`))
+		part, err := w.CreateFormFile("0", "short.mp3")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/query", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	// uploaded returns the number of files in the blob store
+	uploaded := func() int {
+		files, err := ioutil.ReadDir(filepath.Join(dir, "shorts"))
+		if os.IsNotExist(err) {
+			return 0
+		}
+		assert.NoError(t, err)
+		return len(files)
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *model.AudioShortInput, metadata *model.AudioMetadata) (*model.AudioShort, error) {
+				assert.Regexp(t, `^http://localhost:8080/files/shorts/[0-9a-f]{32}\.mp3$`, input.AudioFile)
+				assert.Equal(t, &model.AudioMetadata{Duration: 0.130612244, SampleRate: 44100, Channels: 2, Bitrate: 127706, Codec: "mp3"}, metadata)
+				return &model.AudioShort{ID: "1", Title: input.Title, AudioFile: input.AudioFile, Creator: &model.Creator{}, Metadata: metadata}, nil
+			})
+		mockWaveforms.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		mockHLS.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		mockLoudness.EXPECT().Request(gomock.Any(), "1").Return(nil)
+
+		resp := upload(newMP3(5))
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "abc", resp.Data.UploadAudioShort.Title)
+		assert.Equal(t, 1, uploaded())
+	})
+
+	t.Run("happy path - background requests fail", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			&model.AudioShort{ID: "2", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}, nil)
+		mockWaveforms.EXPECT().Request(gomock.Any(), "2").Return(errors.New("some error"))
+		mockHLS.EXPECT().Request(gomock.Any(), "2").Return(errors.New("some error"))
+		mockLoudness.EXPECT().Request(gomock.Any(), "2").Return(errors.New("some error"))
+
+		resp := upload(newMP3(5))
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "abc", resp.Data.UploadAudioShort.Title)
+		assert.Equal(t, 2, uploaded())
+	})
+
+	t.Run("sad path - unsupported file type", func(t *testing.T) {
+		resp := upload("RIFF\x24\x08\x00\x00WAVEfmt ")
+		assert.Nil(t, resp.Data.UploadAudioShort)
+		assert.Equal(t, ErrorMessageBadRequest+": "+ErrorMessageUnsupportedFileType, resp.Errors[0].Message)
+		assert.Equal(t, 2, uploaded())
+	})
+
+	t.Run("sad path - file too large", func(t *testing.T) {
+		resp := upload("ID3\x04\x00\x00\x00\x00\x00\x00" + strings.Repeat("a", 4096))
+		assert.Nil(t, resp.Data.UploadAudioShort)
+		assert.Equal(t, ErrorMessageBadRequest+": "+ErrorMessageFileTooLarge, resp.Errors[0].Message)
+		assert.Equal(t, 2, uploaded())
+	})
+
+	t.Run("sad path - create fails", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), gomock.Any(), nil).Return(nil, &store.InvalidReferenceError{Constraint: "fk_creator", Err: errors.New("some error")})
+
+		resp := upload("ID3\x04\x00\x00\x00\x00\x00\x00 audio")
+		assert.Nil(t, resp.Data.UploadAudioShort)
+		assert.Equal(t, ErrorMessageCreateFailed, resp.Errors[0].Message)
+		assert.Equal(t, 2, uploaded())
+	})
+}
+
+func TestMutationResolver_ReplaceAudioFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockWaveforms := store.NewMockWaveformsStore(ctrl)
+	mockHLS := store.NewMockHLSStore(ctrl)
+	mockLoudness := store.NewMockLoudnessStore(ctrl)
+	resolver, err := New(mockStore, nil, WithWaveforms(mockWaveforms), WithHLS(mockHLS), WithLoudness(mockLoudness))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	current := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{ID: "1"}}
+	update := `
+	mutation ($audioFile: String!) {
+		updateAudioShort(id: "1", input: {
+			title: "abc",
+			description: "abcs",
+			category: "news",
+			audio_file: $audioFile,
+			creator: {
+				id: "1"
+			}
+		}) {
+			title
+		}
+	}`
+	patch := `
+	mutation ($audioFile: String!) {
+		patchAudioShort(id: "1", patch: { audio_file: $audioFile }) {
+			title
+		}
+	}`
+
+	t.Run("happy path - update requests the jobs of the new audio file", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(current, nil)
+		mockStore.EXPECT().Update(gomock.Any(), "1", gomock.Any(), nil, nil).Return(&model.AudioShort{ID: "1", Title: "abc", AudioFile: "b"}, nil)
+		mockWaveforms.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		mockHLS.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		mockLoudness.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		var resp struct{ UpdateAudioShort struct{ Title string } }
+		c.MustPost(update, &resp, client.Var("audioFile", "b"))
+		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
+	})
+
+	t.Run("happy path - patch requests the jobs of the new audio file", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(current, nil)
+		mockStore.EXPECT().Patch(gomock.Any(), "1", gomock.Any(), nil, nil).Return(&model.AudioShort{ID: "1", Title: "abc", AudioFile: "b"}, nil)
+		mockWaveforms.EXPECT().Request(gomock.Any(), "1").Return(errors.New("some error"))
+		mockHLS.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		mockLoudness.EXPECT().Request(gomock.Any(), "1").Return(nil)
+		var resp struct{ PatchAudioShort struct{ Title string } }
+		c.MustPost(patch, &resp, client.Var("audioFile", "b"))
+		assert.Equal(t, "abc", resp.PatchAudioShort.Title)
+	})
+
+	t.Run("happy path - same audio file", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(current, nil).Times(2)
+		mockStore.EXPECT().Update(gomock.Any(), "1", gomock.Any(), nil, nil).Return(current, nil)
+		mockStore.EXPECT().Patch(gomock.Any(), "1", gomock.Any(), nil, nil).Return(current, nil)
+		var resp struct{ UpdateAudioShort, PatchAudioShort struct{ Title string } }
+		c.MustPost(update, &resp, client.Var("audioFile", "a"))
+		c.MustPost(patch, &resp, client.Var("audioFile", "a"))
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+		var resp struct{ UpdateAudioShort *struct{ Title string } }
+		err := c.Post(update, &resp, client.Var("audioFile", "b"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+}
+
+func TestMutationResolver_AudioValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	dir := t.TempDir()
+	blobStore, err := storage.NewLocalStore(dir, "http://localhost:8080/files")
+	assert.NoError(t, err)
+	resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithUploadLimits(8192, []string{"audio/mpeg"}),
+		WithAudioLimits(time.Second, 3*time.Second, []string{"mp3"}))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	// the referenced audio files in the blob store, 2.6s of MP3, 4s of 8 kHz PCM, and a truncated MP3
+	files := map[string]string{
+		"fixtures/short.mp3": newMP3(100),
+		"fixtures/long.wav": "RIFF\x2C\xFA\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1F\x00\x00\x80\x3E\x00\x00\x02\x00\x10\x00" +
+			"data\x00\xFA\x00\x00" + strings.Repeat("\x00", 64000),
+		"fixtures/truncated.mp3": newMP3(100)[:417*60+100],
+	}
+	for key, file := range files {
+		_, err := blobStore.Put(context.Background(), key, strings.NewReader(file), "audio/mpeg")
+		assert.NoError(t, err)
+	}
+	stored := "http://localhost:8080/files/fixtures"
+	// an external server hosting the same files, on a loopback address that the resolver must not request
+	requested := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested++
+		file, ok := files["fixtures"+r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, strings.NewReader(file))
+	}))
+	defer srv.Close()
+	// a resolver allowed to request the server, as if it had a public address, which reads files of up to 50 kB
+	external, err := New(mockStore, nil, WithBlobStore(blobStore), WithUploadLimits(50000, []string{"audio/mpeg"}),
+		WithAudioLimits(time.Second, 3*time.Second, []string{"mp3"}))
+	assert.NoError(t, err)
+	external.httpClient = srv.Client()
+	ec := client.New(NewServer(external))
+
+	metadata := &model.AudioMetadata{Duration: 2.612244897, SampleRate: 44100, Channels: 2, Bitrate: 127706, Codec: "mp3"}
+	input := func(audioFile string) *model.AudioShortInput {
+		return &model.AudioShortInput{
+			Title:       "abc",
+			Description: "abcs",
+			Category:    "news",
+			AudioFile:   audioFile,
+			Creator:     &model.CreatorInput{ID: "1"},
+		}
+	}
+	m := `
+	mutation ($audioFile: String!) {
+		createAudioShort(input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+			title,
+			metadata {
+				codec
+			}
+		}
+	}`
+
+	t.Run("happy path - create", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input(stored+"/short.mp3"), metadata).
+			Return(&model.AudioShort{Title: "abc", Creator: &model.Creator{}, Metadata: metadata}, nil)
+		var resp struct {
+			CreateAudioShort struct {
+				Title    string
+				Metadata struct{ Codec string }
+			}
+		}
+		c.MustPost(m, &resp, client.Var("audioFile", stored+"/short.mp3"))
+		assert.Equal(t, "mp3", resp.CreateAudioShort.Metadata.Codec)
+	})
+
+	t.Run("happy path - update", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").
+			Return(&model.AudioShort{ID: "1", AudioFile: stored + "/old.mp3", Creator: &model.Creator{ID: "1"}}, nil)
+		mockStore.EXPECT().Update(gomock.Any(), "1", input(stored+"/short.mp3"), metadata, nil).
+			Return(&model.AudioShort{Title: "abc", Creator: &model.Creator{}, Metadata: metadata}, nil)
+		var resp struct {
+			UpdateAudioShort struct{ Title string }
+		}
+		c.MustPost(`
+		mutation ($audioFile: String!) {
+			updateAudioShort(id: "1", input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+				title
+			}
+		}`, &resp, client.Var("audioFile", stored+"/short.mp3"))
+		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
+	})
+
+	t.Run("happy path - patch", func(t *testing.T) {
+		audioFile := stored + "/short.mp3"
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").
+			Return(&model.AudioShort{ID: "1", AudioFile: stored + "/old.mp3", Creator: &model.Creator{ID: "1"}}, nil)
+		mockStore.EXPECT().Patch(gomock.Any(), "1", &model.AudioShortPatch{AudioFile: &audioFile}, metadata, nil).
+			Return(&model.AudioShort{Title: "abc", Creator: &model.Creator{}, Metadata: metadata}, nil)
+		var resp struct {
+			PatchAudioShort struct{ Title string }
+		}
+		c.MustPost(`
+		mutation ($audioFile: String!) {
+			patchAudioShort(id: "1", patch: {audio_file: $audioFile}) {
+				title
+			}
+		}`, &resp, client.Var("audioFile", audioFile))
+		assert.Equal(t, "abc", resp.PatchAudioShort.Title)
+	})
+
+	t.Run("happy path - update keeping an external audio file", func(t *testing.T) {
+		// the audio file was set before validation, and is not probed again as long as it is kept
+		external := "https://static.cdn.dummyurl.com/a.mp3"
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").
+			Return(&model.AudioShort{ID: "1", AudioFile: external, Creator: &model.Creator{ID: "1"}}, nil)
+		mockStore.EXPECT().Update(gomock.Any(), "1", input(external), nil, nil).
+			Return(&model.AudioShort{Title: "abc", AudioFile: external, Creator: &model.Creator{}}, nil)
+		var resp struct {
+			UpdateAudioShort struct{ Title string }
+		}
+		c.MustPost(`
+		mutation ($audioFile: String!) {
+			updateAudioShort(id: "1", input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+				title
+			}
+		}`, &resp, client.Var("audioFile", external))
+		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
+	})
+
+	t.Run("happy path - patch keeping an external audio file", func(t *testing.T) {
+		external := "https://static.cdn.dummyurl.com/a.mp3"
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").
+			Return(&model.AudioShort{ID: "1", AudioFile: external, Creator: &model.Creator{ID: "1"}}, nil)
+		mockStore.EXPECT().Patch(gomock.Any(), "1", &model.AudioShortPatch{AudioFile: &external}, nil, nil).
+			Return(&model.AudioShort{Title: "abc", AudioFile: external, Creator: &model.Creator{}}, nil)
+		var resp struct {
+			PatchAudioShort struct{ Title string }
+		}
+		c.MustPost(`
+		mutation ($audioFile: String!) {
+			patchAudioShort(id: "1", patch: {audio_file: $audioFile}) {
+				title
+			}
+		}`, &resp, client.Var("audioFile", external))
+		assert.Equal(t, "abc", resp.PatchAudioShort.Title)
+	})
+
+	t.Run("sad path - update replacing the audio file with a corrupt one", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").
+			Return(&model.AudioShort{ID: "1", AudioFile: stored + "/short.mp3", Creator: &model.Creator{ID: "1"}}, nil)
+		var resp struct{ UpdateAudioShort *struct{ Title string } }
+		err := c.Post(`
+		mutation ($audioFile: String!) {
+			updateAudioShort(id: "1", input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+				title
+			}
+		}`, &resp, client.Var("audioFile", stored+"/truncated.mp3"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ProblemCodeCorrupt)
+	})
+
+	t.Run("sad path - every problem is listed", func(t *testing.T) {
+		var resp struct{ CreateAudioShort *struct{ Title string } }
+		err := c.Post(m, &resp, client.Var("audioFile", stored+"/long.wav"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+		assert.Contains(t, err.Error(), ProblemCodeUnsupportedCodec)
+		assert.Contains(t, err.Error(), ProblemCodeTooLong)
+		assert.NotContains(t, err.Error(), ProblemCodeTooShort)
+	})
+
+	t.Run("sad path - corrupt", func(t *testing.T) {
+		var resp struct{ CreateAudioShort *struct{ Title string } }
+		err := c.Post(m, &resp, client.Var("audioFile", stored+"/truncated.mp3"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+		assert.Contains(t, err.Error(), ProblemCodeCorrupt)
+	})
+
+	t.Run("sad path - unreadable", func(t *testing.T) {
+		var resp struct{ CreateAudioShort *struct{ Title string } }
+		err := c.Post(m, &resp, client.Var("audioFile", stored+"/missing.mp3"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ProblemCodeUnreadable)
+	})
+
+	t.Run("sad path - private address", func(t *testing.T) {
+		var resp struct{ CreateAudioShort *struct{ Title string } }
+		for _, audioFile := range []string{srv.URL + "/short.mp3", "http://169.254.169.254/latest/meta-data", "a"} {
+			err := c.Post(m, &resp, client.Var("audioFile", audioFile))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), ProblemCodeUnreadable)
+		}
+		assert.Zero(t, requested)
+	})
+
+	t.Run("happy path - create with an external audio file", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input(srv.URL+"/short.mp3"), metadata).
+			Return(&model.AudioShort{Title: "abc", Creator: &model.Creator{}, Metadata: metadata}, nil)
+		var resp struct {
+			CreateAudioShort struct {
+				Title    string
+				Metadata struct{ Codec string }
+			}
+		}
+		ec.MustPost(m, &resp, client.Var("audioFile", srv.URL+"/short.mp3"))
+		assert.Equal(t, "mp3", resp.CreateAudioShort.Metadata.Codec)
+	})
+
+	t.Run("sad path - external audio file", func(t *testing.T) {
+		var resp struct{ CreateAudioShort *struct{ Title string } }
+		err := ec.Post(m, &resp, client.Var("audioFile", srv.URL+"/long.wav"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ProblemCodeTooLarge)
+
+		err = ec.Post(m, &resp, client.Var("audioFile", srv.URL+"/truncated.mp3"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ProblemCodeCorrupt)
+
+		err = ec.Post(m, &resp, client.Var("audioFile", srv.URL+"/missing.mp3"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ProblemCodeUnreadable)
+	})
+
+	t.Run("sad path - upload too short", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		operations, _ := json.Marshal(map[string]interface{}{
+			"query":     `mutation ($file: Upload!) { uploadAudioShort(file: $file, input: {title: "abc", description: "abcs", category: "news", creator: {id: "1"}}) { title } }`,
+			"variables": map[string]interface{}{"file": nil},
+		})
+		assert.NoError(t, w.WriteField("operations", string(operations)))
+		assert.NoError(t, w.WriteField("map", `{"0": ["variables.file"]}`))
+		part, err := w.CreateFormFile("0", "short.mp3")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(newMP3(5)))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/query", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		rec := httptest.NewRecorder()
+		NewServer(resolver).ServeHTTP(rec, req)
+
+		assert.Contains(t, rec.Body.String(), ErrorCodeValidationFailed)
+		assert.Contains(t, rec.Body.String(), ProblemCodeTooShort)
+		files, err := ioutil.ReadDir(filepath.Join(dir, "shorts"))
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
+
+// newWAV returns 8 kHz 16 bit PCM of tenths of a second of tones, each louder and higher than the one before it
+// but every third one
+func newWAV(tenths int) string {
+	var data []byte
+	for i := 0; i < tenths; i++ {
+		step := float64(i%3 + 1)
+		for j := 0; j < 800; j++ {
+			v := int16(0x2000 * step * math.Sin(2*math.Pi*200*step*float64(j)/8000))
+			data = append(data, byte(v), byte(v>>8))
+		}
+	}
+	header := "RIFF" + le32(36+len(data)) + "WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1F\x00\x00\x80\x3E\x00\x00\x02\x00\x10\x00" +
+		"data" + le32(len(data))
+	return header + string(data)
+}
+
+func le32(v int) string {
+	return string([]byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)})
+}
+
+// fingerprintOf returns the fingerprint of the audio file
+func fingerprintOf(t *testing.T, file string) *audio.Fingerprint {
+	fingerprint, err := audio.NewFingerprint(strings.NewReader(file), int64(len(file)))
+	assert.NoError(t, err)
+	return fingerprint
+}
+
+// invert returns the acoustic fingerprint with the bits of the frames flipped
+func invert(acoustic []byte, frames int) []byte {
+	inverted := append([]byte{}, acoustic...)
+	for i := 0; i < frames; i++ {
+		inverted[i] ^= 3
+	}
+	return inverted
+}
+
+func TestMutationResolver_DuplicateDetection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockFingerprints := store.NewMockFingerprintsStore(ctrl)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	file := newWAV(20)
+	audioFile, err := blobStore.Put(context.Background(), "shorts/abc.wav", strings.NewReader(file), "audio/wav")
+	assert.NoError(t, err)
+	fingerprint := fingerprintOf(t, file)
+	resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithDuplicateDetection(mockFingerprints, true, 0.9))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: audioFile, Creator: &model.Creator{}}
+	var resp struct {
+		CreateAudioShort struct{ Title string }
+	}
+	m := `
+	mutation ($audioFile: String!) {
+		createAudioShort(input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+			title
+		}
+	}`
+
+	t.Run("happy path", func(t *testing.T) {
+		// 19 frames of acoustic fingerprint may only be similar enough to 18 to 21 frames
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "1", "").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: "abc", Acoustic: invert(fingerprint.Acoustic, 19)},
+			{ShortID: "3", SHA256: "def"},
+		}, nil)
+		mockStore.EXPECT().Create(gomock.Any(), gomock.Any(), nil).Return(short, nil)
+		mockFingerprints.EXPECT().Save(gomock.Any(), &store.Fingerprint{ShortID: "1", SHA256: fingerprint.SHA256, Acoustic: fingerprint.Acoustic})
+
+		c.MustPost(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Equal(t, "abc", resp.CreateAudioShort.Title)
+	})
+
+	t.Run("happy path - flagged", func(t *testing.T) {
+		resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithDuplicateDetection(mockFingerprints, false, 0.9))
+		assert.NoError(t, err)
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "1", "").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: fingerprint.SHA256},
+		}, nil)
+		mockStore.EXPECT().Create(gomock.Any(), gomock.Any(), nil).Return(short, nil)
+		mockFingerprints.EXPECT().Save(gomock.Any(), &store.Fingerprint{ShortID: "1", SHA256: fingerprint.SHA256, Acoustic: fingerprint.Acoustic, Flagged: true})
+
+		client.New(NewServer(resolver)).MustPost(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Equal(t, "abc", resp.CreateAudioShort.Title)
+	})
+
+	t.Run("happy path - unreadable audio file", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), gomock.Any(), nil).Return(short, nil)
+
+		c.MustPost(m, &resp, client.Var("audioFile", "http://localhost:8080/files/shorts/missing.wav"))
+
+		assert.Equal(t, "abc", resp.CreateAudioShort.Title)
+	})
+
+	t.Run("sad path - exact duplicate", func(t *testing.T) {
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "1", "").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: fingerprint.SHA256},
+		}, nil)
+
+		err := c.Post(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Contains(t, err.Error(), "Audio file is the same as that of short 2 of another creator")
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+	})
+
+	t.Run("sad path - near duplicate", func(t *testing.T) {
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "1", "").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: "abc", Acoustic: invert(fingerprint.Acoustic, 19)},
+			{ShortID: "3", SHA256: "def", Acoustic: invert(fingerprint.Acoustic, 1)},
+		}, nil)
+
+		err := c.Post(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Contains(t, err.Error(), "Audio sounds like that of short 3 of another creator")
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "1", "").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+
+	t.Run("happy path - update replacing the audio file", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(&model.AudioShort{ID: "1", AudioFile: "a", Creator: &model.Creator{ID: "1"}}, nil)
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "1", "1").Return(nil, nil)
+		mockStore.EXPECT().Update(gomock.Any(), "1", gomock.Any(), nil, nil).Return(short, nil)
+		mockFingerprints.EXPECT().Save(gomock.Any(), &store.Fingerprint{ShortID: "1", SHA256: fingerprint.SHA256, Acoustic: fingerprint.Acoustic})
+		var resp struct{ UpdateAudioShort struct{ Title string } }
+		m := `
+		mutation ($audioFile: String!) {
+			updateAudioShort(id: "1", input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+				title
+			}
+		}`
+
+		c.MustPost(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
+	})
+
+	t.Run("happy path - update keeping the audio file", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockStore.EXPECT().Update(gomock.Any(), "1", gomock.Any(), nil, nil).Return(short, nil)
+		var resp struct{ UpdateAudioShort struct{ Title string } }
+		m := `
+		mutation ($audioFile: String!) {
+			updateAudioShort(id: "1", input: {title: "abc", description: "abcs", category: "news", audio_file: $audioFile, creator: {id: "1"}}) {
+				title
+			}
+		}`
+
+		c.MustPost(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
+	})
+
+	t.Run("sad path - patch with a duplicate", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(&model.AudioShort{ID: "1", AudioFile: "a", Creator: &model.Creator{ID: "4"}}, nil)
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "4", "1").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: fingerprint.SHA256},
+		}, nil)
+		var resp struct{ PatchAudioShort *struct{ Title string } }
+		m := `
+		mutation ($audioFile: String!) {
+			patchAudioShort(id: "1", patch: {audio_file: $audioFile}) {
+				title
+			}
+		}`
+
+		err := c.Post(m, &resp, client.Var("audioFile", audioFile))
+
+		assert.Contains(t, err.Error(), "Audio file is the same as that of short 2 of another creator")
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+	})
+}
+
+func TestQueryResolver_DuplicatesOf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockFingerprints := store.NewMockFingerprintsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithDuplicateDetection(mockFingerprints, true, 0.9))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	fingerprint := fingerprintOf(t, newWAV(20))
+	var resp struct {
+		DuplicatesOf []struct {
+			Node       struct{ ID string }
+			Exact      bool
+			Similarity float64
+		}
+	}
+	q := `query { duplicatesOf(id: "1") { node { id }, exact, similarity } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockFingerprints.EXPECT().Get(gomock.Any(), "1").Return(
+			&store.Fingerprint{ShortID: "1", SHA256: fingerprint.SHA256, Acoustic: fingerprint.Acoustic}, nil)
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "", "1").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: "abc", Acoustic: invert(fingerprint.Acoustic, 1)},
+			{ShortID: "3", SHA256: fingerprint.SHA256, Flagged: true},
+			{ShortID: "4", SHA256: fingerprint.SHA256},
+			{ShortID: "5", SHA256: "def", Acoustic: invert(fingerprint.Acoustic, 19)},
+		}, nil)
+		// 4 was hard deleted in the meantime
+		mockStore.EXPECT().GetByIDs(gomock.Any(), []string{"3", "4", "2"}).Return([]*model.AudioShort{
+			{ID: "2", Creator: &model.Creator{}},
+			{ID: "3", Creator: &model.Creator{}},
+		}, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Len(t, resp.DuplicatesOf, 2)
+		assert.Equal(t, "3", resp.DuplicatesOf[0].Node.ID)
+		assert.True(t, resp.DuplicatesOf[0].Exact)
+		assert.Equal(t, 1.0, resp.DuplicatesOf[0].Similarity)
+		assert.Equal(t, "2", resp.DuplicatesOf[1].Node.ID)
+		assert.False(t, resp.DuplicatesOf[1].Exact)
+		assert.InDelta(t, 36/38.0, resp.DuplicatesOf[1].Similarity, 1e-9)
+	})
+
+	t.Run("happy path - not fingerprinted", func(t *testing.T) {
+		mockFingerprints.EXPECT().Get(gomock.Any(), "1").Return(nil, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Empty(t, resp.DuplicatesOf)
+	})
+
+	t.Run("happy path - no duplicates", func(t *testing.T) {
+		mockFingerprints.EXPECT().Get(gomock.Any(), "1").Return(
+			&store.Fingerprint{ShortID: "1", SHA256: fingerprint.SHA256, Acoustic: fingerprint.Acoustic}, nil)
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "", "1").Return(nil, nil)
+
+		c.MustPost(q, &resp)
+
+		assert.Empty(t, resp.DuplicatesOf)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockFingerprints.EXPECT().Get(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+
+	t.Run("sad path - shorts store error", func(t *testing.T) {
+		mockFingerprints.EXPECT().Get(gomock.Any(), "1").Return(
+			&store.Fingerprint{ShortID: "1", SHA256: fingerprint.SHA256, Acoustic: fingerprint.Acoustic}, nil)
+		mockFingerprints.EXPECT().FindCandidates(gomock.Any(), fingerprint.SHA256, 18, 21, "", "1").Return([]*store.Fingerprint{
+			{ShortID: "2", SHA256: fingerprint.SHA256},
+		}, nil)
+		mockStore.EXPECT().GetByIDs(gomock.Any(), []string{"2"}).Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(q, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestMutationResolver_SetTranscript(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockTranscripts := store.NewMockTranscriptsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithTranscripts(mockTranscripts))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{},
+		Metadata: &model.AudioMetadata{Duration: 2.612244897}}
+	m := `
+	mutation ($cues: [CaptionCueInput!]!) {
+		setTranscript(id: "1", cues: $cues) {
+			title
+		}
+	}`
+	var resp struct {
+		SetTranscript *struct{ Title string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Save(gomock.Any(), "1", []*model.CaptionCue{
+			{Start: 0, End: 1.5, Text: "Hello"},
+			{Start: 1.5, End: 2.613, Text: "world"},
+		}).Return(nil)
+
+		c.MustPost(m, &resp, client.Var("cues", []map[string]interface{}{
+			{"start": 0, "end": 1.5, "text": " Hello "},
+			{"start": 1.5, "end": 2.6126, "text": "world"},
+		}))
+
+		assert.Equal(t, "abc", resp.SetTranscript.Title)
+	})
+
+	t.Run("sad path - every problem is listed", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		err := c.Post(m, &resp, client.Var("cues", []map[string]interface{}{
+			{"start": 1, "end": 2, "text": "Hello"},
+			{"start": 0.5, "end": 1, "text": ""},
+			{"start": 0.75, "end": 3, "text": "world"},
+			{"start": 2, "end": 1e300, "text": "!"},
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+		assert.Contains(t, err.Error(), ProblemCodeCueOutOfOrder)
+		assert.Contains(t, err.Error(), ProblemCodeInvalidCueText)
+		assert.Contains(t, err.Error(), ProblemCodeCueOverlap)
+		assert.Contains(t, err.Error(), ProblemCodeCuePastEnd)
+		assert.Contains(t, err.Error(), ProblemCodeInvalidCueTiming)
+	})
+
+	t.Run("sad path - no cues", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		err := c.Post(m, &resp, client.Var("cues", []map[string]interface{}{}))
+
+		assert.Contains(t, err.Error(), ProblemCodeNoCues)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp, client.Var("cues", []map[string]interface{}{{"start": 0, "end": 1, "text": "Hello"}}))
+
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+
+	t.Run("sad path - transcripts disabled", func(t *testing.T) {
+		resolver, err := New(mockStore, nil)
+		assert.NoError(t, err)
+
+		err = client.New(NewServer(resolver)).Post(m, &resp, client.Var("cues", []map[string]interface{}{{"start": 0, "end": 1, "text": "Hello"}}))
+
+		assert.Contains(t, err.Error(), ErrorMessageTranscriptsDisabled)
+	})
+}
+
+func TestMutationResolver_UploadCaptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockTranscripts := store.NewMockTranscriptsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithTranscripts(mockTranscripts))
+	assert.NoError(t, err)
+	srv := NewServer(resolver)
+
+	// upload posts the captions as a GraphQL multipart request and returns the decoded response
+	upload := func(content string) (resp struct {
+		Data struct {
+			UploadCaptions *struct{ Title string }
+		}
+		Errors []struct {
+			Message    string
+			Extensions struct{ Problems []*ValidationProblem }
+		}
+	}) {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		operations, _ := json.Marshal(map[string]interface{}{
+			"query":     `mutation ($file: Upload!) { uploadCaptions(id: "1", file: $file) { title } }`,
+			"variables": map[string]interface{}{"file": nil},
+		})
+		assert.NoError(t, w.WriteField("operations", string(operations)))
+		assert.NoError(t, w.WriteField("map", `{"0": ["variables.file"]}`))
+		part, err := w.CreateFormFile("0", "captions.vtt")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/query", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Save(gomock.Any(), "1", []*model.CaptionCue{
+			{Start: 0.5, End: 1.5, Text: "Hello"},
+			{Start: 1.5, End: 2.25, Text: "world"},
+		}).Return(nil)
+
+		resp := upload("WEBVTT\n\n00:00.500 --> 00:01.500\nHello\n\n00:01.500 --> 00:02.250\n<i>world</i>\n")
+
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "abc", resp.Data.UploadCaptions.Title)
+	})
+
+	t.Run("sad path - overlapping cues", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		resp := upload("1\n00:00:00,500 --> 00:00:01,500\nHello\n\n2\n00:00:01,000 --> 00:00:02,250\nworld\n")
+
+		assert.Nil(t, resp.Data.UploadCaptions)
+		cue := 1
+		assert.Equal(t, []*ValidationProblem{{Code: ProblemCodeCueOverlap, Message: "cue 2: Cue overlaps the previous cue", Cue: &cue}},
+			resp.Errors[0].Extensions.Problems)
+	})
+
+	t.Run("sad path - invalid file", func(t *testing.T) {
+		resp := upload("hello world")
+
+		assert.Nil(t, resp.Data.UploadCaptions)
+		assert.Equal(t, ProblemCodeInvalidCaptions, resp.Errors[0].Extensions.Problems[0].Code)
+	})
+}
+
+func TestMutationResolver_DeleteTranscript(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockTranscripts := store.NewMockTranscriptsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithTranscripts(mockTranscripts))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	m := `mutation { deleteTranscript(id: "1") { title } }`
+	var resp struct {
+		DeleteTranscript *struct{ Title string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+		c.MustPost(m, &resp)
+
+		assert.Equal(t, "abc", resp.DeleteTranscript.Title)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTranscripts.EXPECT().Delete(gomock.Any(), "1").Return(&store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestMutationResolver_SetChapters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockChapters := store.NewMockChaptersStore(ctrl)
+	resolver, err := New(mockStore, nil, WithChapters(mockChapters))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{},
+		Metadata: &model.AudioMetadata{Duration: 2.612244897}}
+	m := `
+	mutation ($chapters: [ChapterInput!]!) {
+		setChapters(id: "1", chapters: $chapters) {
+			title
+		}
+	}`
+	var resp struct {
+		SetChapters *struct{ Title string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		imageURL := "https://example.com/outro.png"
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockChapters.EXPECT().Save(gomock.Any(), "1", []*model.Chapter{
+			{Start: 0, End: 1.5, Title: "Intro"},
+			{Start: 1.5, End: 2.613, Title: "Outro", ImageURL: &imageURL},
+		}).Return(nil)
+
+		c.MustPost(m, &resp, client.Var("chapters", []map[string]interface{}{
+			{"start": 0, "title": " Intro "},
+			{"start": 1.5004, "title": "Outro", "image_url": imageURL},
+		}))
+
+		assert.Equal(t, "abc", resp.SetChapters.Title)
+	})
+
+	t.Run("sad path - every problem is listed", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		err := c.Post(m, &resp, client.Var("chapters", []map[string]interface{}{
+			{"start": 1, "title": "Intro"},
+			{"start": 0.5, "title": ""},
+			{"start": 2, "title": "Outro", "image_url": "ftp://example.com/outro.png"},
+			{"start": 1e300, "title": "End"},
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeValidationFailed)
+		assert.Contains(t, err.Error(), ProblemCodeChapterOutOfOrder)
+		assert.Contains(t, err.Error(), ProblemCodeInvalidTitle)
+		assert.Contains(t, err.Error(), ProblemCodeInvalidImageURL)
+		assert.Contains(t, err.Error(), ProblemCodeInvalidStart)
+	})
+
+	t.Run("sad path - duration unknown", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(&model.AudioShort{ID: "1", AudioFile: "a", Creator: &model.Creator{}}, nil)
+
+		err := c.Post(m, &resp, client.Var("chapters", []map[string]interface{}{{"start": 0, "title": "Intro"}}))
+
+		assert.Contains(t, err.Error(), ProblemCodeDurationUnknown)
+	})
+
+	t.Run("sad path - no chapters", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		err := c.Post(m, &resp, client.Var("chapters", []map[string]interface{}{}))
+
+		assert.Contains(t, err.Error(), ProblemCodeNoChapters)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp, client.Var("chapters", []map[string]interface{}{{"start": 0, "title": "Intro"}}))
+
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+
+	t.Run("sad path - chapters disabled", func(t *testing.T) {
+		resolver, err := New(mockStore, nil)
+		assert.NoError(t, err)
+
+		err = client.New(NewServer(resolver)).Post(m, &resp, client.Var("chapters", []map[string]interface{}{{"start": 0, "title": "Intro"}}))
+
+		assert.Contains(t, err.Error(), ErrorMessageChaptersDisabled)
+	})
+}
+
+func TestMutationResolver_DeleteChapters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockChapters := store.NewMockChaptersStore(ctrl)
+	resolver, err := New(mockStore, nil, WithChapters(mockChapters))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	m := `mutation { deleteChapters(id: "1") { title } }`
+	var resp struct {
+		DeleteChapters *struct{ Title string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockChapters.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+		c.MustPost(m, &resp)
+
+		assert.Equal(t, "abc", resp.DeleteChapters.Title)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockChapters.EXPECT().Delete(gomock.Any(), "1").Return(&store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+// encodePNG returns a white PNG image of the size
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+// uploadImage posts the image to the mutation, which takes it as $file, as a GraphQL multipart request and returns the
+// decoded response
+func uploadImage(t *testing.T, srv http.Handler, mutation string, content []byte) (resp struct {
+	Data   map[string]*struct{ ID string }
+	Errors []struct {
+		Message    string
+		Extensions struct{ Problems []*ValidationProblem }
+	}
+}) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	operations, _ := json.Marshal(map[string]interface{}{"query": mutation, "variables": map[string]interface{}{"file": nil}})
+	assert.NoError(t, w.WriteField("operations", string(operations)))
+	assert.NoError(t, w.WriteField("map", `{"0": ["variables.file"]}`))
+	part, err := w.CreateFormFile("0", "image")
+	assert.NoError(t, err)
+	_, err = part.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/query", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestMutationResolver_UploadCoverArt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockImages := store.NewMockImagesStore(ctrl)
+	dir := t.TempDir()
+	blobStore, err := storage.NewLocalStore(dir, "http://localhost:8080/files")
+	assert.NoError(t, err)
+	resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithArtwork(mockImages))
+	assert.NoError(t, err)
+	srv := NewServer(resolver)
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	m := `mutation ($file: Upload!) { uploadCoverArt(id: "1", file: $file) { id } }`
+
+	// stored returns the names of the blobs of the cover art of the short
+	stored := func() []string {
+		files, err := ioutil.ReadDir(filepath.Join(dir, "covers", "1"))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		assert.NoError(t, err)
+		var names []string
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		return names
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		_, err := blobStore.Put(context.Background(), "covers/1/old.webp", strings.NewReader("RIFF"), "image/webp")
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Save(gomock.Any(), store.CoverArt, "1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ store.ImageOwner, _ string, images []*model.Image) ([]string, error) {
+				assert.Len(t, images, 3)
+				sizes := map[model.ImageSize][2]int{}
+				for _, img := range images {
+					assert.Equal(t, "image/png", img.ContentType)
+					assert.True(t, strings.HasPrefix(img.URL, "http://localhost:8080/files/covers/1/"))
+					sizes[img.Size] = [2]int{img.Width, img.Height}
+				}
+				assert.Equal(t, map[model.ImageSize][2]int{
+					model.ImageSizeSmall:    {64, 32},
+					model.ImageSizeMedium:   {256, 128},
+					model.ImageSizeOriginal: {300, 150},
+				}, sizes)
+				return []string{"http://localhost:8080/files/covers/1/old.webp"}, nil
+			})
+
+		resp := uploadImage(t, srv, m, encodePNG(t, 300, 150))
+
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "1", resp.Data["uploadCoverArt"].ID)
+		// the replaced cover art is deleted
+		assert.Len(t, stored(), 3)
+		assert.NotContains(t, stored(), "old.webp")
+	})
+
+	t.Run("sad path - invalid image", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		resp := uploadImage(t, srv, m, encodePNG(t, 32, 5000))
+
+		assert.Nil(t, resp.Data["uploadCoverArt"])
+		assert.Equal(t, []*ValidationProblem{
+			{Code: ProblemCodeImageTooSmall, Message: "Image must be at least 64x64 pixels"},
+			{Code: ProblemCodeImageTooLarge, Message: "Image must be at most 4096x4096 pixels"},
+		}, resp.Errors[0].Extensions.Problems)
+	})
+
+	t.Run("sad path - unsupported image", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		resp := uploadImage(t, srv, m, []byte("GIF89a"))
+
+		assert.Equal(t, ProblemCodeUnsupportedImage, resp.Errors[0].Extensions.Problems[0].Code)
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		before := stored()
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Save(gomock.Any(), store.CoverArt, "1", gomock.Any()).
+			Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		resp := uploadImage(t, srv, m, encodePNG(t, 100, 100))
+
+		assert.Contains(t, resp.Errors[0].Message, ErrorMessageUploadFailed)
+		// the blobs of the image that was not saved are deleted
+		assert.Equal(t, before, stored())
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+
+		resp := uploadImage(t, srv, m, encodePNG(t, 100, 100))
+
+		assert.Nil(t, resp.Data["uploadCoverArt"])
+		assert.NotEmpty(t, resp.Errors)
+	})
+
+	t.Run("sad path - artwork disabled", func(t *testing.T) {
+		resolver, err := New(mockStore, nil, WithBlobStore(blobStore))
+		assert.NoError(t, err)
+
+		resp := uploadImage(t, NewServer(resolver), m, encodePNG(t, 100, 100))
+
+		assert.Contains(t, resp.Errors[0].Message, ErrorMessageArtworkDisabled)
+	})
+}
+
+func TestMutationResolver_DeleteCoverArt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockImages := store.NewMockImagesStore(ctrl)
+	dir := t.TempDir()
+	blobStore, err := storage.NewLocalStore(dir, "http://localhost:8080/files")
+	assert.NoError(t, err)
+	resolver, err := New(mockStore, nil, WithBlobStore(blobStore), WithArtwork(mockImages))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	m := `mutation { deleteCoverArt(id: "1") { title } }`
+	var resp struct {
+		DeleteCoverArt *struct{ Title string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		url, err := blobStore.Put(context.Background(), "covers/1/a.png", strings.NewReader("png"), "image/png")
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Delete(gomock.Any(), store.CoverArt, "1").Return([]string{url}, nil)
+
+		c.MustPost(m, &resp)
+
+		assert.Equal(t, "abc", resp.DeleteCoverArt.Title)
+		_, err = blobStore.Stat(context.Background(), "covers/1/a.png")
+		assert.Equal(t, storage.ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockImages.EXPECT().Delete(gomock.Any(), store.CoverArt, "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestMutationResolver_SetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockTags := store.NewMockTagsStore(ctrl)
+	resolver, err := New(mockStore, nil, WithTags(mockTags))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	short := &model.AudioShort{ID: "1", Title: "abc", AudioFile: "a", Creator: &model.Creator{}}
+	m := `mutation ($tags: [String!]!) { setTags(id: "1", tags: $tags) { title } }`
+	var resp struct {
+		SetTags *struct{ Title string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTags.EXPECT().Set(gomock.Any(), "1", []string{"covid", "new york", "über_news-2"}).Return(nil)
+
+		c.MustPost(m, &resp, client.Var("tags", []string{" COVID", "new\tyork", "Covid", "Über_News-2"}))
+
+		assert.Equal(t, "abc", resp.SetTags.Title)
+	})
+
+	t.Run("happy path - clear", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockTags.EXPECT().Set(gomock.Any(), "1", []string{}).Return(nil)
+
+		c.MustPost(m, &resp, client.Var("tags", []string{}))
+
+		assert.Equal(t, "abc", resp.SetTags.Title)
+	})
+
+	t.Run("sad path - invalid tags", func(t *testing.T) {
+		tooMany := make([]string, maxTags+1)
+		for i := range tooMany {
+			tooMany[i] = "tag" + strconv.Itoa(i)
+		}
+		for _, tags := range [][]string{{""}, {"covid!"}, {strings.Repeat("a", maxTagLength+1)}, tooMany} {
+			err := c.Post(m, &resp, client.Var("tags", tags))
+
+			assert.Contains(t, err.Error(), ErrorMessageInvalidTags)
+		}
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("no rows")})
+
+		err := c.Post(m, &resp, client.Var("tags", []string{"covid"}))
+
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+
+	t.Run("sad path - tags disabled", func(t *testing.T) {
+		resolver, err := New(mockStore, nil)
+		assert.NoError(t, err)
+
+		err = client.New(NewServer(resolver)).Post(m, &resp, client.Var("tags", []string{"covid"}))
+
+		assert.Contains(t, err.Error(), ErrorMessageTagsDisabled)
+	})
+}
+
+func TestMutationResolver_CreateCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCategories := store.NewMockCategoriesStore(ctrl)
+	resolver, err := New(nil, nil, WithCategories(mockCategories))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	m := `mutation ($input: CategoryInput!) { createCategory(input: $input) { id, slug, name, position } }`
+	var resp struct {
+		CreateCategory *struct {
+			ID       string
+			Slug     string
+			Name     string
+			Position int
+		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		input := &model.CategoryInput{
+			Slug:  "true-crime",
+			Name:  "True crime",
+			Names: []*model.LocalizedNameInput{{Locale: "pt-br", Name: "Crimes reais"}},
+		}
+		mockCategories.EXPECT().Create(gomock.Any(), input).
+			Return(&model.Category{ID: "5", Slug: "true-crime", Name: "True crime", Position: 5}, nil)
+
+		c.MustPost(m, &resp, client.Var("input", map[string]interface{}{
+			"slug":  "true-crime",
+			"name":  " True crime ",
+			"names": []map[string]string{{"locale": "pt-BR", "name": "Crimes reais"}},
+		}))
+
+		assert.Equal(t, "5", resp.CreateCategory.ID)
+		assert.Equal(t, 5, resp.CreateCategory.Position)
+	})
+
+	t.Run("sad path - invalid category", func(t *testing.T) {
+		for _, input := range []map[string]interface{}{
+			{"slug": "True Crime", "name": "True crime"},
+			{"slug": "true--crime", "name": "True crime"},
+			{"slug": strings.Repeat("a", maxCategorySlugLength+1), "name": "True crime"},
+			{"slug": "true-crime", "name": " "},
+			{"slug": "true-crime", "name": strings.Repeat("a", maxCategoryNameLength+1)},
+			{"slug": "true-crime", "name": "True crime", "names": []map[string]string{{"locale": "portuguese", "name": "Crimes reais"}}},
+			{"slug": "true-crime", "name": "True crime", "names": []map[string]string{{"locale": "pt", "name": ""}}},
+			{"slug": "true-crime", "name": "True crime", "names": []map[string]string{{"locale": "pt", "name": "a"}, {"locale": "PT", "name": "b"}}},
+		} {
+			err := c.Post(m, &resp, client.Var("input", input))
+
+			assert.Contains(t, err.Error(), ErrorMessageInvalidCategory)
+		}
+	})
+
+	t.Run("sad path - duplicate slug", func(t *testing.T) {
+		mockCategories.EXPECT().Create(gomock.Any(), gomock.Any()).
+			Return(nil, &store.ConflictError{Constraint: "categories_slug_key", Err: errors.New("duplicate key")})
+
+		err := c.Post(m, &resp, client.Var("input", map[string]interface{}{"slug": "news", "name": "News"}))
+
+		assert.Contains(t, err.Error(), ErrorCodeConflict)
+	})
+
+	t.Run("sad path - categories disabled", func(t *testing.T) {
+		resolver, err := New(nil, nil)
+		assert.NoError(t, err)
+
+		err = client.New(NewServer(resolver)).Post(m, &resp, client.Var("input", map[string]interface{}{"slug": "news", "name": "News"}))
+
+		assert.Contains(t, err.Error(), ErrorMessageCategoriesDisabled)
+	})
+}
+
+func TestMutationResolver_RenameCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCategories := store.NewMockCategoriesStore(ctrl)
+	resolver, err := New(nil, nil, WithCategories(mockCategories))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	category := &model.Category{ID: "1", Slug: "news", Name: "Breaking news", Position: 1}
+	var resp struct {
+		RenameCategory *struct{ Name string }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockCategories.EXPECT().Rename(gomock.Any(), "1", "Breaking news", []*model.LocalizedNameInput{{Locale: "de", Name: "Eilmeldungen"}}).
+			Return(category, nil)
+
+		c.MustPost(`mutation { renameCategory(id: "1", name: "Breaking news", names: [{locale: "de", name: "Eilmeldungen"}]) { name } }`, &resp)
+
+		assert.Equal(t, "Breaking news", resp.RenameCategory.Name)
+	})
+
+	t.Run("happy path - names kept", func(t *testing.T) {
+		mockCategories.EXPECT().Rename(gomock.Any(), "1", "Breaking news", nil).Return(category, nil)
+
+		c.MustPost(`mutation { renameCategory(id: "1", name: "Breaking news") { name } }`, &resp)
+
+		assert.Equal(t, "Breaking news", resp.RenameCategory.Name)
+	})
+
+	t.Run("sad path - invalid name", func(t *testing.T) {
+		err := c.Post(`mutation { renameCategory(id: "1", name: "") { name } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorMessageInvalidCategory)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockCategories.EXPECT().Rename(gomock.Any(), "9", "Breaking news", nil).
+			Return(nil, &store.NotFoundError{Err: errors.New("no rows")})
+
+		err := c.Post(`mutation { renameCategory(id: "9", name: "Breaking news") { name } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+}
+
+func TestMutationResolver_ArchiveCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCategories := store.NewMockCategoriesStore(ctrl)
+	resolver, err := New(nil, nil, WithCategories(mockCategories))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	var resp struct {
+		ArchiveCategory   *struct{ Archived bool }
+		UnarchiveCategory *struct{ Archived bool }
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockCategories.EXPECT().SetArchived(gomock.Any(), "2", true).
+			Return(&model.Category{ID: "2", Slug: "gossip", Name: "Gossip", Archived: true}, nil)
+
+		c.MustPost(`mutation { archiveCategory(id: "2") { archived } }`, &resp)
+
+		assert.True(t, resp.ArchiveCategory.Archived)
+	})
+
+	t.Run("happy path - unarchive", func(t *testing.T) {
+		mockCategories.EXPECT().SetArchived(gomock.Any(), "2", false).
+			Return(&model.Category{ID: "2", Slug: "gossip", Name: "Gossip"}, nil)
+
+		c.MustPost(`mutation { unarchiveCategory(id: "2") { archived } }`, &resp)
+
+		assert.False(t, resp.UnarchiveCategory.Archived)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockCategories.EXPECT().SetArchived(gomock.Any(), "9", true).
+			Return(nil, &store.NotFoundError{Err: errors.New("no rows")})
+
+		err := c.Post(`mutation { archiveCategory(id: "9") { archived } }`, &resp)
+
+		assert.Contains(t, err.Error(), ErrorCodeNotFound)
+	})
+}
+
+func TestMutationResolver_ReorderCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCategories := store.NewMockCategoriesStore(ctrl)
+	resolver, err := New(nil, nil, WithCategories(mockCategories))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	categories := []*model.Category{
+		{ID: "1", Slug: "news", Name: "News", Position: 1},
+		{ID: "2", Slug: "gossip", Name: "Gossip", Position: 2, Archived: true},
+	}
+	m := `mutation ($ids: [ID!]!) { reorderCategories(ids: $ids) { id, position } }`
+	var resp struct {
+		ReorderCategories []struct {
+			ID       string
+			Position int
+		}
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockCategories.EXPECT().GetAll(gomock.Any(), true).Return(categories, nil)
+		mockCategories.EXPECT().Reorder(gomock.Any(), []string{"2", "1"}).Return([]*model.Category{
+			{ID: "2", Slug: "gossip", Name: "Gossip", Position: 1, Archived: true},
+			{ID: "1", Slug: "news", Name: "News", Position: 2},
+		}, nil)
+
+		c.MustPost(m, &resp, client.Var("ids", []string{"2", "1"}))
+
+		assert.Equal(t, "2", resp.ReorderCategories[0].ID)
+		assert.Equal(t, 1, resp.ReorderCategories[0].Position)
+	})
+
+	t.Run("sad path - invalid order", func(t *testing.T) {
+		for _, ids := range [][]string{{"1"}, {"1", "1"}, {"1", "3"}, {"1", "2", "2"}} {
+			mockCategories.EXPECT().GetAll(gomock.Any(), true).Return(categories, nil)
+
+			err := c.Post(m, &resp, client.Var("ids", ids))
+
+			assert.Contains(t, err.Error(), ErrorMessageInvalidCategoryOrder)
+		}
+	})
+
+	t.Run("sad path - store error", func(t *testing.T) {
+		mockCategories.EXPECT().GetAll(gomock.Any(), true).Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		err := c.Post(m, &resp, client.Var("ids", []string{"2", "1"}))
+
+		assert.Contains(t, err.Error(), ErrorCodeUnavailable)
+	})
+}
+
+func TestMutationResolver_DeleteAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	ID := "1"
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "abc",
+		Description: "abcs",
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Delete(gomock.Any(), ID, nil).Return(short, nil)
+		var resp struct {
+			DeleteAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			deleteAudioShort(id: "1")
+			{
+				title,
+				description
+			}
+		}`
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.DeleteAudioShort.Title)
+		assert.Equal(t, "abcs", resp.DeleteAudioShort.Description)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().Delete(gomock.Any(), ID, nil).Return(nil, errors.New("some error"))
+		var resp struct {
+			DeleteAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			deleteAudioShort(id: "1")
+			{
+				title,
+				description
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(m, &resp)
+		})
+	})
+}
+
+func TestMutationResolver_RestoreAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	ID := "1"
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "abc",
+		Description: "abcs",
+		Status:      model.StatusActive,
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().Restore(gomock.Any(), ID, nil).Return(short, nil)
+		var resp struct {
+			RestoreAudioShort struct{ Title, Status string }
+		}
+		m := `
+		mutation {
+			restoreAudioShort(id: "1")
+			{
+				title,
+				status
+			}
+		}`
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.RestoreAudioShort.Title)
+		assert.Equal(t, "active", resp.RestoreAudioShort.Status)
+	})
+
+	t.Run("sad path - not deleted", func(t *testing.T) {
+		mockStore.EXPECT().Restore(gomock.Any(), ID, nil).Return(nil, &store.NotDeletedError{ID: ID, Status: model.StatusBanned})
+		var resp struct {
+			RestoreAudioShort struct{ Title, Status string }
+		}
+		m := `
+		mutation {
+			restoreAudioShort(id: "1")
+			{
+				title,
+				status
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeConflict)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().Restore(gomock.Any(), ID, nil).Return(nil, errors.New("some error"))
+		var resp struct {
+			RestoreAudioShort struct{ Title, Status string }
+		}
+		m := `
+		mutation {
+			restoreAudioShort(id: "1")
+			{
+				title,
+				status
+			}
+		}`
+		assert.Panics(t, func() {
+			c.MustPost(m, &resp)
+		})
+	})
+}
+
+func TestMutationResolver_HardDeleteAudioShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	resolver, err := New(mockStore, nil)
+	assert.NoError(t, err)
+
+	c := client.New(NewServer(resolver))
+
+	ID := "1"
+	short := &model.AudioShort{
+		ID:          "1",
+		Title:       "abc",
+		Description: "abcs",
+		Category:    &model.Category{ID: "1", Slug: "news", Name: "News", Position: 1},
+		AudioFile:   "a",
+		Creator:     &model.Creator{},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().HardDelete(gomock.Any(), ID, nil).Return(short, nil)
+		var resp struct {
+			HardDeleteAudioShort struct{ Title, Description string }
+		}
+		m := `
+		mutation {
+			hardDeleteAudioShort(id: "1")
+			{
 				title,
 				description
 			}
 		}`
-		c.MustPost(q, &resp)
-		assert.Equal(t, "abc", resp.GetAudioShorts[0].Title)
-		assert.Equal(t, "abcs", resp.GetAudioShorts[0].Description)
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.HardDeleteAudioShort.Title)
+		assert.Equal(t, "abcs", resp.HardDeleteAudioShort.Description)
 	})
 
-	t.Run("sad path - page is 0", func(t *testing.T) {
+	t.Run("happy path - deletes the audio file, HLS package and cover art", func(t *testing.T) {
+		blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+		assert.NoError(t, err)
+		url, err := blobStore.Put(context.Background(), "shorts/abc.mp3", strings.NewReader("ID3"), "audio/mpeg")
+		assert.NoError(t, err)
+		_, err = blobStore.Put(context.Background(), "hls/1/index.m3u8", strings.NewReader("#EXTM3U"), "application/vnd.apple.mpegurl")
+		assert.NoError(t, err)
+		_, err = blobStore.Put(context.Background(), "covers/1/abc-small.png", strings.NewReader("\x89PNG"), "image/png")
+		assert.NoError(t, err)
+		resolver, err := New(mockStore, nil, WithBlobStore(blobStore))
+		assert.NoError(t, err)
+		c := client.New(NewServer(resolver))
+
+		mockStore.EXPECT().HardDelete(gomock.Any(), ID, nil).Return(&model.AudioShort{ID: "1", Title: "abc", AudioFile: url}, nil)
 		var resp struct {
-			GetAudioShorts []struct{ Title, Description string }
+			HardDeleteAudioShort struct{ Title string }
 		}
-		q := `
-		query {
-			getAudioShorts(page: 0, limit: 1) {
-				title,
-				description
+		m := `
+		mutation {
+			hardDeleteAudioShort(id: "1")
+			{
+				title
 			}
 		}`
-		assert.Panics(t, func() {
-			c.MustPost(q, &resp)
-		})
+		c.MustPost(m, &resp)
+		assert.Equal(t, "abc", resp.HardDeleteAudioShort.Title)
+		_, err = blobStore.Stat(context.Background(), "shorts/abc.mp3")
+		assert.Equal(t, storage.ErrNotFound, err)
+		_, err = blobStore.Stat(context.Background(), "hls/1/index.m3u8")
+		assert.Equal(t, storage.ErrNotFound, err)
+		_, err = blobStore.Stat(context.Background(), "covers/1/abc-small.png")
+		assert.Equal(t, storage.ErrNotFound, err)
 	})
 
 	t.Run("sad path - error", func(t *testing.T) {
-		mockStore.EXPECT().GetAll(gomock.Any(), uint16(0), uint16(1)).Return(nil, errors.New("some error"))
+		mockStore.EXPECT().HardDelete(gomock.Any(), ID, nil).Return(nil, errors.New("some error"))
 		var resp struct {
-			GetAudioShorts []struct{ Title, Description string }
+			HardDeleteAudioShort struct{ Title, Description string }
 		}
-		q := `
-		query {
-			getAudioShorts(page: 1, limit: 1) {
+		m := `
+		mutation {
+			hardDeleteAudioShort(id: "1")
+			{
 				title,
 				description
 			}
 		}`
 		assert.Panics(t, func() {
-			c.MustPost(q, &resp)
+			c.MustPost(m, &resp)
 		})
 	})
 }
 
-func TestQueryResolver_GetCreators(t *testing.T) {
+func TestQueryResolver_GetCreator(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockStore := store.NewMockCreatorsStore(ctrl)
 	resolver, err := New(nil, mockStore)
 	assert.NoError(t, err)
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
+	c := client.New(NewServer(resolver))
 
 	creator := &model.Creator{
-		ID:    "1",
-		Name:  "hi",
-		Email: "mockemail@gmail.com",
+		ID:       "1",
+		Username: "jackfrost",
+		Name:     "hi",
+		Email:    "mockemail@gmail.com",
+		Status:   model.CreatorStatusActive,
 	}
 
 	t.Run("happy path", func(t *testing.T) {
-		mockStore.EXPECT().GetAll(gomock.Any(), uint16(0), uint16(1)).Return([]*model.Creator{creator}, nil)
-		var resp struct {
-			GetCreators []struct{ ID, Name string }
-		}
-		q := `
-		query {
-			getCreators(page: 1, limit: 1) {
-				id,
-				name
-			}
-		}`
-		c.MustPost(q, &resp)
-		assert.Equal(t, "1", resp.GetCreators[0].ID)
-		assert.Equal(t, "hi", resp.GetCreators[0].Name)
-	})
-
-	t.Run("happy path - default args", func(t *testing.T) {
-		mockStore.EXPECT().GetAll(gomock.Any(), uint16(0), uint16(10)).Return([]*model.Creator{creator}, nil)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(creator, nil)
 		var resp struct {
-			GetCreators []struct{ ID, Name string }
+			GetCreator struct{ Username, Status string }
 		}
 		q := `
 		query {
-			getCreators {
-				id,
-				name
+			getCreator(id: "1") {
+				username,
+				status
 			}
 		}`
 		c.MustPost(q, &resp)
-		assert.Equal(t, "1", resp.GetCreators[0].ID)
-		assert.Equal(t, "hi", resp.GetCreators[0].Name)
+		assert.Equal(t, "jackfrost", resp.GetCreator.Username)
+		assert.Equal(t, "active", resp.GetCreator.Status)
 	})
 
-	t.Run("sad path - page is 0", func(t *testing.T) {
+	t.Run("sad path - error", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, errors.New("some error"))
 		var resp struct {
-			GetCreators []struct{ ID, Name string }
+			GetCreator struct{ Username, Status string }
 		}
 		q := `
 		query {
-			getCreators(page: 0, limit: 1) {
-				id,
-				name
+			getCreator(id: "1") {
+				username,
+				status
 			}
 		}`
 		assert.Panics(t, func() {
 			c.MustPost(q, &resp)
 		})
 	})
+}
 
-	t.Run("sad path - error", func(t *testing.T) {
-		mockStore.EXPECT().GetAll(gomock.Any(), uint16(0), uint16(1)).Return(nil, errors.New("some error"))
-		var resp struct {
-			GetCreators []struct{ ID, Name string }
-		}
-		q := `
-		query {
-			getCreators(page: 1, limit: 1) {
-				id,
-				name
-			}
-		}`
-		assert.Panics(t, func() {
-			c.MustPost(q, &resp)
-		})
+func TestCreatorResolver_Avatar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCreators := store.NewMockCreatorsStore(ctrl)
+	mockImages := store.NewMockImagesStore(ctrl)
+	resolver, err := New(nil, mockCreators, WithArtwork(mockImages))
+	assert.NoError(t, err)
+	c := client.New(NewServer(resolver))
+
+	creator := &model.Creator{ID: "1", Name: "hi"}
+	avatar := &model.Image{Size: model.ImageSizeMedium, URL: "http://localhost:8080/files/avatars/1/a-medium.jpg", Width: 256,
+		Height: 256, ContentType: "image/jpeg"}
+	var resp struct {
+		GetCreator struct{ Avatar *model.Image }
+	}
+
+	mockCreators.EXPECT().GetByID(gomock.Any(), "1").Return(creator, nil)
+	mockImages.EXPECT().Get(gomock.Any(), store.Avatar, "1").Return([]*model.Image{avatar}, nil)
+
+	c.MustPost(`query { getCreator(id: "1") { avatar(size: medium) { size, url, width, height, content_type } } }`, &resp)
+
+	assert.Equal(t, avatar, resp.GetCreator.Avatar)
+}
+
+func TestMutationResolver_UploadAvatar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCreators := store.NewMockCreatorsStore(ctrl)
+	mockImages := store.NewMockImagesStore(ctrl)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	resolver, err := New(nil, mockCreators, WithBlobStore(blobStore), WithArtwork(mockImages))
+	assert.NoError(t, err)
+	srv := NewServer(resolver)
+
+	m := `mutation ($file: Upload!) { uploadAvatar(id: "1", file: $file) { id } }`
+
+	t.Run("happy path", func(t *testing.T) {
+		mockCreators.EXPECT().GetByID(gomock.Any(), "1").Return(&model.Creator{ID: "1"}, nil)
+		mockImages.EXPECT().Save(gomock.Any(), store.Avatar, "1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ store.ImageOwner, _ string, images []*model.Image) ([]string, error) {
+				// images no larger than the smallest thumbnail are only kept at their original size
+				assert.Len(t, images, 1)
+				assert.Equal(t, model.ImageSizeOriginal, images[0].Size)
+				assert.True(t, strings.HasPrefix(images[0].URL, "http://localhost:8080/files/avatars/1/"))
+				return nil, nil
+			})
+
+		resp := uploadImage(t, srv, m, encodePNG(t, 64, 64))
+
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "1", resp.Data["uploadAvatar"].ID)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockCreators.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+
+		resp := uploadImage(t, srv, m, encodePNG(t, 64, 64))
+
+		assert.Nil(t, resp.Data["uploadAvatar"])
+		assert.NotEmpty(t, resp.Errors)
 	})
 }
 
-func TestMutationResolver_UpdateAudioShort(t *testing.T) {
+func TestMutationResolver_CreateCreator(t *testing.T) {
 	ctrl := gomock.NewController(t)
-	mockStore := store.NewMockAudioShortsStore(ctrl)
-	resolver, err := New(mockStore, nil)
+	mockStore := store.NewMockCreatorsStore(ctrl)
+	resolver, err := New(nil, mockStore)
 	assert.NoError(t, err)
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
+	c := client.New(NewServer(resolver))
 
-	short := &model.AudioShort{
-		ID:          "1",
-		Title:       "abc",
-		Description: "abcs",
-		Category:    model.CategoryNews,
-		AudioFile:   "a",
-		Creator:     &model.Creator{},
+	creator := &model.Creator{
+		ID:       "4",
+		Username: "jackfrost",
+		Name:     "Jack Frost",
+		Email:    "jackfrost@gmail.com",
+		Status:   model.CreatorStatusActive,
 	}
-	input := &model.AudioShortInput{
-		Title:       "abc",
-		Description: "abcs",
-		Category:    model.CategoryNews,
-		AudioFile:   "a",
-		Creator:     &model.CreatorInput{ID: "1"},
+	input := &model.CreatorDetailsInput{
+		Username: "jackfrost",
+		Name:     "Jack Frost",
+		Email:    "jackfrost@gmail.com",
 	}
 
 	t.Run("happy path", func(t *testing.T) {
-		mockStore.EXPECT().Update(gomock.Any(), "1", input).Return(short, nil)
+		mockStore.EXPECT().Create(gomock.Any(), input).Return(creator, nil)
 		var resp struct {
-			UpdateAudioShort struct{ Title, Description string }
+			CreateCreator struct{ ID, Status string }
 		}
 		m := `
 		mutation {
-			updateAudioShort(id: "1", input: {
-				title: "abc",
-				description: "abcs",
-				category: news,
-				audio_file: "a",
-				creator: {
-					id: "1"
-				}
+			createCreator(input: {
+				username: " jackfrost ",
+				name: "Jack Frost",
+				email: "jackfrost@gmail.com"
 			}) {
-				title,
-				description
+				id,
+				status
 			}
 		}`
 		c.MustPost(m, &resp)
-		assert.Equal(t, "abc", resp.UpdateAudioShort.Title)
-		assert.Equal(t, "abcs", resp.UpdateAudioShort.Description)
+		assert.Equal(t, "4", resp.CreateCreator.ID)
+		assert.Equal(t, "active", resp.CreateCreator.Status)
 	})
 
-	t.Run("sad path - no args", func(t *testing.T) {
+	t.Run("sad path - invalid email", func(t *testing.T) {
 		var resp struct {
-			UpdateAudioShort struct{ Title, Description string }
+			CreateCreator struct{ ID, Status string }
 		}
 		m := `
 		mutation {
-			updateAudioShort {
-				title,
-				description
+			createCreator(input: {
+				username: "jackfrost",
+				name: "Jack Frost",
+				email: "jackfrost"
+			}) {
+				id,
+				status
 			}
 		}`
 		assert.Panics(t, func() {
@@ -308,24 +3462,41 @@ func TestMutationResolver_UpdateAudioShort(t *testing.T) {
 		})
 	})
 
+	t.Run("sad path - username taken", func(t *testing.T) {
+		mockStore.EXPECT().Create(gomock.Any(), input).Return(nil, &store.ConflictError{Constraint: "creators_username_key", Err: errors.New("some error")})
+		var resp struct {
+			CreateCreator struct{ ID, Status string }
+		}
+		m := `
+		mutation {
+			createCreator(input: {
+				username: "jackfrost",
+				name: "Jack Frost",
+				email: "jackfrost@gmail.com"
+			}) {
+				id,
+				status
+			}
+		}`
+		err := c.Post(m, &resp)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrorCodeConflict)
+	})
+
 	t.Run("sad path - error", func(t *testing.T) {
-		mockStore.EXPECT().Update(gomock.Any(), "1", input).Return(nil, errors.New("some error"))
+		mockStore.EXPECT().Create(gomock.Any(), input).Return(nil, errors.New("some error"))
 		var resp struct {
-			UpdateAudioShort struct{ Title, Description string }
+			CreateCreator struct{ ID, Status string }
 		}
 		m := `
 		mutation {
-			updateAudioShort(id: "1", input: {
-				title: "abc",
-				description: "abcs",
-				category: news,
-				audio_file: "a",
-				creator: {
-					id: "1"
-				}
+			createCreator(input: {
+				username: "jackfrost",
+				name: "Jack Frost",
+				email: "jackfrost@gmail.com"
 			}) {
-				title,
-				description
+				id,
+				status
 			}
 		}`
 		assert.Panics(t, func() {
@@ -334,72 +3505,58 @@ func TestMutationResolver_UpdateAudioShort(t *testing.T) {
 	})
 }
 
-func TestMutationResolver_CreateAudioShort(t *testing.T) {
+func TestMutationResolver_UpdateCreator(t *testing.T) {
 	ctrl := gomock.NewController(t)
-	mockStore := store.NewMockAudioShortsStore(ctrl)
-	resolver, err := New(mockStore, nil)
+	mockStore := store.NewMockCreatorsStore(ctrl)
+	resolver, err := New(nil, mockStore)
 	assert.NoError(t, err)
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
+	c := client.New(NewServer(resolver))
 
-	short := &model.AudioShort{
-		ID:          "1",
-		Title:       "abc",
-		Description: "abcs",
-		Category:    model.CategoryNews,
-		AudioFile:   "a",
-		Creator:     &model.Creator{},
+	creator := &model.Creator{
+		ID:       "1",
+		Username: "jackfrost",
+		Name:     "Jack Frost",
+		Email:    "jackfrost@gmail.com",
+		Status:   model.CreatorStatusActive,
 	}
-	input := &model.AudioShortInput{
-		Title:       "abc",
-		Description: "abcs",
-		Category:    model.CategoryNews,
-		AudioFile:   "a",
-		Creator:     &model.CreatorInput{ID: "1"},
+	input := &model.CreatorDetailsInput{
+		Username: "jackfrost",
+		Name:     "Jack Frost",
+		Email:    "jackfrost@gmail.com",
 	}
 
 	t.Run("happy path", func(t *testing.T) {
-		mockStore.EXPECT().Create(gomock.Any(), input).Return(short, nil)
+		mockStore.EXPECT().Update(gomock.Any(), "1", input).Return(creator, nil)
 		var resp struct {
-			CreateAudioShort struct{ Title, Description string }
+			UpdateCreator struct{ Name string }
 		}
 		m := `
 		mutation {
-			createAudioShort(input: {
-				title: "abc",
-				description: "abcs",
-				category: news,
-				audio_file: "a",
-				creator: {
-					id: "1"
-				}
+			updateCreator(id: "1", input: {
+				username: "jackfrost",
+				name: "Jack Frost",
+				email: "jackfrost@gmail.com"
 			}) {
-				title,
-				description
+				name
 			}
 		}`
 		c.MustPost(m, &resp)
-		assert.Equal(t, "abc", resp.CreateAudioShort.Title)
-		assert.Equal(t, "abcs", resp.CreateAudioShort.Description)
+		assert.Equal(t, "Jack Frost", resp.UpdateCreator.Name)
 	})
 
 	t.Run("sad path - error", func(t *testing.T) {
-		mockStore.EXPECT().Create(gomock.Any(), input).Return(nil, errors.New("some error"))
+		mockStore.EXPECT().Update(gomock.Any(), "1", input).Return(nil, errors.New("some error"))
 		var resp struct {
-			CreateAudioShort struct{ Title, Description string }
+			UpdateCreator struct{ Name string }
 		}
 		m := `
 		mutation {
-			createAudioShort(input: {
-				title: "abc",
-				description: "abcs",
-				category: news,
-				audio_file: "a",
-				creator: {
-					id: "1"
-				}
+			updateCreator(id: "1", input: {
+				username: "jackfrost",
+				name: "Jack Frost",
+				email: "jackfrost@gmail.com"
 			}) {
-				title,
-				description
+				name
 			}
 		}`
 		assert.Panics(t, func() {
@@ -408,107 +3565,57 @@ func TestMutationResolver_CreateAudioShort(t *testing.T) {
 	})
 }
 
-func TestMutationResolver_DeleteAudioShort(t *testing.T) {
+func TestMutationResolver_SetCreatorStatus(t *testing.T) {
 	ctrl := gomock.NewController(t)
-	mockStore := store.NewMockAudioShortsStore(ctrl)
-	resolver, err := New(mockStore, nil)
+	mockStore := store.NewMockCreatorsStore(ctrl)
+	resolver, err := New(nil, mockStore)
 	assert.NoError(t, err)
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
+	c := client.New(NewServer(resolver))
 
-	ID := "1"
-	short := &model.AudioShort{
-		ID:          "1",
-		Title:       "abc",
-		Description: "abcs",
-		Category:    model.CategoryNews,
-		AudioFile:   "a",
-		Creator:     &model.Creator{},
+	creator := &model.Creator{
+		ID:     "3",
+		Status: model.CreatorStatusBanned,
 	}
 
 	t.Run("happy path", func(t *testing.T) {
-		mockStore.EXPECT().Delete(gomock.Any(), ID).Return(short, nil)
+		mockStore.EXPECT().SetStatus(gomock.Any(), "3", model.CreatorStatusBanned, false).Return(creator, nil)
 		var resp struct {
-			DeleteAudioShort struct{ Title, Description string }
+			SetCreatorStatus struct{ Status string }
 		}
 		m := `
 		mutation {
-			deleteAudioShort(id: "1")
-			{
-				title,
-				description
+			setCreatorStatus(id: "3", status: banned) {
+				status
 			}
 		}`
 		c.MustPost(m, &resp)
-		assert.Equal(t, "abc", resp.DeleteAudioShort.Title)
-		assert.Equal(t, "abcs", resp.DeleteAudioShort.Description)
-	})
-
-	t.Run("sad path - error", func(t *testing.T) {
-		mockStore.EXPECT().Delete(gomock.Any(), ID).Return(nil, errors.New("some error"))
-		var resp struct {
-			DeleteAudioShort struct{ Title, Description string }
-		}
-		m := `
-		mutation {
-			deleteAudioShort(id: "1")
-			{
-				title,
-				description
-			}
-		}`
-		assert.Panics(t, func() {
-			c.MustPost(m, &resp)
-		})
+		assert.Equal(t, "banned", resp.SetCreatorStatus.Status)
 	})
-}
-
-func TestMutationResolver_HardDeleteAudioShort(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	mockStore := store.NewMockAudioShortsStore(ctrl)
-	resolver, err := New(mockStore, nil)
-	assert.NoError(t, err)
-
-	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver})))
-
-	ID := "1"
-	short := &model.AudioShort{
-		ID:          "1",
-		Title:       "abc",
-		Description: "abcs",
-		Category:    model.CategoryNews,
-		AudioFile:   "a",
-		Creator:     &model.Creator{},
-	}
 
-	t.Run("happy path", func(t *testing.T) {
-		mockStore.EXPECT().HardDelete(gomock.Any(), ID).Return(short, nil)
+	t.Run("happy path - cascade", func(t *testing.T) {
+		mockStore.EXPECT().SetStatus(gomock.Any(), "3", model.CreatorStatusBanned, true).Return(creator, nil)
 		var resp struct {
-			HardDeleteAudioShort struct{ Title, Description string }
+			SetCreatorStatus struct{ Status string }
 		}
 		m := `
 		mutation {
-			hardDeleteAudioShort(id: "1")
-			{
-				title,
-				description
+			setCreatorStatus(id: "3", status: banned, cascade: true) {
+				status
 			}
 		}`
 		c.MustPost(m, &resp)
-		assert.Equal(t, "abc", resp.HardDeleteAudioShort.Title)
-		assert.Equal(t, "abcs", resp.HardDeleteAudioShort.Description)
+		assert.Equal(t, "banned", resp.SetCreatorStatus.Status)
 	})
 
 	t.Run("sad path - error", func(t *testing.T) {
-		mockStore.EXPECT().HardDelete(gomock.Any(), ID).Return(nil, errors.New("some error"))
+		mockStore.EXPECT().SetStatus(gomock.Any(), "3", model.CreatorStatusBanned, false).Return(nil, errors.New("some error"))
 		var resp struct {
-			HardDeleteAudioShort struct{ Title, Description string }
+			SetCreatorStatus struct{ Status string }
 		}
 		m := `
 		mutation {
-			hardDeleteAudioShort(id: "1")
-			{
-				title,
-				description
+			setCreatorStatus(id: "3", status: banned) {
+				status
 			}
 		}`
 		assert.Panics(t, func() {
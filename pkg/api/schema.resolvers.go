@@ -6,66 +6,779 @@ package api
 import (
 	"context"
 	"github.com/pkg/errors"
+	"strings"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/nooble/task/audio-short-api/pkg/api/generated"
 	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/captions"
+	"github.com/nooble/task/audio-short-api/pkg/chapters"
+	"github.com/nooble/task/audio-short-api/pkg/hls"
 	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/playback"
+	"github.com/nooble/task/audio-short-api/pkg/store"
 )
 
+func (r *audioShortResolver) AudioFile(ctx context.Context, obj *model.AudioShort) (string, error) {
+	if r.blobStore == nil {
+		return obj.AudioFile, nil
+	}
+	// only audio files in the blob store are streamed
+	if _, ok := r.blobStore.Key(obj.AudioFile); !ok {
+		return obj.AudioFile, nil
+	}
+	if r.signer != nil {
+		return r.signer.URL(obj.ID, playback.ListenerFromContext(ctx)), nil
+	}
+	if r.streamingURL != "" {
+		return r.streamingURL + "/" + obj.ID, nil
+	}
+	return obj.AudioFile, nil
+}
+
+func (r *audioShortResolver) Waveform(ctx context.Context, obj *model.AudioShort, resolution *int) (*model.Waveform, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Waveform Of Audio Short With ID " + obj.ID)
+	if r.waveformsStore == nil {
+		return nil, nil
+	}
+	if resolution != nil && *resolution < 1 {
+		logging.WithContext(ctx).Error(ErrorMessageInvalidResolution)
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageInvalidResolution)
+	}
+	waveform, err := r.waveformsStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if waveform != nil && resolution != nil {
+		downsample(waveform, *resolution)
+	}
+	return waveform, nil
+}
+
+func (r *audioShortResolver) HlsURL(ctx context.Context, obj *model.AudioShort) (*string, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get HLS URL Of Audio Short With ID " + obj.ID)
+	if r.hlsStore == nil {
+		return nil, nil
+	}
+	pkg, err := r.hlsStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if pkg == nil || pkg.Status != store.HLSStatusReady {
+		return nil, nil
+	}
+	if r.signer != nil {
+		url := r.signer.HLSURL(obj.ID, hls.PlaylistName, playback.ListenerFromContext(ctx))
+		return &url, nil
+	}
+	if r.streamingURL != "" {
+		url := r.streamingURL + "/" + obj.ID + "/hls/" + hls.PlaylistName
+		return &url, nil
+	}
+	return &pkg.PlaylistURL, nil
+}
+
+func (r *audioShortResolver) Loudness(ctx context.Context, obj *model.AudioShort) (*model.Loudness, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Loudness Of Audio Short With ID " + obj.ID)
+	if r.loudnessStore == nil {
+		return nil, nil
+	}
+	loudness, err := r.loudnessStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return loudness, nil
+}
+
+func (r *audioShortResolver) Transcript(ctx context.Context, obj *model.AudioShort) (*model.Transcript, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Transcript Of Audio Short With ID " + obj.ID)
+	if r.transcriptsStore == nil {
+		return nil, nil
+	}
+	transcript, err := r.transcriptsStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return transcript, nil
+}
+
+func (r *audioShortResolver) Captions(ctx context.Context, obj *model.AudioShort, format *model.CaptionFormat) (*string, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Captions Of Audio Short With ID " + obj.ID)
+	if r.transcriptsStore == nil {
+		return nil, nil
+	}
+	transcript, err := r.transcriptsStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if transcript == nil {
+		return nil, nil
+	}
+	renderFormat := captions.FormatVTT
+	if format != nil && *format == model.CaptionFormatSrt {
+		renderFormat = captions.FormatSRT
+	}
+	rendered := captions.Render(fromCaptionCues(transcript.Cues), renderFormat)
+	return &rendered, nil
+}
+
+func (r *audioShortResolver) Chapters(ctx context.Context, obj *model.AudioShort) ([]*model.Chapter, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Chapters Of Audio Short With ID " + obj.ID)
+	if r.chaptersStore == nil {
+		return []*model.Chapter{}, nil
+	}
+	saved, err := r.chaptersStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return saved, nil
+}
+
+func (r *audioShortResolver) ChaptersID3(ctx context.Context, obj *model.AudioShort) (*string, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get ID3 Chapters Of Audio Short With ID " + obj.ID)
+	if r.chaptersStore == nil {
+		return nil, nil
+	}
+	saved, err := r.chaptersStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if len(saved) == 0 {
+		return nil, nil
+	}
+	tag, err := chapters.ID3(fromModelChapters(saved))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, errors.New(ErrorMessageReadFailed)
+	}
+	encoded := string(tag)
+	return &encoded, nil
+}
+
+func (r *audioShortResolver) CoverArt(ctx context.Context, obj *model.AudioShort, size *model.ImageSize) (*model.Image, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Cover Art Of Audio Short With ID " + obj.ID)
+	if r.imagesStore == nil {
+		return nil, nil
+	}
+	images, err := r.imagesStore.Get(ctx, store.CoverArt, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return pickImage(images, size), nil
+}
+
+func (r *audioShortResolver) Tags(ctx context.Context, obj *model.AudioShort) ([]string, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Tags Of Audio Short With ID " + obj.ID)
+	if r.tagsStore == nil {
+		return []string{}, nil
+	}
+	tags, err := r.tagsStore.Get(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return tags, nil
+}
+
+func (r *categoryResolver) Name(ctx context.Context, obj *model.Category, locale *string) (string, error) {
+	if locale == nil || r.categoriesStore == nil {
+		return obj.Name, nil
+	}
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Name Of Category With ID " + obj.ID + " In Locale " + *locale)
+	names, err := r.categoriesStore.GetNames(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return "", wrapError(err, ErrorMessageReadFailed)
+	}
+	return pickName(names, *locale, obj.Name), nil
+}
+
+func (r *categoryResolver) Names(ctx context.Context, obj *model.Category) ([]*model.LocalizedName, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Names Of Category With ID " + obj.ID)
+	if r.categoriesStore == nil {
+		return []*model.LocalizedName{}, nil
+	}
+	names, err := r.categoriesStore.GetNames(ctx, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return names, nil
+}
+
+func (r *creatorResolver) Avatar(ctx context.Context, obj *model.Creator, size *model.ImageSize) (*model.Image, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Avatar Of Creator With ID " + obj.ID)
+	if r.imagesStore == nil {
+		return nil, nil
+	}
+	images, err := r.imagesStore.Get(ctx, store.Avatar, obj.ID)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return pickImage(images, size), nil
+}
+
 func (r *mutationResolver) CreateAudioShort(ctx context.Context, input model.AudioShortInput) (*model.AudioShort, error) {
 	ctx = logging.NewContext(ctx)
 	logging.WithContext(ctx).Info("Create Audio Short")
-	short, err := r.shortsStore.Create(ctx, &input)
+	metadata, err := r.validateAudioFile(ctx, input.AudioFile)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageValidationFailed).Error())
+		return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+	}
+	fingerprint, err := r.checkDuplicates(ctx, input.AudioFile, input.Creator.ID, "")
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCreateFailed).Error())
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+		}
+		return nil, wrapError(err, ErrorMessageCreateFailed)
+	}
+	short, err := r.shortsStore.Create(ctx, &input, metadata)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCreateFailed).Error())
-		return nil, errors.New(ErrorMessageCreateFailed)
+		var notActive *store.CreatorNotActiveError
+		if errors.As(err, &notActive) {
+			return nil, wrapError(err, ErrorMessageCreatorNotActive)
+		}
+		return nil, wrapError(err, ErrorMessageCreateFailed)
 	}
+	r.saveFingerprint(ctx, short.ID, fingerprint)
 	return short, nil
 }
 
-func (r *mutationResolver) UpdateAudioShort(ctx context.Context, id string, input model.AudioShortInput) (*model.AudioShort, error) {
+func (r *mutationResolver) UploadAudioShort(ctx context.Context, file graphql.Upload, input model.AudioShortUploadInput) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Upload Audio Short")
+	body, contentType, err := r.validateUpload(&file)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest + ": " + err.Error())
+	}
+	key, url, err := r.storeUpload(ctx, body, contentType)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUploadFailed).Error())
+		return nil, wrapError(err, ErrorMessageUploadFailed)
+	}
+	metadata, err := r.probeAudioFile(ctx, url)
+	if err != nil && r.validateAudio {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageValidationFailed).Error())
+		r.deleteUpload(ctx, key)
+		return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+	}
+	fingerprint, err := r.checkDuplicates(ctx, url, input.Creator.ID, "")
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCreateFailed).Error())
+		r.deleteUpload(ctx, key)
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+		}
+		return nil, wrapError(err, ErrorMessageCreateFailed)
+	}
+	// without audio limits, files that cannot be parsed are stored without metadata
+	short, err := r.shortsStore.Create(ctx, &model.AudioShortInput{
+		Title:       input.Title,
+		Description: input.Description,
+		Category:    input.Category,
+		AudioFile:   url,
+		Creator:     input.Creator,
+	}, metadata)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCreateFailed).Error())
+		r.deleteUpload(ctx, key)
+		var notActive *store.CreatorNotActiveError
+		if errors.As(err, &notActive) {
+			return nil, wrapError(err, ErrorMessageCreatorNotActive)
+		}
+		return nil, wrapError(err, ErrorMessageCreateFailed)
+	}
+	r.saveFingerprint(ctx, short.ID, fingerprint)
+	r.requestWaveform(ctx, short.ID)
+	r.requestHLS(ctx, short.ID)
+	r.requestLoudness(ctx, short.ID)
+	return short, nil
+}
+
+func (r *mutationResolver) UpdateAudioShort(ctx context.Context, id string, input model.AudioShortInput, expectedVersion *int) (*model.AudioShort, error) {
 	ctx = logging.NewContext(ctx)
 	logging.WithContext(ctx).Info("Update Audio Short With ID " + id)
-	short, err := r.shortsStore.Update(ctx, id, &input)
+	replaced, err := r.replacedAudioFile(ctx, id, input.AudioFile)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	// only a new audio file is validated, the one kept has its metadata already
+	var metadata *model.AudioMetadata
+	var fingerprint *store.Fingerprint
+	if replaced != nil {
+		metadata, err = r.validateAudioFile(ctx, input.AudioFile)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageValidationFailed).Error())
+			return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+		}
+		fingerprint, err = r.checkDuplicates(ctx, input.AudioFile, input.Creator.ID, id)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+			var validation *ValidationError
+			if errors.As(err, &validation) {
+				return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+			}
+			return nil, wrapError(err, ErrorMessageUpdateFailed)
+		}
+	}
+	short, err := r.shortsStore.Update(ctx, id, &input, metadata, expectedVersion)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
-		return nil, errors.New(ErrorMessageUpdateFailed)
+		var notActive *store.CreatorNotActiveError
+		if errors.As(err, &notActive) {
+			return nil, wrapError(err, ErrorMessageCreatorNotActive)
+		}
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	// the fingerprint, waveform, HLS package and loudness of the replaced audio file are dropped along with it
+	if replaced != nil {
+		r.saveFingerprint(ctx, short.ID, fingerprint)
+		r.requestWaveform(ctx, short.ID)
+		r.requestHLS(ctx, short.ID)
+		r.requestLoudness(ctx, short.ID)
 	}
 	return short, nil
 }
 
-func (r *mutationResolver) DeleteAudioShort(ctx context.Context, id string) (*model.AudioShort, error) {
+func (r *mutationResolver) PatchAudioShort(ctx context.Context, id string, patch model.AudioShortPatch, expectedVersion *int) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Patch Audio Short With ID " + id)
+	err := validatePatch(ctx, &patch)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, wrapError(err, ErrorMessageBadRequest+": "+err.Error())
+	}
+	var metadata *model.AudioMetadata
+	var replaced *model.AudioShort
+	if patch.AudioFile != nil {
+		replaced, err = r.replacedAudioFile(ctx, id, *patch.AudioFile)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+			return nil, wrapError(err, ErrorMessageUpdateFailed)
+		}
+	}
+	// only a new audio file is validated, the one kept has its metadata already
+	var fingerprint *store.Fingerprint
+	if replaced != nil {
+		metadata, err = r.validateAudioFile(ctx, *patch.AudioFile)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageValidationFailed).Error())
+			return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+		}
+		creatorID := replaced.Creator.ID
+		if patch.Creator != nil {
+			creatorID = patch.Creator.ID
+		}
+		fingerprint, err = r.checkDuplicates(ctx, *patch.AudioFile, creatorID, id)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+			var validation *ValidationError
+			if errors.As(err, &validation) {
+				return nil, wrapError(err, ErrorMessageValidationFailed+": "+err.Error())
+			}
+			return nil, wrapError(err, ErrorMessageUpdateFailed)
+		}
+	}
+	short, err := r.shortsStore.Patch(ctx, id, &patch, metadata, expectedVersion)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		var notActive *store.CreatorNotActiveError
+		if errors.As(err, &notActive) {
+			return nil, wrapError(err, ErrorMessageCreatorNotActive)
+		}
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	// the fingerprint, waveform, HLS package and loudness of the replaced audio file are dropped along with it
+	if replaced != nil {
+		r.saveFingerprint(ctx, short.ID, fingerprint)
+		r.requestWaveform(ctx, short.ID)
+		r.requestHLS(ctx, short.ID)
+		r.requestLoudness(ctx, short.ID)
+	}
+	return short, nil
+}
+
+func (r *mutationResolver) DeleteAudioShort(ctx context.Context, id string, expectedVersion *int) (*model.AudioShort, error) {
 	ctx = logging.NewContext(ctx)
 	logging.WithContext(ctx).Info("Delete Audio Short With ID " + id)
-	short, err := r.shortsStore.Delete(ctx, id)
+	short, err := r.shortsStore.Delete(ctx, id, expectedVersion)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageDeleteFailed).Error())
-		return nil, errors.New(ErrorMessageDeleteFailed)
+		return nil, wrapError(err, ErrorMessageDeleteFailed)
+	}
+	return short, nil
+}
+
+func (r *mutationResolver) RestoreAudioShort(ctx context.Context, id string, expectedVersion *int) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Restore Audio Short With ID " + id)
+	short, err := r.shortsStore.Restore(ctx, id, expectedVersion)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageRestoreFailed).Error())
+		var notActive *store.CreatorNotActiveError
+		if errors.As(err, &notActive) {
+			return nil, wrapError(err, ErrorMessageCreatorNotActive)
+		}
+		return nil, wrapError(err, ErrorMessageRestoreFailed)
 	}
 	return short, nil
 }
 
-func (r *mutationResolver) HardDeleteAudioShort(ctx context.Context, id string) (*model.AudioShort, error) {
+func (r *mutationResolver) HardDeleteAudioShort(ctx context.Context, id string, expectedVersion *int) (*model.AudioShort, error) {
 	ctx = logging.NewContext(ctx)
 	logging.WithContext(ctx).Info("Hard Delete Audio Short With ID " + id)
-	short, err := r.shortsStore.HardDelete(ctx, id)
+	short, err := r.shortsStore.HardDelete(ctx, id, expectedVersion)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageHardDeleteFailed).Error())
-		return nil, errors.New(ErrorMessageHardDeleteFailed)
+		return nil, wrapError(err, ErrorMessageHardDeleteFailed)
+	}
+	r.deleteShortFiles(ctx, short)
+	return short, nil
+}
+
+func (r *mutationResolver) CreateCreator(ctx context.Context, input model.CreatorDetailsInput) (*model.Creator, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Create Creator")
+	err := validateCreatorDetails(&input)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	creator, err := r.creatorsStore.Create(ctx, &input)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCreateFailed).Error())
+		return nil, wrapError(err, ErrorMessageCreateFailed)
+	}
+	return creator, nil
+}
+
+func (r *mutationResolver) UpdateCreator(ctx context.Context, id string, input model.CreatorDetailsInput) (*model.Creator, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Update Creator With ID " + id)
+	err := validateCreatorDetails(&input)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	creator, err := r.creatorsStore.Update(ctx, id, &input)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return creator, nil
+}
+
+func (r *mutationResolver) SetCreatorStatus(ctx context.Context, id string, status model.CreatorStatus, cascade *bool) (*model.Creator, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Set Status Of Creator With ID " + id + " To " + status.String())
+	creator, err := r.creatorsStore.SetStatus(ctx, id, status, cascade != nil && *cascade)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return creator, nil
+}
+
+func (r *mutationResolver) SetTranscript(ctx context.Context, id string, cues []*model.CaptionCueInput) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Set Transcript Of Audio Short With ID " + id)
+	return r.saveTranscript(ctx, id, fromCueInputs(cues))
+}
+
+func (r *mutationResolver) UploadCaptions(ctx context.Context, id string, file graphql.Upload) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Upload Captions Of Audio Short With ID " + id)
+	cues, err := parseCaptions(&file)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageInvalidCaptions).Error())
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			return nil, wrapError(err, ErrorMessageInvalidCaptions+": "+err.Error())
+		}
+		return nil, errors.New(ErrorMessageBadRequest + ": " + err.Error())
+	}
+	return r.saveTranscript(ctx, id, cues)
+}
+
+func (r *mutationResolver) DeleteTranscript(ctx context.Context, id string) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Delete Transcript Of Audio Short With ID " + id)
+	if r.transcriptsStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageTranscriptsDisabled)
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	err = r.transcriptsStore.Delete(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageDeleteFailed).Error())
+		return nil, wrapError(err, ErrorMessageDeleteFailed)
 	}
 	return short, nil
 }
 
-func (r *queryResolver) GetAudioShorts(ctx context.Context, page *int, limit *int) ([]*model.AudioShort, error) {
+func (r *mutationResolver) SetChapters(ctx context.Context, id string, chapters []*model.ChapterInput) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Set Chapters Of Audio Short With ID " + id)
+	return r.saveChapters(ctx, id, fromChapterInputs(chapters))
+}
+
+func (r *mutationResolver) DeleteChapters(ctx context.Context, id string) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Delete Chapters Of Audio Short With ID " + id)
+	if r.chaptersStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageChaptersDisabled)
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	err = r.chaptersStore.Delete(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageDeleteFailed).Error())
+		return nil, wrapError(err, ErrorMessageDeleteFailed)
+	}
+	return short, nil
+}
+
+func (r *mutationResolver) UploadCoverArt(ctx context.Context, id string, file graphql.Upload) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Upload Cover Art Of Audio Short With ID " + id)
+	if r.imagesStore == nil || r.blobStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageArtworkDisabled)
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	err = r.uploadImage(ctx, store.CoverArt, id, &file)
+	if err != nil {
+		return nil, err
+	}
+	return short, nil
+}
+
+func (r *mutationResolver) DeleteCoverArt(ctx context.Context, id string) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Delete Cover Art Of Audio Short With ID " + id)
+	if r.imagesStore == nil || r.blobStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageArtworkDisabled)
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	deleted, err := r.imagesStore.Delete(ctx, store.CoverArt, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageDeleteFailed).Error())
+		return nil, wrapError(err, ErrorMessageDeleteFailed)
+	}
+	r.deleteImages(ctx, deleted)
+	return short, nil
+}
+
+func (r *mutationResolver) UploadAvatar(ctx context.Context, id string, file graphql.Upload) (*model.Creator, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Upload Avatar Of Creator With ID " + id)
+	if r.imagesStore == nil || r.blobStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageArtworkDisabled)
+	}
+	creator, err := r.creatorsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	err = r.uploadImage(ctx, store.Avatar, id, &file)
+	if err != nil {
+		return nil, err
+	}
+	return creator, nil
+}
+
+func (r *mutationResolver) DeleteAvatar(ctx context.Context, id string) (*model.Creator, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Delete Avatar Of Creator With ID " + id)
+	if r.imagesStore == nil || r.blobStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageArtworkDisabled)
+	}
+	creator, err := r.creatorsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	deleted, err := r.imagesStore.Delete(ctx, store.Avatar, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageDeleteFailed).Error())
+		return nil, wrapError(err, ErrorMessageDeleteFailed)
+	}
+	r.deleteImages(ctx, deleted)
+	return creator, nil
+}
+
+func (r *mutationResolver) SetTags(ctx context.Context, id string, tags []string) (*model.AudioShort, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Set Tags Of Audio Short With ID " + id)
+	if r.tagsStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageTagsDisabled)
+	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest + ": " + err.Error())
+	}
+	short, err := r.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	err = r.tagsStore.Set(ctx, id, tags)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return short, nil
+}
+
+func (r *mutationResolver) CreateCategory(ctx context.Context, input model.CategoryInput) (*model.Category, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Create Category")
+	if r.categoriesStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageCategoriesDisabled)
+	}
+	err := validateCategory(&input)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest + ": " + err.Error())
+	}
+	category, err := r.categoriesStore.Create(ctx, &input)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCreateFailed).Error())
+		return nil, wrapError(err, ErrorMessageCreateFailed)
+	}
+	return category, nil
+}
+
+func (r *mutationResolver) RenameCategory(ctx context.Context, id string, name string, names []*model.LocalizedNameInput) (*model.Category, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Rename Category With ID " + id)
+	if r.categoriesStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageCategoriesDisabled)
+	}
+	err := validateCategoryNames(&name, names)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest + ": " + err.Error())
+	}
+	category, err := r.categoriesStore.Rename(ctx, id, name, names)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return category, nil
+}
+
+func (r *mutationResolver) ArchiveCategory(ctx context.Context, id string) (*model.Category, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Archive Category With ID " + id)
+	if r.categoriesStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageCategoriesDisabled)
+	}
+	category, err := r.categoriesStore.SetArchived(ctx, id, true)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return category, nil
+}
+
+func (r *mutationResolver) UnarchiveCategory(ctx context.Context, id string) (*model.Category, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Unarchive Category With ID " + id)
+	if r.categoriesStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageCategoriesDisabled)
+	}
+	category, err := r.categoriesStore.SetArchived(ctx, id, false)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return category, nil
+}
+
+func (r *mutationResolver) ReorderCategories(ctx context.Context, ids []string) ([]*model.Category, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Reorder Categories")
+	if r.categoriesStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageCategoriesDisabled)
+	}
+	categories, err := r.categoriesStore.GetAll(ctx, true)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if !sameIDs(ids, categories) {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageInvalidCategoryOrder)
+	}
+	categories, err = r.categoriesStore.Reorder(ctx, ids)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageUpdateFailed).Error())
+		return nil, wrapError(err, ErrorMessageUpdateFailed)
+	}
+	return categories, nil
+}
+
+func (r *queryResolver) GetAudioShorts(ctx context.Context, first *int, after *string, filter *model.AudioShortFilter, orderBy *model.AudioShortOrder, includeStatuses []model.Status) (*model.AudioShortConnection, error) {
 	ctx = logging.NewContext(ctx)
 	logging.WithContext(ctx).Info("Get Audio Shorts")
-	if *page < 1 {
+	cursor, err := parsePagination(first, after, store.ShortsOrderKey(orderBy))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
 		return nil, errors.New(ErrorMessageBadRequest)
 	}
-	shorts, err := r.shortsStore.GetAll(ctx, uint16(*page)-1, uint16(*limit))
+	if filter != nil && len(filter.Tags) > 0 {
+		filter.Tags, err = normalizeTags(filter.Tags)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+			return nil, errors.New(ErrorMessageBadRequest + ": " + err.Error())
+		}
+	}
+	shorts, err := r.shortsStore.GetAll(ctx, uint16(*first), cursor, filter, orderBy, includeStatuses)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
-		return nil, errors.New(ErrorMessageReadFailed)
+		return nil, wrapError(err, ErrorMessageReadFailed)
 	}
 	return shorts, nil
 }
@@ -76,30 +789,177 @@ func (r *queryResolver) GetAudioShort(ctx context.Context, id string) (*model.Au
 	short, err := r.shortsStore.GetByID(ctx, id)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
-		return nil, errors.New(ErrorMessageReadFailed)
+		return nil, wrapError(err, ErrorMessageReadFailed)
 	}
 	return short, nil
 }
 
-func (r *queryResolver) GetCreators(ctx context.Context, page *int, limit *int) ([]*model.Creator, error) {
+func (r *queryResolver) SearchAudioShorts(ctx context.Context, query string, first *int, after *string) (*model.AudioShortSearchConnection, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Search Audio Shorts")
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	cursor, err := parsePagination(first, after, store.SearchOrderKey)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	results, err := r.shortsStore.Search(ctx, query, uint16(*first), cursor)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return results, nil
+}
+
+func (r *queryResolver) GetCreators(ctx context.Context, first *int, after *string) (*model.CreatorConnection, error) {
 	ctx = logging.NewContext(ctx)
 	logging.WithContext(ctx).Info("Get Creators")
-	if *page < 1 {
+	cursor, err := parsePagination(first, after, store.CreatorsOrderKey)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
 		return nil, errors.New(ErrorMessageBadRequest)
 	}
-	shorts, err := r.creatorsStore.GetAll(ctx, uint16(*page)-1, uint16(*limit))
+	creators, err := r.creatorsStore.GetAll(ctx, uint16(*first), cursor)
 	if err != nil {
 		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
-		return nil, errors.New(ErrorMessageReadFailed)
+		return nil, wrapError(err, ErrorMessageReadFailed)
 	}
-	return shorts, nil
+	return creators, nil
+}
+
+func (r *queryResolver) GetCreator(ctx context.Context, id string) (*model.Creator, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Creator With ID " + id)
+	creator, err := r.creatorsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return creator, nil
+}
+
+func (r *queryResolver) DuplicatesOf(ctx context.Context, id string) ([]*model.AudioShortDuplicate, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Duplicates Of Audio Short With ID " + id)
+	duplicates := []*model.AudioShortDuplicate{}
+	if r.fingerprintsStore == nil {
+		return duplicates, nil
+	}
+	fingerprint, err := r.fingerprintsStore.Get(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if fingerprint == nil {
+		return duplicates, nil
+	}
+	found, err := r.findDuplicates(ctx, fingerprint, "", id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	if len(found) == 0 {
+		return duplicates, nil
+	}
+	ids := make([]string, 0, len(found))
+	for _, d := range found {
+		ids = append(ids, d.shortID)
+	}
+	shorts, err := r.shortsStore.GetByIDs(ctx, ids)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	byID := make(map[string]*model.AudioShort, len(shorts))
+	for _, short := range shorts {
+		byID[short.ID] = short
+	}
+	for _, d := range found {
+		short, ok := byID[d.shortID]
+		if !ok {
+			// hard deleted in the meantime
+			continue
+		}
+		duplicates = append(duplicates, &model.AudioShortDuplicate{Node: short, Exact: d.exact, Similarity: d.similarity})
+	}
+	return duplicates, nil
 }
 
+func (r *queryResolver) GetTags(ctx context.Context, first *int, after *string) (*model.TagConnection, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Tags")
+	if r.tagsStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageTagsDisabled)
+	}
+	cursor, err := parsePagination(first, after, store.TagsOrderKey)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	tags, err := r.tagsStore.GetAll(ctx, uint16(*first), cursor)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return tags, nil
+}
+
+func (r *queryResolver) AutocompleteTags(ctx context.Context, prefix string, first *int) ([]*model.Tag, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Autocomplete Tags")
+	if r.tagsStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageTagsDisabled)
+	}
+	prefix = normalizeTag(prefix)
+	if prefix == "" {
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	_, err := parsePagination(first, nil, store.TagsOrderKey)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageBadRequest).Error())
+		return nil, errors.New(ErrorMessageBadRequest)
+	}
+	tags, err := r.tagsStore.Autocomplete(ctx, prefix, uint16(*first))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return tags, nil
+}
+
+func (r *queryResolver) GetCategories(ctx context.Context, includeArchived *bool) ([]*model.Category, error) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Get Categories")
+	if r.categoriesStore == nil {
+		return nil, errors.New(ErrorMessageBadRequest + ": " + ErrorMessageCategoriesDisabled)
+	}
+	categories, err := r.categoriesStore.GetAll(ctx, includeArchived != nil && *includeArchived)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageReadFailed).Error())
+		return nil, wrapError(err, ErrorMessageReadFailed)
+	}
+	return categories, nil
+}
+
+// AudioShort returns generated.AudioShortResolver implementation.
+func (r *Resolver) AudioShort() generated.AudioShortResolver { return &audioShortResolver{r} }
+
+// Category returns generated.CategoryResolver implementation.
+func (r *Resolver) Category() generated.CategoryResolver { return &categoryResolver{r} }
+
+// Creator returns generated.CreatorResolver implementation.
+func (r *Resolver) Creator() generated.CreatorResolver { return &creatorResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+type audioShortResolver struct{ *Resolver }
+type categoryResolver struct{ *Resolver }
+type creatorResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
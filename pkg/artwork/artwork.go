@@ -0,0 +1,249 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinDimension is the minimum width and height in pixels of images
+	MinDimension = 64
+	// MaxDimension is the maximum width and height in pixels of images, which also bounds the memory they are decoded in
+	MaxDimension = 4096
+
+	ContentTypePNG  = "image/png"
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypeWebP = "image/webp"
+
+	// jpegQuality is the quality thumbnails of JPEG images are encoded with
+	jpegQuality = 85
+)
+
+var (
+	// ErrUnsupported is returned by Probe for files that are not PNG, JPEG or WebP images
+	ErrUnsupported = errors.New("Image must be a PNG, JPEG or WebP file")
+	// ErrCorrupt is returned by Probe and Thumbnails for images whose headers or pixels cannot be read
+	ErrCorrupt = errors.New("Image is corrupt")
+	// ErrTooSmall is returned by Validate for images narrower or lower than MinDimension
+	ErrTooSmall = errors.New("Image must be at least 64x64 pixels")
+	// ErrTooLarge is returned by Validate for images wider or higher than MaxDimension
+	ErrTooLarge = errors.New("Image must be at most 4096x4096 pixels")
+)
+
+// extensions are the extensions of the blobs of images, by content type
+var extensions = map[string]string{
+	ContentTypePNG:  ".png",
+	ContentTypeJPEG: ".jpg",
+	ContentTypeWebP: ".webp",
+}
+
+// Extension returns the extension of image files of the content type, e.g. ".png"
+func Extension(contentType string) string {
+	return extensions[contentType]
+}
+
+// Image is an encoded image file
+type Image struct {
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Probe reads the headers of the image file to describe it, without decoding its pixels
+func Probe(data []byte) (*Image, error) {
+	img := &Image{Data: data}
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		img.ContentType = ContentTypePNG
+		cfg, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(ErrCorrupt, err.Error())
+		}
+		img.Width, img.Height = cfg.Width, cfg.Height
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		img.ContentType = ContentTypeJPEG
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(ErrCorrupt, err.Error())
+		}
+		img.Width, img.Height = cfg.Width, cfg.Height
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		img.ContentType = ContentTypeWebP
+		var err error
+		img.Width, img.Height, err = webPSize(data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+	return img, nil
+}
+
+// webPSize returns the size of a WebP image from the header of its first chunk, which is a lossy VP8 frame, a lossless
+// VP8L bitstream or the VP8X header of an extended file, see the WebP container specification
+func webPSize(data []byte) (width, height int, err error) {
+	if len(data) < 30 {
+		return 0, 0, errors.Wrap(ErrCorrupt, "WebP header too short")
+	}
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// a 3 byte frame tag, the start code and 14 bit dimensions with a 2 bit scale
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return 0, 0, errors.Wrap(ErrCorrupt, "invalid VP8 start code")
+		}
+		width = int(binary.LittleEndian.Uint16(chunk[6:]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(chunk[8:]) & 0x3fff)
+	case "VP8L":
+		// the signature byte, then 14 bits each of width and height minus one
+		if chunk[0] != 0x2f {
+			return 0, 0, errors.Wrap(ErrCorrupt, "invalid VP8L signature")
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:])
+		width = int(bits&0x3fff) + 1
+		height = int(bits>>14&0x3fff) + 1
+	case "VP8X":
+		// 4 bytes of flags, then 24 bits each of canvas width and height minus one
+		width = int(uint32(chunk[4])|uint32(chunk[5])<<8|uint32(chunk[6])<<16) + 1
+		height = int(uint32(chunk[7])|uint32(chunk[8])<<8|uint32(chunk[9])<<16) + 1
+	default:
+		return 0, 0, errors.Wrap(ErrCorrupt, "unknown WebP chunk "+string(data[12:16]))
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, errors.Wrap(ErrCorrupt, "empty WebP image")
+	}
+	return width, height, nil
+}
+
+// Validate returns every problem of the dimensions of the image
+func Validate(img *Image) []error {
+	var problems []error
+	if img.Width < MinDimension || img.Height < MinDimension {
+		problems = append(problems, ErrTooSmall)
+	}
+	if img.Width > MaxDimension || img.Height > MaxDimension {
+		problems = append(problems, ErrTooLarge)
+	}
+	return problems
+}
+
+// Thumbnails decodes the image and returns, for each of the sizes smaller than its longer side, a thumbnail that fits
+// within a square of the size, in the format of the image. WebP images get no thumbnails, as the standard library
+// cannot decode them
+func Thumbnails(img *Image, sizes []int) (map[int]*Image, error) {
+	thumbnails := map[int]*Image{}
+	if img.ContentType == ContentTypeWebP {
+		return thumbnails, nil
+	}
+	var decoded image.Image
+	for _, size := range sizes {
+		if size >= img.Width && size >= img.Height {
+			continue
+		}
+		if decoded == nil {
+			var err error
+			decoded, _, err = image.Decode(bytes.NewReader(img.Data))
+			if err != nil {
+				return nil, errors.Wrap(ErrCorrupt, err.Error())
+			}
+		}
+		resized := resize(decoded, size)
+		buf := &bytes.Buffer{}
+		var err error
+		if img.ContentType == ContentTypePNG {
+			err = png.Encode(buf, resized)
+		} else {
+			err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		thumbnails[size] = &Image{
+			ContentType: img.ContentType,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Data:        buf.Bytes(),
+		}
+	}
+	return thumbnails, nil
+}
+
+// resize scales the image down to fit within a square of the size, keeping its aspect ratio; every pixel of the
+// thumbnail is the mean of the pixels of the image it covers, weighted by how much of them it covers
+func resize(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	dw, dh := size, size
+	if sw > sh {
+		dh = max(1, (sh*size+sw/2)/sw)
+	} else {
+		dw = max(1, (sw*size+sh/2)/sh)
+	}
+
+	// the image is converted to premultiplied RGBA, so that transparent pixels do not bleed their color
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	xWeights := boxWeights(sw, dw)
+	yWeights := boxWeights(sh, dh)
+	for y, yw := range yWeights {
+		for x, xw := range xWeights {
+			var sum [4]float64
+			var total float64
+			for _, wy := range yw {
+				for _, wx := range xw {
+					weight := wy.weight * wx.weight
+					i := rgba.PixOffset(wx.index, wy.index)
+					for c := range sum {
+						sum[c] += weight * float64(rgba.Pix[i+c])
+					}
+					total += weight
+				}
+			}
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8(sum[c]/total + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+type boxWeight struct {
+	index  int
+	weight float64
+}
+
+// boxWeights returns, for each of the dst pixels a row or column of src pixels is scaled down to, the src pixels it
+// covers and how much of each
+func boxWeights(src, dst int) [][]boxWeight {
+	weights := make([][]boxWeight, dst)
+	scale := float64(src) / float64(dst)
+	for d := range weights {
+		start, end := float64(d)*scale, float64(d+1)*scale
+		for s := int(start); float64(s) < end && s < src; s++ {
+			weight := math.Min(end, float64(s+1)) - math.Max(start, float64(s))
+			if weight > 0 {
+				weights[d] = append(weights[d], boxWeight{index: s, weight: weight})
+			}
+		}
+	}
+	return weights
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
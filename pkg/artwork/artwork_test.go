@@ -0,0 +1,192 @@
+package artwork
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// encodePNG returns a PNG file of the size, filled with the color
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+// encodeJPEG returns a gray JPEG file of the size
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, jpeg.Encode(buf, image.NewGray(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+// webP returns the RIFF header of a WebP file whose first chunk is of the type, followed by the chunk data
+func webP(chunkType string, chunk ...byte) []byte {
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"+chunkType+"\x00\x00\x00\x00"), chunk...)
+	return append(data, make([]byte, 16)...)
+}
+
+func TestProbe(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want *Image
+	}{
+		{
+			name: "png",
+			data: encodePNG(t, 300, 200, color.White),
+			want: &Image{ContentType: ContentTypePNG, Width: 300, Height: 200},
+		},
+		{
+			name: "jpeg",
+			data: encodeJPEG(t, 120, 640),
+			want: &Image{ContentType: ContentTypeJPEG, Width: 120, Height: 640},
+		},
+		{
+			name: "lossy webp",
+			// frame tag, start code, then 640 and 480 with a scale of 0
+			data: webP("VP8 ", 0, 0, 0, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01),
+			want: &Image{ContentType: ContentTypeWebP, Width: 640, Height: 480},
+		},
+		{
+			name: "lossless webp",
+			// signature, then 100-1 in 14 bits and 50-1 in the next 14 bits
+			data: webP("VP8L", 0x2f, 0x63, 0x40, 0x0c, 0x00),
+			want: &Image{ContentType: ContentTypeWebP, Width: 100, Height: 50},
+		},
+		{
+			name: "extended webp",
+			// flags, then 1000-1 and 2000-1 in 24 bits each
+			data: webP("VP8X", 0, 0, 0, 0, 0xe7, 0x03, 0x00, 0xcf, 0x07, 0x00),
+			want: &Image{ContentType: ContentTypeWebP, Width: 1000, Height: 2000},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			img, err := Probe(c.data)
+
+			assert.NoError(t, err)
+			c.want.Data = c.data
+			assert.Equal(t, c.want, img)
+		})
+	}
+
+	t.Run("sad path - unsupported", func(t *testing.T) {
+		for _, data := range [][]byte{nil, []byte("GIF89a"), []byte("RIFF\x00\x00\x00\x00WAVEfmt ")} {
+			_, err := Probe(data)
+
+			assert.Equal(t, ErrUnsupported, err)
+		}
+	})
+
+	t.Run("sad path - corrupt", func(t *testing.T) {
+		truncated := encodePNG(t, 100, 100, color.White)[:20]
+		for _, data := range [][]byte{truncated, []byte("\xff\xd8\xff\x00"), webP("VP8 ", 0, 0, 0, 1, 2, 3), webP("ALPH")} {
+			_, err := Probe(data)
+
+			assert.Equal(t, ErrCorrupt, errors.Cause(err))
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Validate(&Image{Width: 64, Height: 4096}))
+	assert.Equal(t, []error{ErrTooSmall}, Validate(&Image{Width: 63, Height: 100}))
+	assert.Equal(t, []error{ErrTooLarge}, Validate(&Image{Width: 100, Height: 4097}))
+	assert.Equal(t, []error{ErrTooSmall, ErrTooLarge}, Validate(&Image{Width: 10, Height: 5000}))
+}
+
+func TestThumbnails(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		img, err := Probe(encodePNG(t, 400, 200, color.NRGBA{R: 255, A: 255}))
+		assert.NoError(t, err)
+
+		thumbnails, err := Thumbnails(img, []int{64, 256, 400, 1024})
+
+		assert.NoError(t, err)
+		assert.Len(t, thumbnails, 2)
+		for size, height := range map[int]int{64: 32, 256: 128} {
+			thumbnail := thumbnails[size]
+			assert.Equal(t, ContentTypePNG, thumbnail.ContentType)
+			assert.Equal(t, size, thumbnail.Width)
+			assert.Equal(t, height, thumbnail.Height)
+			decoded, err := png.Decode(bytes.NewReader(thumbnail.Data))
+			assert.NoError(t, err)
+			assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(decoded.At(size/2, height/2)))
+		}
+	})
+
+	t.Run("happy path - jpeg", func(t *testing.T) {
+		img, err := Probe(encodeJPEG(t, 100, 300))
+		assert.NoError(t, err)
+
+		thumbnails, err := Thumbnails(img, []int{64})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ContentTypeJPEG, thumbnails[64].ContentType)
+		assert.Equal(t, 21, thumbnails[64].Width)
+		assert.Equal(t, 64, thumbnails[64].Height)
+		_, err = jpeg.Decode(bytes.NewReader(thumbnails[64].Data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("happy path - webp", func(t *testing.T) {
+		img, err := Probe(webP("VP8L", 0x2f, 0x63, 0x40, 0x0c, 0x00))
+		assert.NoError(t, err)
+
+		thumbnails, err := Thumbnails(img, []int{64})
+
+		assert.NoError(t, err)
+		assert.Empty(t, thumbnails)
+	})
+}
+
+func TestResize(t *testing.T) {
+	// a checkerboard of black and white pixels averages to gray
+	src := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if (x+y)%2 == 0 {
+				src.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	dst := resize(src, 2)
+
+	assert.Equal(t, image.Rect(0, 0, 2, 2), dst.Bounds())
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			assert.Equal(t, color.RGBA{R: 128, G: 128, B: 128, A: 255}, dst.RGBAAt(x, y))
+		}
+	}
+
+	// scaling 4 pixels down to 3, each of the middle pixels is shared by two of the 3
+	assert.Equal(t, [][]boxWeight{
+		{{0, 1}, {1, 1.0 / 3}},
+		{{1, 2.0 / 3}, {2, 2.0 / 3}},
+		{{2, 1.0 / 3}, {3, 1}},
+	}, roundWeights(boxWeights(4, 3)))
+}
+
+// roundWeights rounds the weights to 1/3 for them to compare equal
+func roundWeights(weights [][]boxWeight) [][]boxWeight {
+	for _, w := range weights {
+		for i := range w {
+			w[i].weight = float64(int(w[i].weight*3+0.5)) / 3
+		}
+	}
+	return weights
+}
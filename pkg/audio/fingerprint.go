@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FingerprintFrameRate is the number of frames per second of acoustic fingerprints
+	FingerprintFrameRate = 10
+	// fingerprintMaxShift is the number of frames fingerprints are shifted by when compared, for audio that was trimmed
+	// or padded by up to 2 seconds
+	fingerprintMaxShift = 2 * FingerprintFrameRate
+	// bits of the frames of acoustic fingerprints
+	bitEnergy        = 1 << 0
+	bitZeroCrossings = 1 << 1
+	fingerprintBits  = 2
+)
+
+// Fingerprint identifies the content of an audio file. SHA256 tells apart exact copies of the file; the acoustic
+// fingerprint describes how the audio sounds, one byte per frame, so that copies which were converted to other sample
+// formats or rates, turned up or down or slightly trimmed are still found
+type Fingerprint struct {
+	SHA256 string
+	// nil for audio that cannot be decoded
+	Acoustic []byte
+}
+
+// NewFingerprint hashes the audio file of the given size and, when it can be decoded, computes its acoustic
+// fingerprint; only the hash is returned for audio that cannot be decoded so far, e.g. other than PCM WAVE and MP3 files
+func NewFingerprint(r io.ReaderAt, size int64) (*Fingerprint, error) {
+	hash := sha256.New()
+	_, err := io.Copy(hash, io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := &Fingerprint{SHA256: hex.EncodeToString(hash.Sum(nil))}
+
+	stream, err := openPCM(r, size)
+	if errors.Cause(err) == ErrUnsupported {
+		return fingerprint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fingerprint.Acoustic, err = acousticFingerprint(stream)
+	if err != nil {
+		return nil, err
+	}
+	return fingerprint, nil
+}
+
+// acousticFingerprint splits the audio, mixed down to mono, into frames of a tenth of a second, and sets a bit for
+// each frame louder than the one before, and another one for each frame with more zero crossings, which roughly
+// follows its pitch. Both only depend on the shape of the audio, not on its volume nor its sample rate
+func acousticFingerprint(stream *pcmStream) ([]byte, error) {
+	frameLength := int64(stream.sampleRate / FingerprintFrameRate)
+	if frameLength == 0 {
+		return nil, corrupt("sample rate too low")
+	}
+	frames := stream.frames / frameLength
+	energies := make([]float64, frames)
+	crossings := make([]int, frames)
+	var previous float64
+	err := stream.each(func(i int64, samples []float64) {
+		frame := i / frameLength
+		if frame >= frames {
+			return
+		}
+		var mono float64
+		for _, sample := range samples {
+			mono += sample
+		}
+		mono /= float64(len(samples))
+		energies[frame] += mono * mono
+		if i%frameLength > 0 && (mono < 0) != (previous < 0) {
+			crossings[frame]++
+		}
+		previous = mono
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if frames < 2 {
+		return []byte{}, nil
+	}
+	fingerprint := make([]byte, frames-1)
+	for i := range fingerprint {
+		if energies[i+1] > energies[i] {
+			fingerprint[i] |= bitEnergy
+		}
+		if crossings[i+1] > crossings[i] {
+			fingerprint[i] |= bitZeroCrossings
+		}
+	}
+	return fingerprint, nil
+}
+
+// Similarity compares two acoustic fingerprints and returns the share of their bits that match, from 0 to 1, at the
+// best of the shifts of up to 2 seconds; bits of the longer fingerprint that are not matched count as different, so
+// that a clip is not similar to a longer one it is only part of. Unrelated audio is around 0.5
+func Similarity(a, b []byte) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 0
+	}
+	best := 0
+	for shift := -fingerprintMaxShift; shift <= fingerprintMaxShift; shift++ {
+		matches := 0
+		for i := range a {
+			j := i + shift
+			if j < 0 || j >= len(b) {
+				continue
+			}
+			matches += fingerprintBits - popCount(a[i]^b[j])
+		}
+		if matches > best {
+			best = matches
+		}
+	}
+	return float64(best) / float64(longest*fingerprintBits)
+}
+
+// popCount returns the number of bits set in the fingerprint frame
+func popCount(b byte) int {
+	return int(b&bitEnergy) + int(b&bitZeroCrossings)>>1
+}
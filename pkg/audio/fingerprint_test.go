@@ -0,0 +1,96 @@
+package audio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTones returns the 16 bit mono WAVE file at the sample rate of tenths of a second of tones, each of a random
+// frequency and volume of the seed, times the gain
+func newTones(seed int64, tenths int, sampleRate int, gain float64) []byte {
+	random := rand.New(rand.NewSource(seed))
+	var data []byte
+	for i := 0; i < tenths; i++ {
+		frequency := 100 + random.Float64()*1400
+		volume := 0.1 + random.Float64()*0.8
+		for j := 0; j < sampleRate/10; j++ {
+			t := float64(i)/10 + float64(j)/float64(sampleRate)
+			data = append(data, le16(int(gain*volume*math.Sin(2*math.Pi*frequency*t)*0x7FFF))...)
+		}
+	}
+	fmtChunk := join([]byte("fmt "), le32(16), le16(wavFormatPCM), le16(1), le32(sampleRate),
+		le32(2*sampleRate), le16(2), le16(16))
+	body := join([]byte("WAVE"), fmtChunk, []byte("data"), le32(len(data)), data)
+	return join([]byte("RIFF"), le32(len(body)), body)
+}
+
+func fingerprintOf(t *testing.T, file []byte) *Fingerprint {
+	fingerprint, err := NewFingerprint(bytes.NewReader(file), int64(len(file)))
+	assert.NoError(t, err)
+	return fingerprint
+}
+
+func TestNewFingerprint(t *testing.T) {
+	t.Run("wav", func(t *testing.T) {
+		file := newTones(1, 50, 8000, 1)
+		hash := sha256.Sum256(file)
+
+		fingerprint := fingerprintOf(t, file)
+
+		assert.Equal(t, hex.EncodeToString(hash[:]), fingerprint.SHA256)
+		assert.Len(t, fingerprint.Acoustic, 49)
+	})
+
+	t.Run("not decodable", func(t *testing.T) {
+		file := newADTS(10)
+		hash := sha256.Sum256(file)
+
+		fingerprint := fingerprintOf(t, file)
+
+		assert.Equal(t, hex.EncodeToString(hash[:]), fingerprint.SHA256)
+		assert.Nil(t, fingerprint.Acoustic)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		file := newWAVOf(wavFormatPCM, 1, 16, nil)
+
+		fingerprint, err := NewFingerprint(bytes.NewReader(file), int64(len(file)))
+
+		assert.Nil(t, fingerprint)
+		assert.Equal(t, ErrCorrupt, errors.Cause(err))
+	})
+}
+
+func TestSimilarity(t *testing.T) {
+	original := fingerprintOf(t, newTones(1, 300, 8000, 1)).Acoustic
+
+	cases := []struct {
+		name     string
+		other    []byte
+		min, max float64
+	}{
+		{"same", original, 1, 1},
+		{"quieter", fingerprintOf(t, newTones(1, 300, 8000, 0.25)).Acoustic, 0.95, 1},
+		{"resampled", fingerprintOf(t, newTones(1, 300, 16000, 1)).Acoustic, 0.95, 1},
+		{"trimmed", original[5:], 0.95, 1},
+		{"first half", original[:150], 0.45, 0.55},
+		{"other", fingerprintOf(t, newTones(2, 300, 8000, 1)).Acoustic, 0.3, 0.75},
+		{"empty", []byte{}, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			similarity := Similarity(original, c.other)
+
+			assert.GreaterOrEqual(t, similarity, c.min)
+			assert.LessOrEqual(t, similarity, c.max)
+			assert.Equal(t, similarity, Similarity(c.other, original))
+		})
+	}
+}
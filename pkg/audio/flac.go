@@ -0,0 +1,57 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+)
+
+// flacStreamInfoSize is the size of a STREAMINFO metadata block, header included
+const flacStreamInfoSize = 4 + 34
+
+// streamInfo is the part of a FLAC STREAMINFO block that describes the stream
+type streamInfo struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	Samples       int64
+}
+
+// parseStreamInfo parses the STREAMINFO metadata block, which FLAC streams start with, at b
+func parseStreamInfo(b []byte) (*streamInfo, error) {
+	if len(b) < flacStreamInfoSize || b[0]&0x7F != 0 {
+		return nil, corrupt("missing FLAC STREAMINFO")
+	}
+	// after the block header and the block and frame size bounds, the fields are packed as
+	// 20 bits sample rate, 3 bits channels - 1, 5 bits bits per sample - 1 and 36 bits total samples
+	b = b[4+10:]
+	return &streamInfo{
+		SampleRate:    int(b[0])<<12 | int(b[1])<<4 | int(b[2]>>4),
+		Channels:      int(b[2]>>1&7) + 1,
+		BitsPerSample: int(b[2]&1)<<4 | int(b[3]>>4) + 1,
+		Samples:       int64(b[3]&0xF)<<32 | int64(b[4])<<24 | int64(b[5])<<16 | int64(b[6])<<8 | int64(b[7]),
+	}, nil
+}
+
+// probeFLAC describes a native FLAC file from its STREAMINFO block
+func probeFLAC(r io.ReaderAt, size int64) (*Metadata, error) {
+	b, err := readAt(r, 0, minInt64(size, 4+flacStreamInfoSize))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(b, []byte("fLaC")) {
+		return nil, corrupt("missing FLAC signature")
+	}
+	info, err := parseStreamInfo(b[4:])
+	if err != nil {
+		return nil, err
+	}
+	if info.Samples == 0 {
+		return nil, unsupported("FLAC stream of unknown length")
+	}
+	return &Metadata{
+		Codec:      CodecFLAC,
+		Duration:   samplesDuration(info.Samples, info.SampleRate),
+		SampleRate: info.SampleRate,
+		Channels:   info.Channels,
+	}, nil
+}
@@ -0,0 +1,247 @@
+package audio
+
+import (
+	"io"
+	"math"
+)
+
+const (
+	// ReplayGainReference is the loudness in LUFS ReplayGain 2.0 brings audio to
+	ReplayGainReference = -18
+	// replayGainHeadroom is the true peak in dBTP the replay gain keeps audio below, so that it does not clip
+	replayGainHeadroom = -1
+	// SilenceThreshold is the loudness in LUFS below which a tenth of a second of audio is silent
+	SilenceThreshold = -60
+
+	// gating blocks of 400 ms overlap by 75%, so a block starts every 100 ms, see ITU-R BS.1770-4
+	loudnessBlockSteps = 4
+	loudnessStepRate   = 10
+	absoluteGate       = -70
+	relativeGate       = -10
+	// true peaks are measured on audio oversampled 4 times, with a windowed sinc of 12 taps per phase
+	oversampling    = 4
+	oversamplingTap = 12
+)
+
+// Loudness is the loudness of audio measured after ITU-R BS.1770-4 and EBU R 128
+type Loudness struct {
+	// Integrated is the gated loudness in LUFS of the whole audio, negative infinity for silence
+	Integrated float64
+	// TruePeak is the highest level in dBTP of the audio reconstructed between its samples
+	TruePeak float64
+	// SilenceRatio is the share of the tenths of a second of the audio quieter than SilenceThreshold, from 0 to 1
+	SilenceRatio float64
+}
+
+// ReplayGain returns the gain in dB that brings the audio to ReplayGainReference, lowered so that its true peak stays
+// below -1 dBTP; silence is left as is
+func (l *Loudness) ReplayGain() float64 {
+	if math.IsInf(l.Integrated, -1) {
+		return 0
+	}
+	return math.Min(ReplayGainReference-l.Integrated, replayGainHeadroom-l.TruePeak)
+}
+
+// AnalyzeLoudness decodes the audio file of the given size and measures its loudness. WAVE files, of integer, float,
+// A-law or µ-law PCM, and MP3 files can be decoded; other files fail with ErrUnsupported
+func AnalyzeLoudness(r io.ReaderAt, size int64) (*Loudness, error) {
+	stream, err := openPCM(r, size)
+	if err != nil {
+		return nil, err
+	}
+	stepLength := int64(stream.sampleRate / loudnessStepRate)
+	if stepLength == 0 {
+		return nil, corrupt("sample rate too low")
+	}
+
+	weights := channelWeights(stream.channels)
+	filters := make([]*kWeighting, stream.channels)
+	peaks := make([]*truePeakMeter, stream.channels)
+	for c := range filters {
+		filters[c] = newKWeighting(float64(stream.sampleRate))
+		peaks[c] = newTruePeakMeter()
+	}
+	// the weighted mean square of every step, summed over channels
+	var steps []float64
+	var sum float64
+	err = stream.each(func(i int64, samples []float64) {
+		for c, sample := range samples {
+			peaks[c].add(sample)
+			weighted := filters[c].filter(sample)
+			sum += weights[c] * weighted * weighted
+		}
+		if (i+1)%stepLength == 0 || i+1 == stream.frames {
+			steps = append(steps, sum/float64((i%stepLength)+1))
+			sum = 0
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loudness := &Loudness{Integrated: integratedLoudness(steps), TruePeak: math.Inf(-1)}
+	for _, peak := range peaks {
+		loudness.TruePeak = math.Max(loudness.TruePeak, 20*math.Log10(peak.max()))
+	}
+	silent := 0
+	for _, step := range steps {
+		if blockLoudness(step) < SilenceThreshold {
+			silent++
+		}
+	}
+	loudness.SilenceRatio = float64(silent) / float64(len(steps))
+	return loudness, nil
+}
+
+// integratedLoudness gates the overlapping blocks of the steps, first absolutely then relatively to their loudness,
+// and returns the loudness of the blocks left; audio shorter than a block is measured as a single block
+func integratedLoudness(steps []float64) float64 {
+	var blocks []float64
+	for i := 0; i+loudnessBlockSteps <= len(steps); i++ {
+		blocks = append(blocks, mean(steps[i:i+loudnessBlockSteps]))
+	}
+	if len(blocks) == 0 {
+		blocks = append(blocks, mean(steps))
+	}
+
+	gate := func(blocks []float64, threshold float64) []float64 {
+		var gated []float64
+		for _, block := range blocks {
+			if blockLoudness(block) > threshold {
+				gated = append(gated, block)
+			}
+		}
+		return gated
+	}
+	blocks = gate(blocks, absoluteGate)
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+	blocks = gate(blocks, blockLoudness(mean(blocks))+relativeGate)
+	return blockLoudness(mean(blocks))
+}
+
+// blockLoudness returns the loudness in LUFS of the weighted mean square of a block
+func blockLoudness(meanSquare float64) float64 {
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// channelWeights returns the weights of the channels, in WAVE channel order; the LFE channel of 5.1 audio is left out
+// and its surround channels weigh more
+func channelWeights(channels int) []float64 {
+	weights := make([]float64, channels)
+	for c := range weights {
+		weights[c] = 1
+	}
+	if channels == 6 {
+		weights[3], weights[4], weights[5] = 0, 1.41, 1.41
+	}
+	return weights
+}
+
+// biquad is a second order IIR filter
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) filter(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting is the K-weighting filter of a channel, a high shelf modelling the head followed by a high pass, with
+// the coefficients of BS.1770 derived for the sample rate
+type kWeighting struct {
+	shelf, highPass biquad
+}
+
+func newKWeighting(sampleRate float64) *kWeighting {
+	k := &kWeighting{}
+
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	kk := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + kk/q + kk*kk
+	k.shelf = biquad{
+		b0: (vh + vb*kk/q + kk*kk) / a0,
+		b1: 2 * (kk*kk - vh) / a0,
+		b2: (vh - vb*kk/q + kk*kk) / a0,
+		a1: 2 * (kk*kk - 1) / a0,
+		a2: (1 - kk/q + kk*kk) / a0,
+	}
+
+	f0, q = 38.13547087602444, 0.5003270373238773
+	kk = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1 + kk/q + kk*kk
+	k.highPass = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (kk*kk - 1) / a0,
+		a2: (1 - kk/q + kk*kk) / a0,
+	}
+	return k
+}
+
+func (k *kWeighting) filter(x float64) float64 {
+	return k.highPass.filter(k.shelf.filter(x))
+}
+
+// truePeakMeter keeps the highest absolute value of the samples of a channel and of the values interpolated between
+// them
+type truePeakMeter struct {
+	history []float64
+	phases  [][]float64
+	peak    float64
+}
+
+func newTruePeakMeter() *truePeakMeter {
+	m := &truePeakMeter{history: make([]float64, 2*oversamplingTap)}
+	// the taps of every phase of a Hann windowed sinc, the first phase being the samples themselves
+	for phase := 1; phase < oversampling; phase++ {
+		taps := make([]float64, 2*oversamplingTap)
+		for i := range taps {
+			t := float64(i-oversamplingTap+1) - float64(phase)/oversampling
+			window := 0.5 + 0.5*math.Cos(math.Pi*t/oversamplingTap)
+			taps[i] = sinc(t) * window
+		}
+		m.phases = append(m.phases, taps)
+	}
+	return m
+}
+
+func (m *truePeakMeter) add(sample float64) {
+	m.peak = math.Max(m.peak, math.Abs(sample))
+	copy(m.history, m.history[1:])
+	m.history[len(m.history)-1] = sample
+	for _, taps := range m.phases {
+		var v float64
+		for i, tap := range taps {
+			v += tap * m.history[i]
+		}
+		m.peak = math.Max(m.peak, math.Abs(v))
+	}
+}
+
+func (m *truePeakMeter) max() float64 {
+	return m.peak
+}
+
+func sinc(t float64) float64 {
+	if t == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*t) / (math.Pi * t)
+}
@@ -0,0 +1,128 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFloatWAV returns a 48 kHz float WAVE file of the channels, with the samples of every channel at the time
+func newFloatWAV(channels int, seconds float64, sample func(t float64) float64) []byte {
+	var values []float32
+	for i := 0; i < int(seconds*48000); i++ {
+		v := float32(sample(float64(i) / 48000))
+		for c := 0; c < channels; c++ {
+			values = append(values, v)
+		}
+	}
+	data := float32s(values...)
+	fmtChunk := join([]byte("fmt "), le32(16), le16(wavFormatFloat), le16(channels), le32(48000),
+		le32(48000*4*channels), le16(4*channels), le16(32))
+	body := join([]byte("WAVE"), fmtChunk, []byte("data"), le32(len(data)), data)
+	return join([]byte("RIFF"), le32(len(body)), body)
+}
+
+// sine returns a sine of the frequency and amplitude, starting at the phase
+func sine(frequency, amplitude, phase float64) func(t float64) float64 {
+	return func(t float64) float64 {
+		return amplitude * math.Sin(2*math.Pi*frequency*t+phase)
+	}
+}
+
+func TestAnalyzeLoudness(t *testing.T) {
+	cases := []struct {
+		name         string
+		file         []byte
+		integrated   float64
+		truePeak     float64
+		silenceRatio float64
+		replayGain   float64
+	}{
+		{
+			// the reference of BS.1770, a full scale 1 kHz sine is -3.01 LUFS
+			name:       "full scale sine",
+			file:       newFloatWAV(1, 2, sine(1000, 1, 0)),
+			integrated: -3.01,
+			truePeak:   0,
+			replayGain: -15,
+		},
+		{
+			name:       "quiet sine",
+			file:       newFloatWAV(1, 2, sine(1000, 0.1, 0)),
+			integrated: -23.01,
+			truePeak:   -20,
+			replayGain: 5,
+		},
+		{
+			name:       "stereo sine",
+			file:       newFloatWAV(2, 2, sine(1000, 0.1, 0)),
+			integrated: -20,
+			truePeak:   -20,
+			replayGain: 2,
+		},
+		{
+			// the samples of a sine at a quarter of the sample rate, shifted by 45°, peak at -3 dBFS, in between at 0
+			name:       "inter-sample peaks",
+			file:       newFloatWAV(1, 2, sine(12000, 1, math.Pi/4)),
+			integrated: blockLoudness(0.5 * kWeightingGain(12000)),
+			truePeak:   0,
+			replayGain: ReplayGainReference - blockLoudness(0.5*kWeightingGain(12000)),
+		},
+		{
+			// the 3 blocks overlapping the start of the sine pass the gates, with a quarter to three quarters of sine
+			name:         "half silence",
+			file:         newFloatWAV(1, 2, func(t float64) float64 { return sine(1000, 0.1, 0)(t) * math.Floor(t) }),
+			integrated:   -23.01 + 10*math.Log10(8.5/10),
+			truePeak:     -20,
+			silenceRatio: 0.5,
+			replayGain:   5 - 10*math.Log10(8.5/10),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			loudness, err := AnalyzeLoudness(bytes.NewReader(c.file), int64(len(c.file)))
+
+			assert.NoError(t, err)
+			assert.InDelta(t, c.integrated, loudness.Integrated, 0.1)
+			assert.InDelta(t, c.truePeak, loudness.TruePeak, 0.2)
+			assert.InDelta(t, c.silenceRatio, loudness.SilenceRatio, 0.01)
+			assert.InDelta(t, c.replayGain, loudness.ReplayGain(), 0.2)
+		})
+	}
+
+	t.Run("silence", func(t *testing.T) {
+		file := newFloatWAV(1, 1, func(t float64) float64 { return 0 })
+
+		loudness, err := AnalyzeLoudness(bytes.NewReader(file), int64(len(file)))
+
+		assert.NoError(t, err)
+		assert.True(t, math.IsInf(loudness.Integrated, -1))
+		assert.Equal(t, 1.0, loudness.SilenceRatio)
+		assert.Equal(t, 0.0, loudness.ReplayGain())
+	})
+
+	t.Run("not decodable", func(t *testing.T) {
+		file := newADTS(10)
+
+		loudness, err := AnalyzeLoudness(bytes.NewReader(file), int64(len(file)))
+
+		assert.Nil(t, loudness)
+		assert.Equal(t, ErrUnsupported, errors.Cause(err))
+	})
+}
+
+// kWeightingGain returns the power gain of the K-weighting filter at the frequency, at 48 kHz
+func kWeightingGain(frequency float64) float64 {
+	k := newKWeighting(48000)
+	var sum float64
+	for i := 0; i < 48000; i++ {
+		y := k.filter(math.Sin(2 * math.Pi * frequency * float64(i) / 48000))
+		if i >= 24000 {
+			sum += y * y
+		}
+	}
+	return sum / 24000 / 0.5
+}
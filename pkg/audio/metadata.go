@@ -0,0 +1,128 @@
+package audio
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// codecs of the audio streams the service can describe
+const (
+	CodecPCM      = "pcm"
+	CodecPCMFloat = "pcm_float"
+	CodecALaw     = "alaw"
+	CodecMuLaw    = "mulaw"
+	CodecMP1      = "mp1"
+	CodecMP2      = "mp2"
+	CodecMP3      = "mp3"
+	CodecAAC      = "aac"
+	CodecALAC     = "alac"
+	CodecVorbis   = "vorbis"
+	CodecOpus     = "opus"
+	CodecFLAC     = "flac"
+)
+
+var (
+	// ErrUnsupported is the cause of the errors of containers or codecs that cannot be described
+	ErrUnsupported = errors.New("Unsupported audio format")
+	// ErrCorrupt is the cause of the errors of malformed or truncated files
+	ErrCorrupt = errors.New("Corrupt or truncated audio")
+)
+
+// Metadata describes the audio stream of a file
+type Metadata struct {
+	ContentType string
+	Codec       string
+	Duration    time.Duration
+	SampleRate  int
+	Channels    int
+	// Bitrate is in bits per second, averaged over the whole file
+	Bitrate int
+}
+
+// Probe parses the headers of the audio file of the given size to describe its audio stream. Only the container
+// headers are read, and the frames of the formats that need counting, so it is cheap enough to run on every upload
+func Probe(r io.ReaderAt, size int64) (*Metadata, error) {
+	head, err := readAt(r, 0, minInt64(size, SniffLength))
+	if err != nil {
+		return nil, err
+	}
+	var metadata *Metadata
+	contentType := DetectContentType(head)
+	switch contentType {
+	case ContentTypeWAV:
+		metadata, err = probeWAV(r, size)
+	case ContentTypeMP3, ContentTypeAAC:
+		metadata, err = probeMPEG(r, size)
+	case ContentTypeOgg:
+		metadata, err = probeOgg(r, size)
+	case ContentTypeMP4:
+		metadata, err = probeMP4(r, size)
+	case ContentTypeFLAC:
+		metadata, err = probeFLAC(r, size)
+	default:
+		return nil, errors.Wrap(ErrUnsupported, "unknown container")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Duration <= 0 || metadata.SampleRate <= 0 || metadata.Channels <= 0 {
+		return nil, corrupt("no audio")
+	}
+	if metadata.ContentType == "" {
+		metadata.ContentType = contentType
+	}
+	if metadata.Bitrate == 0 {
+		metadata.Bitrate = bitrate(size, metadata.Duration)
+	}
+	return metadata, nil
+}
+
+// corrupt returns an error caused by ErrCorrupt
+func corrupt(reason string) error {
+	return errors.Wrap(ErrCorrupt, reason)
+}
+
+// unsupported returns an error caused by ErrUnsupported
+func unsupported(reason string) error {
+	return errors.Wrap(ErrUnsupported, reason)
+}
+
+// readAt reads n bytes at the offset; reading past the end of the file means it is truncated
+func readAt(r io.ReaderAt, off, n int64) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := r.ReadAt(b, off)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, corrupt("unexpected end of file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// samplesDuration returns the duration of the number of samples at the sample rate
+func samplesDuration(samples int64, sampleRate int) time.Duration {
+	if sampleRate <= 0 {
+		return 0
+	}
+	seconds := samples / int64(sampleRate)
+	rest := samples % int64(sampleRate)
+	return time.Duration(seconds)*time.Second + time.Duration(rest)*time.Second/time.Duration(sampleRate)
+}
+
+// bitrate returns the average bitrate of bytes played over the duration
+func bitrate(bytes int64, d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(float64(bytes) * 8 / d.Seconds())
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
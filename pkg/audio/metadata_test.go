@@ -0,0 +1,246 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func le16(v int) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(v))
+	return b
+}
+
+func le32(v int) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func be16(v int) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(v))
+	return b
+}
+
+func be32(v int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// newWAV returns a PCM WAVE file with a LIST chunk before its data
+func newWAV(sampleRate, channels, bitsPerSample, dataSize int) []byte {
+	blockAlign := channels * bitsPerSample / 8
+	fmtChunk := join([]byte("fmt "), le32(16), le16(wavFormatPCM), le16(channels), le32(sampleRate),
+		le32(sampleRate*blockAlign), le16(blockAlign), le16(bitsPerSample))
+	list := join([]byte("LIST"), le32(3), []byte("abc\x00"))
+	data := join([]byte("data"), le32(dataSize), make([]byte, dataSize))
+	body := join([]byte("WAVE"), fmtChunk, list, data)
+	return join([]byte("RIFF"), le32(len(body)), body)
+}
+
+// mp3Frame is a 417 byte MPEG-1 layer III frame at 128 kbit/s, 44.1 kHz, stereo
+func mp3Frame() []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return frame
+}
+
+// newMP3 returns an MP3 file of CBR frames behind an ID3v2 tag
+func newMP3(frames int) []byte {
+	b := join([]byte("ID3\x04\x00\x00\x00\x00\x00\x0A"), make([]byte, 10))
+	for i := 0; i < frames; i++ {
+		b = append(b, mp3Frame()...)
+	}
+	return b
+}
+
+// newXingMP3 returns an MP3 file starting with a Xing frame announcing the frames that follow it
+func newXingMP3(frames int) []byte {
+	xing := mp3Frame()
+	copy(xing[4+32:], join([]byte("Xing"), be32(3), be32(frames), be32(417*(frames+1))))
+	b := xing
+	for i := 0; i < frames; i++ {
+		b = append(b, mp3Frame()...)
+	}
+	return b
+}
+
+// newADTS returns an AAC stream of 44.1 kHz stereo ADTS frames
+func newADTS(frames int) []byte {
+	const size = 200
+	var b []byte
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, size)
+		copy(frame, []byte{0xFF, 0xF1, 0x50, 0x80, byte(size >> 3), byte(size&7)<<5 | 0x1F, 0xFC})
+		b = append(b, frame...)
+	}
+	return b
+}
+
+// oggPageOf returns an Ogg page holding one packet
+func oggPageOf(headerType byte, granule int64, serial int, packet []byte) []byte {
+	var segments []byte
+	n := len(packet)
+	for ; n >= 255; n -= 255 {
+		segments = append(segments, 255)
+	}
+	segments = append(segments, byte(n))
+	g := make([]byte, 8)
+	binary.LittleEndian.PutUint64(g, uint64(granule))
+	return join([]byte("OggS\x00"), []byte{headerType}, g, le32(serial), le32(0), le32(0),
+		[]byte{byte(len(segments))}, segments, packet)
+}
+
+// newOpus returns an Ogg Opus file of the duration in 48 kHz samples
+func newOpus(samples int64, eos bool) []byte {
+	head := join([]byte("OpusHead\x01\x02"), le16(312), le32(44100), le16(0), []byte{0})
+	last := byte(0)
+	if eos {
+		last = oggEndOfStream
+	}
+	return join(
+		oggPageOf(0x02, 0, 1, head),
+		oggPageOf(0, 0, 1, []byte("OpusTags")),
+		oggPageOf(0, 48000, 1, make([]byte, 1000)),
+		oggPageOf(last, samples+312, 1, make([]byte, 1000)),
+	)
+}
+
+// newVorbis returns an Ogg Vorbis file of the duration in samples
+func newVorbis(sampleRate int, samples int64) []byte {
+	head := join([]byte("\x01vorbis"), le32(0), []byte{1}, le32(sampleRate), le32(0), le32(96000), le32(0), []byte{0xB8, 0x01})
+	return join(
+		oggPageOf(0x02, 0, 7, head),
+		oggPageOf(oggEndOfStream, samples, 7, make([]byte, 2000)),
+	)
+}
+
+func mp4Atom(typ string, parts ...[]byte) []byte {
+	body := join(parts...)
+	return join(be32(8+len(body)), []byte(typ), body)
+}
+
+// newM4A returns an M4A file with an AAC sound track of the duration in timescale units
+func newM4A(timescale, duration int, mdat []byte) []byte {
+	mdhd := mp4Atom("mdhd", make([]byte, 12), be32(timescale), be32(duration), make([]byte, 4))
+	hdlr := mp4Atom("hdlr", make([]byte, 8), []byte("soun"), make([]byte, 12))
+	decoderConfig := join([]byte{0x04, 13, 0x40, 0x15}, make([]byte, 3), be32(128000), be32(128000))
+	esDescriptor := join([]byte{0x03, byte(3 + len(decoderConfig)), 0, 1, 0}, decoderConfig)
+	esds := mp4Atom("esds", make([]byte, 4), esDescriptor)
+	mp4a := mp4Atom("mp4a", make([]byte, 16), be16(2), be16(16), make([]byte, 4), be16(timescale), be16(0), esds)
+	stsd := mp4Atom("stsd", make([]byte, 4), be32(1), mp4a)
+	trak := mp4Atom("trak", mp4Atom("mdia", mdhd, hdlr, mp4Atom("minf", mp4Atom("stbl", stsd))))
+	return join(mp4Atom("ftyp", []byte("M4A "), be32(0)), mp4Atom("moov", trak), mp4Atom("mdat", mdat))
+}
+
+// newFLAC returns a FLAC file with a STREAMINFO block of the stream
+func newFLAC(sampleRate, channels int, samples int64) []byte {
+	info := make([]byte, 34)
+	info[10] = byte(sampleRate >> 12)
+	info[11] = byte(sampleRate >> 4)
+	info[12] = byte(sampleRate<<4) | byte(channels-1)<<1
+	info[13] = byte(15<<4) | byte(samples>>32&0xF)
+	binary.BigEndian.PutUint32(info[14:], uint32(samples))
+	return join([]byte("fLaC"), []byte{0x80, 0, 0, 34}, info, make([]byte, 100))
+}
+
+func TestProbe(t *testing.T) {
+	cases := []struct {
+		name string
+		file []byte
+		want Metadata
+	}{
+		{
+			name: "wav",
+			file: newWAV(8000, 1, 16, 48000),
+			want: Metadata{ContentType: ContentTypeWAV, Codec: CodecPCM, Duration: 3 * time.Second, SampleRate: 8000, Channels: 1, Bitrate: 128000},
+		},
+		{
+			name: "mp3 cbr",
+			file: newMP3(100),
+			want: Metadata{ContentType: ContentTypeMP3, Codec: CodecMP3, Duration: 2612244897 * time.Nanosecond, SampleRate: 44100, Channels: 2, Bitrate: 127706},
+		},
+		{
+			name: "mp3 xing",
+			file: newXingMP3(100),
+			want: Metadata{ContentType: ContentTypeMP3, Codec: CodecMP3, Duration: 2612244897 * time.Nanosecond, SampleRate: 44100, Channels: 2, Bitrate: 128983},
+		},
+		{
+			name: "aac adts",
+			file: newADTS(431),
+			want: Metadata{ContentType: ContentTypeAAC, Codec: CodecAAC, Duration: 10007800453 * time.Nanosecond, SampleRate: 44100, Channels: 2, Bitrate: 68906},
+		},
+		{
+			name: "ogg opus",
+			file: newOpus(48000*5/2, true),
+			want: Metadata{ContentType: ContentTypeOgg, Codec: CodecOpus, Duration: 2500 * time.Millisecond, SampleRate: 48000, Channels: 2, Bitrate: 6864},
+		},
+		{
+			name: "ogg vorbis",
+			file: newVorbis(22050, 22050*4),
+			want: Metadata{ContentType: ContentTypeOgg, Codec: CodecVorbis, Duration: 4 * time.Second, SampleRate: 22050, Channels: 1, Bitrate: 4186},
+		},
+		{
+			name: "m4a aac",
+			file: newM4A(44100, 44100*3, make([]byte, 1000)),
+			want: Metadata{ContentType: ContentTypeMP4, Codec: CodecAAC, Duration: 3 * time.Second, SampleRate: 44100, Channels: 2, Bitrate: 128000},
+		},
+		{
+			name: "flac",
+			file: newFLAC(44100, 2, 44100*7),
+			want: Metadata{ContentType: ContentTypeFLAC, Codec: CodecFLAC, Duration: 7 * time.Second, SampleRate: 44100, Channels: 2, Bitrate: 162},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			metadata, err := Probe(bytes.NewReader(c.file), int64(len(c.file)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, *metadata)
+		})
+	}
+}
+
+func TestProbe_Invalid(t *testing.T) {
+	wav := newWAV(8000, 1, 16, 48000)
+	mp3 := newMP3(100)
+	opus := newOpus(48000, true)
+	m4a := newM4A(44100, 44100*3, make([]byte, 1000))
+
+	cases := []struct {
+		name  string
+		file  []byte
+		cause error
+	}{
+		{"unknown container", []byte("hello world, not audio"), ErrUnsupported},
+		{"wav truncated", wav[:len(wav)-100], ErrCorrupt},
+		{"wav without data", wav[:44], ErrCorrupt},
+		{"mp3 truncated", mp3[:len(mp3)-100], ErrCorrupt},
+		{"mp3 without frames", append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), make([]byte, 2000)...), ErrCorrupt},
+		{"mp3 xing truncated", newXingMP3(100)[:417*60], ErrCorrupt},
+		{"ogg truncated", opus[:len(opus)-10], ErrCorrupt},
+		{"ogg without end of stream", newOpus(48000, false), ErrCorrupt},
+		{"m4a truncated", m4a[:len(m4a)-10], ErrCorrupt},
+		{"m4a fragmented", newM4A(44100, 0, nil), ErrUnsupported},
+		{"flac of unknown length", newFLAC(44100, 2, 0), ErrUnsupported},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			metadata, err := Probe(bytes.NewReader(c.file), int64(len(c.file)))
+
+			assert.Nil(t, metadata)
+			assert.Equal(t, c.cause, errors.Cause(err))
+		})
+	}
+}
@@ -0,0 +1,970 @@
+package audio
+
+import (
+	"io"
+	"math"
+	"strconv"
+)
+
+// Layer III decoding follows ISO/IEC 11172-3 and 13818-3: the main data of every granule holds the scalefactors and
+// the Huffman coded spectrum of each channel, which are requantized, stereo processed, and turned back into samples by
+// the hybrid filterbank, an IMDCT per subband followed by the polyphase synthesis
+
+const (
+	granuleLines   = 576
+	subbands       = 32
+	subbandSamples = 18
+	// maxReservoir is the most main data a frame can take from the frames before it
+	maxReservoir = 511
+)
+
+// block types of the granules of a channel
+const (
+	blockLong  = 0
+	blockStart = 1
+	blockShort = 2
+	blockStop  = 3
+)
+
+// modeJointStereo is the channel mode of frames that may use middle/side and intensity stereo
+const modeJointStereo = 1
+
+// openMP3 locates the frames of an MP3 file of the given size, whose samples are decoded as they are read
+func openMP3(r io.ReaderAt, size int64) (*pcmStream, error) {
+	start, end, err := audioRange(r, size)
+	if err != nil {
+		return nil, err
+	}
+	first, h, err := firstFrame(r, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if h.codec != CodecMP3 {
+		return nil, unsupported("decoding " + h.codec)
+	}
+	_, _, ok, err := vbrHeader(r, first, h)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		// the frame of a VBR header holds no audio
+		first += int64(h.size)
+	}
+	frames, err := countSamples(r, first, end, h)
+	if err != nil {
+		return nil, err
+	}
+	if frames == 0 {
+		return nil, corrupt("no audio")
+	}
+	decode := func(fn func(i int64, samples []float64)) error {
+		d := &mp3Decoder{}
+		pcm := [2][]float64{make([]float64, h.samples), make([]float64, h.samples)}
+		samples := make([]float64, h.channels)
+		var i int64
+		var decodeErr error
+		err := walkFrames(r, first, end, h, func(off int64, frame frameHeader) {
+			if decodeErr != nil {
+				return
+			}
+			b, err := readAt(r, off, int64(frame.size))
+			if err == nil {
+				err = d.decodeFrame(b, frame, pcm)
+			}
+			if err != nil {
+				decodeErr = err
+				return
+			}
+			for n := 0; n < frame.samples; n++ {
+				for c := range samples {
+					// a mono frame in a stereo stream plays on both channels
+					samples[c] = pcm[c%frame.channels][n]
+				}
+				fn(i, samples)
+				i++
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	}
+	return &pcmStream{sampleRate: h.sampleRate, channels: h.channels, frames: frames, decode: decode}, nil
+}
+
+// granule is the side information of a granule of a channel
+type granule struct {
+	part23Length     int
+	bigValues        int
+	globalGain       int
+	scalefacCompress int
+	windowSwitching  bool
+	blockType        int
+	mixedBlock       bool
+	tableSelect      [3]int
+	subblockGain     [3]int
+	region0Count     int
+	region1Count     int
+	preflag          bool
+	scalefacScale    int
+	count1Table      int
+}
+
+// shortBlocks reports whether the granule has short blocks, in all subbands or all but the first two
+func (g *granule) shortBlocks() bool {
+	return g.windowSwitching && g.blockType == blockShort
+}
+
+// sideInfo is the side information of a frame
+type sideInfo struct {
+	mainDataBegin int
+	scfsi         [2][4]bool
+	granules      [2][2]granule
+}
+
+// scalefactors are the scalefactors of the long bands and of the windows of the short bands of a channel; the
+// illegal values are the intensity stereo positions that mean the band is not intensity coded
+type scalefactors struct {
+	long         [22]int
+	short        [13][3]int
+	illegalLong  [22]int
+	illegalShort [13][3]int
+}
+
+// mp3Decoder decodes the frames of a layer III stream one after the other, keeping what carries over from frame to
+// frame: the main data of the bit reservoir, the overlap of the IMDCT and the state of the polyphase synthesis
+type mp3Decoder struct {
+	reservoir []byte
+	// scalefactors are kept for the second granule of MPEG-1 frames, which may reuse those of the first
+	scalefactors [2]scalefactors
+	overlap      [2][subbands][subbandSamples]float64
+	synthesis    [2]synthesisFilter
+	spectrum     [2][granuleLines]int
+	lines        [2][granuleLines]float64
+	samples      [subbands][subbandSamples]float64
+}
+
+// decodeFrame decodes the frame into pcm, a slice per channel of the samples of the frame. A frame whose main data
+// starts in frames that were not read, e.g. the first ones of a cut stream, decodes to silence
+func (d *mp3Decoder) decodeFrame(frame []byte, h frameHeader, pcm [2][]float64) error {
+	lsf := h.version != mpeg1
+	mode := int(frame[3] >> 6)
+	modeExtension := int(frame[3]>>4) & 3
+	off := 4
+	if frame[1]&1 == 0 {
+		// the header is followed by a CRC
+		off += 2
+	}
+	sideSize := 32
+	switch {
+	case !lsf && h.channels == 1:
+		sideSize = 17
+	case lsf && h.channels == 1:
+		sideSize = 9
+	case lsf:
+		sideSize = 17
+	}
+	if off+sideSize > len(frame) {
+		return corrupt("side information truncated")
+	}
+	side, err := readSideInfo(&bitReader{b: frame[off : off+sideSize]}, h.channels, lsf)
+	if err != nil {
+		return err
+	}
+	main := frame[off+sideSize:]
+	if side.mainDataBegin > len(d.reservoir) {
+		d.keep(main)
+		for c := 0; c < h.channels; c++ {
+			for i := range pcm[c][:h.samples] {
+				pcm[c][i] = 0
+			}
+		}
+		return nil
+	}
+	data := make([]byte, 0, side.mainDataBegin+len(main))
+	data = append(data, d.reservoir[len(d.reservoir)-side.mainDataBegin:]...)
+	data = append(data, main...)
+	d.keep(main)
+
+	bands, ok := scalefactorBands[h.sampleRate]
+	if !ok {
+		return unsupported("decoding MP3 at " + strconv.Itoa(h.sampleRate) + " Hz")
+	}
+	br := &bitReader{b: data}
+	granules := 2
+	if lsf {
+		granules = 1
+	}
+	for gr := 0; gr < granules; gr++ {
+		for ch := 0; ch < h.channels; ch++ {
+			g := &side.granules[gr][ch]
+			end := br.pos + g.part23Length
+			if lsf {
+				intensity := mode == modeJointStereo && modeExtension&1 != 0 && ch == 1
+				d.readLSFScalefactors(br, g, ch, intensity)
+			} else {
+				d.readScalefactors(br, g, ch, gr, side.scfsi[ch])
+			}
+			if br.pos > end {
+				return corrupt("scalefactors overrun the granule")
+			}
+			err = readSpectrum(br, g, bands.long, bands.short, end, &d.spectrum[ch])
+			if err != nil {
+				return err
+			}
+			if end > len(data)*8 {
+				return corrupt("main data truncated")
+			}
+			br.pos = end
+			requantize(g, &d.scalefactors[ch], bands.long, bands.short, &d.spectrum[ch], &d.lines[ch])
+		}
+		if mode == modeJointStereo && h.channels == 2 {
+			d.stereo(&side.granules[gr], modeExtension, lsf, bands.long, bands.short)
+		}
+		for ch := 0; ch < h.channels; ch++ {
+			g := &side.granules[gr][ch]
+			lines := &d.lines[ch]
+			if g.shortBlocks() {
+				reorder(g, bands.short, lines)
+			}
+			antialias(g, lines)
+			d.hybrid(g, ch, lines)
+			for t := 0; t < subbandSamples; t++ {
+				var s [subbands]float64
+				for sb := range s {
+					s[sb] = d.samples[sb][t]
+				}
+				out := pcm[ch][gr*granuleLines+t*subbands:]
+				d.synthesis[ch].synthesize(&s, out[:subbands])
+			}
+		}
+	}
+	return nil
+}
+
+// keep adds the main data of a frame to the bit reservoir
+func (d *mp3Decoder) keep(main []byte) {
+	d.reservoir = append(d.reservoir, main...)
+	if n := len(d.reservoir) - maxReservoir; n > 0 {
+		d.reservoir = append(d.reservoir[:0], d.reservoir[n:]...)
+	}
+}
+
+// readSideInfo reads the side information of a frame of MPEG-1, or of MPEG-2 and 2.5 with their lower sample rates
+func readSideInfo(br *bitReader, channels int, lsf bool) (*sideInfo, error) {
+	side := &sideInfo{}
+	granules := 2
+	if lsf {
+		granules = 1
+		side.mainDataBegin = br.bits(8)
+		// private bits
+		br.skip(channels)
+	} else {
+		side.mainDataBegin = br.bits(9)
+		// private bits
+		if channels == 1 {
+			br.skip(5)
+		} else {
+			br.skip(3)
+		}
+		for ch := 0; ch < channels; ch++ {
+			for band := range side.scfsi[ch] {
+				side.scfsi[ch][band] = br.bits(1) == 1
+			}
+		}
+	}
+	for gr := 0; gr < granules; gr++ {
+		for ch := 0; ch < channels; ch++ {
+			g := &side.granules[gr][ch]
+			g.part23Length = br.bits(12)
+			g.bigValues = br.bits(9)
+			g.globalGain = br.bits(8)
+			if lsf {
+				g.scalefacCompress = br.bits(9)
+			} else {
+				g.scalefacCompress = br.bits(4)
+			}
+			g.windowSwitching = br.bits(1) == 1
+			if g.windowSwitching {
+				g.blockType = br.bits(2)
+				g.mixedBlock = br.bits(1) == 1
+				for i := 0; i < 2; i++ {
+					g.tableSelect[i] = br.bits(5)
+				}
+				for i := range g.subblockGain {
+					g.subblockGain[i] = br.bits(3)
+				}
+				if g.blockType == blockLong {
+					return nil, corrupt("window switching without a block type")
+				}
+				// the regions are implicit, only short blocks have a longer first region
+				g.region0Count = 7
+				if g.blockType == blockShort && !g.mixedBlock {
+					g.region0Count = 8
+				}
+				g.region1Count = 20 - g.region0Count
+			} else {
+				for i := range g.tableSelect {
+					g.tableSelect[i] = br.bits(5)
+				}
+				g.region0Count = br.bits(4)
+				g.region1Count = br.bits(3)
+			}
+			if !lsf {
+				g.preflag = br.bits(1) == 1
+			}
+			g.scalefacScale = br.bits(1)
+			g.count1Table = br.bits(1)
+			if g.bigValues > granuleLines/2 {
+				return nil, corrupt("too many big values")
+			}
+		}
+	}
+	return side, nil
+}
+
+// readScalefactors reads the scalefactors of a channel of an MPEG-1 granule; the second granule reuses those of the
+// first for the groups of bands selected by scfsi
+func (d *mp3Decoder) readScalefactors(br *bitReader, g *granule, ch, gr int, scfsi [4]bool) {
+	sf := &d.scalefactors[ch]
+	// intensity positions of MPEG-1 range from 0 to 6
+	for sfb := range sf.illegalLong {
+		sf.illegalLong[sfb] = 7
+	}
+	for sfb := range sf.illegalShort {
+		sf.illegalShort[sfb] = [3]int{7, 7, 7}
+	}
+	slen := scalefactorSizes[g.scalefacCompress]
+	if g.shortBlocks() {
+		sfb := 0
+		if g.mixedBlock {
+			for ; sfb < 8; sfb++ {
+				sf.long[sfb] = br.bits(slen[0])
+			}
+			sfb = 3
+		}
+		for ; sfb < 12; sfb++ {
+			n := slen[0]
+			if sfb >= 6 {
+				n = slen[1]
+			}
+			for w := range sf.short[sfb] {
+				sf.short[sfb][w] = br.bits(n)
+			}
+		}
+		sf.short[12] = [3]int{}
+		return
+	}
+	groups := [5]int{0, 6, 11, 16, 21}
+	for i := 0; i < 4; i++ {
+		if gr == 1 && scfsi[i] {
+			continue
+		}
+		n := slen[0]
+		if i >= 2 {
+			n = slen[1]
+		}
+		for sfb := groups[i]; sfb < groups[i+1]; sfb++ {
+			sf.long[sfb] = br.bits(n)
+		}
+	}
+	sf.long[21] = 0
+}
+
+// readLSFScalefactors reads the scalefactors of a channel of an MPEG-2 or 2.5 granule, whose sizes are packed in
+// scalefac_compress, differently for the right channel of intensity stereo
+func (d *mp3Decoder) readLSFScalefactors(br *bitReader, g *granule, ch int, intensity bool) {
+	sf := &d.scalefactors[ch]
+	var slen [4]int
+	table := 0
+	sfc := g.scalefacCompress
+	switch {
+	case intensity && sfc>>1 < 180:
+		sfc >>= 1
+		slen = [4]int{sfc / 36, sfc % 36 / 6, sfc % 36 % 6, 0}
+		table = 3
+	case intensity && sfc>>1 < 244:
+		sfc = sfc>>1 - 180
+		slen = [4]int{sfc & 63 >> 4, sfc & 15 >> 2, sfc & 3, 0}
+		table = 4
+	case intensity:
+		sfc = sfc>>1 - 244
+		slen = [4]int{sfc / 3, sfc % 3, 0, 0}
+		table = 5
+	case sfc < 400:
+		slen = [4]int{sfc >> 4 / 5, sfc >> 4 % 5, sfc & 15 >> 2, sfc & 3}
+	case sfc < 500:
+		sfc -= 400
+		slen = [4]int{sfc >> 2 / 5, sfc >> 2 % 5, sfc & 3, 0}
+		table = 1
+	default:
+		sfc -= 500
+		slen = [4]int{sfc / 3, sfc % 3, 0, 0}
+		table = 2
+		g.preflag = true
+	}
+	block := 0
+	if g.shortBlocks() {
+		block = 1
+		if g.mixedBlock {
+			block = 2
+		}
+	}
+
+	// the scalefactors are read in band order: long bands, then the windows of each short band
+	longBands := 22
+	if g.shortBlocks() {
+		longBands = 0
+		if g.mixedBlock {
+			longBands = 6
+		}
+	}
+	i := 0
+	for part, count := range lsfScalefactorCounts[table][block] {
+		for n := 0; n < count; n, i = n+1, i+1 {
+			value, illegal := br.bits(slen[part]), 1<<uint(slen[part])-1
+			if i < longBands {
+				sf.long[i], sf.illegalLong[i] = value, illegal
+				continue
+			}
+			sfb, w := (i-longBands)/3, (i-longBands)%3
+			if longBands > 0 {
+				sfb += 3
+			}
+			sf.short[sfb][w], sf.illegalShort[sfb][w] = value, illegal
+		}
+	}
+	for ; i < longBands; i++ {
+		sf.long[i], sf.illegalLong[i] = 0, 0
+	}
+	if longBands < 22 {
+		// the last short band, after the bands read, has no scalefactors
+		sfb := (i - longBands) / 3
+		if longBands > 0 {
+			sfb += 3
+		}
+		for ; sfb < 13; sfb++ {
+			sf.short[sfb], sf.illegalShort[sfb] = [3]int{}, [3]int{}
+		}
+	}
+}
+
+// readSpectrum decodes the Huffman coded spectrum of a granule of a channel, which ends at the bit position end
+func readSpectrum(br *bitReader, g *granule, long [23]int, short [14]int, end int, spectrum *[granuleLines]int) error {
+	region1, region2 := granuleLines, granuleLines
+	switch {
+	case g.shortBlocks() && !g.mixedBlock:
+		region1 = 3 * short[3]
+	case g.windowSwitching:
+		region1 = long[g.region0Count+1]
+	default:
+		region1 = long[minInt(g.region0Count+1, 22)]
+		region2 = long[minInt(g.region0Count+g.region1Count+2, 22)]
+	}
+
+	i := 0
+	for ; i < 2*g.bigValues; i += 2 {
+		selected := g.tableSelect[0]
+		if i >= region2 {
+			selected = g.tableSelect[2]
+		} else if i >= region1 {
+			selected = g.tableSelect[1]
+		}
+		x, y, err := readBigValues(br, selected)
+		if err != nil {
+			return err
+		}
+		spectrum[i], spectrum[i+1] = x, y
+	}
+	if br.pos > end {
+		return corrupt("big values overrun the granule")
+	}
+	tree := count1Trees[g.count1Table]
+	for i+4 <= granuleLines && br.pos < end {
+		symbol := tree.decode(br)
+		var quad [4]int
+		for j := range quad {
+			if symbol>>uint(3-j)&1 != 0 {
+				quad[j] = 1 - 2*br.bits(1)
+			}
+		}
+		if br.pos > end {
+			// the last quadruple is cut by the end of the granule, and dropped
+			break
+		}
+		copy(spectrum[i:], quad[:])
+		i += 4
+	}
+	for ; i < granuleLines; i++ {
+		spectrum[i] = 0
+	}
+	return nil
+}
+
+// readBigValues decodes a pair of big values with the Huffman table selected
+func readBigValues(br *bitReader, selected int) (x, y int, err error) {
+	tree, ok := bigValueTrees[tableSelects[selected].table]
+	if !ok {
+		if selected == 0 {
+			return 0, 0, nil
+		}
+		return 0, 0, corrupt("invalid Huffman table")
+	}
+	symbol := tree.decode(br)
+	size := bigValueTables[tableSelects[selected].table].size
+	x, y = symbol/size, symbol%size
+	linbits := tableSelects[selected].linbits
+	if x == 15 && linbits > 0 {
+		x += br.bits(linbits)
+	}
+	if x != 0 && br.bits(1) == 1 {
+		x = -x
+	}
+	if y == 15 && linbits > 0 {
+		y += br.bits(linbits)
+	}
+	if y != 0 && br.bits(1) == 1 {
+		y = -y
+	}
+	return x, y, nil
+}
+
+// requantize scales the decoded spectrum of a granule of a channel with its global gain, subblock gains and
+// scalefactors; the lines of short blocks are left in the order they are coded, by band, window and frequency
+func requantize(g *granule, sf *scalefactors, long [23]int, short [14]int, spectrum *[granuleLines]int, lines *[granuleLines]float64) {
+	gain := float64(g.globalGain-210) / 4
+	multiplier := 0.5 * float64(1+g.scalefacScale)
+	longEnd := granuleLines
+	if g.shortBlocks() {
+		longEnd = 0
+		if g.mixedBlock {
+			longEnd = 36
+		}
+	}
+	i := 0
+	for sfb := 0; i < longEnd; sfb++ {
+		scale := sf.long[sfb]
+		if g.preflag {
+			scale += pretab[sfb]
+		}
+		factor := math.Exp2(gain - multiplier*float64(scale))
+		for ; i < long[sfb+1] && i < longEnd; i++ {
+			lines[i] = pow43(spectrum[i]) * factor
+		}
+	}
+	if longEnd == granuleLines {
+		return
+	}
+	sfb := 0
+	for 3*short[sfb] < longEnd {
+		sfb++
+	}
+	for ; sfb < 13; sfb++ {
+		width := short[sfb+1] - short[sfb]
+		for w := 0; w < 3; w++ {
+			factor := math.Exp2(gain - 2*float64(g.subblockGain[w]) - multiplier*float64(sf.short[sfb][w]))
+			for j := 0; j < width; j, i = j+1, i+1 {
+				lines[i] = pow43(spectrum[i]) * factor
+			}
+		}
+	}
+}
+
+// pow43 returns the value of a quantized line, its magnitude raised to the power of 4/3
+func pow43(v int) float64 {
+	switch {
+	case v == 0:
+		return 0
+	case v < 0:
+		return -pow43(-v)
+	case v < len(pow43Table):
+		return pow43Table[v]
+	}
+	return math.Pow(float64(v), 4.0/3)
+}
+
+// pow43Table caches the values of the quantized lines of the Huffman tables without linbits
+var pow43Table = func() (table [16]float64) {
+	for i := range table {
+		table[i] = math.Pow(float64(i), 4.0/3)
+	}
+	return table
+}()
+
+// stereo turns the middle/side or intensity coded lines of a granule back into left and right; intensity stereo codes
+// the bands above the last non-zero line of the right channel, with positions in the scalefactors of the right channel
+func (d *mp3Decoder) stereo(g *[2]granule, modeExtension int, lsf bool, long [23]int, short [14]int) {
+	left, right := &d.lines[0], &d.lines[1]
+	midSide := modeExtension&2 != 0
+	if modeExtension&1 == 0 {
+		if midSide {
+			midSideStereo(left[:], right[:])
+		}
+		return
+	}
+	sf := &d.scalefactors[1]
+	scale := intensityScale(&g[1], lsf)
+	if !g[1].shortBlocks() {
+		last := granuleLines - 1
+		for last >= 0 && right[last] == 0 {
+			last--
+		}
+		sfb := 0
+		for sfb < 21 && long[sfb+1] <= last {
+			sfb++
+		}
+		if last >= 0 {
+			sfb++
+		}
+		if midSide {
+			midSideStereo(left[:long[sfb]], right[:long[sfb]])
+		}
+		for ; sfb < 22; sfb++ {
+			position, illegal := sf.long[minInt(sfb, 20)], sf.illegalLong[minInt(sfb, 20)]
+			intensityStereo(left[long[sfb]:long[sfb+1]], right[long[sfb]:long[sfb+1]], position, illegal, lsf, scale, midSide)
+		}
+		return
+	}
+
+	// the lines of short blocks are still in coded order, and each window has its own last non-zero band
+	longEnd := 0
+	if g[1].mixedBlock {
+		longEnd = 36
+		if midSide {
+			midSideStereo(left[:longEnd], right[:longEnd])
+		}
+	}
+	first := 0
+	for 3*short[first] < longEnd {
+		first++
+	}
+	for w := 0; w < 3; w++ {
+		last := first - 1
+		for sfb := first; sfb < 13; sfb++ {
+			start, width := 3*short[sfb]+w*(short[sfb+1]-short[sfb]), short[sfb+1]-short[sfb]
+			for _, v := range right[start : start+width] {
+				if v != 0 {
+					last = sfb
+					break
+				}
+			}
+		}
+		for sfb := first; sfb < 13; sfb++ {
+			start, width := 3*short[sfb]+w*(short[sfb+1]-short[sfb]), short[sfb+1]-short[sfb]
+			l, r := left[start:start+width], right[start:start+width]
+			if sfb <= last {
+				if midSide {
+					midSideStereo(l, r)
+				}
+				continue
+			}
+			position, illegal := sf.short[minInt(sfb, 11)][w], sf.illegalShort[minInt(sfb, 11)][w]
+			intensityStereo(l, r, position, illegal, lsf, scale, midSide)
+		}
+	}
+}
+
+// intensityScale returns the ratio of the intensity positions of MPEG-2, set by the scalefac_compress of the right
+// channel
+func intensityScale(g *granule, lsf bool) float64 {
+	if !lsf {
+		return 0
+	}
+	return math.Exp2(-0.25 * float64(1+g.scalefacCompress&1))
+}
+
+// midSideStereo turns middle and side lines into left and right
+func midSideStereo(left, right []float64) {
+	for i := range left {
+		m, s := left[i], right[i]
+		left[i], right[i] = (m+s)/math.Sqrt2, (m-s)/math.Sqrt2
+	}
+}
+
+// intensityStereo spreads the lines of a band of the left channel over both channels by the intensity position;
+// bands with an illegal position are middle/side or plain stereo
+func intensityStereo(left, right []float64, position, illegal int, lsf bool, scale float64, midSide bool) {
+	if position == illegal {
+		if midSide {
+			midSideStereo(left, right)
+		}
+		return
+	}
+	kl, kr := 1.0, 1.0
+	switch {
+	case !lsf:
+		angle := float64(position) * math.Pi / 12
+		kl = math.Sin(angle) / (math.Sin(angle) + math.Cos(angle))
+		kr = math.Cos(angle) / (math.Sin(angle) + math.Cos(angle))
+	case position%2 == 1:
+		kl = math.Pow(scale, float64(position+1)/2)
+	case position > 0:
+		kr = math.Pow(scale, float64(position)/2)
+	}
+	for i := range left {
+		left[i], right[i] = left[i]*kl, left[i]*kr
+	}
+}
+
+// reorder puts the lines of the short blocks of a granule in the order of the IMDCT, interleaving the windows of
+// every frequency
+func reorder(g *granule, short [14]int, lines *[granuleLines]float64) {
+	longEnd := 0
+	if g.mixedBlock {
+		longEnd = 36
+	}
+	var reordered [granuleLines]float64
+	sfb := 0
+	for 3*short[sfb] < longEnd {
+		sfb++
+	}
+	for ; sfb < 13; sfb++ {
+		start, width := 3*short[sfb], short[sfb+1]-short[sfb]
+		for w := 0; w < 3; w++ {
+			for j := 0; j < width; j++ {
+				reordered[start+3*j+w] = lines[start+w*width+j]
+			}
+		}
+	}
+	copy(lines[longEnd:], reordered[longEnd:])
+}
+
+// antialias reduces the aliasing between the subbands of long blocks
+func antialias(g *granule, lines *[granuleLines]float64) {
+	limit := subbands
+	if g.shortBlocks() {
+		if !g.mixedBlock {
+			return
+		}
+		limit = 2
+	}
+	for sb := 1; sb < limit; sb++ {
+		for i := 0; i < 8; i++ {
+			lower, upper := sb*subbandSamples-1-i, sb*subbandSamples+i
+			a, b := lines[lower], lines[upper]
+			lines[lower] = a*antialiasCS[i] - b*antialiasCA[i]
+			lines[upper] = b*antialiasCS[i] + a*antialiasCA[i]
+		}
+	}
+}
+
+// hybrid turns the lines of each subband of a granule of a channel into subband samples with an IMDCT, windowed by
+// the block type and overlapped with the previous granule
+func (d *mp3Decoder) hybrid(g *granule, ch int, lines *[granuleLines]float64) {
+	for sb := 0; sb < subbands; sb++ {
+		in := lines[sb*subbandSamples : (sb+1)*subbandSamples]
+		overlap := &d.overlap[ch][sb]
+		out := &d.samples[sb]
+		blockType := blockLong
+		if g.windowSwitching && !(g.mixedBlock && sb < 2) {
+			blockType = g.blockType
+		}
+		silent := true
+		for _, v := range in {
+			if v != 0 {
+				silent = false
+				break
+			}
+		}
+		var y [2 * subbandSamples]float64
+		switch {
+		case silent:
+		case blockType == blockShort:
+			for w := 0; w < 3; w++ {
+				for i := 0; i < 12; i++ {
+					var sum float64
+					for k := 0; k < 6; k++ {
+						sum += in[3*k+w] * imdctShort[i][k]
+					}
+					y[6+6*w+i] += sum * imdctWindows[blockShort][i]
+				}
+			}
+		default:
+			for i := range y {
+				var sum float64
+				for k, v := range in {
+					sum += v * imdctLong[i][k]
+				}
+				y[i] = sum * imdctWindows[blockType][i]
+			}
+		}
+		for i := 0; i < subbandSamples; i++ {
+			out[i] = y[i] + overlap[i]
+			overlap[i] = y[subbandSamples+i]
+			// the odd subbands are inverted in frequency
+			if sb%2 == 1 && i%2 == 1 {
+				out[i] = -out[i]
+			}
+		}
+	}
+}
+
+// synthesisFilter is the polyphase filterbank turning the samples of the 32 subbands into PCM samples, with a ring
+// buffer of its last 16 matrixed vectors
+type synthesisFilter struct {
+	v      [1024]float64
+	offset int
+}
+
+// synthesize turns a sample of each subband into 32 PCM samples
+func (f *synthesisFilter) synthesize(s *[subbands]float64, out []float64) {
+	f.offset = (f.offset - 64) & 1023
+	v := f.v[f.offset : f.offset+64]
+	for i := range v {
+		var sum float64
+		for k, sample := range s {
+			sum += synthesisMatrix[i][k] * sample
+		}
+		v[i] = sum
+	}
+	for j := 0; j < subbands; j++ {
+		var sum float64
+		for i := 0; i < 8; i++ {
+			sum += f.v[(f.offset+128*i+j)&1023] * synthesisWindow[64*i+j]
+			sum += f.v[(f.offset+128*i+96+j)&1023] * synthesisWindow[64*i+32+j]
+		}
+		out[j] = clamp(sum)
+	}
+}
+
+var (
+	// antialiasCS and antialiasCA are the butterflies of the alias reduction
+	antialiasCS, antialiasCA = func() (cs, ca [8]float64) {
+		for i, c := range antialiasCoefficients {
+			cs[i] = 1 / math.Sqrt(1+c*c)
+			ca[i] = c / math.Sqrt(1+c*c)
+		}
+		return cs, ca
+	}()
+	// imdctLong and imdctShort are the cosines of the IMDCT of long and short blocks
+	imdctLong = func() (table [36][18]float64) {
+		for i := range table {
+			for k := range table[i] {
+				table[i][k] = math.Cos(math.Pi / 72 * float64((2*i+1+18)*(2*k+1)))
+			}
+		}
+		return table
+	}()
+	imdctShort = func() (table [12][6]float64) {
+		for i := range table {
+			for k := range table[i] {
+				table[i][k] = math.Cos(math.Pi / 24 * float64((2*i+1+6)*(2*k+1)))
+			}
+		}
+		return table
+	}()
+	// imdctWindows are the windows of the IMDCT by block type; that of short blocks spans 12 samples
+	imdctWindows = func() (windows [4][36]float64) {
+		for i := 0; i < 36; i++ {
+			windows[blockLong][i] = math.Sin(math.Pi / 36 * (float64(i) + 0.5))
+		}
+		for i := 0; i < 18; i++ {
+			windows[blockStart][i] = windows[blockLong][i]
+			windows[blockStop][i+18] = windows[blockLong][i+18]
+		}
+		for i := 18; i < 24; i++ {
+			windows[blockStart][i] = 1
+			windows[blockStop][i-12] = 1
+		}
+		for i := 24; i < 30; i++ {
+			windows[blockStart][i] = math.Sin(math.Pi / 12 * (float64(i-18) + 0.5))
+			windows[blockStop][i-18] = math.Sin(math.Pi / 12 * (float64(i-24) + 0.5))
+		}
+		for i := 0; i < 12; i++ {
+			windows[blockShort][i] = math.Sin(math.Pi / 12 * (float64(i) + 0.5))
+		}
+		return windows
+	}()
+	// synthesisMatrix are the cosines matrixing the subband samples of the polyphase synthesis
+	synthesisMatrix = func() (matrix [64][subbands]float64) {
+		for i := range matrix {
+			for k := range matrix[i] {
+				matrix[i][k] = math.Cos(math.Pi / 64 * float64((16+i)*(2*k+1)))
+			}
+		}
+		return matrix
+	}()
+)
+
+// huffmanTree decodes a Huffman code bit by bit: a node is a pair of entries, which are either the index of the next
+// node or, when negative, the symbol s of a leaf stored as -s-1
+type huffmanTree []int
+
+// newHuffmanTree builds the decoding tree of the Huffman table
+func newHuffmanTree(table huffmanTable) huffmanTree {
+	tree := huffmanTree{0, 0}
+	for symbol, code := range table.codes {
+		node := 0
+		for bit := int(table.lengths[symbol]) - 1; bit >= 0; bit-- {
+			entry := 2*node + int(code>>uint(bit)&1)
+			if bit == 0 {
+				tree[entry] = -symbol - 1
+				break
+			}
+			if tree[entry] == 0 {
+				tree[entry] = len(tree) / 2
+				tree = append(tree, 0, 0)
+			}
+			node = tree[entry]
+		}
+	}
+	return tree
+}
+
+// decode reads a code and returns its symbol; bits past the end of the data read as zeros
+func (t huffmanTree) decode(br *bitReader) int {
+	node := 0
+	for {
+		entry := t[2*node+br.bits(1)]
+		if entry < 0 {
+			return -entry - 1
+		}
+		if entry == 0 {
+			// unreachable with complete codes, but keeps corrupt trees from looping
+			return 0
+		}
+		node = entry
+	}
+}
+
+var (
+	bigValueTrees = func() map[int]huffmanTree {
+		trees := make(map[int]huffmanTree, len(bigValueTables))
+		for n, table := range bigValueTables {
+			trees[n] = newHuffmanTree(table)
+		}
+		return trees
+	}()
+	count1Trees = [2]huffmanTree{newHuffmanTree(count1Tables[0]), newHuffmanTree(count1Tables[1])}
+)
+
+// bitReader reads big-endian bit fields; bits past the end of the data read as zeros
+type bitReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *bitReader) bits(n int) int {
+	v := 0
+	for ; n > 0; n-- {
+		i := r.pos >> 3
+		bit := 0
+		if i < len(r.b) {
+			bit = int(r.b[i]>>uint(7-r.pos&7)) & 1
+		}
+		v = v<<1 | bit
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) skip(n int) {
+	r.pos += n
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,208 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// bitWriter writes big-endian bit fields
+type bitWriter struct {
+	b   []byte
+	pos int
+}
+
+func (w *bitWriter) write(n, v int) {
+	for bit := n - 1; bit >= 0; bit-- {
+		if w.pos>>3 == len(w.b) {
+			w.b = append(w.b, 0)
+		}
+		if v>>uint(bit)&1 == 1 {
+			w.b[w.pos>>3] |= 0x80 >> uint(w.pos&7)
+		}
+		w.pos++
+	}
+}
+
+// toneFrame returns a 417 byte MPEG-1 layer III frame at 128 kbit/s, 44.1 kHz, stereo, whose granules hold the
+// spectral line at 1 in both channels, coded with count1 table B and scaled by the global gain
+func toneFrame(line, gain int) []byte {
+	// the spectrum of a granule of a channel, coded the same four times
+	main := &bitWriter{}
+	for i := 0; i < 4; i++ {
+		for q := 0; q < line/4; q++ {
+			// count1 table B codes a quadruple as its inverted bits
+			main.write(4, 15)
+		}
+		main.write(4, 15^8>>uint(line%4))
+		main.write(1, 0) // sign
+	}
+
+	side := &bitWriter{}
+	side.write(9, 0) // main_data_begin
+	side.write(3, 0) // private bits
+	side.write(8, 0) // scfsi
+	for i := 0; i < 4; i++ {
+		side.write(12, main.pos/4) // part2_3_length
+		side.write(9, 0)           // big_values
+		side.write(8, gain)        // global_gain
+		side.write(4, 0)           // scalefac_compress
+		side.write(1, 0)           // window_switching_flag
+		side.write(15, 0)          // table_select
+		side.write(7, 0)           // region0_count, region1_count
+		side.write(2, 0)           // preflag, scalefac_scale
+		side.write(1, 1)           // count1table_select
+	}
+	frame := mp3Frame()
+	copy(frame[4:], side.b)
+	copy(frame[36:], main.b)
+	return frame
+}
+
+// newToneMP3 returns an MP3 file of tone frames
+func newToneMP3(frames, line, gain int) []byte {
+	var b []byte
+	for i := 0; i < frames; i++ {
+		b = append(b, toneFrame(line, gain)...)
+	}
+	return b
+}
+
+// decodeMP3 decodes the channels of the MP3 file
+func decodeMP3(t *testing.T, file []byte) [][]float64 {
+	stream, err := openPCM(bytes.NewReader(file), int64(len(file)))
+	assert.NoError(t, err)
+	channels := make([][]float64, stream.channels)
+	err = stream.each(func(i int64, samples []float64) {
+		for c, sample := range samples {
+			channels[c] = append(channels[c], sample)
+		}
+	})
+	assert.NoError(t, err)
+	return channels
+}
+
+func TestHuffmanTrees(t *testing.T) {
+	tables := map[string]huffmanTable{"count1 A": count1Tables[0], "count1 B": count1Tables[1]}
+	for n, table := range bigValueTables {
+		tables["big values "+strconv.Itoa(n)] = table
+	}
+	for name, table := range tables {
+		t.Run(name, func(t *testing.T) {
+			tree := newHuffmanTree(table)
+			w := &bitWriter{}
+			for symbol, code := range table.codes {
+				w.write(int(table.lengths[symbol]), int(code))
+			}
+
+			r := &bitReader{b: w.b}
+			for symbol := range table.codes {
+				assert.Equal(t, symbol, tree.decode(r))
+			}
+			assert.Equal(t, w.pos, r.pos)
+		})
+	}
+}
+
+func TestOpenMP3(t *testing.T) {
+	t.Run("tones", func(t *testing.T) {
+		for _, line := range []int{3, 20, 100, 301, 500} {
+			channels := decodeMP3(t, newToneMP3(20, line, 202))
+
+			// the frequency of the line, from the zero crossings after the first frame, within the width of a line
+			crossings := 0
+			for i := 1152; i < len(channels[0]); i++ {
+				if channels[0][i-1] < 0 && channels[0][i] >= 0 {
+					crossings++
+				}
+			}
+			want := (float64(line) + 0.5) * 44100 / 1152
+			assert.InDelta(t, want, float64(crossings)*44100/float64(len(channels[0])-1152), 44100/1152.0, "line %d", line)
+			// a global gain of 202 scales the line by a quarter
+			assert.InDelta(t, 0.25, peak(channels[0][1152:]), 0.025, "line %d", line)
+			assert.Equal(t, channels[0], channels[1])
+		}
+	})
+
+	t.Run("middle/side stereo", func(t *testing.T) {
+		file := newToneMP3(10, 20, 202)
+		for i := 0; i < len(file); i += 417 {
+			// joint stereo, with middle/side but without intensity stereo
+			file[i+3] = 0x60
+		}
+
+		channels := decodeMP3(t, file)
+
+		// a side as loud as the middle leaves the right channel silent
+		assert.InDelta(t, 0.25*math.Sqrt2, peak(channels[0][1152:]), 0.035)
+		assert.Equal(t, 0.0, peak(channels[1]))
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		channels := decodeMP3(t, newMP3(10))
+
+		assert.Len(t, channels[0], 11520)
+		assert.Equal(t, 0.0, peak(channels[0]))
+		assert.Equal(t, 0.0, peak(channels[1]))
+	})
+
+	t.Run("xing frame left out", func(t *testing.T) {
+		channels := decodeMP3(t, newXingMP3(10))
+
+		assert.Len(t, channels[0], 11520)
+	})
+
+	t.Run("main data in frames cut off", func(t *testing.T) {
+		file := newToneMP3(3, 20, 202)
+		// the main data of the first frame starts 100 bytes before it
+		file[4], file[5] = 100>>1, 0
+
+		channels := decodeMP3(t, file)
+
+		assert.Equal(t, 0.0, peak(channels[0][:1152]))
+		assert.NotEqual(t, 0.0, peak(channels[0][1152:]))
+	})
+}
+
+func TestOpenMP3_Invalid(t *testing.T) {
+	mp2 := make([]byte, 522)
+	copy(mp2, []byte{0xFF, 0xFD, 0x90, 0x00})
+	tooManyBigValues := mp3Frame()
+	side := &bitWriter{}
+	side.write(20+12, 0)
+	side.write(9, 300)
+	copy(tooManyBigValues[4:], side.b)
+
+	cases := []struct {
+		name  string
+		file  []byte
+		cause error
+	}{
+		{"mp2", bytes.Repeat(mp2, 5), ErrUnsupported},
+		{"too many big values", join(mp3Frame(), tooManyBigValues), ErrCorrupt},
+		{"truncated", newMP3(10)[:417*5], ErrCorrupt},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stream, err := openPCM(bytes.NewReader(c.file), int64(len(c.file)))
+			if err == nil {
+				err = stream.each(func(i int64, samples []float64) {})
+			}
+
+			assert.Equal(t, c.cause, errors.Cause(err))
+		})
+	}
+}
+
+// peak returns the largest magnitude of the samples
+func peak(samples []float64) float64 {
+	var max float64
+	for _, sample := range samples {
+		max = math.Max(max, math.Abs(sample))
+	}
+	return max
+}
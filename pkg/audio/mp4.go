@@ -0,0 +1,284 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+// mp4AACObjectTypes and mp4MP3ObjectTypes are the objectTypeIndication values of the decoder config descriptor
+// of AAC and MP3 streams
+var (
+	mp4AACObjectTypes = map[byte]bool{0x40: true, 0x66: true, 0x67: true, 0x68: true}
+	mp4MP3ObjectTypes = map[byte]bool{0x69: true, 0x6B: true}
+)
+
+// atom is a box of an ISO base media file
+type atom struct {
+	typ string
+	// body and end are the offsets of the content of the atom and of its end
+	body, end int64
+}
+
+// readAtoms returns the atoms between the offsets; an atom running past the end means the file is truncated
+func readAtoms(r io.ReaderAt, off, end int64) ([]atom, error) {
+	var atoms []atom
+	for off+8 <= end {
+		header, err := readAt(r, off, 8)
+		if err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		a := atom{typ: string(header[4:8]), body: off + 8}
+		switch size {
+		case 0:
+			// the last atom of the file may run to its end
+			size = end - off
+		case 1:
+			large, err := readAt(r, off+8, 8)
+			if err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(large))
+			a.body += 8
+		}
+		a.end = off + size
+		if a.end < a.body || a.end > end {
+			return nil, corrupt(a.typ + " atom truncated")
+		}
+		atoms = append(atoms, a)
+		off = a.end
+	}
+	return atoms, nil
+}
+
+// findAtom returns the first atom of the type between the offsets
+func findAtom(r io.ReaderAt, off, end int64, typ string) (atom, bool, error) {
+	atoms, err := readAtoms(r, off, end)
+	if err != nil {
+		return atom{}, false, err
+	}
+	for _, a := range atoms {
+		if a.typ == typ {
+			return a, true, nil
+		}
+	}
+	return atom{}, false, nil
+}
+
+// findPath returns the atom at the path of nested atom types, e.g. "mdia", "minf", "stbl"
+func findPath(r io.ReaderAt, parent atom, path ...string) (atom, bool, error) {
+	a := parent
+	for _, typ := range path {
+		var (
+			ok  bool
+			err error
+		)
+		a, ok, err = findAtom(r, a.body, a.end, typ)
+		if err != nil || !ok {
+			return atom{}, false, err
+		}
+	}
+	return a, true, nil
+}
+
+// probeMP4 describes the first sound track of an MP4 or M4A file from the atoms of its movie header
+func probeMP4(r io.ReaderAt, size int64) (*Metadata, error) {
+	atoms, err := readAtoms(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	var moov *atom
+	var mdat int64
+	for i := range atoms {
+		switch atoms[i].typ {
+		case "moov":
+			moov = &atoms[i]
+		case "mdat":
+			mdat += atoms[i].end - atoms[i].body
+		}
+	}
+	if moov == nil {
+		return nil, corrupt("missing moov atom")
+	}
+
+	traks, err := readAtoms(r, moov.body, moov.end)
+	if err != nil {
+		return nil, err
+	}
+	for _, trak := range traks {
+		if trak.typ != "trak" {
+			continue
+		}
+		mdia, ok, err := findPath(r, trak, "mdia")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		hdlr, ok, err := findPath(r, mdia, "hdlr")
+		if err != nil {
+			return nil, err
+		}
+		if !ok || hdlr.end-hdlr.body < 12 {
+			continue
+		}
+		handler, err := readAt(r, hdlr.body+8, 4)
+		if err != nil {
+			return nil, err
+		}
+		if string(handler) != "soun" {
+			continue
+		}
+		metadata, err := probeMP4Track(r, mdia)
+		if err != nil {
+			return nil, err
+		}
+		if metadata.Bitrate == 0 {
+			metadata.Bitrate = bitrate(mdat, metadata.Duration)
+		}
+		return metadata, nil
+	}
+	return nil, corrupt("no sound track")
+}
+
+// probeMP4Track describes a sound track from its media header and its first sample description
+func probeMP4Track(r io.ReaderAt, mdia atom) (*Metadata, error) {
+	mdhd, ok, err := findPath(r, mdia, "mdhd")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, corrupt("missing mdhd atom")
+	}
+	header, err := readAt(r, mdhd.body, minInt64(mdhd.end-mdhd.body, 32))
+	if err != nil {
+		return nil, err
+	}
+	var timescale, duration int64
+	switch {
+	case header[0] == 1 && len(header) >= 32:
+		timescale = int64(binary.BigEndian.Uint32(header[20:]))
+		duration = int64(binary.BigEndian.Uint64(header[24:]))
+	case header[0] == 0 && len(header) >= 20:
+		timescale = int64(binary.BigEndian.Uint32(header[12:]))
+		duration = int64(binary.BigEndian.Uint32(header[16:]))
+	default:
+		return nil, corrupt("invalid mdhd atom")
+	}
+	if duration == 0 || duration == 0xFFFFFFFF {
+		return nil, unsupported("fragmented MP4")
+	}
+	if timescale == 0 {
+		return nil, corrupt("invalid mdhd atom")
+	}
+
+	stsd, ok, err := findPath(r, mdia, "minf", "stbl", "stsd")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, corrupt("missing stsd atom")
+	}
+	// the sample descriptions follow the version, flags and entry count
+	entries, err := readAtoms(r, stsd.body+8, stsd.end)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || entries[0].end-entries[0].body < 28 {
+		return nil, corrupt("missing sample description")
+	}
+	entry := entries[0]
+	b, err := readAt(r, entry.body, 28)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &Metadata{
+		Duration:   samplesDuration(duration, int(timescale)),
+		Channels:   int(binary.BigEndian.Uint16(b[16:])),
+		SampleRate: int(binary.BigEndian.Uint16(b[24:])),
+	}
+	switch entry.typ {
+	case "mp4a":
+		objectType, avgBitrate, err := mp4DecoderConfig(r, entry)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case mp4AACObjectTypes[objectType]:
+			metadata.Codec = CodecAAC
+		case mp4MP3ObjectTypes[objectType]:
+			metadata.Codec = CodecMP3
+		default:
+			return nil, unsupported("MP4 audio object type 0x" + strconv.FormatUint(uint64(objectType), 16))
+		}
+		metadata.Bitrate = avgBitrate
+	case ".mp3":
+		metadata.Codec = CodecMP3
+	case "alac":
+		metadata.Codec = CodecALAC
+	case "Opus":
+		metadata.Codec = CodecOpus
+	case "fLaC":
+		metadata.Codec = CodecFLAC
+	default:
+		return nil, unsupported("MP4 sample entry " + entry.typ)
+	}
+	return metadata, nil
+}
+
+// mp4DecoderConfig reads the object type and average bitrate of the decoder config descriptor in the esds atom of
+// an mp4a sample entry
+func mp4DecoderConfig(r io.ReaderAt, entry atom) (objectType byte, avgBitrate int, err error) {
+	// the child atoms of a version 0 audio sample entry follow its 28 bytes of fields
+	esds, ok, err := findAtom(r, entry.body+28, entry.end, "esds")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return 0, 0, corrupt("missing esds atom")
+	}
+	b, err := readAt(r, esds.body, minInt64(esds.end-esds.body, 128))
+	if err != nil {
+		return 0, 0, err
+	}
+	// skip the version and flags, then walk the descriptors down to the decoder config descriptor
+	for i := 4; i < len(b); {
+		tag := b[i]
+		i++
+		// the length is coded on up to 4 bytes of 7 bits
+		for n := 0; n < 4 && i < len(b); n++ {
+			i++
+			if b[i-1]&0x80 == 0 {
+				break
+			}
+		}
+		switch tag {
+		case 0x03:
+			// ES descriptor: ES_ID and flags, then the optional fields the flags announce
+			if i+3 > len(b) {
+				return 0, 0, corrupt("invalid esds atom")
+			}
+			flags := b[i+2]
+			i += 3
+			if flags&0x80 != 0 {
+				i += 2
+			}
+			if flags&0x40 != 0 && i < len(b) {
+				i += 1 + int(b[i])
+			}
+			if flags&0x20 != 0 {
+				i += 2
+			}
+		case 0x04:
+			if i+13 > len(b) {
+				return 0, 0, corrupt("invalid esds atom")
+			}
+			return b[i], int(binary.BigEndian.Uint32(b[i+9:])), nil
+		default:
+			return 0, 0, corrupt("invalid esds atom")
+		}
+	}
+	return 0, 0, corrupt("missing decoder config descriptor")
+}
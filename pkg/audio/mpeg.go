@@ -0,0 +1,331 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// MPEG audio versions, from the version bits of the frame header
+const (
+	mpeg25 = 0
+	mpeg2  = 2
+	mpeg1  = 3
+)
+
+// mpegBitrates are the bitrates in kbit/s of the bitrate indexes, for MPEG-1 layers I, II and III, and for
+// MPEG-2 and 2.5 layer I, and layers II and III
+var mpegBitrates = [5][15]int{
+	{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+// mpegSampleRates are the sample rates of the sample rate indexes, by version
+var mpegSampleRates = map[int][3]int{
+	mpeg1:  {44100, 48000, 32000},
+	mpeg2:  {22050, 24000, 16000},
+	mpeg25: {11025, 12000, 8000},
+}
+
+// adtsSampleRates are the sample rates of the sampling frequency indexes of ADTS headers
+var adtsSampleRates = [13]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// frameHeader describes an MPEG audio or ADTS frame
+type frameHeader struct {
+	codec      string
+	version    int
+	layer      int
+	sampleRate int
+	channels   int
+	// size is the size of the whole frame, header included
+	size    int
+	samples int
+}
+
+// consistent reports whether the frames belong to the same stream
+func (h frameHeader) consistent(other frameHeader) bool {
+	return h.codec == other.codec && h.version == other.version && h.layer == other.layer && h.sampleRate == other.sampleRate
+}
+
+// parseMPEGHeader parses the 4 byte header of an MPEG audio frame
+func parseMPEGHeader(b []byte) (frameHeader, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return frameHeader{}, false
+	}
+	version := int(b[1]>>3) & 3
+	layer := 4 - int(b[1]>>1)&3
+	bitrateIndex := int(b[2] >> 4)
+	sampleRateIndex := int(b[2]>>2) & 3
+	padding := int(b[2]>>1) & 1
+	if version == 1 || layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		// reserved values, or the free format that cannot be sized from the header
+		return frameHeader{}, false
+	}
+
+	table := layer - 1
+	if version != mpeg1 {
+		table = 4
+		if layer == 1 {
+			table = 3
+		}
+	}
+	bitrate := mpegBitrates[table][bitrateIndex] * 1000
+	h := frameHeader{
+		codec:      [4]string{"", CodecMP1, CodecMP2, CodecMP3}[layer],
+		version:    version,
+		layer:      layer,
+		sampleRate: mpegSampleRates[version][sampleRateIndex],
+		channels:   2,
+	}
+	if b[3]>>6 == 3 {
+		h.channels = 1
+	}
+	switch {
+	case layer == 1:
+		h.samples = 384
+		h.size = (12*bitrate/h.sampleRate + padding) * 4
+	case layer == 3 && version != mpeg1:
+		h.samples = 576
+		h.size = 72*bitrate/h.sampleRate + padding
+	default:
+		h.samples = 1152
+		h.size = 144*bitrate/h.sampleRate + padding
+	}
+	return h, true
+}
+
+// parseADTSHeader parses the 7 byte header of an ADTS frame of AAC audio
+func parseADTSHeader(b []byte) (frameHeader, bool) {
+	if len(b) < 7 || b[0] != 0xFF || b[1]&0xF6 != 0xF0 {
+		return frameHeader{}, false
+	}
+	sampleRateIndex := int(b[2]>>2) & 0xF
+	if sampleRateIndex >= len(adtsSampleRates) {
+		return frameHeader{}, false
+	}
+	channels := int(b[2]&1)<<2 | int(b[3]>>6)
+	if channels == 7 {
+		channels = 8
+	}
+	size := int(b[3]&3)<<11 | int(b[4])<<3 | int(b[5]>>5)
+	if size < 7 {
+		return frameHeader{}, false
+	}
+	return frameHeader{
+		codec:      CodecAAC,
+		sampleRate: adtsSampleRates[sampleRateIndex],
+		channels:   channels,
+		size:       size,
+		samples:    (int(b[6]&3) + 1) * 1024,
+	}, true
+}
+
+// parseFrameHeader parses the header of an MPEG audio or ADTS frame
+func parseFrameHeader(b []byte) (frameHeader, bool) {
+	if h, ok := parseADTSHeader(b); ok {
+		return h, true
+	}
+	return parseMPEGHeader(b)
+}
+
+// id3Size returns the size of the ID3v2 tags at the offset
+func id3Size(r io.ReaderAt, off, size int64) (int64, error) {
+	start := off
+	for off+10 <= size {
+		header, err := readAt(r, off, 10)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+		// the size is a 28 bit synchsafe integer, excluding the header and the footer
+		n := int64(header[6]&0x7F)<<21 | int64(header[7]&0x7F)<<14 | int64(header[8]&0x7F)<<7 | int64(header[9]&0x7F)
+		off += 10 + n
+		if header[5]&0x10 != 0 {
+			off += 10
+		}
+	}
+	if off > size {
+		return 0, corrupt("ID3 tag truncated")
+	}
+	return off - start, nil
+}
+
+// audioRange returns the range of an MPEG audio or ADTS file that holds frames, without its ID3 tags
+func audioRange(r io.ReaderAt, size int64) (start, end int64, err error) {
+	start, err = id3Size(r, 0, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	end = size
+	if size-start >= 128 {
+		tail, err := readAt(r, size-128, 3)
+		if err != nil {
+			return 0, 0, err
+		}
+		if string(tail) == "TAG" {
+			end -= 128
+		}
+	}
+	return start, end, nil
+}
+
+// firstFrame returns the offset and header of the first frame in the range, confirmed by the header of the frame
+// that follows it, so that a stray sync word in leftover tag data is skipped
+func firstFrame(r io.ReaderAt, start, end int64) (int64, frameHeader, error) {
+	const window = 64 << 10
+	b, err := readAt(r, start, minInt64(end-start, window))
+	if err != nil {
+		return 0, frameHeader{}, err
+	}
+	for i := 0; i+4 <= len(b); i++ {
+		h, ok := parseFrameHeader(b[i:])
+		if !ok {
+			continue
+		}
+		next := i + h.size
+		if start+int64(next) == end {
+			return start + int64(i), h, nil
+		}
+		if next+7 <= len(b) {
+			if following, ok := parseFrameHeader(b[next:]); ok && following.consistent(h) {
+				return start + int64(i), h, nil
+			}
+		}
+	}
+	return 0, frameHeader{}, corrupt("no audio frames")
+}
+
+// probeMPEG describes an MPEG audio or ADTS file, from its Xing or VBRI header when it has one, or else by
+// counting its frames
+func probeMPEG(r io.ReaderAt, size int64) (*Metadata, error) {
+	start, end, err := audioRange(r, size)
+	if err != nil {
+		return nil, err
+	}
+	first, h, err := firstFrame(r, start, end)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &Metadata{
+		ContentType: ContentTypeMP3,
+		Codec:       h.codec,
+		SampleRate:  h.sampleRate,
+		Channels:    h.channels,
+	}
+	if h.codec == CodecAAC {
+		metadata.ContentType = ContentTypeAAC
+	}
+
+	if h.layer == 3 {
+		frames, bytes, ok, err := vbrHeader(r, first, h)
+		if err != nil {
+			return nil, err
+		}
+		if ok && frames > 0 {
+			if bytes > 0 && first+bytes > end {
+				return nil, corrupt("fewer bytes than the VBR header announces")
+			}
+			metadata.Duration = samplesDuration(frames*int64(h.samples), h.sampleRate)
+			metadata.Bitrate = bitrate(end-first, metadata.Duration)
+			return metadata, nil
+		}
+	}
+
+	samples, err := countSamples(r, first, end, h)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Duration = samplesDuration(samples, h.sampleRate)
+	metadata.Bitrate = bitrate(end-first, metadata.Duration)
+	return metadata, nil
+}
+
+// vbrHeader reads the frame and byte counts of the Xing, Info or VBRI header in the first frame, if any
+func vbrHeader(r io.ReaderAt, off int64, h frameHeader) (frames, bytes int64, ok bool, err error) {
+	b, err := readAt(r, off, int64(h.size))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	// the Xing header follows the side information, whose size depends on the version and channels
+	side := 32
+	switch {
+	case h.version == mpeg1 && h.channels == 1:
+		side = 17
+	case h.version != mpeg1 && h.channels == 2:
+		side = 17
+	case h.version != mpeg1:
+		side = 9
+	}
+	if x := 4 + side; x+8 <= len(b) && (string(b[x:x+4]) == "Xing" || string(b[x:x+4]) == "Info") {
+		flags := binary.BigEndian.Uint32(b[x+4:])
+		x += 8
+		if flags&1 != 0 && x+4 <= len(b) {
+			frames = int64(binary.BigEndian.Uint32(b[x:]))
+			x += 4
+		}
+		if flags&2 != 0 && x+4 <= len(b) {
+			bytes = int64(binary.BigEndian.Uint32(b[x:]))
+		}
+		return frames, bytes, true, nil
+	}
+	if v := 4 + 32; v+18 <= len(b) && string(b[v:v+4]) == "VBRI" {
+		bytes = int64(binary.BigEndian.Uint32(b[v+10:]))
+		frames = int64(binary.BigEndian.Uint32(b[v+14:]))
+		return frames, bytes, true, nil
+	}
+	return 0, 0, false, nil
+}
+
+// countSamples walks the frames of the range from the first one and returns the number of samples they hold
+func countSamples(r io.ReaderAt, first, end int64, h frameHeader) (int64, error) {
+	var samples int64
+	err := walkFrames(r, first, end, h, func(off int64, frame frameHeader) {
+		samples += int64(frame.samples)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return samples, nil
+}
+
+// walkFrames calls fn with the offset and header of every frame of the range from the first one, skipping junk
+// between frames; a frame of the stream cut short by the end of the file means it is truncated
+func walkFrames(r io.ReaderAt, first, end int64, h frameHeader, fn func(off int64, frame frameHeader)) error {
+	br := bufio.NewReaderSize(io.NewSectionReader(r, first, end-first), 64<<10)
+	off := first
+	for {
+		b, err := br.Peek(7)
+		if len(b) < 4 {
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		frame, ok := parseFrameHeader(b)
+		if !ok || !frame.consistent(h) {
+			_, err = br.Discard(1)
+			if err != nil {
+				return err
+			}
+			off++
+			continue
+		}
+		if off+int64(frame.size) > end {
+			return corrupt("last frame truncated")
+		}
+		_, err = br.Discard(frame.size)
+		if err != nil {
+			return err
+		}
+		fn(off, frame)
+		off += int64(frame.size)
+	}
+}
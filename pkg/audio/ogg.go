@@ -0,0 +1,133 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	oggHeaderSize = 27
+	// oggMaxPageSize is the size of a page with 255 segments of 255 bytes
+	oggMaxPageSize = oggHeaderSize + 255 + 255*255
+	oggEndOfStream = 0x04
+	// opusSampleRate is the rate Opus granule positions count at, whatever the rate of the input
+	opusSampleRate = 48000
+)
+
+// oggPage is the header of an Ogg page
+type oggPage struct {
+	headerType byte
+	granule    int64
+	serial     uint32
+	// size is the size of the whole page, header and segment table included
+	size     int
+	segments []byte
+}
+
+// parseOggPage parses the header of the page starting at b
+func parseOggPage(b []byte) (oggPage, bool) {
+	if len(b) < oggHeaderSize || !bytes.HasPrefix(b, []byte("OggS")) || b[4] != 0 {
+		return oggPage{}, false
+	}
+	n := int(b[26])
+	if len(b) < oggHeaderSize+n {
+		return oggPage{}, false
+	}
+	page := oggPage{
+		headerType: b[5],
+		granule:    int64(binary.LittleEndian.Uint64(b[6:])),
+		serial:     binary.LittleEndian.Uint32(b[14:]),
+		segments:   b[oggHeaderSize : oggHeaderSize+n],
+		size:       oggHeaderSize + n,
+	}
+	for _, segment := range page.segments {
+		page.size += int(segment)
+	}
+	return page, true
+}
+
+// probeOgg describes the first logical stream of an Ogg file from its identification header, and its duration from
+// the granule position of its last page
+func probeOgg(r io.ReaderAt, size int64) (*Metadata, error) {
+	b, err := readAt(r, 0, minInt64(size, oggMaxPageSize))
+	if err != nil {
+		return nil, err
+	}
+	first, ok := parseOggPage(b)
+	if !ok || first.size > len(b) {
+		return nil, corrupt("invalid first Ogg page")
+	}
+	packet := b[oggHeaderSize+len(first.segments) : first.size]
+
+	var (
+		metadata = &Metadata{}
+		preSkip  int64
+		rate     int
+	)
+	switch {
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 19:
+		metadata.Codec = CodecOpus
+		metadata.Channels = int(packet[9])
+		metadata.SampleRate = opusSampleRate
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:]))
+		rate = opusSampleRate
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 28:
+		metadata.Codec = CodecVorbis
+		metadata.Channels = int(packet[11])
+		metadata.SampleRate = int(binary.LittleEndian.Uint32(packet[12:]))
+		rate = metadata.SampleRate
+	case bytes.HasPrefix(packet, []byte("\x7FFLAC")) && len(packet) >= 13+flacStreamInfoSize:
+		// the mapping header is followed by the native signature and the STREAMINFO block
+		info, err := parseStreamInfo(packet[13:])
+		if err != nil {
+			return nil, err
+		}
+		metadata.Codec = CodecFLAC
+		metadata.Channels = info.Channels
+		metadata.SampleRate = info.SampleRate
+		rate = metadata.SampleRate
+	default:
+		return nil, unsupported("unknown Ogg stream")
+	}
+
+	granule, err := lastGranule(r, size, first.serial)
+	if err != nil {
+		return nil, err
+	}
+	if granule < preSkip {
+		return nil, corrupt("granule position before the pre-skip")
+	}
+	metadata.Duration = samplesDuration(granule-preSkip, rate)
+	return metadata, nil
+}
+
+// lastGranule returns the granule position of the last page of the stream, which must end the stream
+func lastGranule(r io.ReaderAt, size int64, serial uint32) (int64, error) {
+	start := size - minInt64(size, 2*oggMaxPageSize)
+	tail, err := readAt(r, start, size-start)
+	if err != nil {
+		return 0, err
+	}
+	last := true
+	for i := len(tail) - oggHeaderSize; i >= 0; i-- {
+		page, ok := parseOggPage(tail[i:])
+		if !ok {
+			continue
+		}
+		if last {
+			// the last page of the file tells whether it was cut short
+			if i+page.size > len(tail) {
+				return 0, corrupt("last Ogg page truncated")
+			}
+			if page.serial == serial && page.headerType&oggEndOfStream == 0 {
+				return 0, corrupt("missing end of stream")
+			}
+			last = false
+		}
+		if page.serial == serial && page.granule != -1 {
+			return page.granule, nil
+		}
+	}
+	return 0, corrupt("no granule position")
+}
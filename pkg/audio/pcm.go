@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"bufio"
+	"io"
+)
+
+// pcmStream is the decoded audio of a file, read sample frame by sample frame
+type pcmStream struct {
+	sampleRate int
+	channels   int
+	frames     int64
+	decode     func(fn func(i int64, samples []float64)) error
+}
+
+// openPCM locates the samples of the audio file of the given size. WAVE files, of integer, float, A-law or µ-law PCM,
+// and MP3 files can be decoded; other files fail with ErrUnsupported
+func openPCM(r io.ReaderAt, size int64) (*pcmStream, error) {
+	head, err := readAt(r, 0, minInt64(size, SniffLength))
+	if err != nil {
+		return nil, err
+	}
+	switch contentType := DetectContentType(head); contentType {
+	case ContentTypeWAV:
+		return openWAV(r, size)
+	case ContentTypeMP3:
+		return openMP3(r, size)
+	default:
+		return nil, unsupported("decoding " + contentType)
+	}
+}
+
+// openWAV locates the PCM samples of a WAVE file of the given size
+func openWAV(r io.ReaderAt, size int64) (*pcmStream, error) {
+	wav, err := readWAV(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if wav.blockAlign == 0 || wav.blockAlign%wav.channels != 0 {
+		return nil, corrupt("invalid block align")
+	}
+	sampleSize := wav.blockAlign / wav.channels
+	decodeSample, err := sampleDecoder(wav.code, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+	frames := wav.dataSize / int64(wav.blockAlign)
+	if frames == 0 {
+		return nil, corrupt("no audio")
+	}
+	decode := func(fn func(i int64, samples []float64)) error {
+		data := bufio.NewReaderSize(io.NewSectionReader(r, wav.dataOffset, frames*int64(wav.blockAlign)), 64<<10)
+		frame := make([]byte, wav.blockAlign)
+		samples := make([]float64, wav.channels)
+		for i := int64(0); i < frames; i++ {
+			_, err := io.ReadFull(data, frame)
+			if err != nil {
+				return err
+			}
+			for c := range samples {
+				samples[c] = decodeSample(frame[c*sampleSize : (c+1)*sampleSize])
+			}
+			fn(i, samples)
+		}
+		return nil
+	}
+	return &pcmStream{sampleRate: wav.sampleRate, channels: wav.channels, frames: frames, decode: decode}, nil
+}
+
+// each calls fn with the index and the samples of every sample frame, one per channel from -1 to 1; the samples are
+// only valid until fn returns
+func (s *pcmStream) each(fn func(i int64, samples []float64)) error {
+	return s.decode(fn)
+}
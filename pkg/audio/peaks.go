@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Peak is the lowest and the highest sample of a part of the audio, across channels, from -1 to 1
+type Peak struct {
+	Min float64
+	Max float64
+}
+
+// Peaks decodes the audio file of the given size and returns the peaks of resolution consecutive parts of equal
+// length, or of every sample frame of shorter audio. WAVE files, of integer, float, A-law or µ-law PCM, and MP3 files
+// can be decoded; other files fail with ErrUnsupported
+func Peaks(r io.ReaderAt, size int64, resolution int) ([]Peak, error) {
+	stream, err := openPCM(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if int64(resolution) > stream.frames {
+		resolution = int(stream.frames)
+	}
+
+	peaks := make([]Peak, resolution)
+	for i := range peaks {
+		peaks[i] = Peak{Min: math.Inf(1), Max: math.Inf(-1)}
+	}
+	err = stream.each(func(i int64, samples []float64) {
+		peak := &peaks[i*int64(resolution)/stream.frames]
+		for _, sample := range samples {
+			peak.Min = math.Min(peak.Min, sample)
+			peak.Max = math.Max(peak.Max, sample)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return peaks, nil
+}
+
+// sampleDecoder returns the function converting a sample of the WAVE format and size to a value from -1 to 1
+func sampleDecoder(code uint16, sampleSize int) (func(b []byte) float64, error) {
+	switch {
+	case code == wavFormatPCM && sampleSize == 1:
+		// 8 bit samples are unsigned
+		return func(b []byte) float64 { return float64(int(b[0])-128) / 128 }, nil
+	case code == wavFormatPCM && sampleSize <= 4:
+		// shorter samples are left-justified in their container, so the container size gives the full scale
+		shift := uint(32 - 8*sampleSize)
+		return func(b []byte) float64 {
+			var v uint32
+			for i := len(b) - 1; i >= 0; i-- {
+				v = v<<8 | uint32(b[i])
+			}
+			return float64(int32(v<<shift)) / (1 << 31)
+		}, nil
+	case code == wavFormatFloat && sampleSize == 4:
+		return func(b []byte) float64 { return clamp(float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))) }, nil
+	case code == wavFormatFloat && sampleSize == 8:
+		return func(b []byte) float64 { return clamp(math.Float64frombits(binary.LittleEndian.Uint64(b))) }, nil
+	case code == wavFormatALaw && sampleSize == 1:
+		return func(b []byte) float64 { return float64(decodeALaw(b[0])) / (1 << 15) }, nil
+	case code == wavFormatMuLaw && sampleSize == 1:
+		return func(b []byte) float64 { return float64(decodeMuLaw(b[0])) / (1 << 15) }, nil
+	}
+	return nil, unsupported(wavCodecs[code] + " samples of " + strconv.Itoa(sampleSize) + " bytes")
+}
+
+// clamp bounds float samples, which may overshoot, to -1 to 1
+func clamp(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
+}
+
+// decodeALaw expands a G.711 A-law sample to 16 bits
+func decodeALaw(b byte) int16 {
+	b ^= 0x55
+	t := int16(b&0x0F)<<4 + 8
+	if segment := b >> 4 & 0x07; segment > 0 {
+		t = (t + 0x100) << (segment - 1)
+	}
+	if b&0x80 == 0 {
+		return -t
+	}
+	return t
+}
+
+// decodeMuLaw expands a G.711 µ-law sample to 16 bits
+func decodeMuLaw(b byte) int16 {
+	b = ^b
+	t := (int16(b&0x0F)<<3 + 0x84) << (b >> 4 & 0x07)
+	if b&0x80 != 0 {
+		return 0x84 - t
+	}
+	return t - 0x84
+}
@@ -0,0 +1,135 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newWAVOf returns a WAVE file of the format holding the sample data
+func newWAVOf(format, channels, bitsPerSample int, data []byte) []byte {
+	blockAlign := channels * bitsPerSample / 8
+	fmtChunk := join([]byte("fmt "), le32(16), le16(format), le16(channels), le32(8000),
+		le32(8000*blockAlign), le16(blockAlign), le16(bitsPerSample))
+	body := join([]byte("WAVE"), fmtChunk, []byte("data"), le32(len(data)), data)
+	return join([]byte("RIFF"), le32(len(body)), body)
+}
+
+func float32s(values ...float32) []byte {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+	}
+	return b
+}
+
+func TestPeaks(t *testing.T) {
+	cases := []struct {
+		name       string
+		file       []byte
+		resolution int
+		want       []Peak
+	}{
+		{
+			name: "pcm 16 bit stereo",
+			file: newWAVOf(wavFormatPCM, 2, 16, join(
+				le16(0x4000), le16(0), le16(-0x4000), le16(0x2000),
+				le16(0x7FFF), le16(-0x8000), le16(0), le16(0),
+			)),
+			resolution: 2,
+			want:       []Peak{{Min: -0.5, Max: 0.5}, {Min: -1, Max: 0x7FFF / 32768.0}},
+		},
+		{
+			name:       "pcm 8 bit",
+			file:       newWAVOf(wavFormatPCM, 1, 8, []byte{128, 192, 0, 64, 255, 128}),
+			resolution: 3,
+			want:       []Peak{{Min: 0, Max: 0.5}, {Min: -1, Max: -0.5}, {Min: 0, Max: 127 / 128.0}},
+		},
+		{
+			name:       "pcm 24 bit",
+			file:       newWAVOf(wavFormatPCM, 1, 24, []byte{0, 0, 0x40, 0, 0, 0xC0}),
+			resolution: 1,
+			want:       []Peak{{Min: -0.5, Max: 0.5}},
+		},
+		{
+			name:       "float clamped",
+			file:       newWAVOf(wavFormatFloat, 1, 32, float32s(0.25, -1.5, 2, -0.25)),
+			resolution: 2,
+			want:       []Peak{{Min: -1, Max: 0.25}, {Min: -0.25, Max: 1}},
+		},
+		{
+			name:       "a-law",
+			file:       newWAVOf(wavFormatALaw, 1, 8, []byte{0xD5, 0x55}),
+			resolution: 1,
+			want:       []Peak{{Min: -8 / 32768.0, Max: 8 / 32768.0}},
+		},
+		{
+			name:       "mu-law",
+			file:       newWAVOf(wavFormatMuLaw, 1, 8, []byte{0xFF, 0x80, 0x00}),
+			resolution: 1,
+			want:       []Peak{{Min: -32124 / 32768.0, Max: 32124 / 32768.0}},
+		},
+		{
+			name:       "mp3 silence",
+			file:       newMP3(10),
+			resolution: 2,
+			want:       []Peak{{Min: 0, Max: 0}, {Min: 0, Max: 0}},
+		},
+		{
+			name:       "fewer frames than the resolution",
+			file:       newWAVOf(wavFormatPCM, 1, 16, join(le16(0x4000), le16(-0x4000))),
+			resolution: 100,
+			want:       []Peak{{Min: 0.5, Max: 0.5}, {Min: -0.5, Max: -0.5}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			peaks, err := Peaks(bytes.NewReader(c.file), int64(len(c.file)), c.resolution)
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, peaks)
+		})
+	}
+}
+
+func TestPeaks_MP3(t *testing.T) {
+	file := newToneMP3(10, 20, 202)
+
+	peaks, err := Peaks(bytes.NewReader(file), int64(len(file)), 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, peaks, 2)
+	for _, peak := range peaks {
+		// the tone of a line scaled by a quarter
+		assert.InDelta(t, -0.25, peak.Min, 0.025)
+		assert.InDelta(t, 0.25, peak.Max, 0.025)
+	}
+}
+
+func TestPeaks_Invalid(t *testing.T) {
+	wav := newWAVOf(wavFormatPCM, 1, 16, make([]byte, 100))
+
+	cases := []struct {
+		name  string
+		file  []byte
+		cause error
+	}{
+		{"aac", newADTS(10), ErrUnsupported},
+		{"mp3 truncated", newMP3(10)[:417*5], ErrCorrupt},
+		{"24 bit float", newWAVOf(wavFormatFloat, 1, 24, make([]byte, 30)), ErrUnsupported},
+		{"wav truncated", wav[:len(wav)-10], ErrCorrupt},
+		{"wav without samples", newWAVOf(wavFormatPCM, 1, 16, nil), ErrCorrupt},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			peaks, err := Peaks(bytes.NewReader(c.file), int64(len(c.file)), 10)
+
+			assert.Nil(t, peaks)
+			assert.Equal(t, c.cause, errors.Cause(err))
+		})
+	}
+}
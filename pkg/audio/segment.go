@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"io"
+	"time"
+)
+
+// Segment is a run of whole frames of an MPEG audio or ADTS file, which players can decode on its own
+type Segment struct {
+	Offset int64
+	Size   int64
+	// Start is the time of the first sample of the segment in the whole audio
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// Segments splits the MP3 or ADTS AAC file of the given size into segments of whole frames lasting at least the target
+// duration each, but for the last one, and returns them with the content type of their frames. Other files fail with
+// ErrUnsupported
+func Segments(r io.ReaderAt, size int64, target time.Duration) (contentType string, segments []Segment, err error) {
+	head, err := readAt(r, 0, minInt64(size, SniffLength))
+	if err != nil {
+		return "", nil, err
+	}
+	contentType = DetectContentType(head)
+	if contentType != ContentTypeMP3 && contentType != ContentTypeAAC {
+		return "", nil, unsupported("segmenting " + contentType)
+	}
+	start, end, err := audioRange(r, size)
+	if err != nil {
+		return "", nil, err
+	}
+	first, h, err := firstFrame(r, start, end)
+	if err != nil {
+		return "", nil, err
+	}
+	switch h.codec {
+	case CodecMP3:
+		contentType = ContentTypeMP3
+	case CodecAAC:
+		contentType = ContentTypeAAC
+	default:
+		return "", nil, unsupported("segmenting " + h.codec)
+	}
+
+	var (
+		segment  = Segment{Offset: first}
+		samples  int64
+		previous int64
+		last     = first
+	)
+	err = walkFrames(r, first, end, h, func(off int64, frame frameHeader) {
+		if samples-previous > 0 && samplesDuration(samples-previous, h.sampleRate) >= target {
+			segment.Size = off - segment.Offset
+			segment.Duration = samplesDuration(samples, h.sampleRate) - segment.Start
+			segments = append(segments, segment)
+			segment = Segment{Offset: off, Start: samplesDuration(samples, h.sampleRate)}
+			previous = samples
+		}
+		samples += int64(frame.samples)
+		last = off + int64(frame.size)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if samples > previous {
+		segment.Size = last - segment.Offset
+		segment.Duration = samplesDuration(samples, h.sampleRate) - segment.Start
+		segments = append(segments, segment)
+	}
+	return contentType, segments, nil
+}
@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegments(t *testing.T) {
+	t.Run("mp3", func(t *testing.T) {
+		file := newMP3(10)
+		contentType, segments, err := Segments(bytes.NewReader(file), int64(len(file)), 100*time.Millisecond)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ContentTypeMP3, contentType)
+		assert.Equal(t, []Segment{
+			{Offset: 20, Size: 4 * 417, Start: 0, Duration: samplesDuration(4*1152, 44100)},
+			{Offset: 20 + 4*417, Size: 4 * 417, Start: samplesDuration(4*1152, 44100), Duration: samplesDuration(8*1152, 44100) - samplesDuration(4*1152, 44100)},
+			{Offset: 20 + 8*417, Size: 2 * 417, Start: samplesDuration(8*1152, 44100), Duration: samplesDuration(10*1152, 44100) - samplesDuration(8*1152, 44100)},
+		}, segments)
+	})
+
+	t.Run("adts", func(t *testing.T) {
+		file := newADTS(5)
+		contentType, segments, err := Segments(bytes.NewReader(file), int64(len(file)), 50*time.Millisecond)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ContentTypeAAC, contentType)
+		assert.Equal(t, []Segment{
+			{Offset: 0, Size: 600, Start: 0, Duration: samplesDuration(3*1024, 44100)},
+			{Offset: 600, Size: 400, Start: samplesDuration(3*1024, 44100), Duration: samplesDuration(5*1024, 44100) - samplesDuration(3*1024, 44100)},
+		}, segments)
+	})
+
+	t.Run("shorter than the target", func(t *testing.T) {
+		file := newMP3(2)
+		_, segments, err := Segments(bytes.NewReader(file), int64(len(file)), time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Segment{{Offset: 20, Size: 2 * 417, Duration: samplesDuration(2*1152, 44100)}}, segments)
+	})
+}
+
+func TestSegments_Invalid(t *testing.T) {
+	mp3 := newMP3(3)
+
+	cases := []struct {
+		name  string
+		file  []byte
+		cause error
+	}{
+		{"wav", newWAV(8000, 1, 16, 100), ErrUnsupported},
+		{"flac", newFLAC(44100, 2, 44100), ErrUnsupported},
+		{"mp3 truncated", mp3[:len(mp3)-10], ErrCorrupt},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, segments, err := Segments(bytes.NewReader(c.file), int64(len(c.file)), time.Second)
+
+			assert.Nil(t, segments)
+			assert.Equal(t, c.cause, errors.Cause(err))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package audio
+
+import "bytes"
+
+// content types of the audio containers the service recognises
+const (
+	ContentTypeWAV  = "audio/wav"
+	ContentTypeMP3  = "audio/mpeg"
+	ContentTypeAAC  = "audio/aac"
+	ContentTypeOgg  = "audio/ogg"
+	ContentTypeMP4  = "audio/mp4"
+	ContentTypeFLAC = "audio/flac"
+)
+
+// SniffLength is the number of leading bytes DetectContentType needs at most
+const SniffLength = 12
+
+// extensions are the file extensions of the content types
+var extensions = map[string]string{
+	ContentTypeWAV:  ".wav",
+	ContentTypeMP3:  ".mp3",
+	ContentTypeAAC:  ".aac",
+	ContentTypeOgg:  ".ogg",
+	ContentTypeMP4:  ".m4a",
+	ContentTypeFLAC: ".flac",
+}
+
+// DetectContentType returns the content type of the audio container starting with head, from its magic bytes,
+// or "" when it is not recognised; unlike http.DetectContentType, it also recognises headerless MP3 and AAC streams
+func DetectContentType(head []byte) string {
+	switch {
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return ContentTypeWAV
+	case len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp")):
+		return ContentTypeMP4
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return ContentTypeOgg
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return ContentTypeFLAC
+	case bytes.HasPrefix(head, []byte("ID3")):
+		return ContentTypeMP3
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xF6 == 0xF0:
+		// ADTS frame sync with layer 0
+		return ContentTypeAAC
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0 && head[1]&0x06 != 0:
+		// MPEG audio frame sync with a valid layer
+		return ContentTypeMP3
+	}
+	return ""
+}
+
+// Extension returns the file extension of the content type, e.g. ".mp3", or "" when it is not recognised
+func Extension(contentType string) string {
+	return extensions[contentType]
+}
@@ -0,0 +1,37 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectContentType(t *testing.T) {
+	cases := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"wav", []byte("RIFF\x24\x08\x00\x00WAVEfmt "), ContentTypeWAV},
+		{"mp3 with id3", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), ContentTypeMP3},
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x64}, ContentTypeMP3},
+		{"aac adts", []byte{0xFF, 0xF1, 0x50, 0x80}, ContentTypeAAC},
+		{"ogg", []byte("OggS\x00\x02"), ContentTypeOgg},
+		{"m4a", []byte("\x00\x00\x00\x20ftypM4A "), ContentTypeMP4},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), ContentTypeFLAC},
+		{"riff but not wave", []byte("RIFF\x24\x08\x00\x00AVI LIST"), ""},
+		{"text", []byte("hello world!"), ""},
+		{"empty", nil, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, DetectContentType(c.head))
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+	assert.Equal(t, ".mp3", Extension(ContentTypeMP3))
+	assert.Equal(t, ".m4a", Extension(ContentTypeMP4))
+	assert.Equal(t, "", Extension("text/plain"))
+}
@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+// WAVE format codes of the fmt chunk
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatFloat      = 0x0003
+	wavFormatALaw       = 0x0006
+	wavFormatMuLaw      = 0x0007
+	wavFormatExtensible = 0xFFFE
+)
+
+var wavCodecs = map[uint16]string{
+	wavFormatPCM:   CodecPCM,
+	wavFormatFloat: CodecPCMFloat,
+	wavFormatALaw:  CodecALaw,
+	wavFormatMuLaw: CodecMuLaw,
+}
+
+// wavFile is the stream a RIFF WAVE file describes in its fmt chunk, and where its data chunk is
+type wavFile struct {
+	code          uint16
+	channels      int
+	sampleRate    int
+	byteRate      int64
+	blockAlign    int
+	bitsPerSample int
+	dataOffset    int64
+	dataSize      int64
+}
+
+// readWAV reads the fmt chunk of a RIFF WAVE file and locates its data chunk
+func readWAV(r io.ReaderAt, size int64) (*wavFile, error) {
+	var (
+		format     []byte
+		dataOffset int64
+		dataSize   int64 = -1
+	)
+	for off := int64(12); off+8 <= size && (format == nil || dataSize < 0); {
+		header, err := readAt(r, off, 8)
+		if err != nil {
+			return nil, err
+		}
+		n := int64(binary.LittleEndian.Uint32(header[4:]))
+		body := off + 8
+		switch string(header[:4]) {
+		case "fmt ":
+			if n < 16 {
+				return nil, corrupt("fmt chunk too short")
+			}
+			format, err = readAt(r, body, minInt64(n, 40))
+			if err != nil {
+				return nil, err
+			}
+		case "data":
+			dataOffset, dataSize = body, n
+			if n == 0xFFFFFFFF {
+				// streamed files do not know the size of their data in advance
+				dataSize = size - body
+			}
+			if body+dataSize > size {
+				return nil, corrupt("data chunk truncated")
+			}
+		}
+		// chunks are word aligned
+		off = body + n + n&1
+	}
+	if format == nil || dataSize < 0 {
+		return nil, corrupt("missing fmt or data chunk")
+	}
+
+	code := binary.LittleEndian.Uint16(format)
+	if code == wavFormatExtensible && len(format) >= 26 {
+		// the format code is the first field of the sub format GUID
+		code = binary.LittleEndian.Uint16(format[24:])
+	}
+	if _, ok := wavCodecs[code]; !ok {
+		return nil, unsupported("WAVE format 0x" + strconv.FormatUint(uint64(code), 16))
+	}
+	wav := &wavFile{
+		code:          code,
+		channels:      int(binary.LittleEndian.Uint16(format[2:])),
+		sampleRate:    int(binary.LittleEndian.Uint32(format[4:])),
+		byteRate:      int64(binary.LittleEndian.Uint32(format[8:])),
+		blockAlign:    int(binary.LittleEndian.Uint16(format[12:])),
+		bitsPerSample: int(binary.LittleEndian.Uint16(format[14:])),
+		dataOffset:    dataOffset,
+		dataSize:      dataSize,
+	}
+	if wav.channels == 0 || wav.sampleRate == 0 || wav.byteRate == 0 {
+		return nil, corrupt("invalid fmt chunk")
+	}
+	return wav, nil
+}
+
+// probeWAV describes a RIFF WAVE file from its fmt chunk and the size of its data chunk
+func probeWAV(r io.ReaderAt, size int64) (*Metadata, error) {
+	wav, err := readWAV(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return &Metadata{
+		Codec:      wavCodecs[wav.code],
+		Duration:   samplesDuration(wav.dataSize, int(wav.byteRate)),
+		SampleRate: wav.sampleRate,
+		Channels:   wav.channels,
+		Bitrate:    int(wav.byteRate * 8),
+	}, nil
+}
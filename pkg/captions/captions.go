@@ -0,0 +1,249 @@
+package captions
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Format is a text format of captions
+type Format string
+
+const (
+	FormatVTT Format = "vtt"
+	FormatSRT Format = "srt"
+)
+
+const (
+	// MaxTextLength is the maximum number of characters of the text of a cue
+	MaxTextLength = 500
+	// MaxTime is the latest time a cue can end at
+	MaxTime = 100 * time.Hour
+)
+
+var (
+	// ErrInvalidFile is returned when parsing a file that is neither WebVTT nor SubRip
+	ErrInvalidFile = errors.New("Captions are not valid WebVTT or SubRip")
+
+	// ErrNoCues is returned by Validate for captions without cues
+	ErrNoCues = errors.New("Captions have no cues")
+	// ErrInvalidTiming is returned by Validate for cues that end before they start, start before the audio or end after
+	// MaxTime
+	ErrInvalidTiming = errors.New("Cue must end after it starts, between 0 and 100 hours")
+	// ErrInvalidText is returned by Validate for cues with blank text, blank lines or "-->" in their text, or text
+	// longer than MaxTextLength
+	ErrInvalidText = errors.New("Cue text must be given, up to 500 characters, without blank lines or \"-->\"")
+	// ErrOutOfOrder is returned by Validate for cues that start before the cue before them
+	ErrOutOfOrder = errors.New("Cue starts before the previous cue")
+	// ErrOverlap is returned by Validate for cues that start before the cue before them ends
+	ErrOverlap = errors.New("Cue overlaps the previous cue")
+	// ErrPastEnd is returned by Validate for cues that end after the audio
+	ErrPastEnd = errors.New("Cue ends after the audio")
+)
+
+// Cue is a caption shown from its start to its end, as plain text
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// CueError is a problem of the cue at the index, counted from 0
+type CueError struct {
+	Index int
+	Err   error
+}
+
+func (e *CueError) Error() string {
+	return "cue " + strconv.Itoa(e.Index+1) + ": " + e.Err.Error()
+}
+
+func (e *CueError) Unwrap() error { return e.Err }
+
+// Validate returns every problem of the cues, which must be in order of their start and must not overlap, so that a
+// single caption is shown at a time; cues must end by the duration of the audio, unless it is 0 as it is not known
+func Validate(cues []Cue, duration time.Duration) []error {
+	if len(cues) == 0 {
+		return []error{ErrNoCues}
+	}
+	var problems []error
+	for i, cue := range cues {
+		text := strings.TrimSpace(cue.Text)
+		switch {
+		case cue.Start < 0 || cue.End <= cue.Start || cue.End > MaxTime:
+			problems = append(problems, &CueError{Index: i, Err: ErrInvalidTiming})
+		case duration > 0 && cue.End > duration:
+			problems = append(problems, &CueError{Index: i, Err: ErrPastEnd})
+		}
+		if text == "" || len([]rune(text)) > MaxTextLength || strings.Contains(text, "-->") || hasBlankLine(text) {
+			problems = append(problems, &CueError{Index: i, Err: ErrInvalidText})
+		}
+		if i == 0 {
+			continue
+		}
+		switch previous := cues[i-1]; {
+		case cue.Start < previous.Start:
+			problems = append(problems, &CueError{Index: i, Err: ErrOutOfOrder})
+		case cue.Start < previous.End:
+			problems = append(problems, &CueError{Index: i, Err: ErrOverlap})
+		}
+	}
+	return problems
+}
+
+func hasBlankLine(text string) bool {
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Render returns the cues in the format
+func Render(cues []Cue, format Format) string {
+	b := &strings.Builder{}
+	if format == FormatVTT {
+		b.WriteString("WEBVTT\n\n")
+	}
+	for i, cue := range cues {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		text := cue.Text
+		if format == FormatSRT {
+			b.WriteString(strconv.Itoa(i+1) + "\n")
+		} else {
+			// WebVTT cue text is markup, so plain text is escaped
+			text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+		}
+		b.WriteString(timestamp(cue.Start, format) + " --> " + timestamp(cue.End, format) + "\n")
+		b.WriteString(text + "\n")
+	}
+	return b.String()
+}
+
+// timestamp formats the time as hours, minutes, seconds and milliseconds, which SubRip separates with a comma
+func timestamp(t time.Duration, format Format) string {
+	ms := t.Milliseconds()
+	separator := "."
+	if format == FormatSRT {
+		separator = ","
+	}
+	return pad(ms/3600000, 2) + ":" + pad(ms/60000%60, 2) + ":" + pad(ms/1000%60, 2) + separator + pad(ms%1000, 3)
+}
+
+func pad(n int64, width int) string {
+	s := strconv.FormatInt(n, 10)
+	for len(s) < width {
+		s = "0" + s
+	}
+	return s
+}
+
+var (
+	// timingLine matches the timing line of a cue, with WebVTT cue settings after the end
+	timingLine = regexp.MustCompile(`^((?:\d+:)?\d{2}:\d{2}[.,]\d{3})\s+-->\s+((?:\d+:)?\d{2}:\d{2}[.,]\d{3})(?:\s.*)?$`)
+	// markup matches the tags of WebVTT cue text, and the formatting tags and position codes SubRip players support
+	markup = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+)
+
+// Parse reads WebVTT or SubRip captions, told apart by the WEBVTT header, into cues of plain text: markup such as
+// voice or italic tags is dropped, and WebVTT notes, styles and regions are skipped
+func Parse(r io.Reader) ([]Cue, error) {
+	scanner := bufio.NewScanner(r)
+	var blocks [][]string
+	var block []string
+	for n := 0; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(block) > 0 {
+				blocks = append(blocks, block)
+			}
+			block = nil
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return nil, ErrInvalidFile
+	}
+
+	vtt := blocks[0][0] == "WEBVTT" || strings.HasPrefix(blocks[0][0], "WEBVTT ") || strings.HasPrefix(blocks[0][0], "WEBVTT\t")
+	if vtt {
+		// the header block may carry metadata headers
+		blocks = blocks[1:]
+	}
+	cues := make([]Cue, 0, len(blocks))
+	for i, block := range blocks {
+		if vtt && (strings.HasPrefix(block[0], "NOTE") || block[0] == "STYLE" || block[0] == "REGION") {
+			continue
+		}
+		// cues may have an identifier, a sequence number in SubRip, before their timing line
+		if len(block) > 1 && !strings.Contains(block[0], "-->") {
+			block = block[1:]
+		}
+		match := timingLine.FindStringSubmatch(strings.TrimSpace(block[0]))
+		if match == nil || len(block) < 2 {
+			return nil, errors.Wrap(ErrInvalidFile, "block "+strconv.Itoa(i+1))
+		}
+		start, err := parseTimestamp(match[1])
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidFile, "block "+strconv.Itoa(i+1))
+		}
+		end, err := parseTimestamp(match[2])
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidFile, "block "+strconv.Itoa(i+1))
+		}
+		lines := make([]string, 0, len(block)-1)
+		for _, line := range block[1:] {
+			line = strings.TrimSpace(markup.ReplaceAllString(line, ""))
+			if vtt {
+				line = html.UnescapeString(line)
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		cues = append(cues, Cue{Start: start, End: end, Text: strings.Join(lines, "\n")})
+	}
+	return cues, nil
+}
+
+// parseTimestamp parses [hours:]minutes:seconds.milliseconds, with a comma in SubRip
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(strings.Replace(s, ",", ".", 1), ":")
+	seconds := strings.SplitN(parts[len(parts)-1], ".", 2)
+	sec, err := strconv.Atoi(seconds[0])
+	if err != nil || sec > 59 {
+		return 0, ErrInvalidFile
+	}
+	ms, err := strconv.Atoi(seconds[1])
+	if err != nil {
+		return 0, ErrInvalidFile
+	}
+	var minutes int
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		if err != nil || (i == len(parts)-2 && n > 59) {
+			return 0, ErrInvalidFile
+		}
+		minutes = minutes*60 + n
+	}
+	return time.Duration(minutes)*time.Minute + time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
@@ -0,0 +1,148 @@
+package captions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		cues     []Cue
+		duration time.Duration
+		want     []error
+	}{
+		{
+			name: "valid",
+			cues: []Cue{
+				{Start: 0, End: time.Second, Text: "Hello"},
+				{Start: time.Second, End: 2 * time.Second, Text: "world"},
+			},
+			duration: 2 * time.Second,
+		},
+		{
+			name: "unknown duration",
+			cues: []Cue{{Start: time.Minute, End: time.Hour, Text: "Hello"}},
+		},
+		{
+			name: "no cues",
+			want: []error{ErrNoCues},
+		},
+		{
+			name: "invalid timing and text",
+			cues: []Cue{
+				{Start: time.Second, End: time.Second, Text: "Hello"},
+				{Start: 2 * time.Second, End: 3 * time.Second, Text: " "},
+				{Start: 3 * time.Second, End: 4 * time.Second, Text: "a --> b"},
+				{Start: 4 * time.Second, End: 5 * time.Second, Text: "a\n\nb"},
+				{Start: 5 * time.Second, End: 6 * time.Second, Text: strings.Repeat("a", MaxTextLength+1)},
+				{Start: 6 * time.Second, End: MaxTime + time.Millisecond, Text: "Hello"},
+			},
+			want: []error{
+				&CueError{Index: 0, Err: ErrInvalidTiming},
+				&CueError{Index: 1, Err: ErrInvalidText},
+				&CueError{Index: 2, Err: ErrInvalidText},
+				&CueError{Index: 3, Err: ErrInvalidText},
+				&CueError{Index: 4, Err: ErrInvalidText},
+				&CueError{Index: 5, Err: ErrInvalidTiming},
+			},
+		},
+		{
+			name: "out of order, overlapping and past the end",
+			cues: []Cue{
+				{Start: 2 * time.Second, End: 3 * time.Second, Text: "b"},
+				{Start: time.Second, End: 2 * time.Second, Text: "a"},
+				{Start: 1500 * time.Millisecond, End: 4 * time.Second, Text: "c"},
+			},
+			duration: 3 * time.Second,
+			want: []error{
+				&CueError{Index: 1, Err: ErrOutOfOrder},
+				&CueError{Index: 2, Err: ErrPastEnd},
+				&CueError{Index: 2, Err: ErrOverlap},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, Validate(c.cues, c.duration))
+		})
+	}
+}
+
+func TestCueError(t *testing.T) {
+	err := &CueError{Index: 2, Err: ErrOverlap}
+
+	assert.Equal(t, "cue 3: Cue overlaps the previous cue", err.Error())
+	assert.True(t, errors.Is(err, ErrOverlap))
+}
+
+func TestRender(t *testing.T) {
+	cues := []Cue{
+		{Start: 500 * time.Millisecond, End: 2 * time.Second, Text: "Tom & Jerry <3"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 3*time.Minute, Text: "Two\nlines"},
+	}
+
+	t.Run("vtt", func(t *testing.T) {
+		assert.Equal(t, "WEBVTT\n\n"+
+			"00:00:00.500 --> 00:00:02.000\nTom &amp; Jerry &lt;3\n\n"+
+			"01:02:03.045 --> 01:03:00.000\nTwo\nlines\n", Render(cues, FormatVTT))
+	})
+
+	t.Run("srt", func(t *testing.T) {
+		assert.Equal(t, "1\n00:00:00,500 --> 00:00:02,000\nTom & Jerry <3\n\n"+
+			"2\n01:02:03,045 --> 01:03:00,000\nTwo\nlines\n", Render(cues, FormatSRT))
+	})
+}
+
+func TestParse(t *testing.T) {
+	want := []Cue{
+		{Start: 500 * time.Millisecond, End: 2 * time.Second, Text: "Tom & Jerry <3"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 3*time.Minute, Text: "Two\nlines"},
+	}
+
+	t.Run("happy path - vtt", func(t *testing.T) {
+		cues, err := Parse(strings.NewReader("\ufeffWEBVTT - captions\nKind: captions\n\n" +
+			"NOTE written by hand\n\n" +
+			"intro\n00:00.500 --> 00:02.000 align:start\n<v Tom>Tom &amp; Jerry &lt;3</v>\n\n" +
+			"01:02:03.045 --> 01:03:00.000\n<i>Two</i>\nlines\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, cues)
+	})
+
+	t.Run("happy path - srt", func(t *testing.T) {
+		cues, err := Parse(strings.NewReader("1\r\n00:00:00,500 --> 00:00:02,000\r\n{\\an8}Tom & Jerry <3\r\n\r\n" +
+			"2\r\n01:02:03,045 --> 01:03:00,000\r\n<i>Two</i>\r\nlines\r\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, cues)
+	})
+
+	t.Run("happy path - round trip", func(t *testing.T) {
+		for _, format := range []Format{FormatVTT, FormatSRT} {
+			cues, err := Parse(strings.NewReader(Render(want, format)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, cues)
+		}
+	})
+
+	t.Run("sad path - invalid", func(t *testing.T) {
+		for _, file := range []string{
+			"",
+			"hello world",
+			"WEBVTT\n\n00:00.500 --> 00:02.000\n",
+			"1\n00:00:00,500 -> 00:00:02,000\nHello\n",
+			"1\n00:00:61,500 --> 00:01:02,000\nHello\n",
+		} {
+			cues, err := Parse(strings.NewReader(file))
+
+			assert.True(t, errors.Is(err, ErrInvalidFile), file)
+			assert.Nil(t, cues)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package chapters
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxChapters is the maximum number of chapters of a short
+	MaxChapters = 100
+	// MaxTitleLength is the maximum number of characters of the title of a chapter
+	MaxTitleLength = 200
+	// MaxImageURLLength is the maximum length of the image URL of a chapter
+	MaxImageURLLength = 2048
+)
+
+var (
+	// ErrDurationUnknown is returned by Validate when the duration of the audio is not known
+	ErrDurationUnknown = errors.New("Chapters need the duration of the audio, which is not known")
+	// ErrNoChapters is returned by Validate without chapters
+	ErrNoChapters = errors.New("Chapters must be given")
+	// ErrTooMany is returned by Validate for more than MaxChapters chapters
+	ErrTooMany = errors.New("Short has more than 100 chapters")
+	// ErrInvalidStart is returned by Validate for chapters that start before the audio or at or after its end
+	ErrInvalidStart = errors.New("Chapter must start within the audio")
+	// ErrOutOfOrder is returned by Validate for chapters that do not start after the chapter before them
+	ErrOutOfOrder = errors.New("Chapter must start after the previous chapter")
+	// ErrInvalidTitle is returned by Validate for blank titles, or titles longer than MaxTitleLength
+	ErrInvalidTitle = errors.New("Chapter title must be given, up to 200 characters")
+	// ErrInvalidImageURL is returned by Validate for image URLs that are not absolute HTTP or HTTPS URLs
+	ErrInvalidImageURL = errors.New("Chapter image URL must be an absolute HTTP or HTTPS URL")
+)
+
+// Chapter is a section of the audio, from its start to the start of the next chapter or the end of the audio
+type Chapter struct {
+	Start time.Duration
+	End   time.Duration
+	Title string
+	// optional
+	ImageURL string
+}
+
+// ChapterError is a problem of the chapter at the index, counted from 0
+type ChapterError struct {
+	Index int
+	Err   error
+}
+
+func (e *ChapterError) Error() string {
+	return "chapter " + strconv.Itoa(e.Index+1) + ": " + e.Err.Error()
+}
+
+func (e *ChapterError) Unwrap() error { return e.Err }
+
+// Validate returns every problem of the chapters, which must start within the audio of the duration, each after the
+// one before it
+func Validate(chapters []Chapter, duration time.Duration) []error {
+	switch {
+	case duration <= 0:
+		return []error{ErrDurationUnknown}
+	case len(chapters) == 0:
+		return []error{ErrNoChapters}
+	case len(chapters) > MaxChapters:
+		return []error{ErrTooMany}
+	}
+	var problems []error
+	for i, chapter := range chapters {
+		switch {
+		case chapter.Start < 0 || chapter.Start >= duration:
+			problems = append(problems, &ChapterError{Index: i, Err: ErrInvalidStart})
+		case i > 0 && chapter.Start <= chapters[i-1].Start:
+			problems = append(problems, &ChapterError{Index: i, Err: ErrOutOfOrder})
+		}
+		title := strings.TrimSpace(chapter.Title)
+		if title == "" || len([]rune(title)) > MaxTitleLength {
+			problems = append(problems, &ChapterError{Index: i, Err: ErrInvalidTitle})
+		}
+		if chapter.ImageURL != "" && !isHTTPURL(chapter.ImageURL) {
+			problems = append(problems, &ChapterError{Index: i, Err: ErrInvalidImageURL})
+		}
+	}
+	return problems
+}
+
+func isHTTPURL(s string) bool {
+	if len(s) > MaxImageURLLength {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// WithEnds returns the chapters, each ending where the next one starts and the last one at the end of the audio
+func WithEnds(chapters []Chapter, duration time.Duration) []Chapter {
+	ended := make([]Chapter, len(chapters))
+	for i, chapter := range chapters {
+		chapter.End = duration
+		if i+1 < len(chapters) {
+			chapter.End = chapters[i+1].Start
+		}
+		ended[i] = chapter
+	}
+	return ended
+}
+
+// unusedOffset tells that the byte offsets of a CHAP frame are not used, times are used instead
+const unusedOffset = 0xFFFFFFFF
+
+type (
+	// id3Tag is the table of contents and the chapters of an ID3v2 tag, after the ID3v2 Chapter Frame Addendum
+	id3Tag struct {
+		TableOfContents id3TableOfContents `json:"CTOC"`
+		Chapters        []id3Chapter       `json:"CHAP"`
+	}
+
+	id3TableOfContents struct {
+		ElementID       string   `json:"element_id"`
+		TopLevel        bool     `json:"top_level"`
+		Ordered         bool     `json:"ordered"`
+		ChildElementIDs []string `json:"child_element_ids"`
+	}
+
+	id3Chapter struct {
+		ElementID   string           `json:"element_id"`
+		StartTime   int64            `json:"start_time"`
+		EndTime     int64            `json:"end_time"`
+		StartOffset uint32           `json:"start_offset"`
+		EndOffset   uint32           `json:"end_offset"`
+		SubFrames   id3ChapterFrames `json:"sub_frames"`
+	}
+
+	id3ChapterFrames struct {
+		Title string      `json:"TIT2"`
+		Image *id3Picture `json:"APIC,omitempty"`
+	}
+
+	// id3Picture is an attached picture given as a link, with the "-->" MIME type
+	id3Picture struct {
+		MIMEType    string `json:"mime_type"`
+		PictureType int    `json:"picture_type"`
+		Description string `json:"description"`
+		URL         string `json:"data"`
+	}
+)
+
+// ID3 returns the chapters as the JSON of the CTOC and CHAP frames of an ID3v2 tag, for tools that write them into
+// audio files: times are in milliseconds, byte offsets are unused, titles are TIT2 sub-frames and images linked APIC
+// sub-frames
+func ID3(chapters []Chapter) ([]byte, error) {
+	tag := id3Tag{
+		TableOfContents: id3TableOfContents{ElementID: "toc", TopLevel: true, Ordered: true, ChildElementIDs: []string{}},
+		Chapters:        make([]id3Chapter, len(chapters)),
+	}
+	for i, chapter := range chapters {
+		id := "chp" + strconv.Itoa(i+1)
+		tag.TableOfContents.ChildElementIDs = append(tag.TableOfContents.ChildElementIDs, id)
+		tag.Chapters[i] = id3Chapter{
+			ElementID:   id,
+			StartTime:   chapter.Start.Milliseconds(),
+			EndTime:     chapter.End.Milliseconds(),
+			StartOffset: unusedOffset,
+			EndOffset:   unusedOffset,
+			SubFrames:   id3ChapterFrames{Title: chapter.Title},
+		}
+		if chapter.ImageURL != "" {
+			tag.Chapters[i].SubFrames.Image = &id3Picture{MIMEType: "-->", URL: chapter.ImageURL}
+		}
+	}
+	return json.Marshal(tag)
+}
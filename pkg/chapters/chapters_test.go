@@ -0,0 +1,120 @@
+package chapters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		chapters []Chapter
+		duration time.Duration
+		want     []error
+	}{
+		{
+			name: "valid",
+			chapters: []Chapter{
+				{Start: 0, Title: "Intro"},
+				{Start: time.Minute, Title: "Story", ImageURL: "https://example.com/story.jpg"},
+			},
+			duration: 2 * time.Minute,
+		},
+		{
+			name:     "unknown duration",
+			chapters: []Chapter{{Start: 0, Title: "Intro"}},
+			want:     []error{ErrDurationUnknown},
+		},
+		{
+			name:     "no chapters",
+			duration: time.Minute,
+			want:     []error{ErrNoChapters},
+		},
+		{
+			name:     "too many chapters",
+			chapters: make([]Chapter, MaxChapters+1),
+			duration: time.Minute,
+			want:     []error{ErrTooMany},
+		},
+		{
+			name: "every problem",
+			chapters: []Chapter{
+				{Start: -time.Second, Title: "Intro"},
+				{Start: 30 * time.Second, Title: " "},
+				{Start: 30 * time.Second, Title: "Story", ImageURL: "ftp://example.com/story.jpg"},
+				{Start: time.Minute, Title: strings.Repeat("a", MaxTitleLength+1), ImageURL: "/story.jpg"},
+			},
+			duration: time.Minute,
+			want: []error{
+				&ChapterError{Index: 0, Err: ErrInvalidStart},
+				&ChapterError{Index: 1, Err: ErrInvalidTitle},
+				&ChapterError{Index: 2, Err: ErrOutOfOrder},
+				&ChapterError{Index: 2, Err: ErrInvalidImageURL},
+				&ChapterError{Index: 3, Err: ErrInvalidStart},
+				&ChapterError{Index: 3, Err: ErrInvalidTitle},
+				&ChapterError{Index: 3, Err: ErrInvalidImageURL},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, Validate(c.chapters, c.duration))
+		})
+	}
+}
+
+func TestChapterError(t *testing.T) {
+	err := &ChapterError{Index: 1, Err: ErrOutOfOrder}
+
+	assert.Equal(t, "chapter 2: Chapter must start after the previous chapter", err.Error())
+	assert.True(t, errors.Is(err, ErrOutOfOrder))
+}
+
+func TestWithEnds(t *testing.T) {
+	chapters := WithEnds([]Chapter{{Start: 0, Title: "Intro"}, {Start: time.Minute, Title: "Story"}}, 90*time.Second)
+
+	assert.Equal(t, []Chapter{
+		{Start: 0, End: time.Minute, Title: "Intro"},
+		{Start: time.Minute, End: 90 * time.Second, Title: "Story"},
+	}, chapters)
+}
+
+func TestID3(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		tag, err := ID3([]Chapter{
+			{Start: 0, End: time.Minute, Title: "Intro"},
+			{Start: time.Minute, End: 90500 * time.Millisecond, Title: "Story", ImageURL: "https://example.com/story.jpg"},
+		})
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"CTOC": {"element_id": "toc", "top_level": true, "ordered": true, "child_element_ids": ["chp1", "chp2"]},
+			"CHAP": [
+				{
+					"element_id": "chp1", "start_time": 0, "end_time": 60000,
+					"start_offset": 4294967295, "end_offset": 4294967295,
+					"sub_frames": {"TIT2": "Intro"}
+				},
+				{
+					"element_id": "chp2", "start_time": 60000, "end_time": 90500,
+					"start_offset": 4294967295, "end_offset": 4294967295,
+					"sub_frames": {
+						"TIT2": "Story",
+						"APIC": {"mime_type": "-->", "picture_type": 0, "description": "", "data": "https://example.com/story.jpg"}
+					}
+				}
+			]
+		}`, string(tag))
+	})
+
+	t.Run("happy path - no chapters", func(t *testing.T) {
+		tag, err := ID3(nil)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"CTOC": {"element_id": "toc", "top_level": true, "ordered": true, "child_element_ids": []}, "CHAP": []}`, string(tag))
+	})
+}
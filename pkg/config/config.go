@@ -1,6 +1,10 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 // Config stores env variables
 type Config struct {
@@ -15,6 +19,83 @@ type Config struct {
 		Port     string `envconfig:"POSTGRES_PORT" default:"5432"`
 		Host     string `envconfig:"POSTGRES_HOST" default:"localhost"`
 	}
+	// Upload limits the audio files uploaded with uploadAudioShort; the max size is in bytes
+	Upload struct {
+		MaxSize      int64    `envconfig:"UPLOAD_MAX_SIZE" default:"20971520"`
+		AllowedTypes []string `envconfig:"UPLOAD_ALLOWED_TYPES" default:"audio/mpeg,audio/wav,audio/ogg,audio/mp4"`
+	}
+	// Audio limits the audio files of shorts, which are probed on create, update and upload when validation is enabled
+	Audio struct {
+		Validate      bool          `envconfig:"AUDIO_VALIDATE" default:"true"`
+		MinDuration   time.Duration `envconfig:"AUDIO_MIN_DURATION" default:"1s"`
+		MaxDuration   time.Duration `envconfig:"AUDIO_MAX_DURATION" default:"5m"`
+		AllowedCodecs []string      `envconfig:"AUDIO_ALLOWED_CODECS" default:"pcm,mp3,aac,vorbis,opus,flac"`
+	}
+	// Duplicates detects shorts created with the audio of shorts of other creators, the same file or audio at least as
+	// similar as the similarity, from 0 to 1; duplicates are rejected, or created and flagged for moderators
+	Duplicates struct {
+		Enabled    bool    `envconfig:"DUPLICATES_ENABLED" default:"true"`
+		Reject     bool    `envconfig:"DUPLICATES_REJECT" default:"true"`
+		Similarity float64 `envconfig:"DUPLICATES_SIMILARITY" default:"0.9"`
+	}
+	// Storage is where uploaded audio files are stored, either a directory on the local disk, served by the API at the
+	// base URL, or an S3-compatible bucket, served at its own base URL, which defaults to the bucket URL
+	Storage struct {
+		Backend string `envconfig:"STORAGE_BACKEND" default:"local"`
+		Dir     string `envconfig:"STORAGE_DIR" default:"data"`
+		BaseURL string `envconfig:"STORAGE_BASE_URL" default:"http://localhost:8080/files"`
+		S3      struct {
+			Endpoint  string `envconfig:"STORAGE_S3_ENDPOINT"`
+			Region    string `envconfig:"STORAGE_S3_REGION" default:"us-east-1"`
+			Bucket    string `envconfig:"STORAGE_S3_BUCKET"`
+			AccessKey string `envconfig:"STORAGE_S3_ACCESS_KEY"`
+			SecretKey string `envconfig:"STORAGE_S3_SECRET_KEY"`
+			BaseURL   string `envconfig:"STORAGE_S3_BASE_URL"`
+		}
+	}
+	// Playback signs the URLs audio is streamed from with the first of the keys, given as id:secret; the other keys are
+	// still accepted, so that keys can be rotated. Without keys, audio is streamed from unsigned URLs under the base URL
+	Playback struct {
+		BaseURL      string        `envconfig:"PLAYBACK_BASE_URL" default:"http://localhost:8080/audio"`
+		Keys         []string      `envconfig:"PLAYBACK_KEYS"`
+		Expiry       time.Duration `envconfig:"PLAYBACK_URL_EXPIRY" default:"1h"`
+		BindListener bool          `envconfig:"PLAYBACK_BIND_LISTENER" default:"false"`
+	}
+	// Waveform generates the waveforms of uploaded shorts in the background, of resolution peaks each; waveforms left
+	// processing for longer than the timeout are generated again
+	Waveform struct {
+		Enabled    bool          `envconfig:"WAVEFORM_ENABLED" default:"true"`
+		Resolution int           `envconfig:"WAVEFORM_RESOLUTION" default:"1000"`
+		Interval   time.Duration `envconfig:"WAVEFORM_INTERVAL" default:"5s"`
+		BatchSize  uint16        `envconfig:"WAVEFORM_BATCH_SIZE" default:"10"`
+		Timeout    time.Duration `envconfig:"WAVEFORM_TIMEOUT" default:"10m"`
+	}
+	// HLS packages uploaded shorts as HLS in the background, in segments of about the segment duration; packages left
+	// processing for longer than the timeout are packaged again
+	HLS struct {
+		Enabled         bool          `envconfig:"HLS_ENABLED" default:"true"`
+		SegmentDuration time.Duration `envconfig:"HLS_SEGMENT_DURATION" default:"6s"`
+		Interval        time.Duration `envconfig:"HLS_INTERVAL" default:"5s"`
+		BatchSize       uint16        `envconfig:"HLS_BATCH_SIZE" default:"5"`
+		Timeout         time.Duration `envconfig:"HLS_TIMEOUT" default:"10m"`
+	}
+	// Loudness measures the loudness of uploaded shorts in the background; shorts with at least the silence ratio of
+	// silence are flagged as mostly silent, and measures left processing for longer than the timeout are taken again
+	Loudness struct {
+		Enabled      bool          `envconfig:"LOUDNESS_ENABLED" default:"true"`
+		SilenceRatio float64       `envconfig:"LOUDNESS_SILENCE_RATIO" default:"0.8"`
+		Interval     time.Duration `envconfig:"LOUDNESS_INTERVAL" default:"5s"`
+		BatchSize    uint16        `envconfig:"LOUDNESS_BATCH_SIZE" default:"10"`
+		Timeout      time.Duration `envconfig:"LOUDNESS_TIMEOUT" default:"10m"`
+	}
+	// Purger hard deletes shorts that have been deleted for longer than the retention period
+	Purger struct {
+		Enabled   bool          `envconfig:"PURGER_ENABLED" default:"true"`
+		Retention time.Duration `envconfig:"PURGER_RETENTION" default:"720h"`
+		Interval  time.Duration `envconfig:"PURGER_INTERVAL" default:"1h"`
+		BatchSize uint16        `envconfig:"PURGER_BATCH_SIZE" default:"100"`
+		DryRun    bool          `envconfig:"PURGER_DRY_RUN" default:"false"`
+	}
 }
 
 func New() (*Config, error) {
@@ -0,0 +1,229 @@
+package hls
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/audio"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidConfig  = "HLS segment duration, interval, batch size and timeout must be positive"
+	ErrorMessageClaimFailed    = "Failed to claim pending HLS packages"
+	ErrorMessagePackageFailed  = "Failed to package audio as HLS"
+	ErrorMessageSaveFailed     = "Failed to save HLS package"
+	ErrorMessageNotInBlobStore = "Audio file is not kept in the blob store"
+	ErrorMessageNoBlobStore    = "No blob store to read audio files from"
+)
+
+const (
+	// PlaylistName is the name of the playlist of every package, next to its segments
+	PlaylistName = "index.m3u8"
+	// ContentTypePlaylist is the content type of playlists
+	ContentTypePlaylist = "application/vnd.apple.mpegurl"
+)
+
+// names are the names of the files of packages, the playlist and its segments
+var names = regexp.MustCompile(`^(index\.m3u8|segment[0-9]+\.(mp3|aac))$`)
+
+// Key returns the blob key of the file of the HLS package of the short with the name, and false for names that are
+// not part of packages
+func Key(shortID, name string) (string, bool) {
+	if !names.MatchString(name) {
+		return "", false
+	}
+	return KeyPrefix(shortID) + name, true
+}
+
+// KeyPrefix returns the prefix of the blob keys of the files of the HLS package of the short
+func KeyPrefix(shortID string) string {
+	return "hls/" + shortID + "/"
+}
+
+// Packager periodically claims the pending HLS packages of shorts and segments their audio files into packed audio
+// segments and a playlist in the blob store. Only MP3 and ADTS AAC files are segmented, at frame boundaries, which
+// needs no decoding
+type Packager struct {
+	hlsStore        store.HLSStore
+	blobStore       storage.BlobStore
+	segmentDuration time.Duration
+	interval        time.Duration
+	batchSize       uint16
+	timeout         time.Duration
+	now             func() time.Time
+}
+
+// New returns a packager of the HLS packages of the store, reading audio files from and writing packages to the blob
+// store
+func New(hlsStore store.HLSStore, blobStore storage.BlobStore, cfg *config.Config) (*Packager, error) {
+	if cfg.HLS.SegmentDuration <= 0 || cfg.HLS.Interval <= 0 || cfg.HLS.BatchSize == 0 || cfg.HLS.Timeout <= 0 {
+		return nil, errors.New(ErrorMessageInvalidConfig)
+	}
+	return &Packager{
+		hlsStore:        hlsStore,
+		blobStore:       blobStore,
+		segmentDuration: cfg.HLS.SegmentDuration,
+		interval:        cfg.HLS.Interval,
+		batchSize:       cfg.HLS.BatchSize,
+		timeout:         cfg.HLS.Timeout,
+		now:             time.Now,
+	}, nil
+}
+
+// Run packages the pending shorts every interval until the context is done
+func (p *Packager) Run(ctx context.Context) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("HLS packager started, segment duration " + p.segmentDuration.String() + ", interval " + p.interval.String())
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logging.WithContext(ctx).Info("HLS packager stopped")
+			return
+		case <-ticker.C:
+			packaged, err := p.Package(ctx)
+			if err != nil {
+				logging.WithContext(ctx).Error(err.Error())
+			}
+			if packaged > 0 {
+				logging.WithContext(ctx).Info("Packaged " + strconv.Itoa(packaged) + " shorts as HLS")
+			}
+		}
+	}
+}
+
+// Package claims the pending packages batch by batch, along with those left processing for longer than the timeout,
+// and packages them; it returns the number of packages that were claimed
+func (p *Packager) Package(ctx context.Context) (packaged int, err error) {
+	for {
+		jobs, err := p.hlsStore.Claim(ctx, p.now().Add(-p.timeout), p.batchSize)
+		if err != nil {
+			return packaged, errors.Wrap(err, ErrorMessageClaimFailed)
+		}
+		for _, job := range jobs {
+			p.packageJob(ctx, job)
+		}
+		packaged += len(jobs)
+		if len(jobs) < int(p.batchSize) {
+			return packaged, nil
+		}
+	}
+}
+
+// packageJob segments the audio file of the job and saves the playlist, or marks the package as failed, or as
+// unsupported when the audio cannot be segmented
+func (p *Packager) packageJob(ctx context.Context, job *store.HLSJob) {
+	// claims older than the timeout are taken over, so the packaging must not outlive it
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	playlistURL, segments, err := p.write(ctx, job)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessagePackageFailed+" short ID:"+job.ShortID).Error())
+		status := store.HLSStatusFailed
+		if errors.Cause(err) == audio.ErrUnsupported {
+			status = store.HLSStatusUnsupported
+		}
+		err = p.hlsStore.Fail(ctx, job.ShortID, status, err.Error())
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageSaveFailed+" short ID:"+job.ShortID).Error())
+		}
+		return
+	}
+	err = p.hlsStore.Complete(ctx, job.ShortID, playlistURL, segments)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageSaveFailed+" short ID:"+job.ShortID).Error())
+	}
+}
+
+// write segments the audio file of the job, which must be kept in the blob store, and puts the segments then the
+// playlist next to each other, so that a playlist is only found once all of its segments are
+func (p *Packager) write(ctx context.Context, job *store.HLSJob) (playlistURL string, segments int, err error) {
+	if p.blobStore == nil {
+		return "", 0, errors.New(ErrorMessageNoBlobStore)
+	}
+	key, ok := p.blobStore.Key(job.AudioFile)
+	if !ok {
+		return "", 0, errors.New(ErrorMessageNotInBlobStore)
+	}
+	reader, err := storage.NewReaderAt(ctx, p.blobStore, key)
+	if err != nil {
+		return "", 0, err
+	}
+	contentType, parts, err := audio.Segments(reader, reader.Size(), p.segmentDuration)
+	if err != nil {
+		return "", 0, err
+	}
+
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		names[i] = "segment" + strconv.Itoa(i) + audio.Extension(contentType)
+		segmentKey, _ := Key(job.ShortID, names[i])
+		body := io.MultiReader(bytes.NewReader(timestampTag(part.Start)), io.NewSectionReader(reader, part.Offset, part.Size))
+		_, err = p.blobStore.Put(ctx, segmentKey, body, contentType)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	playlistKey, _ := Key(job.ShortID, PlaylistName)
+	playlistURL, err = p.blobStore.Put(ctx, playlistKey, strings.NewReader(playlist(parts, names)), ContentTypePlaylist)
+	if err != nil {
+		return "", 0, err
+	}
+	return playlistURL, len(parts), nil
+}
+
+// playlist returns the VOD media playlist of the segments, with the given names
+func playlist(segments []audio.Segment, names []string) string {
+	target := 1
+	for _, segment := range segments {
+		if d := int(math.Ceil(segment.Duration.Seconds())); d > target {
+			target = d
+		}
+	}
+	b := &strings.Builder{}
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	b.WriteString("#EXT-X-TARGETDURATION:" + strconv.Itoa(target) + "\n")
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	for i, segment := range segments {
+		b.WriteString("#EXTINF:" + strconv.FormatFloat(segment.Duration.Seconds(), 'f', 3, 64) + ",\n")
+		b.WriteString(names[i] + "\n")
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.String()
+}
+
+// timestampOwner is the owner of the ID3 PRIV frame giving the timestamp of packed audio segments
+const timestampOwner = "com.apple.streaming.transportStreamTimestamp"
+
+// timestampTag returns the ID3v2.4 tag that starts every packed audio segment, holding the timestamp of its first
+// sample as a 33 bit count of a 90 kHz clock
+func timestampTag(start time.Duration) []byte {
+	ticks := uint64(start * 90000 / time.Second)
+	priv := append([]byte(timestampOwner+"\x00"),
+		byte(ticks>>56), byte(ticks>>48), byte(ticks>>40), byte(ticks>>32),
+		byte(ticks>>24), byte(ticks>>16), byte(ticks>>8), byte(ticks))
+	frame := append(append([]byte("PRIV"), synchsafe(len(priv))...), 0, 0)
+	frame = append(frame, priv...)
+	tag := append([]byte{'I', 'D', '3', 4, 0, 0}, synchsafe(len(frame))...)
+	return append(tag, frame...)
+}
+
+// synchsafe encodes the size as the 4 byte synchsafe integer of ID3v2 headers
+func synchsafe(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
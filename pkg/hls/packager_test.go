@@ -0,0 +1,156 @@
+package hls
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.HLS.SegmentDuration = 100 * time.Millisecond
+	cfg.HLS.Interval = time.Second
+	cfg.HLS.BatchSize = 2
+	cfg.HLS.Timeout = time.Minute
+	return cfg
+}
+
+// mp3Frame is a 417 byte MPEG-1 layer III frame at 128 kbit/s, 44.1 kHz, stereo, lasting 1152 samples
+func mp3Frame() []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return frame
+}
+
+// newMP3 returns an MP3 file of CBR frames
+func newMP3(frames int) []byte {
+	var b []byte
+	for i := 0; i < frames; i++ {
+		b = append(b, mp3Frame()...)
+	}
+	return b
+}
+
+// read returns the content of the blob of the key
+func read(t *testing.T, blobStore storage.BlobStore, key string) []byte {
+	r, _, err := blobStore.Get(context.Background(), key, 0, -1)
+	assert.NoError(t, err)
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestNew(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		p, err := New(nil, nil, newConfig())
+
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+	})
+
+	t.Run("sad path - no segment duration", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.HLS.SegmentDuration = 0
+		p, err := New(nil, nil, cfg)
+
+		assert.Error(t, err)
+		assert.Nil(t, p)
+	})
+}
+
+func TestKey(t *testing.T) {
+	key, ok := Key("1", "index.m3u8")
+	assert.True(t, ok)
+	assert.Equal(t, "hls/1/index.m3u8", key)
+
+	key, ok = Key("1", "segment12.aac")
+	assert.True(t, ok)
+	assert.Equal(t, "hls/1/segment12.aac", key)
+
+	for _, name := range []string{"", "../shorts/abc.mp3", "segment.mp3", "segment1.wav", "index.m3u8/x"} {
+		_, ok = Key("1", name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestTimestampTag(t *testing.T) {
+	tag := timestampTag(2 * time.Second)
+
+	assert.Equal(t, []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 63}, tag[:10])
+	assert.Equal(t, []byte{'P', 'R', 'I', 'V', 0, 0, 0, 53, 0, 0}, tag[10:20])
+	assert.Equal(t, timestampOwner+"\x00", string(tag[20:65]))
+	// 2 seconds of the 90 kHz clock
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0x02, 0xBF, 0x20}, tag[65:])
+}
+
+func TestPackager_Package(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockHLSStore(ctrl)
+	now := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	staleBefore := now.Add(-time.Minute)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	ctx := logging.NewContext(context.Background())
+
+	mp3, err := blobStore.Put(ctx, "shorts/1.mp3", bytes.NewReader(newMP3(6)), "audio/mpeg")
+	assert.NoError(t, err)
+	wav, err := blobStore.Put(ctx, "shorts/2.wav", bytes.NewReader([]byte("RIFF\x24\x00\x00\x00WAVEfmt ")), "audio/wav")
+	assert.NoError(t, err)
+
+	p, err := New(mockStore, blobStore, newConfig())
+	assert.NoError(t, err)
+	p.now = func() time.Time { return now }
+
+	t.Run("happy path - batches", func(t *testing.T) {
+		gomock.InOrder(
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.HLSJob{
+				{ShortID: "1", AudioFile: mp3},
+				{ShortID: "2", AudioFile: wav},
+			}, nil),
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.HLSJob{
+				{ShortID: "3", AudioFile: "https://example.com/3.mp3"},
+			}, nil),
+		)
+		mockStore.EXPECT().Complete(gomock.Any(), "1", "http://localhost:8080/files/hls/1/index.m3u8", 2)
+		mockStore.EXPECT().Fail(gomock.Any(), "2", store.HLSStatusUnsupported, gomock.Any())
+		mockStore.EXPECT().Fail(gomock.Any(), "3", store.HLSStatusFailed, ErrorMessageNotInBlobStore)
+
+		packaged, err := p.Package(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, packaged)
+		assert.Equal(t, "#EXTM3U\n"+
+			"#EXT-X-VERSION:3\n"+
+			"#EXT-X-TARGETDURATION:1\n"+
+			"#EXT-X-MEDIA-SEQUENCE:0\n"+
+			"#EXT-X-PLAYLIST-TYPE:VOD\n"+
+			"#EXTINF:0.104,\n"+
+			"segment0.mp3\n"+
+			"#EXTINF:0.052,\n"+
+			"segment1.mp3\n"+
+			"#EXT-X-ENDLIST\n", string(read(t, blobStore, "hls/1/index.m3u8")))
+		segment := read(t, blobStore, "hls/1/segment1.mp3")
+		assert.Equal(t, timestampTag(4*1152*time.Second/44100), segment[:73])
+		assert.Equal(t, newMP3(2), segment[73:])
+	})
+
+	t.Run("sad path - claim fails", func(t *testing.T) {
+		mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return(nil, errors.New("some error"))
+
+		packaged, err := p.Package(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, packaged)
+	})
+}
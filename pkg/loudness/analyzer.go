@@ -0,0 +1,153 @@
+package loudness
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/audio"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidConfig  = "Loudness interval, batch size and timeout must be positive, and silence ratio between 0 and 1"
+	ErrorMessageClaimFailed    = "Failed to claim pending loudness analyses"
+	ErrorMessageAnalyzeFailed  = "Failed to analyze loudness"
+	ErrorMessageSaveFailed     = "Failed to save loudness"
+	ErrorMessageNotInBlobStore = "Audio file is not kept in the blob store"
+	ErrorMessageNoBlobStore    = "No blob store to read audio files from"
+)
+
+// Analyzer periodically claims the pending loudness analyses of shorts and measures the loudness of their audio files
+// in the blob store, so that players can normalize the volume of the shorts they play in a row
+type Analyzer struct {
+	loudnessStore store.LoudnessStore
+	blobStore     storage.BlobStore
+	silenceRatio  float64
+	interval      time.Duration
+	batchSize     uint16
+	timeout       time.Duration
+	now           func() time.Time
+}
+
+// New returns an analyzer of the loudness of the store, reading audio files from the blob store
+func New(loudnessStore store.LoudnessStore, blobStore storage.BlobStore, cfg *config.Config) (*Analyzer, error) {
+	if cfg.Loudness.SilenceRatio <= 0 || cfg.Loudness.SilenceRatio > 1 || cfg.Loudness.Interval <= 0 || cfg.Loudness.BatchSize == 0 || cfg.Loudness.Timeout <= 0 {
+		return nil, errors.New(ErrorMessageInvalidConfig)
+	}
+	return &Analyzer{
+		loudnessStore: loudnessStore,
+		blobStore:     blobStore,
+		silenceRatio:  cfg.Loudness.SilenceRatio,
+		interval:      cfg.Loudness.Interval,
+		batchSize:     cfg.Loudness.BatchSize,
+		timeout:       cfg.Loudness.Timeout,
+		now:           time.Now,
+	}, nil
+}
+
+// Run analyzes the pending shorts every interval until the context is done
+func (a *Analyzer) Run(ctx context.Context) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Loudness analyzer started, interval " + a.interval.String())
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logging.WithContext(ctx).Info("Loudness analyzer stopped")
+			return
+		case <-ticker.C:
+			analyzed, err := a.Analyze(ctx)
+			if err != nil {
+				logging.WithContext(ctx).Error(err.Error())
+			}
+			if analyzed > 0 {
+				logging.WithContext(ctx).Info("Analyzed the loudness of " + strconv.Itoa(analyzed) + " shorts")
+			}
+		}
+	}
+}
+
+// Analyze claims the pending analyses batch by batch, along with those left processing for longer than the timeout,
+// and measures them; it returns the number of analyses that were claimed
+func (a *Analyzer) Analyze(ctx context.Context) (analyzed int, err error) {
+	for {
+		jobs, err := a.loudnessStore.Claim(ctx, a.now().Add(-a.timeout), a.batchSize)
+		if err != nil {
+			return analyzed, errors.Wrap(err, ErrorMessageClaimFailed)
+		}
+		for _, job := range jobs {
+			a.analyze(ctx, job)
+		}
+		analyzed += len(jobs)
+		if len(jobs) < int(a.batchSize) {
+			return analyzed, nil
+		}
+	}
+}
+
+// analyze measures the loudness of the audio file of the job and saves it, or marks the analysis as failed, or as
+// unsupported when the audio cannot be decoded
+func (a *Analyzer) analyze(ctx context.Context, job *store.LoudnessJob) {
+	// claims older than the timeout are taken over, so the analysis must not outlive it
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	measured, err := a.measure(ctx, job.AudioFile)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageAnalyzeFailed+" short ID:"+job.ShortID).Error())
+		status := store.LoudnessStatusFailed
+		if errors.Cause(err) == audio.ErrUnsupported {
+			status = store.LoudnessStatusUnsupported
+		}
+		err = a.loudnessStore.Fail(ctx, job.ShortID, status, err.Error())
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageSaveFailed+" short ID:"+job.ShortID).Error())
+		}
+		return
+	}
+	err = a.loudnessStore.Complete(ctx, job.ShortID, a.toModel(measured))
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageSaveFailed+" short ID:"+job.ShortID).Error())
+	}
+}
+
+// measure decodes the audio file at the URL, which must be kept in the blob store, and measures its loudness
+func (a *Analyzer) measure(ctx context.Context, url string) (*audio.Loudness, error) {
+	if a.blobStore == nil {
+		return nil, errors.New(ErrorMessageNoBlobStore)
+	}
+	key, ok := a.blobStore.Key(url)
+	if !ok {
+		return nil, errors.New(ErrorMessageNotInBlobStore)
+	}
+	reader, err := storage.NewReaderAt(ctx, a.blobStore, key)
+	if err != nil {
+		return nil, err
+	}
+	return audio.AnalyzeLoudness(reader, reader.Size())
+}
+
+// toModel returns the loudness as exposed by the API; the loudness and true peak of silence, negative infinity, are
+// left out
+func (a *Analyzer) toModel(measured *audio.Loudness) *model.Loudness {
+	loudness := &model.Loudness{
+		SilenceRatio: measured.SilenceRatio,
+		MostlySilent: measured.SilenceRatio >= a.silenceRatio,
+		ReplayGain:   measured.ReplayGain(),
+	}
+	if !math.IsInf(measured.Integrated, -1) {
+		loudness.Integrated = &measured.Integrated
+	}
+	if !math.IsInf(measured.TruePeak, -1) {
+		loudness.TruePeak = &measured.TruePeak
+	}
+	return loudness
+}
@@ -0,0 +1,138 @@
+package loudness
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Loudness.SilenceRatio = 0.8
+	cfg.Loudness.Interval = time.Second
+	cfg.Loudness.BatchSize = 2
+	cfg.Loudness.Timeout = time.Minute
+	return cfg
+}
+
+// newWAV returns a mono 16 bit PCM WAVE file of a second of the sample function at 8 kHz
+func newWAV(sample func(i int) int16) []byte {
+	data := &bytes.Buffer{}
+	for i := 0; i < 8000; i++ {
+		_ = binary.Write(data, binary.LittleEndian, sample(i))
+	}
+	header := &bytes.Buffer{}
+	header.WriteString("RIFF")
+	_ = binary.Write(header, binary.LittleEndian, uint32(36+data.Len()))
+	header.WriteString("WAVEfmt ")
+	_ = binary.Write(header, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(header, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(header, binary.LittleEndian, []uint32{8000, 16000})
+	_ = binary.Write(header, binary.LittleEndian, []uint16{2, 16})
+	header.WriteString("data")
+	_ = binary.Write(header, binary.LittleEndian, uint32(data.Len()))
+	return append(header.Bytes(), data.Bytes()...)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		a, err := New(nil, nil, newConfig())
+
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+	})
+
+	t.Run("sad path - invalid silence ratio", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Loudness.SilenceRatio = 1.5
+		a, err := New(nil, nil, cfg)
+
+		assert.Error(t, err)
+		assert.Nil(t, a)
+	})
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockLoudnessStore(ctrl)
+	now := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	staleBefore := now.Add(-time.Minute)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	ctx := logging.NewContext(context.Background())
+
+	tone, err := blobStore.Put(ctx, "shorts/1.wav", bytes.NewReader(newWAV(func(i int) int16 {
+		return int16(3276 * math.Sin(2*math.Pi*1000*float64(i)/8000))
+	})), "audio/wav")
+	assert.NoError(t, err)
+	silence, err := blobStore.Put(ctx, "shorts/2.wav", bytes.NewReader(newWAV(func(i int) int16 { return 0 })), "audio/wav")
+	assert.NoError(t, err)
+	flac, err := blobStore.Put(ctx, "shorts/3.flac", strings.NewReader("fLaC"+strings.Repeat("\x00", 100)), "audio/flac")
+	assert.NoError(t, err)
+
+	a, err := New(mockStore, blobStore, newConfig())
+	assert.NoError(t, err)
+	a.now = func() time.Time { return now }
+
+	t.Run("happy path - batches", func(t *testing.T) {
+		gomock.InOrder(
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.LoudnessJob{
+				{ShortID: "1", AudioFile: tone},
+				{ShortID: "2", AudioFile: silence},
+			}, nil),
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.LoudnessJob{
+				{ShortID: "3", AudioFile: flac},
+			}, nil),
+		)
+		mockStore.EXPECT().Complete(gomock.Any(), "1", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, shortID string, loudness *model.Loudness) error {
+				// a sine of a tenth of full scale
+				assert.InDelta(t, -23, *loudness.Integrated, 0.5)
+				assert.InDelta(t, -20, *loudness.TruePeak, 0.5)
+				assert.False(t, loudness.MostlySilent)
+				assert.InDelta(t, 5, loudness.ReplayGain, 0.5)
+				return nil
+			})
+		mockStore.EXPECT().Complete(gomock.Any(), "2", &model.Loudness{SilenceRatio: 1, MostlySilent: true})
+		mockStore.EXPECT().Fail(gomock.Any(), "3", store.LoudnessStatusUnsupported, gomock.Any())
+
+		analyzed, err := a.Analyze(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, analyzed)
+	})
+
+	t.Run("happy path - external audio file", func(t *testing.T) {
+		mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.LoudnessJob{
+			{ShortID: "4", AudioFile: "https://example.com/4.wav"},
+		}, nil)
+		mockStore.EXPECT().Fail(gomock.Any(), "4", store.LoudnessStatusFailed, ErrorMessageNotInBlobStore)
+
+		analyzed, err := a.Analyze(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, analyzed)
+	})
+
+	t.Run("sad path - claim fails", func(t *testing.T) {
+		mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return(nil, errors.New("some error"))
+
+		analyzed, err := a.Analyze(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, analyzed)
+	})
+}
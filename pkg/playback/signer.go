@@ -0,0 +1,177 @@
+package playback
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidKey    = "Playback keys must be given as id:secret, with unique IDs"
+	ErrorMessageInvalidConfig = "Playback base URL and expiry must be set"
+)
+
+var (
+	// ErrExpired is returned when verifying a URL past its expiry
+	ErrExpired = errors.New("Playback URL expired")
+	// ErrInvalidSignature is returned when verifying a URL that was not signed for the short and listener, or with a
+	// key that is no longer active
+	ErrInvalidSignature = errors.New("Invalid playback URL signature")
+)
+
+// query parameters of signed URLs
+const (
+	paramExpires   = "expires"
+	paramKeyID     = "kid"
+	paramSignature = "sig"
+)
+
+// Key is an HMAC key of signed URLs, told apart by its ID
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Signer signs the URLs the audio of shorts is streamed from, so that they expire and, with listener binding, only
+// play for the listener they were signed for. URLs are signed with the first key and verified with any of them, so
+// that a new key can be put first while URLs signed with the previous one are still in use
+type Signer struct {
+	baseURL      string
+	keys         []Key
+	expiry       time.Duration
+	bindListener bool
+	now          func() time.Time
+}
+
+// New returns the signer of the playback config, or nil when no keys are configured and URLs are not signed
+func New(cfg *config.Config) (*Signer, error) {
+	if len(cfg.Playback.Keys) == 0 {
+		return nil, nil
+	}
+	keys, err := ParseKeys(cfg.Playback.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(cfg.Playback.BaseURL, keys, cfg.Playback.Expiry, cfg.Playback.BindListener)
+}
+
+// ParseKeys parses keys given as id:secret
+func ParseKeys(specs []string) ([]Key, error) {
+	keys := make([]Key, 0, len(specs))
+	ids := map[string]bool{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" || ids[parts[0]] {
+			return nil, errors.New(ErrorMessageInvalidKey)
+		}
+		ids[parts[0]] = true
+		keys = append(keys, Key{ID: parts[0], Secret: []byte(parts[1])})
+	}
+	return keys, nil
+}
+
+func NewSigner(baseURL string, keys []Key, expiry time.Duration, bindListener bool) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New(ErrorMessageInvalidKey)
+	}
+	if baseURL == "" || expiry <= 0 {
+		return nil, errors.New(ErrorMessageInvalidConfig)
+	}
+	return &Signer{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		keys:         keys,
+		expiry:       expiry,
+		bindListener: bindListener,
+		now:          time.Now,
+	}, nil
+}
+
+// URL returns the signed URL of the audio of the short, bound to the listener when listener binding is enabled
+func (s *Signer) URL(shortID, listener string) string {
+	return s.baseURL + "/" + shortID + "?" + s.query(shortID, listener).Encode()
+}
+
+// HLSURL returns the signed URL of the file of the HLS package of the short with the name; the signature covers the
+// short rather than the file, so that the streaming handler can pass the signature of a playlist on to its segments
+func (s *Signer) HLSURL(shortID, name, listener string) string {
+	return s.baseURL + "/" + shortID + "/hls/" + name + "?" + s.query(shortID, listener).Encode()
+}
+
+// query returns the expiry, key ID and signature of the URLs of the short
+func (s *Signer) query(shortID, listener string) url.Values {
+	expires := strconv.FormatInt(s.now().Add(s.expiry).Unix(), 10)
+	key := s.keys[0]
+	query := url.Values{}
+	query.Set(paramExpires, expires)
+	query.Set(paramKeyID, key.ID)
+	query.Set(paramSignature, s.sign(key, shortID, expires, listener))
+	return query
+}
+
+// Verify checks that the query of a URL was signed for the short, and for the listener with listener binding, by an
+// active key, and has not expired
+func (s *Signer) Verify(shortID string, query url.Values, listener string) error {
+	expires, err := strconv.ParseInt(query.Get(paramExpires), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	for _, key := range s.keys {
+		if key.ID != query.Get(paramKeyID) {
+			continue
+		}
+		signature := s.sign(key, shortID, query.Get(paramExpires), listener)
+		if !hmac.Equal([]byte(query.Get(paramSignature)), []byte(signature)) {
+			return ErrInvalidSignature
+		}
+		if s.now().Unix() > expires {
+			return ErrExpired
+		}
+		return nil
+	}
+	return ErrInvalidSignature
+}
+
+// sign returns the URL-safe base64 HMAC-SHA256 of the short, expiry and listener
+func (s *Signer) sign(key Key, shortID, expires, listener string) string {
+	if !s.bindListener {
+		listener = ""
+	}
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(shortID + "\n" + expires + "\n" + listener))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type listenerKey struct{}
+
+// Listener returns the listener of the request, which is the client address; behind a proxy, the proxy should set
+// the address of its clients as RemoteAddr
+func Listener(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware keeps the listener of requests in their context, for resolvers to sign URLs for them
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), listenerKey{}, Listener(r))))
+	})
+}
+
+// ListenerFromContext returns the listener kept in the context by Middleware, if any
+func ListenerFromContext(ctx context.Context) string {
+	listener, _ := ctx.Value(listenerKey{}).(string)
+	return listener
+}
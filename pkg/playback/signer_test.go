@@ -0,0 +1,194 @@
+package playback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSigner returns a signer with the keys, as of the given time
+func newSigner(t *testing.T, now time.Time, bindListener bool, keys ...string) *Signer {
+	parsed, err := ParseKeys(keys)
+	assert.NoError(t, err)
+	s, err := NewSigner("http://localhost:8080/audio/", parsed, time.Hour, bindListener)
+	assert.NoError(t, err)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+// queryOf returns the query of a signed URL
+func queryOf(t *testing.T, signed string) url.Values {
+	u, err := url.Parse(signed)
+	assert.NoError(t, err)
+	return u.Query()
+}
+
+func TestNew(t *testing.T) {
+	t.Run("happy path - unsigned", func(t *testing.T) {
+		s, err := New(&config.Config{})
+
+		assert.NoError(t, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Playback.BaseURL = "http://localhost:8080/audio"
+		cfg.Playback.Keys = []string{"2:new", "1:old"}
+		cfg.Playback.Expiry = time.Hour
+		s, err := New(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Key{{ID: "2", Secret: []byte("new")}, {ID: "1", Secret: []byte("old")}}, s.keys)
+	})
+
+	t.Run("sad path - invalid keys", func(t *testing.T) {
+		for _, keys := range [][]string{{"secret"}, {":secret"}, {"1:"}, {"1:a", "1:b"}} {
+			cfg := &config.Config{}
+			cfg.Playback.BaseURL = "http://localhost:8080/audio"
+			cfg.Playback.Keys = keys
+			cfg.Playback.Expiry = time.Hour
+			s, err := New(cfg)
+
+			assert.Error(t, err)
+			assert.Nil(t, s)
+		}
+	})
+
+	t.Run("sad path - no expiry", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Playback.BaseURL = "http://localhost:8080/audio"
+		cfg.Playback.Keys = []string{"1:secret"}
+		s, err := New(cfg)
+
+		assert.Error(t, err)
+		assert.Nil(t, s)
+	})
+}
+
+func TestSigner_URL(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	s := newSigner(t, now, false, "1:secret")
+
+	signed := s.URL("42", "10.0.0.1")
+
+	assert.True(t, strings.HasPrefix(signed, "http://localhost:8080/audio/42?"))
+	query := queryOf(t, signed)
+	assert.Equal(t, "1600003600", query.Get("expires"))
+	assert.Equal(t, "1", query.Get("kid"))
+	assert.NotEmpty(t, query.Get("sig"))
+}
+
+func TestSigner_HLSURL(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	s := newSigner(t, now, false, "1:secret")
+
+	signed := s.HLSURL("42", "index.m3u8", "")
+
+	assert.True(t, strings.HasPrefix(signed, "http://localhost:8080/audio/42/hls/index.m3u8?"))
+	assert.NoError(t, s.Verify("42", queryOf(t, signed), ""))
+}
+
+func TestSigner_Verify(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	t.Run("happy path", func(t *testing.T) {
+		s := newSigner(t, now, false, "1:secret")
+
+		err := s.Verify("42", queryOf(t, s.URL("42", "10.0.0.1")), "10.0.0.2")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("happy path - rotated key", func(t *testing.T) {
+		old := newSigner(t, now, false, "1:old")
+		rotated := newSigner(t, now, false, "2:new", "1:old")
+
+		err := rotated.Verify("42", queryOf(t, old.URL("42", "")), "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2", queryOf(t, rotated.URL("42", "")).Get("kid"))
+	})
+
+	t.Run("happy path - bound listener", func(t *testing.T) {
+		s := newSigner(t, now, true, "1:secret")
+
+		err := s.Verify("42", queryOf(t, s.URL("42", "10.0.0.1")), "10.0.0.1")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("sad path - other listener", func(t *testing.T) {
+		s := newSigner(t, now, true, "1:secret")
+
+		err := s.Verify("42", queryOf(t, s.URL("42", "10.0.0.1")), "10.0.0.2")
+
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("sad path - other short", func(t *testing.T) {
+		s := newSigner(t, now, false, "1:secret")
+
+		err := s.Verify("43", queryOf(t, s.URL("42", "")), "")
+
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("sad path - retired key", func(t *testing.T) {
+		old := newSigner(t, now, false, "1:old")
+		rotated := newSigner(t, now, false, "2:new")
+
+		err := rotated.Verify("42", queryOf(t, old.URL("42", "")), "")
+
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("sad path - tampered expiry", func(t *testing.T) {
+		s := newSigner(t, now, false, "1:secret")
+		query := queryOf(t, s.URL("42", ""))
+		query.Set("expires", "1700000000")
+
+		err := s.Verify("42", query, "")
+
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("sad path - expired", func(t *testing.T) {
+		s := newSigner(t, now, false, "1:secret")
+		query := queryOf(t, s.URL("42", ""))
+		s.now = func() time.Time { return now.Add(time.Hour + time.Second) }
+
+		err := s.Verify("42", query, "")
+
+		assert.Equal(t, ErrExpired, err)
+	})
+
+	t.Run("sad path - unsigned", func(t *testing.T) {
+		s := newSigner(t, now, false, "1:secret")
+
+		err := s.Verify("42", url.Values{}, "")
+
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	var listener string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listener = ListenerFromContext(r.Context())
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = "10.0.0.1:5678"
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "10.0.0.1", listener)
+	assert.Empty(t, ListenerFromContext(context.Background()))
+}
@@ -0,0 +1,151 @@
+package purger
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/hls"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidConfig         = "Purger retention, interval and batch size must be positive"
+	ErrorMessagePurgeFailed           = "Failed to purge deleted shorts"
+	ErrorMessageCleanupFailed         = "Failed to delete the audio file of a purged short"
+	ErrorMessageHLSCleanupFailed      = "Failed to delete the HLS package of a purged short"
+	ErrorMessageCoverArtCleanupFailed = "Failed to delete the cover art of a purged short"
+)
+
+// Purger periodically hard deletes the shorts that have been deleted for longer than the retention period,
+// in batches so that the shorts store is never locked for long, along with their audio files, HLS packages and cover
+// art in the blob store
+type Purger struct {
+	shortsStore store.AudioShortsStore
+	blobStore   storage.BlobStore
+	retention   time.Duration
+	interval    time.Duration
+	batchSize   uint16
+	dryRun      bool
+	now         func() time.Time
+}
+
+// Summary describes the shorts removed by a purge, or that would have been in a dry run
+type Summary struct {
+	Shorts  []*model.AudioShort
+	Batches int
+	DryRun  bool
+}
+
+// New returns a purger of the shorts store; the blob store may be nil, in which case audio files are left in place
+func New(shortsStore store.AudioShortsStore, blobStore storage.BlobStore, cfg *config.Config) (*Purger, error) {
+	if cfg.Purger.Retention <= 0 || cfg.Purger.Interval <= 0 || cfg.Purger.BatchSize == 0 {
+		return nil, errors.New(ErrorMessageInvalidConfig)
+	}
+	return &Purger{
+		shortsStore: shortsStore,
+		blobStore:   blobStore,
+		retention:   cfg.Purger.Retention,
+		interval:    cfg.Purger.Interval,
+		batchSize:   cfg.Purger.BatchSize,
+		dryRun:      cfg.Purger.DryRun,
+		now:         time.Now,
+	}, nil
+}
+
+// Run purges every interval until the context is done; the first purge happens after one interval,
+// once migrations are done
+func (p *Purger) Run(ctx context.Context) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Purger started, retention " + p.retention.String() + ", interval " + p.interval.String())
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logging.WithContext(ctx).Info("Purger stopped")
+			return
+		case <-ticker.C:
+			summary, err := p.Purge(ctx)
+			if err != nil {
+				logging.WithContext(ctx).Error(err.Error())
+			}
+			logSummary(ctx, summary)
+		}
+	}
+}
+
+// Purge hard deletes, batch by batch, the shorts deleted before the retention period; on failure the summary
+// holds the shorts of the batches purged so far
+func (p *Purger) Purge(ctx context.Context) (summary *Summary, err error) {
+	deletedBefore := p.now().Add(-p.retention)
+	summary = &Summary{DryRun: p.dryRun}
+	afterID := "0"
+	for {
+		shorts, err := p.shortsStore.Purge(ctx, deletedBefore, afterID, p.batchSize, p.dryRun)
+		if err != nil {
+			return summary, errors.Wrap(err, ErrorMessagePurgeFailed)
+		}
+		if len(shorts) == 0 {
+			return summary, nil
+		}
+		summary.Batches++
+		summary.Shorts = append(summary.Shorts, shorts...)
+		if !p.dryRun {
+			p.deleteFiles(ctx, shorts)
+		}
+		if len(shorts) < int(p.batchSize) {
+			return summary, nil
+		}
+		afterID = shorts[len(shorts)-1].ID
+	}
+}
+
+// deleteFiles removes the audio files of purged shorts that are kept in the blob store, and their HLS packages and
+// cover art; failures are only logged, as the shorts are already gone
+func (p *Purger) deleteFiles(ctx context.Context, shorts []*model.AudioShort) {
+	if p.blobStore == nil {
+		return
+	}
+	for _, short := range shorts {
+		err := p.blobStore.DeletePrefix(ctx, hls.KeyPrefix(short.ID))
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageHLSCleanupFailed+" ID:"+short.ID).Error())
+		}
+		err = p.blobStore.DeletePrefix(ctx, store.CoverArt.KeyPrefix(short.ID))
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCoverArtCleanupFailed+" ID:"+short.ID).Error())
+		}
+		key, ok := p.blobStore.Key(short.AudioFile)
+		if !ok {
+			continue
+		}
+		err = p.blobStore.Delete(ctx, key)
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageCleanupFailed+" ID:"+short.ID).Error())
+		}
+	}
+}
+
+// logSummary logs the IDs and titles of the purged shorts
+func logSummary(ctx context.Context, summary *Summary) {
+	if summary == nil || len(summary.Shorts) == 0 {
+		return
+	}
+	purged := make([]string, 0, len(summary.Shorts))
+	for _, short := range summary.Shorts {
+		purged = append(purged, short.ID+" ("+short.Title+")")
+	}
+	message := "Purged "
+	if summary.DryRun {
+		message = "Dry run, would have purged "
+	}
+	logging.WithContext(ctx).Info(message + strconv.Itoa(len(summary.Shorts)) + " shorts in " +
+		strconv.Itoa(summary.Batches) + " batches: " + strings.Join(purged, ", "))
+}
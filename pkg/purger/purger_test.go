@@ -0,0 +1,135 @@
+package purger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfig(dryRun bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.Purger.Retention = 720 * time.Hour
+	cfg.Purger.Interval = time.Hour
+	cfg.Purger.BatchSize = 2
+	cfg.Purger.DryRun = dryRun
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		p, err := New(nil, nil, newConfig(false))
+
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+	})
+
+	t.Run("sad path - no batch size", func(t *testing.T) {
+		cfg := newConfig(false)
+		cfg.Purger.BatchSize = 0
+		p, err := New(nil, nil, cfg)
+
+		assert.Error(t, err)
+		assert.Nil(t, p)
+	})
+}
+
+func TestPurger_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	now := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	deletedBefore := now.Add(-720 * time.Hour)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+
+	// shorts returns purged shorts with audio files, HLS packages and cover art in the blob store
+	shorts := func(ids ...string) []*model.AudioShort {
+		var shorts []*model.AudioShort
+		for _, id := range ids {
+			url, err := blobStore.Put(context.Background(), "shorts/"+id+".mp3", strings.NewReader("ID3"), "audio/mpeg")
+			assert.NoError(t, err)
+			_, err = blobStore.Put(context.Background(), "hls/"+id+"/index.m3u8", strings.NewReader("#EXTM3U"),
+				"application/vnd.apple.mpegurl")
+			assert.NoError(t, err)
+			_, err = blobStore.Put(context.Background(), "covers/"+id+"/abc.png", strings.NewReader("\x89PNG"), "image/png")
+			assert.NoError(t, err)
+			shorts = append(shorts, &model.AudioShort{ID: id, Title: "abc", AudioFile: url, Creator: &model.Creator{ID: "1"}})
+		}
+		return shorts
+	}
+
+	// stored reports whether the audio file of the short is still in the blob store, and asserts that its HLS package
+	// and cover art are kept or removed along with it
+	stored := func(id string) bool {
+		_, err := blobStore.Stat(context.Background(), "shorts/"+id+".mp3")
+		_, hlsErr := blobStore.Stat(context.Background(), "hls/"+id+"/index.m3u8")
+		_, coverErr := blobStore.Stat(context.Background(), "covers/"+id+"/abc.png")
+		assert.Equal(t, err == nil, hlsErr == nil, id)
+		assert.Equal(t, err == nil, coverErr == nil, id)
+		return err == nil
+	}
+
+	t.Run("happy path - batches", func(t *testing.T) {
+		p, err := New(mockStore, blobStore, newConfig(false))
+		assert.NoError(t, err)
+		p.now = func() time.Time { return now }
+		gomock.InOrder(
+			mockStore.EXPECT().Purge(gomock.Any(), deletedBefore, "0", uint16(2), false).Return(shorts("1", "4"), nil),
+			mockStore.EXPECT().Purge(gomock.Any(), deletedBefore, "4", uint16(2), false).Return(shorts("5"), nil),
+		)
+
+		ctx := logging.NewContext(context.Background())
+		summary, err := p.Purge(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, summary.Batches)
+		assert.Equal(t, 3, len(summary.Shorts))
+		assert.False(t, summary.DryRun)
+		assert.False(t, stored("1"))
+		assert.False(t, stored("5"))
+	})
+
+	t.Run("happy path - dry run", func(t *testing.T) {
+		p, err := New(mockStore, blobStore, newConfig(true))
+		assert.NoError(t, err)
+		p.now = func() time.Time { return now }
+		gomock.InOrder(
+			mockStore.EXPECT().Purge(gomock.Any(), deletedBefore, "0", uint16(2), true).Return(shorts("1", "4"), nil),
+			mockStore.EXPECT().Purge(gomock.Any(), deletedBefore, "4", uint16(2), true).Return(nil, nil),
+		)
+
+		ctx := logging.NewContext(context.Background())
+		summary, err := p.Purge(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, summary.Batches)
+		assert.Equal(t, 2, len(summary.Shorts))
+		assert.True(t, summary.DryRun)
+		assert.True(t, stored("1"))
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		p, err := New(mockStore, blobStore, newConfig(false))
+		assert.NoError(t, err)
+		p.now = func() time.Time { return now }
+		gomock.InOrder(
+			mockStore.EXPECT().Purge(gomock.Any(), deletedBefore, "0", uint16(2), false).Return(shorts("1", "4"), nil),
+			mockStore.EXPECT().Purge(gomock.Any(), deletedBefore, "4", uint16(2), false).Return(nil, errors.New("some error")),
+		)
+
+		ctx := logging.NewContext(context.Background())
+		summary, err := p.Purge(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, 2, len(summary.Shorts))
+	})
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxRedirects is the number of redirects followed by the clients of NewPublicClient
+const maxRedirects = 3
+
+// ErrPrivateAddress is returned when a client of NewPublicClient is asked to connect to an address that is not public
+var ErrPrivateAddress = errors.New("Address is not public")
+
+// privateNetworks are the IPv4 and IPv6 ranges that are not reachable from the internet, besides the loopback,
+// link-local, multicast and unspecified addresses
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8",      // this network
+	"10.0.0.0/8",     // private
+	"100.64.0.0/10",  // carrier-grade NAT
+	"172.16.0.0/12",  // private
+	"192.0.0.0/24",   // protocol assignments
+	"192.168.0.0/16", // private
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, and broadcast
+	"fc00::/7",       // unique local
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// NewPublicClient returns an HTTP client for URLs given by users, which only connects to public addresses so that they
+// cannot reach the loopback interface, private networks or cloud metadata endpoints, ignores proxies, follows few
+// redirects, and gives up on requests after the timeout
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublic}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       time.Minute,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("Too many redirects")
+			}
+			return nil
+		},
+	}
+}
+
+// dialPublic refuses connections to addresses that are not public; it runs on the resolved address of every
+// connection, so that host names and redirects cannot point to private addresses either
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublic(ip) {
+		return errors.Wrap(ErrPrivateAddress, address)
+	}
+	return nil
+}
+
+// isPublic tells whether the IP address is reachable from the internet
+func isPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
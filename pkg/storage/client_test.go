@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicClient(t *testing.T) {
+	requested := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested++
+	}))
+	defer srv.Close()
+	client := NewPublicClient(time.Second)
+
+	t.Run("sad path - loopback", func(t *testing.T) {
+		_, err := client.Get(srv.URL)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPrivateAddress))
+		assert.Zero(t, requested)
+	})
+
+	t.Run("sad path - host name of a private address", func(t *testing.T) {
+		_, err := client.Get("http://localhost:" + strconv.Itoa(srv.Listener.Addr().(*net.TCPAddr).Port))
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPrivateAddress))
+		assert.Zero(t, requested)
+	})
+}
+
+func TestIsPublic(t *testing.T) {
+	for ip, public := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::248": true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"::ffff:127.0.0.1":     false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"100.64.0.1":           false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"0.0.0.0":              false,
+		"::":                   false,
+		"224.0.0.1":            false,
+		"255.255.255.255":      false,
+	} {
+		assert.Equal(t, public, isPublic(net.ParseIP(ip)), ip)
+	}
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LocalStore stores blobs as files in a directory on the local disk, served by its handler
+type LocalStore struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStore(dir, baseURL string) (*LocalStore, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, contentType string) (url string, err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(name), 0755)
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+
+	// write to a temporary file first, so that a failed upload never leaves a partial blob behind
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	_, err = io.Copy(tmp, &contextReader{ctx: ctx, r: r})
+	if err != nil {
+		_ = tmp.Close()
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	err = os.Rename(tmp.Name(), name)
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string, offset, length int64) (r io.ReadCloser, info *BlobInfo, err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrorMessageGetFailed)
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+		}
+	}()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrorMessageGetFailed)
+	}
+	info = s.info(key, fi)
+	if offset < 0 || (offset > 0 && offset >= info.Size) {
+		return nil, nil, ErrInvalidRange
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrorMessageGetFailed)
+	}
+	if length < 0 {
+		return f, info, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(f, length), Closer: f}, info, nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, key string) (info *BlobInfo, err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageStatFailed)
+	}
+	return s.info(key, fi), nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(name)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, ErrorMessageDeleteFailed)
+	}
+	return nil
+}
+
+func (s *LocalStore) DeletePrefix(ctx context.Context, prefix string) error {
+	err := validatePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	name, err := s.path(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(name)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageDeleteFailed)
+	}
+	return nil
+}
+
+func (s *LocalStore) Key(url string) (string, bool) {
+	return keyOf(s.baseURL, url)
+}
+
+// Handler serves the blobs with keys under one of the prefixes, e.g. "covers/", without listing directories; other
+// blobs, such as audio files streamed with their own checks, are not found. Range requests are supported
+func (s *LocalStore) Handler(prefixes ...string) http.Handler {
+	files := http.FileServer(http.Dir(s.dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if validateKey(key) != nil || !hasPrefix(key, prefixes) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeOf(r.URL.Path))
+		files.ServeHTTP(w, r)
+	})
+}
+
+func (s *LocalStore) info(key string, fi os.FileInfo) *BlobInfo {
+	return &BlobInfo{
+		Key:          key,
+		Size:         fi.Size(),
+		ContentType:  contentTypeOf(key),
+		ETag:         `"` + strconv.FormatInt(fi.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(fi.Size(), 36) + `"`,
+		LastModified: fi.ModTime(),
+	}
+}
+
+// hasPrefix reports whether the key starts with one of the prefixes
+func hasPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// path returns the file of the key, which must be a relative slash-separated path inside the directory
+func (s *LocalStore) path(key string) (string, error) {
+	err := validateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// contextReader stops reading once the context is done, e.g. when the client disconnects mid-upload
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// limitedReadCloser reads a range of a blob, and closes the whole blob
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStore(dir, "http://localhost:8080/files/")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("happy path - put", func(t *testing.T) {
+		url, err := s.Put(ctx, "shorts/abc.mp3", strings.NewReader("ID3 audio"), "audio/mpeg")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost:8080/files/shorts/abc.mp3", url)
+		content, err := ioutil.ReadFile(filepath.Join(dir, "shorts", "abc.mp3"))
+		assert.NoError(t, err)
+		assert.Equal(t, "ID3 audio", string(content))
+	})
+
+	t.Run("happy path - serve", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Handler("shorts/").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shorts/abc.mp3", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ID3 audio", w.Body.String())
+	})
+
+	t.Run("sad path - no directory listing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Handler("shorts/").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shorts/", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("sad path - outside the served prefixes", func(t *testing.T) {
+		for _, path := range []string{"/shorts/abc.mp3", "/covers/../shorts/abc.mp3", "/covers"} {
+			w := httptest.NewRecorder()
+			s.Handler("covers/").ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+	})
+
+	t.Run("happy path - get range", func(t *testing.T) {
+		r, info, err := s.Get(ctx, "shorts/abc.mp3", 4, 3)
+
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, "aud", string(content))
+		assert.Equal(t, int64(9), info.Size)
+		assert.Equal(t, "audio/mpeg", info.ContentType)
+		assert.NotEmpty(t, info.ETag)
+	})
+
+	t.Run("happy path - get to the end", func(t *testing.T) {
+		r, _, err := s.Get(ctx, "shorts/abc.mp3", 4, -1)
+
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, "audio", string(content))
+	})
+
+	t.Run("sad path - get after the end", func(t *testing.T) {
+		_, _, err := s.Get(ctx, "shorts/abc.mp3", 9, -1)
+
+		assert.Equal(t, ErrInvalidRange, err)
+	})
+
+	t.Run("happy path - stat", func(t *testing.T) {
+		info, err := s.Stat(ctx, "shorts/abc.mp3")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "shorts/abc.mp3", info.Key)
+		assert.Equal(t, int64(9), info.Size)
+	})
+
+	t.Run("happy path - key", func(t *testing.T) {
+		key, ok := s.Key("http://localhost:8080/files/shorts/abc.mp3")
+		assert.True(t, ok)
+		assert.Equal(t, "shorts/abc.mp3", key)
+
+		_, ok = s.Key("https://cdn.example.com/shorts/abc.mp3")
+		assert.False(t, ok)
+		_, ok = s.Key("http://localhost:8080/files/../abc.mp3")
+		assert.False(t, ok)
+	})
+
+	t.Run("happy path - delete", func(t *testing.T) {
+		assert.NoError(t, s.Delete(ctx, "shorts/abc.mp3"))
+		assert.NoError(t, s.Delete(ctx, "shorts/abc.mp3"))
+		assert.NoFileExists(t, filepath.Join(dir, "shorts", "abc.mp3"))
+		_, err := s.Stat(ctx, "shorts/abc.mp3")
+		assert.Equal(t, ErrNotFound, err)
+		_, _, err = s.Get(ctx, "shorts/abc.mp3", 0, -1)
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("happy path - delete prefix", func(t *testing.T) {
+		for _, key := range []string{"hls/1/index.m3u8", "hls/1/segment0.mp3", "hls/10/index.m3u8"} {
+			_, err := s.Put(ctx, key, strings.NewReader("#EXTM3U"), "application/vnd.apple.mpegurl")
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, s.DeletePrefix(ctx, "hls/1/"))
+		assert.NoError(t, s.DeletePrefix(ctx, "hls/1/"))
+		assert.NoDirExists(t, filepath.Join(dir, "hls", "1"))
+		assert.FileExists(t, filepath.Join(dir, "hls", "10", "index.m3u8"))
+	})
+
+	t.Run("sad path - invalid keys", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../abc.mp3", "shorts/../../abc.mp3", "shorts//abc.mp3"} {
+			_, err := s.Put(ctx, key, strings.NewReader("ID3 audio"), "audio/mpeg")
+			assert.Error(t, err, key)
+		}
+		for _, prefix := range []string{"", "/", "hls/1", "../", "hls/../../"} {
+			assert.Error(t, s.DeletePrefix(ctx, prefix), prefix)
+		}
+	})
+
+	t.Run("sad path - cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := s.Put(cancelled, "shorts/def.mp3", strings.NewReader("ID3 audio"), "audio/mpeg")
+
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dir, "shorts", "def.mp3"))
+		files, err := ioutil.ReadDir(filepath.Join(dir, "shorts"))
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
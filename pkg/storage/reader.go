@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidURL  = "URL must be an absolute http or https URL"
+	ErrorMessageInvalidSeek = "Invalid seek"
+)
+
+// readerChunkSize is the size of the ranges ReaderAt gets from the blob store
+const readerChunkSize = 256 << 10
+
+// ReaderAt reads a blob at random offsets, e.g. to parse the headers of an audio file, with ranged gets of whole
+// chunks so that small reads do not each cost a request
+type ReaderAt struct {
+	size int64
+	// get reads length bytes starting at offset
+	get func(offset, length int64) (io.ReadCloser, error)
+	// chunk is the last chunk read, starting at offset
+	chunk  []byte
+	offset int64
+}
+
+// NewReaderAt returns a reader of the blob of the key
+func NewReaderAt(ctx context.Context, store BlobStore, key string) (*ReaderAt, error) {
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	get := func(offset, length int64) (io.ReadCloser, error) {
+		body, _, err := store.Get(ctx, key, offset, length)
+		return body, err
+	}
+	return &ReaderAt{size: info.Size, get: get}, nil
+}
+
+// NewURLReaderAt returns a reader of the file at an http or https URL, read with HTTP range requests; servers that
+// ignore the ranges are only read from the start. Files larger than maxSize bytes are refused with ErrTooLarge, and
+// only the chunks read are downloaded
+func NewURLReaderAt(ctx context.Context, client *http.Client, url string, maxSize int64) (*ReaderAt, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, errors.New(ErrorMessageInvalidURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageStatFailed)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageStatFailed)
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.New(ErrorMessageStatFailed + ": " + resp.Status)
+	case resp.ContentLength < 0:
+		return nil, errors.New(ErrorMessageStatFailed + ": unknown size")
+	case resp.ContentLength > maxSize:
+		return nil, ErrTooLarge
+	}
+
+	get := func(offset, length int64) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageGetFailed)
+		}
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageGetFailed)
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent, resp.StatusCode == http.StatusOK && offset == 0:
+			return resp.Body, nil
+		case resp.StatusCode == http.StatusOK:
+			_ = resp.Body.Close()
+			return nil, errors.New(ErrorMessageGetFailed + ": range requests are not supported")
+		case resp.StatusCode == http.StatusNotFound:
+			_ = resp.Body.Close()
+			return nil, ErrNotFound
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			_ = resp.Body.Close()
+			return nil, ErrInvalidRange
+		}
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 512))
+		_ = resp.Body.Close()
+		return nil, errors.New(ErrorMessageGetFailed + ": " + resp.Status)
+	}
+	return &ReaderAt{size: resp.ContentLength, get: get}, nil
+}
+
+// Size returns the size of the blob
+func (r *ReaderAt) Size() int64 {
+	return r.size
+}
+
+func (r *ReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	for n < len(p) {
+		if off >= r.size {
+			return n, io.EOF
+		}
+		if off < r.offset || off >= r.offset+int64(len(r.chunk)) {
+			err = r.load(off - off%readerChunkSize)
+			if err != nil {
+				return n, err
+			}
+		}
+		copied := copy(p[n:], r.chunk[off-r.offset:])
+		n += copied
+		off += int64(copied)
+	}
+	return n, nil
+}
+
+// load reads the chunk starting at the offset
+func (r *ReaderAt) load(offset int64) error {
+	length := r.size - offset
+	if length > readerChunkSize {
+		length = readerChunkSize
+	}
+	body, err := r.get(offset, length)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	chunk := make([]byte, length)
+	_, err = io.ReadFull(body, chunk)
+	if err != nil {
+		return err
+	}
+	r.chunk, r.offset = chunk, offset
+	return nil
+}
+
+// ReadSeeker reads a blob sequentially from any offset, e.g. to serve range requests with http.ServeContent; the
+// blob is read with a single get from the offset to the end, which is only started on the first read after a seek
+type ReadSeeker struct {
+	ctx    context.Context
+	store  BlobStore
+	info   *BlobInfo
+	offset int64
+	body   io.ReadCloser
+}
+
+// NewReadSeeker returns a read seeker of the blob of the key, to be closed once done
+func NewReadSeeker(ctx context.Context, store BlobStore, key string) (*ReadSeeker, error) {
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadSeeker{ctx: ctx, store: store, info: info}, nil
+}
+
+// Info describes the blob
+func (r *ReadSeeker) Info() *BlobInfo {
+	return r.info
+}
+
+func (r *ReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.info.Size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, _, err := r.store.Get(r.ctx, r.info.Key, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *ReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.info.Size
+	case io.SeekStart:
+	default:
+		return 0, errors.New(ErrorMessageInvalidSeek)
+	}
+	if offset < 0 {
+		return 0, errors.New(ErrorMessageInvalidSeek)
+	}
+	if offset != r.offset {
+		err := r.Close()
+		if err != nil {
+			return 0, err
+		}
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+// Close closes the get in progress, if any
+func (r *ReadSeeker) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
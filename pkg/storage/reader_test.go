@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blob returns content spanning a few chunks of ReaderAt
+func blob() []byte {
+	b := make([]byte, 2*readerChunkSize+100)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestReaderAt(t *testing.T) {
+	content := blob()
+	s, err := NewLocalStore(t.TempDir(), "http://localhost:8080/files/")
+	assert.NoError(t, err)
+	ctx := context.Background()
+	_, err = s.Put(ctx, "shorts/abc.mp3", bytes.NewReader(content), "audio/mpeg")
+	assert.NoError(t, err)
+
+	t.Run("happy path - across chunks", func(t *testing.T) {
+		r, err := NewReaderAt(ctx, s, "shorts/abc.mp3")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), r.Size())
+
+		p := make([]byte, 200)
+		n, err := r.ReadAt(p, readerChunkSize-100)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, n)
+		assert.Equal(t, content[readerChunkSize-100:readerChunkSize+100], p)
+	})
+
+	t.Run("happy path - end of blob", func(t *testing.T) {
+		r, err := NewReaderAt(ctx, s, "shorts/abc.mp3")
+		assert.NoError(t, err)
+
+		p := make([]byte, 200)
+		n, err := r.ReadAt(p, int64(len(content)-50))
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, 50, n)
+		assert.Equal(t, content[len(content)-50:], p[:n])
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		r, err := NewReaderAt(ctx, s, "shorts/missing.mp3")
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestURLReaderAt(t *testing.T) {
+	content := blob()
+	ranged := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abc.mp3":
+			if r.Header.Get("Range") != "" {
+				ranged++
+			}
+			http.ServeContent(w, r, "abc.mp3", time.Time{}, bytes.NewReader(content))
+		case "/unranged.mp3":
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	maxSize := int64(len(content))
+
+	t.Run("happy path", func(t *testing.T) {
+		r, err := NewURLReaderAt(ctx, srv.Client(), srv.URL+"/abc.mp3", maxSize)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), r.Size())
+
+		p := make([]byte, 200)
+		n, err := r.ReadAt(p, 2*readerChunkSize-100)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, n)
+		assert.Equal(t, content[2*readerChunkSize-100:2*readerChunkSize+100], p)
+		assert.Equal(t, 2, ranged)
+	})
+
+	t.Run("happy path - ranges ignored at the start", func(t *testing.T) {
+		r, err := NewURLReaderAt(ctx, srv.Client(), srv.URL+"/unranged.mp3", maxSize)
+		assert.NoError(t, err)
+
+		p := make([]byte, 10)
+		_, err = r.ReadAt(p, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, content[:10], p)
+		_, err = r.ReadAt(p, readerChunkSize)
+		assert.Error(t, err)
+	})
+
+	t.Run("sad path - too large", func(t *testing.T) {
+		r, err := NewURLReaderAt(ctx, srv.Client(), srv.URL+"/abc.mp3", maxSize-1)
+		assert.Equal(t, ErrTooLarge, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		r, err := NewURLReaderAt(ctx, srv.Client(), srv.URL+"/missing.mp3", maxSize)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("sad path - not http", func(t *testing.T) {
+		r, err := NewURLReaderAt(ctx, srv.Client(), "file:///etc/passwd", maxSize)
+		assert.Error(t, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestReadSeeker(t *testing.T) {
+	content := blob()
+	s, err := NewLocalStore(t.TempDir(), "http://localhost:8080/files/")
+	assert.NoError(t, err)
+	ctx := context.Background()
+	_, err = s.Put(ctx, "shorts/abc.mp3", bytes.NewReader(content), "audio/mpeg")
+	assert.NoError(t, err)
+
+	t.Run("happy path - seek and read", func(t *testing.T) {
+		r, err := NewReadSeeker(ctx, s, "shorts/abc.mp3")
+		assert.NoError(t, err)
+		defer r.Close()
+		assert.Equal(t, int64(len(content)), r.Info().Size)
+
+		size, err := r.Seek(0, io.SeekEnd)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), size)
+
+		offset, err := r.Seek(readerChunkSize, io.SeekStart)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(readerChunkSize), offset)
+		p := make([]byte, 100)
+		_, err = io.ReadFull(r, p)
+		assert.NoError(t, err)
+		assert.Equal(t, content[readerChunkSize:readerChunkSize+100], p)
+
+		_, err = r.Seek(-200, io.SeekCurrent)
+		assert.NoError(t, err)
+		_, err = io.ReadFull(r, p)
+		assert.NoError(t, err)
+		assert.Equal(t, content[readerChunkSize-100:readerChunkSize], p)
+
+		_, err = r.Seek(-50, io.SeekEnd)
+		assert.NoError(t, err)
+		rest, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, content[len(content)-50:], rest)
+	})
+
+	t.Run("sad path - negative offset", func(t *testing.T) {
+		r, err := NewReadSeeker(ctx, s, "shorts/abc.mp3")
+		assert.NoError(t, err)
+
+		_, err = r.Seek(-1, io.SeekStart)
+		assert.Error(t, err)
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		r, err := NewReadSeeker(ctx, s, "shorts/missing.mp3")
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, r)
+	})
+}
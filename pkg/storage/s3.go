@@ -0,0 +1,494 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidS3Config = "S3 endpoint, region, bucket and credentials are required"
+	ErrorMessageUnexpectedS3    = "Unexpected S3 response"
+)
+
+const (
+	s3Algorithm   = "AWS4-HMAC-SHA256"
+	s3Service     = "s3"
+	s3TimeFormat  = "20060102T150405Z"
+	s3DateFormat  = "20060102"
+	s3MaxExpiry   = 7 * 24 * time.Hour
+	s3EmptyHash   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3Unsigned    = "UNSIGNED-PAYLOAD"
+	s3HeaderDate  = "X-Amz-Date"
+	s3HeaderHash  = "X-Amz-Content-Sha256"
+	s3HeaderAuth  = "Authorization"
+	s3HeaderRange = "Range"
+)
+
+// S3Config locates a bucket of an S3-compatible object store, e.g. AWS S3 or MinIO
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// BaseURL is the public URL of the bucket, e.g. a CDN in front of it; it defaults to the bucket URL
+	BaseURL string
+}
+
+// S3Store stores blobs as objects of a bucket, addressed path-style so that it works with any S3-compatible store;
+// requests are signed with AWS Signature Version 4
+type S3Store struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	baseURL   string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Region == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New(ErrorMessageInvalidS3Config)
+	}
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	_, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageInvalidS3Config)
+	}
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = endpoint + "/" + cfg.Bucket
+	}
+	return &S3Store{
+		endpoint:  endpoint,
+		region:    cfg.Region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		baseURL:   baseURL,
+		client:    http.DefaultClient,
+		now:       time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, contentType string) (url string, err error) {
+	err = validateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	// the payload is spooled to a temporary file, as its size and hash must be known before it is sent
+	tmp, err := ioutil.TempFile("", "s3-upload-*")
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), &contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+
+	var body io.Reader = http.NoBody
+	if size > 0 {
+		body = ioutil.NopCloser(tmp)
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	resp, err := s.do(req, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessagePutFailed)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Wrap(unexpected(resp), ErrorMessagePutFailed)
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string, offset, length int64) (r io.ReadCloser, info *BlobInfo, err error) {
+	err = validateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if offset < 0 {
+		return nil, nil, ErrInvalidRange
+	}
+	if length == 0 {
+		info, err = s.Stat(ctx, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if offset > 0 && offset >= info.Size {
+			return nil, nil, ErrInvalidRange
+		}
+		return ioutil.NopCloser(strings.NewReader("")), info, nil
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrorMessageGetFailed)
+	}
+	if offset > 0 || length > 0 {
+		end := ""
+		if length > 0 {
+			end = strconv.FormatInt(offset+length-1, 10)
+		}
+		req.Header.Set(s3HeaderRange, "bytes="+strconv.FormatInt(offset, 10)+"-"+end)
+	}
+	resp, err := s.do(req, s3EmptyHash)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrorMessageGetFailed)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		info, err = s.info(key, resp)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, nil, errors.Wrap(err, ErrorMessageGetFailed)
+		}
+		return resp.Body, info, nil
+	case http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, nil, ErrNotFound
+	case http.StatusRequestedRangeNotSatisfiable:
+		_ = resp.Body.Close()
+		return nil, nil, ErrInvalidRange
+	}
+	defer resp.Body.Close()
+	return nil, nil, errors.Wrap(unexpected(resp), ErrorMessageGetFailed)
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (info *BlobInfo, err error) {
+	err = validateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageStatFailed)
+	}
+	resp, err := s.do(req, s3EmptyHash)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageStatFailed)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		info, err = s.info(key, resp)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageStatFailed)
+		}
+		return info, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	}
+	return nil, errors.Wrap(unexpected(resp), ErrorMessageStatFailed)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	err := validateKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageDeleteFailed)
+	}
+	resp, err := s.do(req, s3EmptyHash)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageDeleteFailed)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return errors.Wrap(unexpected(resp), ErrorMessageDeleteFailed)
+}
+
+// DeletePrefix lists the objects under the prefix a page at a time, and deletes them one by one
+func (s *S3Store) DeletePrefix(ctx context.Context, prefix string) error {
+	err := validatePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	token := ""
+	for {
+		var keys []string
+		keys, token, err = s.list(ctx, prefix, token)
+		if err != nil {
+			return errors.Wrap(err, ErrorMessageDeleteFailed)
+		}
+		for _, key := range keys {
+			err = s.Delete(ctx, key)
+			if err != nil {
+				return err
+			}
+		}
+		if token == "" {
+			return nil
+		}
+	}
+}
+
+// s3ListResult is the page of objects of a ListObjectsV2 response
+type s3ListResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// list returns the keys of a page of the objects under the prefix, and the token of the next page, if any
+func (s *S3Store) list(ctx context.Context, prefix, token string) (keys []string, next string, err error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		s.endpoint+"/"+uriEncode(s.bucket, false)+"?"+canonicalQuery(query), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := s.do(req, s3EmptyHash)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", unexpected(resp)
+	}
+	var result s3ListResult
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, "", errors.Wrap(err, ErrorMessageUnexpectedS3)
+	}
+	if result.IsTruncated && result.NextContinuationToken == "" {
+		return nil, "", errors.New(ErrorMessageUnexpectedS3 + ": truncated listing without a continuation token")
+	}
+	for _, object := range result.Contents {
+		keys = append(keys, object.Key)
+	}
+	if result.IsTruncated {
+		next = result.NextContinuationToken
+	}
+	return keys, next, nil
+}
+
+// SignedURL returns a presigned GET URL of the object, e.g. for private buckets behind no CDN; S3 caps the expiry at
+// 7 days
+func (s *S3Store) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	err := validateKey(key)
+	if err != nil {
+		return "", err
+	}
+	if expiry <= 0 || expiry > s3MaxExpiry {
+		return "", errors.New(ErrorMessageSignFailed + ": expiry must be between 1s and " + s3MaxExpiry.String())
+	}
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return "", errors.Wrap(err, ErrorMessageSignFailed)
+	}
+	return s.presign(u, s.now(), expiry), nil
+}
+
+func (s *S3Store) Key(url string) (string, bool) {
+	return keyOf(s.baseURL, url)
+}
+
+func (s *S3Store) objectURL(key string) string {
+	return s.endpoint + "/" + uriEncode(s.bucket+"/"+key, false)
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, s.objectURL(key), body)
+}
+
+// do signs the request with the hex SHA-256 of its payload and sends it
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, s.now())
+	return s.client.Do(req)
+}
+
+// sign adds the date, payload hash and Authorization headers to the request
+func (s *S3Store) sign(req *http.Request, payloadHash string, t time.Time) {
+	t = t.UTC()
+	req.Header.Set(s3HeaderDate, t.Format(s3TimeFormat))
+	req.Header.Set(s3HeaderHash, payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := s.scope(t)
+	req.Header.Set(s3HeaderAuth, s3Algorithm+" Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+s.signature(t, canonicalRequest))
+}
+
+// presign returns the URL with the query parameters of a presigned request, which only signs the host header
+func (s *S3Store) presign(u *url.URL, t time.Time, expiry time.Duration) string {
+	t = t.UTC()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(t))
+	query.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		uriEncode(u.Path, false),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3Unsigned,
+	}, "\n")
+	return u.Scheme + "://" + u.Host + uriEncode(u.Path, false) + "?" + canonicalQuery(query) +
+		"&X-Amz-Signature=" + s.signature(t, canonicalRequest)
+}
+
+func (s *S3Store) scope(t time.Time) string {
+	return t.Format(s3DateFormat) + "/" + s.region + "/" + s3Service + "/aws4_request"
+}
+
+// signature signs the canonical request with the key derived from the secret key, date, region and service
+func (s *S3Store) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + t.Format(s3TimeFormat) + "\n" + s.scope(t) + "\n" + hex.EncodeToString(hash[:])
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), t.Format(s3DateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// info describes the object from the headers of a GET or HEAD response
+func (s *S3Store) info(key string, resp *http.Response) (*BlobInfo, error) {
+	size := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 0-9/1234
+		contentRange := resp.Header.Get("Content-Range")
+		i := strings.LastIndex(contentRange, "/")
+		if i < 0 {
+			return nil, errors.New(ErrorMessageUnexpectedS3 + ": Content-Range " + contentRange)
+		}
+		var err error
+		size, err = strconv.ParseInt(contentRange[i+1:], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUnexpectedS3+": Content-Range "+contentRange)
+		}
+	}
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = contentTypeOf(key)
+	}
+	return &BlobInfo{
+		Key:          key,
+		Size:         size,
+		ContentType:  contentType,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: lastModified,
+	}, nil
+}
+
+// unexpected returns the status and the start of the body of an error response
+func unexpected(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return errors.New(ErrorMessageUnexpectedS3 + ": " + resp.Status + " " + strings.TrimSpace(string(body)))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery returns the query sorted by name, with names and values URI encoded
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pairs []string
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters of RFC 3986, and the slashes of paths
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidKey     = "Invalid blob key"
+	ErrorMessageInvalidBackend = "Unknown storage backend"
+	ErrorMessagePutFailed      = "Failed to store blob"
+	ErrorMessageGetFailed      = "Failed to read blob"
+	ErrorMessageStatFailed     = "Failed to stat blob"
+	ErrorMessageDeleteFailed   = "Failed to delete blob"
+	ErrorMessageSignFailed     = "Failed to sign blob URL"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	// ErrNotFound is returned when there is no blob under the key
+	ErrNotFound = errors.New("Blob not found")
+	// ErrInvalidRange is returned when the requested range starts after the end of the blob
+	ErrInvalidRange = errors.New("Invalid blob range")
+	// ErrTooLarge is returned when a file read from a URL is larger than allowed
+	ErrTooLarge = errors.New("File is too large")
+)
+
+// BlobStore stores the audio files of shorts and the images of shorts and creators under keys, e.g. "shorts/1f2e.mp3"
+type BlobStore interface {
+	// Put stores the content read from r under the key, and returns the URL it is served at
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (url string, err error)
+	// Get reads length bytes of the blob starting at offset; a negative length reads to the end of the blob.
+	// The info describes the whole blob, not the range
+	Get(ctx context.Context, key string, offset, length int64) (r io.ReadCloser, info *BlobInfo, err error)
+	// Stat describes the blob of the key
+	Stat(ctx context.Context, key string) (info *BlobInfo, err error)
+	// Delete removes the blob of the key; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every blob whose key starts with the prefix, which must end with a slash, e.g. "hls/1/";
+	// deleting a prefix without blobs is not an error
+	DeletePrefix(ctx context.Context, prefix string) error
+	// Key returns the key of a URL returned by Put, and false for URLs that are not served by the store
+	Key(url string) (key string, ok bool)
+}
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// New returns the blob store of the configured backend
+func New(cfg *config.Config) (BlobStore, error) {
+	switch cfg.Storage.Backend {
+	case BackendLocal:
+		return NewLocalStore(cfg.Storage.Dir, cfg.Storage.BaseURL)
+	case BackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			Bucket:    cfg.Storage.S3.Bucket,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			BaseURL:   cfg.Storage.S3.BaseURL,
+		})
+	}
+	return nil, errors.New(ErrorMessageInvalidBackend + ": " + cfg.Storage.Backend)
+}
+
+// validateKey checks that the key is a relative slash-separated path that does not escape its root
+func validateKey(key string) error {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return errors.New(ErrorMessageInvalidKey + ": " + key)
+	}
+	return nil
+}
+
+// validatePrefix checks that the prefix is a valid key followed by a slash
+func validatePrefix(prefix string) error {
+	if !strings.HasSuffix(prefix, "/") || validateKey(strings.TrimSuffix(prefix, "/")) != nil {
+		return errors.New(ErrorMessageInvalidKey + ": " + prefix)
+	}
+	return nil
+}
+
+// keyOf returns the key of a URL under the base URL
+func keyOf(baseURL, url string) (string, bool) {
+	if !strings.HasPrefix(url, baseURL+"/") {
+		return "", false
+	}
+	key := strings.TrimPrefix(url, baseURL+"/")
+	if validateKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// contentTypes are the content types of the blobs, by extension, when the backend does not keep them
+var contentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".flac": "audio/flac",
+	".m3u8": "application/vnd.apple.mpegurl",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".webp": "image/webp",
+}
+
+func contentTypeOf(key string) string {
+	contentType, ok := contentTypes[path.Ext(key)]
+	if !ok {
+		return "application/octet-stream"
+	}
+	return contentType
+}
@@ -0,0 +1,267 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=categories.go -destination=categories_mock.go -package=store CategoriesStore
+
+// CategoriesStore is the repository for the categories of shorts, which admins create, rename, archive and reorder;
+// shorts reference categories by their slug, which never changes
+type (
+	CategoriesStore interface {
+		// GetAll returns the categories by ascending position, with the archived ones when included
+		GetAll(ctx context.Context, includeArchived bool) (categories []*model.Category, err error)
+		// GetNames returns the localized names of the category by locale, empty when it has none
+		GetNames(ctx context.Context, id string) (names []*model.LocalizedName, err error)
+		// Create inserts a new category after the others, with its localized names
+		Create(ctx context.Context, input *model.CategoryInput) (category *model.Category, err error)
+		// Rename updates the name of the category, and replaces its localized names unless they are nil
+		Rename(ctx context.Context, id string, name string, names []*model.LocalizedNameInput) (category *model.Category, err error)
+		// SetArchived archives or unarchives the category
+		SetArchived(ctx context.Context, id string, archived bool) (category *model.Category, err error)
+		// Reorder moves the categories to the positions of their IDs in the list, and returns them all in their new order
+		Reorder(ctx context.Context, ids []string) (categories []*model.Category, err error)
+	}
+
+	categoriesStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewCategoriesStore(db *sql.DB) (CategoriesStore, error) {
+	return &categoriesStore{
+		db: db,
+	}, nil
+}
+
+func (s *categoriesStore) GetAll(ctx context.Context, includeArchived bool) (categories []*model.Category, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	categories, err = findCategories(ctx, tx, includeArchived)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *categoriesStore) GetNames(ctx context.Context, id string) (names []*model.LocalizedName, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	names, err = findCategoryNames(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" category ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *categoriesStore) Create(ctx context.Context, input *model.CategoryInput) (category *model.Category, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	id, err := createCategory(ctx, tx, input)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCreateFailed)
+	}
+	err = saveCategoryNames(ctx, tx, id, input.Names)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCreateFailed+" ID:"+id)
+	}
+	category, err = findCategoryByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *categoriesStore) Rename(ctx context.Context, id string, name string, names []*model.LocalizedNameInput) (category *model.Category, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = renameCategory(ctx, tx, id, name)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	category, err = findCategoryByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+	if names != nil {
+		err = saveCategoryNames(ctx, tx, id, names)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *categoriesStore) SetArchived(ctx context.Context, id string, archived bool) (category *model.Category, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = setCategoryArchived(ctx, tx, id, archived)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	category, err = findCategoryByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *categoriesStore) Reorder(ctx context.Context, ids []string) (categories []*model.Category, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = reorderCategories(ctx, tx, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed)
+	}
+	categories, err = findCategories(ctx, tx, true)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: categories.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockCategoriesStore is a mock of CategoriesStore interface.
+type MockCategoriesStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoriesStoreMockRecorder
+}
+
+// MockCategoriesStoreMockRecorder is the mock recorder for MockCategoriesStore.
+type MockCategoriesStoreMockRecorder struct {
+	mock *MockCategoriesStore
+}
+
+// NewMockCategoriesStore creates a new mock instance.
+func NewMockCategoriesStore(ctrl *gomock.Controller) *MockCategoriesStore {
+	mock := &MockCategoriesStore{ctrl: ctrl}
+	mock.recorder = &MockCategoriesStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategoriesStore) EXPECT() *MockCategoriesStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCategoriesStore) Create(ctx context.Context, input *model.CategoryInput) (*model.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, input)
+	ret0, _ := ret[0].(*model.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCategoriesStoreMockRecorder) Create(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCategoriesStore)(nil).Create), ctx, input)
+}
+
+// GetAll mocks base method.
+func (m *MockCategoriesStore) GetAll(ctx context.Context, includeArchived bool) ([]*model.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, includeArchived)
+	ret0, _ := ret[0].([]*model.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockCategoriesStoreMockRecorder) GetAll(ctx, includeArchived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCategoriesStore)(nil).GetAll), ctx, includeArchived)
+}
+
+// GetNames mocks base method.
+func (m *MockCategoriesStore) GetNames(ctx context.Context, id string) ([]*model.LocalizedName, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNames", ctx, id)
+	ret0, _ := ret[0].([]*model.LocalizedName)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNames indicates an expected call of GetNames.
+func (mr *MockCategoriesStoreMockRecorder) GetNames(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNames", reflect.TypeOf((*MockCategoriesStore)(nil).GetNames), ctx, id)
+}
+
+// Rename mocks base method.
+func (m *MockCategoriesStore) Rename(ctx context.Context, id, name string, names []*model.LocalizedNameInput) (*model.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, id, name, names)
+	ret0, _ := ret[0].(*model.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockCategoriesStoreMockRecorder) Rename(ctx, id, name, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockCategoriesStore)(nil).Rename), ctx, id, name, names)
+}
+
+// Reorder mocks base method.
+func (m *MockCategoriesStore) Reorder(ctx context.Context, ids []string) ([]*model.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", ctx, ids)
+	ret0, _ := ret[0].([]*model.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockCategoriesStoreMockRecorder) Reorder(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockCategoriesStore)(nil).Reorder), ctx, ids)
+}
+
+// SetArchived mocks base method.
+func (m *MockCategoriesStore) SetArchived(ctx context.Context, id string, archived bool) (*model.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArchived", ctx, id, archived)
+	ret0, _ := ret[0].(*model.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetArchived indicates an expected call of SetArchived.
+func (mr *MockCategoriesStoreMockRecorder) SetArchived(ctx, id, archived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArchived", reflect.TypeOf((*MockCategoriesStore)(nil).SetArchived), ctx, id, archived)
+}
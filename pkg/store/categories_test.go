@@ -0,0 +1,308 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoriesStore_GetAll(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCategoriesStore(db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "slug", "name", "position", "archived"}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, slug, name, position, archived FROM categories WHERE NOT archived ORDER BY position, id")).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "news", "News", 1, false).
+				AddRow("5", "comedy", "Comedy", 2, false))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Category{
+			{ID: "1", Slug: "news", Name: "News", Position: 1},
+			{ID: "5", Slug: "comedy", Name: "Comedy", Position: 2},
+		}, resp)
+	})
+
+	t.Run("happy path - include archived", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, slug, name, position, archived FROM categories ORDER BY position, id")).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("2", "gossip", "Gossip", 1, true))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Category{{ID: "2", Slug: "gossip", Name: "Gossip", Position: 1, Archived: true}}, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, slug, name, position, archived FROM categories")).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, false)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCategoriesStore_GetNames(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCategoriesStore(db)
+	assert.NoError(t, err)
+
+	query := "SELECT locale, name FROM category_names WHERE category_id = $1 ORDER BY locale"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"locale", "name"}).
+				AddRow("de", "Nachrichten").
+				AddRow("pt-br", "Notícias"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetNames(ctx, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.LocalizedName{{Locale: "de", Name: "Nachrichten"}, {Locale: "pt-br", Name: "Notícias"}}, resp)
+	})
+
+	t.Run("happy path - no names", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"locale", "name"}))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetNames(ctx, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.LocalizedName{}, resp)
+	})
+}
+
+func TestCategoriesStore_Create(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCategoriesStore(db)
+	assert.NoError(t, err)
+
+	input := &model.CategoryInput{
+		Slug:  "comedy",
+		Name:  "Comedy",
+		Names: []*model.LocalizedNameInput{{Locale: "de", Name: "Komödie"}},
+	}
+	insert := "INSERT INTO categories( slug, name, position ) SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM categories RETURNING id"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(insert)).
+			WithArgs("comedy", "Comedy").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("5"))
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM category_names WHERE category_id = $1")).
+			WithArgs("5").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO category_names( category_id, locale, name ) VALUES ($1, $2, $3 )")).
+			WithArgs("5", "de", "Komödie").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT slug, name, position, archived FROM categories WHERE id = $1")).
+			WithArgs("5").
+			WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "position", "archived"}).AddRow("comedy", "Comedy", 5, false))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Category{ID: "5", Slug: "comedy", Name: "Comedy", Position: 5}, resp)
+	})
+
+	t.Run("sad path - duplicate slug", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(insert)).
+			WithArgs("comedy", "Comedy").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "categories_slug_key"})
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input)
+
+		var conflict *ConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCategoriesStore_Rename(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCategoriesStore(db)
+	assert.NoError(t, err)
+
+	update := "UPDATE categories SET name = $1 WHERE id = $2"
+	find := "SELECT slug, name, position, archived FROM categories WHERE id = $1"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(update)).
+			WithArgs("Breaking news", "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(find)).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "position", "archived"}).AddRow("news", "Breaking news", 1, false))
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM category_names WHERE category_id = $1")).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Rename(ctx, "1", "Breaking news", []*model.LocalizedNameInput{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Breaking news", resp.Name)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("happy path - names left", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(update)).
+			WithArgs("Breaking news", "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(find)).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "position", "archived"}).AddRow("news", "Breaking news", 1, false))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Rename(ctx, "1", "Breaking news", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Breaking news", resp.Name)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(update)).
+			WithArgs("Breaking news", "9").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(find)).
+			WithArgs("9").
+			WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Rename(ctx, "9", "Breaking news", nil)
+
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCategoriesStore_SetArchived(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCategoriesStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE categories SET archived = $1 WHERE id = $2")).
+			WithArgs(true, "2").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT slug, name, position, archived FROM categories WHERE id = $1")).
+			WithArgs("2").
+			WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "position", "archived"}).AddRow("gossip", "Gossip", 2, true))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.SetArchived(ctx, "2", true)
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Archived)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE categories SET archived = $1 WHERE id = $2")).
+			WithArgs(false, "2").
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.SetArchived(ctx, "2", false)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCategoriesStore_Reorder(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCategoriesStore(db)
+	assert.NoError(t, err)
+
+	reorder := "UPDATE categories AS g SET position = o.position FROM unnest($1::int[]) WITH ORDINALITY AS o(id, position) WHERE g.id = o.id"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(reorder)).
+			WithArgs(pq.Array([]string{"2", "1"})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, slug, name, position, archived FROM categories ORDER BY position, id")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "name", "position", "archived"}).
+				AddRow("2", "gossip", "Gossip", 1, false).
+				AddRow("1", "news", "News", 2, false))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Reorder(ctx, []string{"2", "1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Category{
+			{ID: "2", Slug: "gossip", Name: "Gossip", Position: 1},
+			{ID: "1", Slug: "news", Name: "News", Position: 2},
+		}, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(reorder)).
+			WithArgs(pq.Array([]string{"2", "1"})).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Reorder(ctx, []string{"2", "1"})
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
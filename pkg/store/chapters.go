@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=chapters.go -destination=chapters_mock.go -package=store ChaptersStore
+
+// ChaptersStore is the repository for the chapters of shorts, kept in order with their start and end to the millisecond
+type (
+	ChaptersStore interface {
+		// Get returns the chapters of the short in order, empty when none were set
+		Get(ctx context.Context, shortID string) (chapters []*model.Chapter, err error)
+		// Save replaces the chapters of the short
+		Save(ctx context.Context, shortID string, chapters []*model.Chapter) (err error)
+		// Delete drops the chapters of the short, if any
+		Delete(ctx context.Context, shortID string) (err error)
+	}
+
+	chaptersStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewChaptersStore(db *sql.DB) (ChaptersStore, error) {
+	return &chaptersStore{
+		db: db,
+	}, nil
+}
+
+func (s *chaptersStore) Get(ctx context.Context, shortID string) (chapters []*model.Chapter, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	chapters, err = findChapters(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *chaptersStore) Save(ctx context.Context, shortID string, chapters []*model.Chapter) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = saveChapters(ctx, tx, shortID, chapters)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *chaptersStore) Delete(ctx context.Context, shortID string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = deleteChapters(ctx, tx, shortID)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageDeleteFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: chapters.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockChaptersStore is a mock of ChaptersStore interface.
+type MockChaptersStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockChaptersStoreMockRecorder
+}
+
+// MockChaptersStoreMockRecorder is the mock recorder for MockChaptersStore.
+type MockChaptersStoreMockRecorder struct {
+	mock *MockChaptersStore
+}
+
+// NewMockChaptersStore creates a new mock instance.
+func NewMockChaptersStore(ctrl *gomock.Controller) *MockChaptersStore {
+	mock := &MockChaptersStore{ctrl: ctrl}
+	mock.recorder = &MockChaptersStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChaptersStore) EXPECT() *MockChaptersStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockChaptersStore) Delete(ctx context.Context, shortID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, shortID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockChaptersStoreMockRecorder) Delete(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockChaptersStore)(nil).Delete), ctx, shortID)
+}
+
+// Get mocks base method.
+func (m *MockChaptersStore) Get(ctx context.Context, shortID string) ([]*model.Chapter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].([]*model.Chapter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockChaptersStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockChaptersStore)(nil).Get), ctx, shortID)
+}
+
+// Save mocks base method.
+func (m *MockChaptersStore) Save(ctx context.Context, shortID string, chapters []*model.Chapter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, shortID, chapters)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockChaptersStoreMockRecorder) Save(ctx, shortID, chapters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockChaptersStore)(nil).Save), ctx, shortID, chapters)
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaptersStore_Get(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewChaptersStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT start_ms, end_ms, title, image_url FROM chapters WHERE short_id = $1 ORDER BY position")
+	columns := []string{"start_ms", "end_ms", "title", "image_url"}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(0, 1500, "Intro", nil).
+				AddRow(1500, 3000, "Outro", "https://example.com/outro.png"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		imageURL := "https://example.com/outro.png"
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Chapter{
+			{Start: 0, End: 1.5, Title: "Intro"},
+			{Start: 1.5, End: 3, Title: "Outro", ImageURL: &imageURL},
+		}, resp)
+	})
+
+	t.Run("happy path - not set", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows(columns))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Chapter{}, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestChaptersStore_Save(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewChaptersStore(db)
+	assert.NoError(t, err)
+
+	deleteQuery := regexp.QuoteMeta("DELETE FROM chapters WHERE short_id = $1")
+	insertQuery := regexp.QuoteMeta("INSERT INTO chapters( short_id, position, start_ms, end_ms, title, image_url ) VALUES ($1, $2, $3, $4, $5, $6 )")
+	imageURL := "https://example.com/outro.png"
+	chapters := []*model.Chapter{
+		{Start: 0, End: 1.5, Title: "Intro"},
+		{Start: 1.5, End: 3.0004, Title: "Outro", ImageURL: &imageURL},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(deleteQuery).
+			WithArgs(shortID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectExec(insertQuery).
+			WithArgs(shortID, 0, 0, 1500, "Intro", nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(insertQuery).
+			WithArgs(shortID, 1, 1500, 3000, "Outro", imageURL).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Save(ctx, shortID, chapters)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(deleteQuery).
+			WithArgs(shortID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectExec(insertQuery).
+			WithArgs(shortID, 0, 0, 1500, "Intro", nil).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Save(ctx, shortID, chapters)
+
+		assert.Error(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestChaptersStore_Delete(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewChaptersStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM chapters WHERE short_id = $1")).
+			WithArgs(shortID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Delete(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
@@ -14,7 +14,16 @@ import (
 // CreatorsStore is the repository for creators
 type (
 	CreatorsStore interface {
-		GetAll(ctx context.Context, page, limit uint16) (shorts []*model.Creator, err error)
+		// GetByID returns the entry corresponding to the given ID
+		GetByID(ctx context.Context, id string) (creator *model.Creator, err error)
+		// GetAll returns up to first entries after the given cursor, newest first
+		GetAll(ctx context.Context, first uint16, after *Cursor) (creators *model.CreatorConnection, err error)
+		// Create inserts a new active entry into the table
+		Create(ctx context.Context, input *model.CreatorDetailsInput) (creator *model.Creator, err error)
+		// Update updates the details of the entry
+		Update(ctx context.Context, id string, input *model.CreatorDetailsInput) (creator *model.Creator, err error)
+		// SetStatus updates the status of the entry; when banning with cascade, the active shorts of the creator are banned too
+		SetStatus(ctx context.Context, id string, status model.CreatorStatus, cascade bool) (creator *model.Creator, err error)
 	}
 
 	creatorsStore struct {
@@ -29,18 +38,20 @@ func NewCreatorsStore(db *sql.DB) (*creatorsStore, error) {
 	}, nil
 }
 
-func (s *creatorsStore) GetAll(ctx context.Context, page, limit uint16) (creators []*model.Creator, err error) {
+func (s *creatorsStore) GetByID(ctx context.Context, id string) (creator *model.Creator, err error) {
 	s.RLock()
 	defer s.RUnlock()
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -48,10 +59,178 @@ func (s *creatorsStore) GetAll(ctx context.Context, page, limit uint16) (creator
 		}
 	}()
 
-	creators, err = findAllCreators(ctx, tx, page, limit)
+	creator, err = findCreatorByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *creatorsStore) GetAll(ctx context.Context, first uint16, after *Cursor) (creators *model.CreatorConnection, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	// fetch one extra entry to know if there is a next page
+	edges, err := findAllCreators(ctx, tx, first+1, after)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrorMessageFindFailed)
 	}
+	count, err := countCreators(ctx, tx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCountFailed)
+	}
+
+	creators = &model.CreatorConnection{
+		PageInfo:   &model.PageInfo{},
+		TotalCount: count,
+	}
+	if len(edges) > int(first) {
+		edges = edges[:first]
+		creators.PageInfo.HasNextPage = true
+	}
+	if len(edges) > 0 {
+		creators.PageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+	creators.Edges = edges
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *creatorsStore) Create(ctx context.Context, input *model.CreatorDetailsInput) (creator *model.Creator, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	id, err := createCreator(ctx, tx, input)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCreateFailed)
+	}
+	creator, err = findCreatorByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *creatorsStore) Update(ctx context.Context, id string, input *model.CreatorDetailsInput) (creator *model.Creator, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = updateCreator(ctx, tx, id, input)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	creator, err = findCreatorByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *creatorsStore) SetStatus(ctx context.Context, id string, status model.CreatorStatus, cascade bool) (creator *model.Creator, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = setCreatorStatus(ctx, tx, id, status)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	if cascade && status == model.CreatorStatusBanned {
+		err = banCreatorShorts(ctx, tx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" shorts of ID:"+id)
+		}
+	}
+	creator, err = findCreatorByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
 
 	err = tx.Commit()
 	if err != nil {
@@ -35,17 +35,77 @@ func (m *MockCreatorsStore) EXPECT() *MockCreatorsStoreMockRecorder {
 	return m.recorder
 }
 
+// Create mocks base method.
+func (m *MockCreatorsStore) Create(ctx context.Context, input *model.CreatorDetailsInput) (*model.Creator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, input)
+	ret0, _ := ret[0].(*model.Creator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCreatorsStoreMockRecorder) Create(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCreatorsStore)(nil).Create), ctx, input)
+}
+
 // GetAll mocks base method.
-func (m *MockCreatorsStore) GetAll(ctx context.Context, page, limit uint16) ([]*model.Creator, error) {
+func (m *MockCreatorsStore) GetAll(ctx context.Context, first uint16, after *Cursor) (*model.CreatorConnection, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx, page, limit)
-	ret0, _ := ret[0].([]*model.Creator)
+	ret := m.ctrl.Call(m, "GetAll", ctx, first, after)
+	ret0, _ := ret[0].(*model.CreatorConnection)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockCreatorsStoreMockRecorder) GetAll(ctx, page, limit interface{}) *gomock.Call {
+func (mr *MockCreatorsStoreMockRecorder) GetAll(ctx, first, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCreatorsStore)(nil).GetAll), ctx, first, after)
+}
+
+// GetByID mocks base method.
+func (m *MockCreatorsStore) GetByID(ctx context.Context, id string) (*model.Creator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*model.Creator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockCreatorsStoreMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCreatorsStore)(nil).GetByID), ctx, id)
+}
+
+// SetStatus mocks base method.
+func (m *MockCreatorsStore) SetStatus(ctx context.Context, id string, status model.CreatorStatus, cascade bool) (*model.Creator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, id, status, cascade)
+	ret0, _ := ret[0].(*model.Creator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockCreatorsStoreMockRecorder) SetStatus(ctx, id, status, cascade interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockCreatorsStore)(nil).SetStatus), ctx, id, status, cascade)
+}
+
+// Update mocks base method.
+func (m *MockCreatorsStore) Update(ctx context.Context, id string, input *model.CreatorDetailsInput) (*model.Creator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, input)
+	ret0, _ := ret[0].(*model.Creator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCreatorsStoreMockRecorder) Update(ctx, id, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCreatorsStore)(nil).GetAll), ctx, page, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCreatorsStore)(nil).Update), ctx, id, input)
 }
@@ -4,17 +4,71 @@ import (
 	"context"
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
 	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"regexp"
 	"testing"
 )
 
+func TestCreatorsStore_GetByID(t *testing.T) {
+	var (
+		ID       = "1"
+		username = "jackfrost"
+		name     = "hi"
+		email    = "mockemail@gmail.com"
+		status   = model.CreatorStatusActive
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCreatorsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT username, name, email, status FROM creators WHERE id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "email", "status"}).
+				AddRow(username, name, email, status))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetByID(ctx, ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ID, resp.ID)
+		assert.Equal(t, username, resp.Username)
+		assert.Equal(t, name, resp.Name)
+		assert.Equal(t, email, resp.Email)
+		assert.Equal(t, status, resp.Status)
+	})
+
+	t.Run("sad path - no rows", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT username, name, email, status FROM creators WHERE id = $1")).
+			WithArgs(ID).
+			WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetByID(ctx, ID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
 func TestCreatorsStore_GetAll(t *testing.T) {
 	var (
-		ID    = "1"
-		name  = "hi"
-		email = "mockemail@gmail.com"
+		ID        = "1"
+		username  = "jackfrost"
+		name      = "hi"
+		email     = "mockemail@gmail.com"
+		status    = model.CreatorStatusActive
+		createdAt = "2021-01-02 03:04:05.123456+00"
 	)
 	db, sqlMock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -24,32 +78,246 @@ func TestCreatorsStore_GetAll(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT id, name, email FROM creators ORDER BY id ASC LIMIT $1 OFFSET $2")).
-			WithArgs(1, 0).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
-				AddRow(ID, name, email))
+			regexp.QuoteMeta("SELECT id, username, name, email, status, created_at::text FROM creators ORDER BY created_at DESC, id DESC LIMIT $1")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "name", "email", "status", "created_at"}).
+				AddRow(ID, username, name, email, status, createdAt))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM creators")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(resp.Edges))
+		assert.Equal(t, 1, resp.TotalCount)
+		assert.False(t, resp.PageInfo.HasNextPage)
+		assert.Equal(t, ID, resp.Edges[0].Node.ID)
+		assert.Equal(t, name, resp.Edges[0].Node.Name)
+		assert.Equal(t, email, resp.Edges[0].Node.Email)
+		assert.Equal(t, username, resp.Edges[0].Node.Username)
+		assert.Equal(t, status, resp.Edges[0].Node.Status)
+	})
+
+	t.Run("happy path - after cursor", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT id, username, name, email, status, created_at::text FROM creators WHERE (created_at, id) < ($2::timestamptz, $3::int) ORDER BY created_at DESC, id DESC LIMIT $1")).
+			WithArgs(2, createdAt, "2").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "name", "email", "status", "created_at"}).
+				AddRow(ID, username, name, email, status, createdAt))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM creators")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.GetAll(ctx, 0, 1)
+		resp, err := store.GetAll(ctx, 1, &Cursor{Key: CreatorsOrderKey, Value: createdAt, ID: "2"})
 
 		assert.NoError(t, err)
-		assert.Equal(t, 1, len(resp))
-		assert.Equal(t, ID, resp[0].ID)
-		assert.Equal(t, name, resp[0].Name)
-		assert.Equal(t, email, resp[0].Email)
+		assert.Equal(t, 1, len(resp.Edges))
+		assert.Equal(t, ID, resp.Edges[0].Node.ID)
 	})
 
 	t.Run("sad path - no rows", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT id, name, email FROM creators ORDER BY id ASC LIMIT $1 OFFSET $2")).
-			WithArgs(1, 0).
+			regexp.QuoteMeta("SELECT id, username, name, email, status, created_at::text FROM creators ORDER BY created_at DESC, id DESC LIMIT $1")).
+			WithArgs(2).
 			WillReturnError(sql.ErrNoRows)
 		sqlMock.ExpectRollback()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.GetAll(ctx, 0, 1)
+		resp, err := store.GetAll(ctx, 1, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCreatorsStore_Create(t *testing.T) {
+	var (
+		ID       = "4"
+		username = "jackfrost"
+		name     = "hi"
+		email    = "mockemail@gmail.com"
+		status   = model.CreatorStatusActive
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCreatorsStore(db)
+	assert.NoError(t, err)
+
+	input := &model.CreatorDetailsInput{
+		Username: username,
+		Name:     name,
+		Email:    email,
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("INSERT INTO creators( username, name, email, status ) VALUES ($1, $2, $3, $4 ) RETURNING id")).
+			WithArgs(username, name, email, status).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ID))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT username, name, email, status FROM creators WHERE id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "email", "status"}).
+				AddRow(username, name, email, status))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ID, resp.ID)
+		assert.Equal(t, username, resp.Username)
+		assert.Equal(t, status, resp.Status)
+	})
+
+	t.Run("sad path - failed insert", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("INSERT INTO creators( username, name, email, status ) VALUES ($1, $2, $3, $4 ) RETURNING id")).
+			WithArgs(username, name, email, status).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCreatorsStore_Update(t *testing.T) {
+	var (
+		ID       = "1"
+		username = "jackfrost"
+		name     = "hi"
+		email    = "mockemail@gmail.com"
+		status   = model.CreatorStatusActive
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCreatorsStore(db)
+	assert.NoError(t, err)
+
+	input := &model.CreatorDetailsInput{
+		Username: username,
+		Name:     name,
+		Email:    email,
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE creators SET username = $1, name = $2, email = $3 WHERE id = $4")).
+			WithArgs(username, name, email, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT username, name, email, status FROM creators WHERE id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "email", "status"}).
+				AddRow(username, name, email, status))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Update(ctx, ID, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ID, resp.ID)
+		assert.Equal(t, name, resp.Name)
+	})
+
+	t.Run("sad path - failed update", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE creators SET username = $1, name = $2, email = $3 WHERE id = $4")).
+			WithArgs(username, name, email, ID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Update(ctx, ID, input)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestCreatorsStore_SetStatus(t *testing.T) {
+	var (
+		ID       = "1"
+		username = "jackfrost"
+		name     = "hi"
+		email    = "mockemail@gmail.com"
+		status   = model.CreatorStatusBanned
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewCreatorsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE creators SET status = $1 WHERE id = $2")).
+			WithArgs(status, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT username, name, email, status FROM creators WHERE id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "email", "status"}).
+				AddRow(username, name, email, status))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.SetStatus(ctx, ID, status, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, status, resp.Status)
+	})
+
+	t.Run("happy path - cascade", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE creators SET status = $1 WHERE id = $2")).
+			WithArgs(status, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET status = $1 WHERE creator_id = $2 AND status = $3")).
+			WithArgs(model.StatusBanned, ID, model.StatusActive).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT username, name, email, status FROM creators WHERE id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "email", "status"}).
+				AddRow(username, name, email, status))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.SetStatus(ctx, ID, status, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, status, resp.Status)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - failed update", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE creators SET status = $1 WHERE id = $2")).
+			WithArgs(status, ID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.SetStatus(ctx, ID, status, false)
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
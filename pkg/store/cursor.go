@@ -0,0 +1,109 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/pkg/errors"
+)
+
+// CreatorsOrderKey is the cursor key of creators, which are always listed newest first
+const CreatorsOrderKey = "created_at_desc"
+
+// SearchOrderKey is the cursor key of search results, which are always listed by descending rank
+const SearchOrderKey = "rank_desc"
+
+// TagsOrderKey is the cursor key of tags, which are always listed most used first
+const TagsOrderKey = "usage_count_desc"
+
+// ShortsOrderKey returns the cursor key of the given audio shorts ordering
+func ShortsOrderKey(orderBy *model.AudioShortOrder) string {
+	field, direction := shortsOrder(orderBy)
+	return field.String() + "_" + direction.String()
+}
+
+// Cursor is the position of an entry in a keyset paginated list, ordered by (sort value, id)
+type Cursor struct {
+	// Key identifies the ordering the cursor was created for, e.g. "created_at_desc"
+	Key string `json:"k"`
+	// Value is the text representation of the sort column of the entry
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// EncodeCursor returns the opaque string representation of the cursor
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c) // marshalling a struct of strings cannot fail
+	return base64.URLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor previously returned by EncodeCursor, and checks it belongs to the given ordering
+func DecodeCursor(s string, key string) (*Cursor, error) {
+	raw, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageInvalidCursor)
+	}
+	c := &Cursor{}
+	err = json.Unmarshal(raw, c)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageInvalidCursor)
+	}
+	if c.Key != key {
+		return nil, errors.New(ErrorMessageInvalidCursor)
+	}
+	// the ID and value are cast in queries, so values that would fail the cast are rejected here
+	_, err = strconv.ParseInt(c.ID, 10, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageInvalidCursor)
+	}
+	if parse, ok := cursorValueParsers[key[:strings.LastIndex(key, "_")+1]]; ok {
+		err = parse(c.Value)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageInvalidCursor)
+		}
+	}
+	return c, nil
+}
+
+// cursorValueParsers check the values of cursors can be cast to the type of their sort column, by the column prefix of
+// their key; values of text columns need no check
+var cursorValueParsers = map[string]func(value string) error{
+	"created_at_":  parseTimestamp,
+	"updated_at_":  parseTimestamp,
+	"play_count_":  parseInteger,
+	"usage_count_": parseInteger,
+	"rank_":        parseReal,
+}
+
+// timestampLayouts are the layouts of timestamptz columns cast to text, whose offset has minutes or seconds only when
+// the time zone needs them
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+}
+
+func parseTimestamp(value string) error {
+	var err error
+	for _, layout := range timestampLayouts {
+		_, err = time.Parse(layout, value)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func parseInteger(value string) error {
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err
+}
+
+func parseReal(value string) error {
+	_, err := strconv.ParseFloat(value, 32)
+	return err
+}
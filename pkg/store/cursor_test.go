@@ -0,0 +1,87 @@
+package store
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("happy path - round trip", func(t *testing.T) {
+		c := Cursor{
+			Key:   "title_asc",
+			Value: "a|b",
+			ID:    "42",
+		}
+		decoded, err := DecodeCursor(EncodeCursor(c), "title_asc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, c, *decoded)
+	})
+
+	t.Run("sad path - not base64", func(t *testing.T) {
+		resp, err := DecodeCursor("!!!", "title_asc")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - not json", func(t *testing.T) {
+		resp, err := DecodeCursor(base64.URLEncoding.EncodeToString([]byte("2021-01-02|1")), "title_asc")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - missing ID", func(t *testing.T) {
+		resp, err := DecodeCursor(EncodeCursor(Cursor{Key: "title_asc", Value: "a"}), "title_asc")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - different ordering", func(t *testing.T) {
+		resp, err := DecodeCursor(EncodeCursor(Cursor{Key: "title_asc", Value: "a", ID: "1"}), "title_desc")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+	t.Run("happy path - typed values", func(t *testing.T) {
+		for key, value := range map[string]string{
+			"created_at_desc":  "2021-01-02 03:04:05.123456+00",
+			"updated_at_asc":   "2021-01-02 08:34:05+05:30",
+			"play_count_desc":  "12",
+			"usage_count_desc": "3",
+			SearchOrderKey:     "0.0607927",
+		} {
+			_, err := DecodeCursor(EncodeCursor(Cursor{Key: key, Value: value, ID: "1"}), key)
+
+			assert.NoError(t, err, key)
+		}
+	})
+
+	t.Run("sad path - tampered ID", func(t *testing.T) {
+		for _, id := range []string{"abc", "1 OR 1=1", "99999999999"} {
+			resp, err := DecodeCursor(EncodeCursor(Cursor{Key: "title_asc", Value: "a", ID: id}), "title_asc")
+
+			assert.Error(t, err)
+			assert.Nil(t, resp)
+		}
+	})
+
+	t.Run("sad path - tampered value", func(t *testing.T) {
+		for key, value := range map[string]string{
+			"created_at_desc":  "yesterday",
+			"updated_at_asc":   "2021-01-02",
+			"play_count_desc":  "many",
+			"usage_count_desc": "1.5",
+			SearchOrderKey:     "high",
+		} {
+			resp, err := DecodeCursor(EncodeCursor(Cursor{Key: key, Value: value, ID: "1"}), key)
+
+			assert.Error(t, err, key)
+			assert.Nil(t, resp)
+		}
+	})
+}
@@ -1,5 +1,17 @@
 package store
 
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/pkg/errors"
+)
+
 const (
 	ErrorMessageTransactionFailed = "Failed to start transaction"
 	ErrorMessageCommitFailed      = "Failed to commit transaction"
@@ -7,6 +19,139 @@ const (
 
 	ErrorMessageCreateFailed = "Failed to create"
 	ErrorMessageFindFailed   = "Failed to find"
+	ErrorMessageCountFailed  = "Failed to count"
 	ErrorMessageUpdateFailed = "Failed to update"
 	ErrorMessageDeleteFailed = "Failed to delete"
+
+	ErrorMessageInvalidCursor = "Invalid cursor"
+	ErrorMessageInvalidOrder  = "Invalid order"
+
+	ErrorMessageCreatorNotActive = "Creator is not active"
+	ErrorMessageCategoryArchived = "Category is archived"
+	ErrorMessageNotFound         = "Entry not found"
+	ErrorMessageConflict         = "Entry conflicts with an existing one"
+	ErrorMessageInvalidReference = "Entry references a missing entry"
+	ErrorMessageUnavailable      = "Database is unavailable"
+	ErrorMessageVersionConflict  = "Entry was changed since the expected version"
+	ErrorMessageNotDeleted       = "Entry is not deleted"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqCodeUniqueViolation     = "23505"
+	pqCodeForeignKeyViolation = "23503"
+
+	pqClassConnectionException   = "08"
+	pqClassInsufficientResources = "53"
+	pqClassOperatorIntervention  = "57"
 )
+
+// CreatorNotActiveError is returned when writing a short for a creator that is banned or suspended
+type CreatorNotActiveError struct {
+	CreatorID string
+	Status    model.CreatorStatus
+}
+
+func (e *CreatorNotActiveError) Error() string {
+	return ErrorMessageCreatorNotActive + " ID:" + e.CreatorID + " status:" + e.Status.String()
+}
+
+// CategoryArchivedError is returned when setting an archived category on a short
+type CategoryArchivedError struct {
+	Slug string
+}
+
+func (e *CategoryArchivedError) Error() string {
+	return ErrorMessageCategoryArchived + " slug:" + e.Slug
+}
+
+// VersionConflictError is returned when writing an entry that was changed since the expected version
+type VersionConflictError struct {
+	ID       string
+	Expected int
+	Actual   int
+}
+
+func (e *VersionConflictError) Error() string {
+	return ErrorMessageVersionConflict + " ID:" + e.ID + " expected:" + strconv.Itoa(e.Expected) + " actual:" + strconv.Itoa(e.Actual)
+}
+
+// NotDeletedError is returned when restoring a short that is not deleted, e.g. a banned one
+type NotDeletedError struct {
+	ID     string
+	Status model.Status
+}
+
+func (e *NotDeletedError) Error() string {
+	return ErrorMessageNotDeleted + " ID:" + e.ID + " status:" + e.Status.String()
+}
+
+// NotFoundError is returned when the requested entry does not exist
+type NotFoundError struct {
+	Err error
+}
+
+func (e *NotFoundError) Error() string { return ErrorMessageNotFound + ": " + e.Err.Error() }
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// ConflictError is returned when a write violates a unique constraint
+type ConflictError struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ConflictError) Error() string {
+	return ErrorMessageConflict + " constraint:" + e.Constraint + ": " + e.Err.Error()
+}
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+// InvalidReferenceError is returned when a write references an entry that does not exist
+type InvalidReferenceError struct {
+	Constraint string
+	Err        error
+}
+
+func (e *InvalidReferenceError) Error() string {
+	return ErrorMessageInvalidReference + " constraint:" + e.Constraint + ": " + e.Err.Error()
+}
+func (e *InvalidReferenceError) Unwrap() error { return e.Err }
+
+// UnavailableError is returned when the database cannot be reached; the operation may be retried
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string { return ErrorMessageUnavailable + ": " + e.Err.Error() }
+func (e *UnavailableError) Unwrap() error { return e.Err }
+
+// classifyError wraps a database error into one of the typed errors above, keeping its message;
+// errors that are already typed or cannot be classified are returned as is
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	cause := errors.Cause(err)
+
+	var pqErr *pq.Error
+	var netErr net.Error
+	switch {
+	case cause == sql.ErrNoRows:
+		return &NotFoundError{Err: err}
+	case cause == driver.ErrBadConn, cause == sql.ErrConnDone, cause == context.DeadlineExceeded:
+		return &UnavailableError{Err: err}
+	case errors.As(cause, &pqErr):
+		switch {
+		case pqErr.Code == pqCodeUniqueViolation:
+			return &ConflictError{Constraint: pqErr.Constraint, Err: err}
+		case pqErr.Code == pqCodeForeignKeyViolation:
+			return &InvalidReferenceError{Constraint: pqErr.Constraint, Err: err}
+		case pqErr.Code.Class() == pqClassConnectionException,
+			pqErr.Code.Class() == pqClassInsufficientResources,
+			pqErr.Code.Class() == pqClassOperatorIntervention:
+			return &UnavailableError{Err: err}
+		}
+	case errors.As(cause, &netErr):
+		return &UnavailableError{Err: err}
+	}
+	return err
+}
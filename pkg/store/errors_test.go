@@ -0,0 +1,60 @@
+package store
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Run("happy path - not found", func(t *testing.T) {
+		err := classifyError(errors.Wrap(sql.ErrNoRows, ErrorMessageFindFailed))
+
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+		assert.Contains(t, err.Error(), ErrorMessageFindFailed)
+	})
+
+	t.Run("happy path - conflict", func(t *testing.T) {
+		err := classifyError(errors.Wrap(&pq.Error{Code: "23505", Constraint: "audio_shorts_title_creator_id_key"}, ErrorMessageCreateFailed))
+
+		var conflict *ConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Equal(t, "audio_shorts_title_creator_id_key", conflict.Constraint)
+	})
+
+	t.Run("happy path - invalid reference", func(t *testing.T) {
+		err := classifyError(errors.Wrap(&pq.Error{Code: "23503", Constraint: "fk_creator"}, ErrorMessageUpdateFailed))
+
+		var invalidReference *InvalidReferenceError
+		assert.True(t, errors.As(err, &invalidReference))
+		assert.Equal(t, "fk_creator", invalidReference.Constraint)
+	})
+
+	t.Run("happy path - unavailable", func(t *testing.T) {
+		for _, cause := range []error{driver.ErrBadConn, sql.ErrConnDone, &pq.Error{Code: "08006"}, &pq.Error{Code: "57P01"}} {
+			err := classifyError(errors.Wrap(cause, ErrorMessageTransactionFailed))
+
+			var unavailable *UnavailableError
+			assert.True(t, errors.As(err, &unavailable))
+		}
+	})
+
+	t.Run("happy path - already typed", func(t *testing.T) {
+		notActive := &CreatorNotActiveError{CreatorID: "1"}
+		err := errors.Wrap(notActive, ErrorMessageCreateFailed)
+
+		assert.Equal(t, err, classifyError(err))
+	})
+
+	t.Run("happy path - unclassified", func(t *testing.T) {
+		err := errors.New("some error")
+
+		assert.Equal(t, err, classifyError(err))
+		assert.Nil(t, classifyError(nil))
+	})
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=fingerprints.go -destination=fingerprints_mock.go -package=store FingerprintsStore
+
+// FingerprintsStore is the repository for the fingerprints of the audio files of shorts, which are looked up to find
+// shorts whose audio duplicates that of others
+type (
+	FingerprintsStore interface {
+		// Get returns the fingerprint of the audio file of the short, or nil when it was not fingerprinted
+		Get(ctx context.Context, shortID string) (fingerprint *Fingerprint, err error)
+		// Save stores the fingerprint of the audio file of its short, replacing the previous one
+		Save(ctx context.Context, fingerprint *Fingerprint) (err error)
+		// FindCandidates returns the fingerprints that may be duplicates, those of the same hash or of an acoustic
+		// fingerprint from minLength to maxLength bytes long; those of the shorts of the creator, and the short itself,
+		// are left out when their IDs are given
+		FindCandidates(ctx context.Context, sha256 string, minLength, maxLength int, creatorID, shortID string) (fingerprints []*Fingerprint, err error)
+	}
+
+	// Fingerprint is the fingerprint of the audio file of a short, flagged when the short was created although its
+	// audio duplicates that of shorts of other creators
+	Fingerprint struct {
+		ShortID  string
+		SHA256   string
+		Acoustic []byte
+		Flagged  bool
+	}
+
+	fingerprintsStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewFingerprintsStore(db *sql.DB) (FingerprintsStore, error) {
+	return &fingerprintsStore{
+		db: db,
+	}, nil
+}
+
+func (s *fingerprintsStore) Get(ctx context.Context, shortID string) (fingerprint *Fingerprint, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	fingerprint, err = findFingerprint(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *fingerprintsStore) Save(ctx context.Context, fingerprint *Fingerprint) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = saveFingerprint(ctx, tx, fingerprint)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCreateFailed+" short ID:"+fingerprint.ShortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *fingerprintsStore) FindCandidates(ctx context.Context, sha256 string, minLength, maxLength int, creatorID, shortID string) (fingerprints []*Fingerprint, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	fingerprints, err = findCandidateFingerprints(ctx, tx, sha256, minLength, maxLength, creatorID, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: fingerprints.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFingerprintsStore is a mock of FingerprintsStore interface.
+type MockFingerprintsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockFingerprintsStoreMockRecorder
+}
+
+// MockFingerprintsStoreMockRecorder is the mock recorder for MockFingerprintsStore.
+type MockFingerprintsStoreMockRecorder struct {
+	mock *MockFingerprintsStore
+}
+
+// NewMockFingerprintsStore creates a new mock instance.
+func NewMockFingerprintsStore(ctrl *gomock.Controller) *MockFingerprintsStore {
+	mock := &MockFingerprintsStore{ctrl: ctrl}
+	mock.recorder = &MockFingerprintsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFingerprintsStore) EXPECT() *MockFingerprintsStoreMockRecorder {
+	return m.recorder
+}
+
+// FindCandidates mocks base method.
+func (m *MockFingerprintsStore) FindCandidates(ctx context.Context, sha256 string, minLength, maxLength int, creatorID, shortID string) ([]*Fingerprint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCandidates", ctx, sha256, minLength, maxLength, creatorID, shortID)
+	ret0, _ := ret[0].([]*Fingerprint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCandidates indicates an expected call of FindCandidates.
+func (mr *MockFingerprintsStoreMockRecorder) FindCandidates(ctx, sha256, minLength, maxLength, creatorID, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCandidates", reflect.TypeOf((*MockFingerprintsStore)(nil).FindCandidates), ctx, sha256, minLength, maxLength, creatorID, shortID)
+}
+
+// Get mocks base method.
+func (m *MockFingerprintsStore) Get(ctx context.Context, shortID string) (*Fingerprint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].(*Fingerprint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockFingerprintsStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFingerprintsStore)(nil).Get), ctx, shortID)
+}
+
+// Save mocks base method.
+func (m *MockFingerprintsStore) Save(ctx context.Context, fingerprint *Fingerprint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, fingerprint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockFingerprintsStoreMockRecorder) Save(ctx, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockFingerprintsStore)(nil).Save), ctx, fingerprint)
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const sha256Hex = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestFingerprintsStore_Get(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewFingerprintsStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT sha256, acoustic, flagged FROM audio_fingerprints WHERE short_id = $1")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"sha256", "acoustic", "flagged"}).
+				AddRow(sha256Hex, []byte{0, 1, 2, 3}, true))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Fingerprint{ShortID: shortID, SHA256: sha256Hex, Acoustic: []byte{0, 1, 2, 3}, Flagged: true}, resp)
+	})
+
+	t.Run("happy path - not fingerprinted", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"sha256", "acoustic", "flagged"}))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestFingerprintsStore_Save(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewFingerprintsStore(db)
+	assert.NoError(t, err)
+
+	saveQuery := regexp.QuoteMeta("INSERT INTO audio_fingerprints( short_id, sha256, acoustic, flagged ) VALUES ($1, $2, $3, $4 ) ON CONFLICT (short_id) DO UPDATE SET sha256 = EXCLUDED.sha256, acoustic = EXCLUDED.acoustic, flagged = EXCLUDED.flagged")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(saveQuery).
+			WithArgs("1", sha256Hex, []byte{0, 1}, false).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Save(ctx, &Fingerprint{ShortID: "1", SHA256: sha256Hex, Acoustic: []byte{0, 1}})
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(saveQuery).
+			WithArgs("2", sha256Hex, []byte(nil), true).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Save(ctx, &Fingerprint{ShortID: "2", SHA256: sha256Hex, Flagged: true})
+
+		assert.Error(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestFingerprintsStore_FindCandidates(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewFingerprintsStore(db)
+	assert.NoError(t, err)
+
+	selects := "SELECT f.short_id, f.sha256, f.acoustic, f.flagged FROM audio_fingerprints AS f INNER JOIN audio_shorts AS a ON a.id = f.short_id " +
+		"WHERE (f.sha256 = $1 OR octet_length(f.acoustic) BETWEEN $2 AND $3)"
+	columns := []string{"short_id", "sha256", "acoustic", "flagged"}
+
+	t.Run("happy path - of other creators", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(selects+" AND a.creator_id <> $4 ORDER BY f.short_id")).
+			WithArgs(sha256Hex, 90, 110, "1").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("2", sha256Hex, nil, false).
+				AddRow("3", "abc", []byte{1, 2}, true)).RowsWillBeClosed()
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.FindCandidates(ctx, sha256Hex, 90, 110, "1", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*Fingerprint{
+			{ShortID: "2", SHA256: sha256Hex},
+			{ShortID: "3", SHA256: "abc", Acoustic: []byte{1, 2}, Flagged: true},
+		}, resp)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("happy path - of other shorts", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(selects+" AND f.short_id <> $4 ORDER BY f.short_id")).
+			WithArgs(sha256Hex, 90, 110, "4").
+			WillReturnRows(sqlmock.NewRows(columns))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.FindCandidates(ctx, sha256Hex, 90, 110, "", "4")
+
+		assert.NoError(t, err)
+		assert.Empty(t, resp)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(selects)).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.FindCandidates(ctx, sha256Hex, 90, 110, "1", "")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
@@ -3,63 +3,176 @@ package store
 import (
 	"context"
 	"database/sql"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/pkg/errors"
 )
 
 func findOneByID(ctx context.Context, tx *sql.Tx, id string) (short *model.AudioShort, err error) {
 	var (
-		title       string
-		description string
-		status      string
-		category    string
-		audioFile   string
-		creatorID   string
-		name        string
-		email       string
+		title         string
+		description   string
+		status        string
+		category      categoryColumns
+		audioFile     string
+		version       int
+		metadata      nullMetadata
+		creatorID     string
+		name          string
+		email         string
+		username      string
+		creatorStatus string
 	)
 	query := "SELECT " +
 		"a.title, " +
 		"a.description, " +
 		"a.status, " +
 		"a.category, " +
+		"g.id, " +
+		"g.name, " +
+		"g.position, " +
+		"g.archived, " +
 		"a.audio_file, " +
+		"a.version, " +
+		"a.duration_ms, " +
+		"a.sample_rate, " +
+		"a.channels, " +
+		"a.bitrate, " +
+		"a.codec, " +
 		"c.id, " +
 		"c.name, " +
-		"c.email " +
+		"c.email, " +
+		"c.username, " +
+		"c.status " +
 		"FROM audio_shorts AS a," +
-		"creators AS c " +
+		"creators AS c," +
+		"categories AS g " +
 		"WHERE " +
 		"c.id = a.creator_id " +
+		"AND g.slug = a.category " +
 		"AND a.id = $1"
 
 	row := tx.QueryRowContext(ctx, query, id)
-	err = row.Scan(&title, &description, &status, &category, &audioFile, &creatorID, &name, &email)
+	err = row.Scan(&title, &description, &status, &category.slug, &category.id, &category.name, &category.position, &category.archived, &audioFile, &version, &metadata.durationMs, &metadata.sampleRate, &metadata.channels, &metadata.bitrate, &metadata.codec, &creatorID, &name, &email, &username, &creatorStatus)
 	short = &model.AudioShort{
 		ID:          id,
 		Title:       title,
 		Description: description,
 		Status:      model.Status(status),
-		Category:    model.Category(category),
+		Category:    category.toModel(),
 		AudioFile:   audioFile,
+		Version:     version,
+		Metadata:    metadata.toModel(),
 		Creator: &model.Creator{
-			ID:    creatorID,
-			Name:  name,
-			Email: email,
+			ID:       creatorID,
+			Username: username,
+			Name:     name,
+			Email:    email,
+			Status:   model.CreatorStatus(creatorStatus),
 		},
 	}
 	return
 }
 
+func findAllByIDs(ctx context.Context, tx *sql.Tx, ids []string) (shorts []*model.AudioShort, err error) {
+	var (
+		id            string
+		title         string
+		description   string
+		status        string
+		category      categoryColumns
+		audioFile     string
+		version       int
+		metadata      nullMetadata
+		creatorID     string
+		name          string
+		email         string
+		username      string
+		creatorStatus string
+	)
+	query := "SELECT " +
+		"a.id, " +
+		"a.title, " +
+		"a.description, " +
+		"a.status, " +
+		"a.category, " +
+		"g.id, " +
+		"g.name, " +
+		"g.position, " +
+		"g.archived, " +
+		"a.audio_file, " +
+		"a.version, " +
+		"a.duration_ms, " +
+		"a.sample_rate, " +
+		"a.channels, " +
+		"a.bitrate, " +
+		"a.codec, " +
+		"c.id, " +
+		"c.name, " +
+		"c.email, " +
+		"c.username, " +
+		"c.status " +
+		"FROM audio_shorts AS a," +
+		"creators AS c," +
+		"categories AS g " +
+		"WHERE " +
+		"c.id = a.creator_id " +
+		"AND g.slug = a.category " +
+		"AND a.id = ANY($1::int[])"
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = rows.Close()
+	}()
+
+	for rows.Next() {
+		err = rows.Scan(&id, &title, &description, &status, &category.slug, &category.id, &category.name, &category.position, &category.archived, &audioFile, &version, &metadata.durationMs, &metadata.sampleRate, &metadata.channels, &metadata.bitrate, &metadata.codec, &creatorID, &name, &email, &username, &creatorStatus)
+		if err != nil {
+			return nil, err
+		}
+		shorts = append(shorts, &model.AudioShort{
+			ID:          id,
+			Title:       title,
+			Description: description,
+			Status:      model.Status(status),
+			Category:    category.toModel(),
+			AudioFile:   audioFile,
+			Version:     version,
+			Metadata:    metadata.toModel(),
+			Creator: &model.Creator{
+				ID:       creatorID,
+				Username: username,
+				Name:     name,
+				Email:    email,
+				Status:   model.CreatorStatus(creatorStatus),
+			},
+		})
+	}
+	return
+}
+
 func findOneByUnique(ctx context.Context, tx *sql.Tx, inputTitle string, creatorID string) (short *model.AudioShort, err error) {
 	var (
-		id          string
-		title       string
-		description string
-		status      string
-		category    string
-		audioFile   string
-		name        string
-		email       string
+		id            string
+		title         string
+		description   string
+		status        string
+		category      categoryColumns
+		audioFile     string
+		version       int
+		metadata      nullMetadata
+		name          string
+		email         string
+		username      string
+		creatorStatus string
 	)
 	query := "SELECT " +
 		"a.id, " +
@@ -67,66 +180,327 @@ func findOneByUnique(ctx context.Context, tx *sql.Tx, inputTitle string, creator
 		"a.description, " +
 		"a.status, " +
 		"a.category, " +
+		"g.id, " +
+		"g.name, " +
+		"g.position, " +
+		"g.archived, " +
 		"a.audio_file, " +
+		"a.version, " +
+		"a.duration_ms, " +
+		"a.sample_rate, " +
+		"a.channels, " +
+		"a.bitrate, " +
+		"a.codec, " +
 		"c.name, " +
-		"c.email " +
+		"c.email, " +
+		"c.username, " +
+		"c.status " +
 		"FROM audio_shorts AS a," +
-		"creators AS c " +
+		"creators AS c," +
+		"categories AS g " +
 		"WHERE " +
 		"c.id = a.creator_id " +
+		"AND g.slug = a.category " +
 		"AND a.title = $1 " +
 		"AND a.creator_id = $2"
 
 	row := tx.QueryRowContext(ctx, query, inputTitle, creatorID)
-	err = row.Scan(&id, &title, &description, &status, &category, &audioFile, &name, &email)
+	err = row.Scan(&id, &title, &description, &status, &category.slug, &category.id, &category.name, &category.position, &category.archived, &audioFile, &version, &metadata.durationMs, &metadata.sampleRate, &metadata.channels, &metadata.bitrate, &metadata.codec, &name, &email, &username, &creatorStatus)
 	short = &model.AudioShort{
 		ID:          id,
 		Title:       title,
 		Description: description,
 		Status:      model.Status(status),
-		Category:    model.Category(category),
+		Category:    category.toModel(),
 		AudioFile:   audioFile,
+		Version:     version,
+		Metadata:    metadata.toModel(),
 		Creator: &model.Creator{
-			ID:    creatorID,
-			Name:  name,
-			Email: email,
+			ID:       creatorID,
+			Username: username,
+			Name:     name,
+			Email:    email,
+			Status:   model.CreatorStatus(creatorStatus),
 		},
 	}
 	return
 }
 
-func findAllShorts(ctx context.Context, tx *sql.Tx, page, limit uint16) (shorts []*model.AudioShort, err error) {
-	shorts = make([]*model.AudioShort, 0, limit) // set cap at limit
+type orderColumn struct {
+	name string
+	cast string // the type cursor values are cast to
+}
+
+// shortsOrderColumns whitelists the columns audio shorts can be ordered by
+var shortsOrderColumns = map[model.AudioShortOrderField]orderColumn{
+	model.AudioShortOrderFieldCreatedAt: {name: "a.created_at", cast: "timestamptz"},
+	model.AudioShortOrderFieldUpdatedAt: {name: "a.updated_at", cast: "timestamptz"},
+	model.AudioShortOrderFieldTitle:     {name: "a.title", cast: "text"},
+	model.AudioShortOrderFieldPlayCount: {name: "a.play_count", cast: "bigint"},
+}
+
+// shortsOrder returns the ordering of audio shorts, defaulting to newest first
+func shortsOrder(orderBy *model.AudioShortOrder) (model.AudioShortOrderField, model.OrderDirection) {
+	if orderBy == nil {
+		return model.AudioShortOrderFieldCreatedAt, model.OrderDirectionDesc
+	}
+	return orderBy.Field, orderBy.Direction
+}
+
+// visibleStatuses returns the statuses of the shorts that are listed: active ones, and the included ones
+func visibleStatuses(include []model.Status) []string {
+	statuses := []string{model.StatusActive.String()}
+	for _, status := range include {
+		if status != model.StatusActive {
+			statuses = append(statuses, status.String())
+		}
+	}
+	return statuses
+}
+
+// hideStatuses hides the deleted and banned shorts, unless included
+func hideStatuses(b *queryBuilder, includeStatuses []model.Status) {
+	b.where("a.status = ANY(" + b.arg(pq.Array(visibleStatuses(includeStatuses))) + "::audio_shorts_status[])")
+}
+
+// filterShorts adds the conditions of the filter to the query
+func filterShorts(b *queryBuilder, filter *model.AudioShortFilter) {
+	if filter == nil {
+		return
+	}
+	if len(filter.Categories) > 0 {
+		b.where("a.category = ANY(" + b.arg(pq.Array(filter.Categories)) + "::text[])")
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, status.String())
+		}
+		b.where("a.status = ANY(" + b.arg(pq.Array(statuses)) + "::audio_shorts_status[])")
+	}
+	if len(filter.CreatorIds) > 0 {
+		b.where("a.creator_id = ANY(" + b.arg(pq.Array(filter.CreatorIds)) + "::int[])")
+	}
+	if filter.CreatedAfter != nil {
+		b.where("a.created_at >= " + b.arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		b.where("a.created_at < " + b.arg(*filter.CreatedBefore))
+	}
+	if filter.TitleContains != nil && *filter.TitleContains != "" {
+		b.where("a.title ILIKE " + b.arg("%"+escapeLike(*filter.TitleContains)+"%"))
+	}
+	if filter.DuplicateFlagged != nil {
+		flagged := "EXISTS (SELECT 1 FROM audio_fingerprints AS f WHERE f.short_id = a.id AND f.flagged)"
+		if !*filter.DuplicateFlagged {
+			flagged = "NOT " + flagged
+		}
+		b.where(flagged)
+	}
+	if filter.MostlySilent != nil {
+		silent := "EXISTS (SELECT 1 FROM loudness AS l WHERE l.short_id = a.id AND l.mostly_silent)"
+		if !*filter.MostlySilent {
+			silent = "NOT " + silent
+		}
+		b.where(silent)
+	}
+	if len(filter.Tags) > 0 {
+		b.where("EXISTS (SELECT 1 FROM audio_short_tags AS st JOIN tags AS t ON t.id = st.tag_id " +
+			"WHERE st.short_id = a.id AND t.name = ANY(" + b.arg(pq.Array(filter.Tags)) + "::text[]))")
+	}
+}
+
+func findAllShorts(ctx context.Context, tx *sql.Tx, limit uint16, after *Cursor, filter *model.AudioShortFilter, orderBy *model.AudioShortOrder, includeStatuses []model.Status) (edges []*model.AudioShortEdge, err error) {
+	edges = make([]*model.AudioShortEdge, 0, limit) // set cap at limit
+	var (
+		id            string
+		title         string
+		description   string
+		status        string
+		category      categoryColumns
+		audioFile     string
+		version       int
+		metadata      nullMetadata
+		sortValue     string
+		creatorID     string
+		name          string
+		email         string
+		username      string
+		creatorStatus string
+	)
+	field, direction := shortsOrder(orderBy)
+	column, ok := shortsOrderColumns[field]
+	if !ok {
+		return nil, errors.New(ErrorMessageInvalidOrder)
+	}
+	dir, cmp := "ASC", ">"
+	if direction == model.OrderDirectionDesc {
+		dir, cmp = "DESC", "<"
+	}
+
+	b := newQueryBuilder("SELECT " +
+		"a.id, " +
+		"a.title, " +
+		"a.description, " +
+		"a.status, " +
+		"a.category, " +
+		"g.id, " +
+		"g.name, " +
+		"g.position, " +
+		"g.archived, " +
+		"a.audio_file, " +
+		"a.version, " +
+		"a.duration_ms, " +
+		"a.sample_rate, " +
+		"a.channels, " +
+		"a.bitrate, " +
+		"a.codec, " +
+		column.name + "::text, " +
+		"c.id, " +
+		"c.name, " +
+		"c.email, " +
+		"c.username, " +
+		"c.status " +
+		"FROM audio_shorts AS a," +
+		"creators AS c," +
+		"categories AS g").
+		where("c.id = a.creator_id").
+		where("g.slug = a.category")
+	filterShorts(b, filter)
+	hideStatuses(b, includeStatuses)
+	if after != nil {
+		// keyset pagination on (sort column, id)
+		value := b.arg(after.Value)
+		afterID := b.arg(after.ID)
+		b.where("(" + column.name + ", a.id) " + cmp + " (" + value + "::" + column.cast + ", " + afterID + "::int)")
+	}
+	query, args := b.order(column.name+" "+dir, "a.id "+dir).
+		withLimit(limit).
+		build()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = rows.Close()
+	}()
+
+	key := ShortsOrderKey(orderBy)
+	for rows.Next() {
+		err = rows.Scan(&id, &title, &description, &status, &category.slug, &category.id, &category.name, &category.position, &category.archived, &audioFile, &version, &metadata.durationMs, &metadata.sampleRate, &metadata.channels, &metadata.bitrate, &metadata.codec, &sortValue, &creatorID, &name, &email, &username, &creatorStatus)
+		if err != nil {
+			return nil, err
+		}
+		short := &model.AudioShort{
+			ID:          id,
+			Title:       title,
+			Description: description,
+			Status:      model.Status(status),
+			Category:    category.toModel(),
+			AudioFile:   audioFile,
+			Version:     version,
+			Metadata:    metadata.toModel(),
+			Creator: &model.Creator{
+				ID:       creatorID,
+				Username: username,
+				Name:     name,
+				Email:    email,
+				Status:   model.CreatorStatus(creatorStatus),
+			},
+		}
+		edges = append(edges, &model.AudioShortEdge{
+			Cursor: EncodeCursor(Cursor{Key: key, Value: sortValue, ID: id}),
+			Node:   short,
+		})
+	}
+	return
+}
+
+func countShorts(ctx context.Context, tx *sql.Tx, filter *model.AudioShortFilter, includeStatuses []model.Status) (count int, err error) {
+	b := newQueryBuilder("SELECT " +
+		"COUNT(*) " +
+		"FROM audio_shorts AS a")
+	filterShorts(b, filter)
+	hideStatuses(b, includeStatuses)
+	query, args := b.build()
+
+	row := tx.QueryRowContext(ctx, query, args...)
+	err = row.Scan(&count)
+	return
+}
+
+// searchHighlightOptions are the ts_headline options of search result highlights
+const searchHighlightOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2"
+
+func searchShorts(ctx context.Context, tx *sql.Tx, text string, limit uint16, after *Cursor) (edges []*model.AudioShortSearchEdge, err error) {
+	edges = make([]*model.AudioShortSearchEdge, 0, limit) // set cap at limit
 	var (
-		id          string
-		title       string
-		description string
-		status      string
-		category    string
-		audioFile   string
-		creatorID   string
-		name        string
-		email       string
+		id                   string
+		title                string
+		description          string
+		status               string
+		category             categoryColumns
+		audioFile            string
+		version              int
+		metadata             nullMetadata
+		rank                 float64
+		titleHighlight       string
+		descriptionHighlight string
+		creatorID            string
+		name                 string
+		email                string
+		username             string
+		creatorStatus        string
 	)
-	query := "SELECT " +
+	// the tsquery and highlight options are referenced in the SELECT clause, so register them first
+	b := newQueryBuilder("")
+	tsQuery := b.arg(prefixTSQuery(text))
+	options := b.arg(searchHighlightOptions)
+	b.selects = "SELECT " +
 		"a.id, " +
 		"a.title, " +
 		"a.description, " +
 		"a.status, " +
 		"a.category, " +
+		"g.id, " +
+		"g.name, " +
+		"g.position, " +
+		"g.archived, " +
 		"a.audio_file, " +
+		"a.version, " +
+		"a.duration_ms, " +
+		"a.sample_rate, " +
+		"a.channels, " +
+		"a.bitrate, " +
+		"a.codec, " +
+		"ts_rank(a.search_vector, q), " +
+		"ts_headline('english', a.title, q, " + options + "), " +
+		"ts_headline('english', a.description, q, " + options + "), " +
 		"c.id, " +
 		"c.name, " +
-		"c.email " +
+		"c.email, " +
+		"c.username, " +
+		"c.status " +
 		"FROM audio_shorts AS a," +
-		"creators AS c " +
-		"WHERE " +
-		"c.id = a.creator_id " +
-		"ORDER BY a.id ASC " +
-		"LIMIT $1 " +
-		"OFFSET $2"
+		"creators AS c," +
+		"categories AS g," +
+		"to_tsquery('english', " + tsQuery + ") AS q"
+	b.where("c.id = a.creator_id").
+		where("g.slug = a.category").
+		where("a.search_vector @@ q")
+	hideStatuses(b, nil)
+	if after != nil {
+		// keyset pagination on (rank, id)
+		value := b.arg(after.Value)
+		afterID := b.arg(after.ID)
+		b.where("(ts_rank(a.search_vector, q), a.id) < (" + value + "::real, " + afterID + "::int)")
+	}
+	query, args := b.order("ts_rank(a.search_vector, q) DESC", "a.id DESC").
+		withLimit(limit).
+		build()
 
-	rows, err := tx.QueryContext(ctx, query, limit, page)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +509,7 @@ func findAllShorts(ctx context.Context, tx *sql.Tx, page, limit uint16) (shorts
 	}()
 
 	for rows.Next() {
-		err = rows.Scan(&id, &title, &description, &status, &category, &audioFile, &creatorID, &name, &email)
+		err = rows.Scan(&id, &title, &description, &status, &category.slug, &category.id, &category.name, &category.position, &category.archived, &audioFile, &version, &metadata.durationMs, &metadata.sampleRate, &metadata.channels, &metadata.bitrate, &metadata.codec, &rank, &titleHighlight, &descriptionHighlight, &creatorID, &name, &email, &username, &creatorStatus)
 		if err != nil {
 			return nil, err
 		}
@@ -144,20 +518,64 @@ func findAllShorts(ctx context.Context, tx *sql.Tx, page, limit uint16) (shorts
 			Title:       title,
 			Description: description,
 			Status:      model.Status(status),
-			Category:    model.Category(category),
+			Category:    category.toModel(),
 			AudioFile:   audioFile,
+			Version:     version,
+			Metadata:    metadata.toModel(),
 			Creator: &model.Creator{
-				ID:    creatorID,
-				Name:  name,
-				Email: email,
+				ID:       creatorID,
+				Username: username,
+				Name:     name,
+				Email:    email,
+				Status:   model.CreatorStatus(creatorStatus),
 			},
 		}
-		shorts = append(shorts, short)
+		edges = append(edges, &model.AudioShortSearchEdge{
+			Cursor:               EncodeCursor(Cursor{Key: SearchOrderKey, Value: strconv.FormatFloat(rank, 'g', -1, 32), ID: id}),
+			Node:                 short,
+			Rank:                 rank,
+			TitleHighlight:       titleHighlight,
+			DescriptionHighlight: descriptionHighlight,
+		})
 	}
 	return
 }
 
-func createOne(ctx context.Context, tx *sql.Tx, input *model.AudioShortInput) (err error) {
+func countSearchShorts(ctx context.Context, tx *sql.Tx, text string) (count int, err error) {
+	query := "SELECT " +
+		"COUNT(*) " +
+		"FROM audio_shorts AS a," +
+		"to_tsquery('english', $1) AS q " +
+		"WHERE " +
+		"a.search_vector @@ q " +
+		"AND a.status = ANY($2::audio_shorts_status[])"
+
+	row := tx.QueryRowContext(ctx, query, prefixTSQuery(text), pq.Array(visibleStatuses(nil)))
+	err = row.Scan(&count)
+	return
+}
+
+func checkVersion(ctx context.Context, tx *sql.Tx, id string, expected int) (err error) {
+	var version int
+	query := "SELECT " +
+		"version " +
+		"FROM audio_shorts " +
+		"WHERE id = $1 " +
+		"FOR UPDATE"
+
+	row := tx.QueryRowContext(ctx, query, id)
+	err = row.Scan(&version)
+	if err != nil {
+		return err
+	}
+	if version != expected {
+		return &VersionConflictError{ID: id, Expected: expected, Actual: version}
+	}
+	return nil
+}
+
+func createOne(ctx context.Context, tx *sql.Tx, input *model.AudioShortInput, metadata *model.AudioMetadata) (err error) {
+	m := newNullMetadata(metadata)
 	query := "INSERT INTO " +
 		"audio_shorts( " +
 		"title, " +
@@ -165,21 +583,32 @@ func createOne(ctx context.Context, tx *sql.Tx, input *model.AudioShortInput) (e
 		"status, " +
 		"category, " +
 		"audio_file, " +
-		"creator_id " +
+		"creator_id, " +
+		"duration_ms, " +
+		"sample_rate, " +
+		"channels, " +
+		"bitrate, " +
+		"codec " +
 		") VALUES (" +
 		"$1, " +
 		"$2, " +
 		"$3, " + // set default status as 'active'
 		"$4, " +
 		"$5, " +
-		"$6 " +
+		"$6, " +
+		"$7, " +
+		"$8, " +
+		"$9, " +
+		"$10, " +
+		"$11 " +
 		")"
 
-	_, err = tx.ExecContext(ctx, query, input.Title, input.Description, model.StatusActive.String(), input.Category.String(), input.AudioFile, input.Creator.ID)
+	_, err = tx.ExecContext(ctx, query, input.Title, input.Description, model.StatusActive.String(), input.Category, input.AudioFile, input.Creator.ID,
+		m.durationMs, m.sampleRate, m.channels, m.bitrate, m.codec)
 	return
 }
 
-func updateOne(ctx context.Context, tx *sql.Tx, id string, input *model.AudioShortInput) (err error) {
+func updateOne(ctx context.Context, tx *sql.Tx, id string, input *model.AudioShortInput, metadata *model.AudioMetadata) (err error) {
 	query := "UPDATE " +
 		"audio_shorts " +
 		"SET " +
@@ -187,10 +616,70 @@ func updateOne(ctx context.Context, tx *sql.Tx, id string, input *model.AudioSho
 		"description = $2, " +
 		"category = $3, " +
 		"audio_file = $4, " +
-		"creator_id = $5 " +
-		"WHERE id = $6"
+		"creator_id = $5"
+	args := []interface{}{input.Title, input.Description, input.Category, input.AudioFile, input.Creator.ID}
+	// without metadata, the columns are left to the trigger clearing them when the audio file changes
+	if metadata != nil {
+		m := newNullMetadata(metadata)
+		query += ", " +
+			"duration_ms = $6, " +
+			"sample_rate = $7, " +
+			"channels = $8, " +
+			"bitrate = $9, " +
+			"codec = $10"
+		args = append(args, m.durationMs, m.sampleRate, m.channels, m.bitrate, m.codec)
+	}
+	args = append(args, id)
+	query += " WHERE id = $" + strconv.Itoa(len(args))
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return
+}
+
+func patchOne(ctx context.Context, tx *sql.Tx, id string, patch *model.AudioShortPatch, metadata *model.AudioMetadata) (err error) {
+	var (
+		sets []string
+		args []interface{}
+	)
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, column+" = $"+strconv.Itoa(len(args)))
+	}
+	if patch.Title != nil {
+		set("title", *patch.Title)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	if patch.Category != nil {
+		set("category", *patch.Category)
+	}
+	if patch.AudioFile != nil {
+		set("audio_file", *patch.AudioFile)
+	}
+	if patch.Creator != nil {
+		set("creator_id", patch.Creator.ID)
+	}
+	if metadata != nil {
+		m := newNullMetadata(metadata)
+		set("duration_ms", m.durationMs)
+		set("sample_rate", m.sampleRate)
+		set("channels", m.channels)
+		set("bitrate", m.bitrate)
+		set("codec", m.codec)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	args = append(args, id)
+	query := "UPDATE " +
+		"audio_shorts " +
+		"SET " +
+		strings.Join(sets, ", ") + " " +
+		"WHERE id = $" + strconv.Itoa(len(args))
 
-	_, err = tx.ExecContext(ctx, query, input.Title, input.Description, input.Category.String(), input.AudioFile, input.Creator.ID, id)
+	_, err = tx.ExecContext(ctx, query, args...)
 	return
 }
 
@@ -205,6 +694,18 @@ func softDeleteOne(ctx context.Context, tx *sql.Tx, id string) (err error) {
 	return
 }
 
+func restoreOne(ctx context.Context, tx *sql.Tx, id string) (err error) {
+	query := "UPDATE " +
+		"audio_shorts " +
+		"SET " +
+		"status = $1 " +
+		"WHERE id = $2 " +
+		"AND status = $3"
+
+	_, err = tx.ExecContext(ctx, query, model.StatusActive.String(), id, model.StatusDeleted.String())
+	return
+}
+
 func hardDeleteOne(ctx context.Context, tx *sql.Tx, id string) (err error) {
 	query := "DELETE FROM " +
 		"audio_shorts " +
@@ -214,23 +715,78 @@ func hardDeleteOne(ctx context.Context, tx *sql.Tx, id string) (err error) {
 	return
 }
 
-func findAllCreators(ctx context.Context, tx *sql.Tx, page, limit uint16) (creators []*model.Creator, err error) {
-	creators = make([]*model.Creator, 0, limit) // set cap at limit
+func findPurgeable(ctx context.Context, tx *sql.Tx, deletedBefore time.Time, afterID string, limit uint16) (shorts []*model.AudioShort, err error) {
+	var (
+		id        string
+		title     string
+		audioFile string
+		creatorID string
+	)
+	query := "SELECT " +
+		"id, " +
+		"title, " +
+		"audio_file, " +
+		"creator_id " +
+		"FROM audio_shorts " +
+		"WHERE status = $1 " +
+		"AND deleted_at < $2 " +
+		"AND id > $3::int " +
+		"ORDER BY id " +
+		"LIMIT $4 " +
+		"FOR UPDATE"
+
+	rows, err := tx.QueryContext(ctx, query, model.StatusDeleted.String(), deletedBefore, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = rows.Close()
+	}()
+
+	for rows.Next() {
+		err = rows.Scan(&id, &title, &audioFile, &creatorID)
+		if err != nil {
+			return nil, err
+		}
+		shorts = append(shorts, &model.AudioShort{
+			ID:        id,
+			Title:     title,
+			AudioFile: audioFile,
+			Status:    model.StatusDeleted,
+			Creator:   &model.Creator{ID: creatorID},
+		})
+	}
+	return
+}
+
+func findAllCreators(ctx context.Context, tx *sql.Tx, limit uint16, after *Cursor) (edges []*model.CreatorEdge, err error) {
+	edges = make([]*model.CreatorEdge, 0, limit) // set cap at limit
 	var (
-		id    string
-		name  string
-		email string
+		id        string
+		username  string
+		name      string
+		email     string
+		status    string
+		createdAt string
 	)
 	query := "SELECT " +
 		"id, " +
+		"username, " +
 		"name, " +
-		"email " +
-		"FROM creators " +
-		"ORDER BY id ASC " +
-		"LIMIT $1 " +
-		"OFFSET $2"
+		"email, " +
+		"status, " +
+		"created_at::text " +
+		"FROM creators "
+	args := []interface{}{limit}
+	if after != nil {
+		// keyset pagination; newest entries first
+		query += "WHERE (created_at, id) < ($2::timestamptz, $3::int) "
+		args = append(args, after.Value, after.ID)
+	}
+	query += "ORDER BY created_at DESC, id DESC " +
+		"LIMIT $1"
 
-	rows, err := tx.QueryContext(ctx, query, limit, page)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -239,16 +795,1175 @@ func findAllCreators(ctx context.Context, tx *sql.Tx, page, limit uint16) (creat
 	}()
 
 	for rows.Next() {
-		err = rows.Scan(&id, &name, &email)
+		err = rows.Scan(&id, &username, &name, &email, &status, &createdAt)
 		if err != nil {
 			return nil, err
 		}
-		short := &model.Creator{
-			ID:    id,
-			Name:  name,
-			Email: email,
+		creator := &model.Creator{
+			ID:       id,
+			Username: username,
+			Name:     name,
+			Email:    email,
+			Status:   model.CreatorStatus(status),
 		}
-		creators = append(creators, short)
+		edges = append(edges, &model.CreatorEdge{
+			Cursor: EncodeCursor(Cursor{Key: CreatorsOrderKey, Value: createdAt, ID: id}),
+			Node:   creator,
+		})
+	}
+	return
+}
+
+func countCreators(ctx context.Context, tx *sql.Tx) (count int, err error) {
+	query := "SELECT " +
+		"COUNT(*) " +
+		"FROM creators"
+
+	row := tx.QueryRowContext(ctx, query)
+	err = row.Scan(&count)
+	return
+}
+
+func findCreatorByID(ctx context.Context, tx *sql.Tx, id string) (creator *model.Creator, err error) {
+	var (
+		username string
+		name     string
+		email    string
+		status   string
+	)
+	query := "SELECT " +
+		"username, " +
+		"name, " +
+		"email, " +
+		"status " +
+		"FROM creators " +
+		"WHERE id = $1"
+
+	row := tx.QueryRowContext(ctx, query, id)
+	err = row.Scan(&username, &name, &email, &status)
+	creator = &model.Creator{
+		ID:       id,
+		Username: username,
+		Name:     name,
+		Email:    email,
+		Status:   model.CreatorStatus(status),
+	}
+	return
+}
+
+func createCreator(ctx context.Context, tx *sql.Tx, input *model.CreatorDetailsInput) (id string, err error) {
+	query := "INSERT INTO " +
+		"creators( " +
+		"username, " +
+		"name, " +
+		"email, " +
+		"status " +
+		") VALUES (" +
+		"$1, " +
+		"$2, " +
+		"$3, " +
+		"$4 " + // set default status as 'active'
+		") RETURNING id"
+
+	row := tx.QueryRowContext(ctx, query, input.Username, input.Name, input.Email, model.CreatorStatusActive.String())
+	err = row.Scan(&id)
+	return
+}
+
+func updateCreator(ctx context.Context, tx *sql.Tx, id string, input *model.CreatorDetailsInput) (err error) {
+	query := "UPDATE " +
+		"creators " +
+		"SET " +
+		"username = $1, " +
+		"name = $2, " +
+		"email = $3 " +
+		"WHERE id = $4"
+
+	_, err = tx.ExecContext(ctx, query, input.Username, input.Name, input.Email, id)
+	return
+}
+
+func setCreatorStatus(ctx context.Context, tx *sql.Tx, id string, status model.CreatorStatus) (err error) {
+	query := "UPDATE " +
+		"creators " +
+		"SET " +
+		"status = $1 " +
+		"WHERE id = $2"
+
+	_, err = tx.ExecContext(ctx, query, status.String(), id)
+	return
+}
+
+// checkCreatorActive locks the creator for the rest of the transaction, so that it cannot be banned while a short is
+// being written for it, and returns a CreatorNotActiveError if it is not active
+func checkCreatorActive(ctx context.Context, tx *sql.Tx, id string) (err error) {
+	var status string
+	query := "SELECT " +
+		"status " +
+		"FROM creators " +
+		"WHERE id = $1 " +
+		"FOR SHARE"
+
+	row := tx.QueryRowContext(ctx, query, id)
+	err = row.Scan(&status)
+	if err == sql.ErrNoRows {
+		return &InvalidReferenceError{Constraint: "fk_creator", Err: err}
+	}
+	if err != nil {
+		return err
+	}
+	if model.CreatorStatus(status) != model.CreatorStatusActive {
+		return &CreatorNotActiveError{CreatorID: id, Status: model.CreatorStatus(status)}
+	}
+	return nil
+}
+
+// checkCategoryActive locks the category for the rest of the transaction, so that it cannot be archived while a short
+// is being written in it, and returns a CategoryArchivedError if it is archived, unless the short, if any, is already
+// in it
+func checkCategoryActive(ctx context.Context, tx *sql.Tx, slug string, shortID string) (err error) {
+	var archived, kept bool
+	query := "SELECT " +
+		"g.archived, " +
+		"EXISTS (SELECT 1 FROM audio_shorts AS a WHERE a.id = $2 AND a.category = g.slug) " +
+		"FROM categories AS g " +
+		"WHERE g.slug = $1 " +
+		"FOR SHARE"
+
+	row := tx.QueryRowContext(ctx, query, slug, sql.NullString{String: shortID, Valid: shortID != ""})
+	err = row.Scan(&archived, &kept)
+	if err == sql.ErrNoRows {
+		return &InvalidReferenceError{Constraint: "fk_category", Err: err}
 	}
+	if err != nil {
+		return err
+	}
+	if archived && !kept {
+		return &CategoryArchivedError{Slug: slug}
+	}
+	return nil
+}
+
+func banCreatorShorts(ctx context.Context, tx *sql.Tx, creatorID string) (err error) {
+	query := "UPDATE " +
+		"audio_shorts " +
+		"SET " +
+		"status = $1 " +
+		"WHERE creator_id = $2 " +
+		"AND status = $3"
+
+	_, err = tx.ExecContext(ctx, query, model.StatusBanned.String(), creatorID, model.StatusActive.String())
+	return
+}
+
+// categoryColumns are the columns of the category joined to a short, by its slug
+type categoryColumns struct {
+	id       string
+	slug     string
+	name     string
+	position int
+	archived bool
+}
+
+func (c categoryColumns) toModel() *model.Category {
+	return &model.Category{
+		ID:       c.id,
+		Slug:     c.slug,
+		Name:     c.name,
+		Position: c.position,
+		Archived: c.archived,
+	}
+}
+
+// nullMetadata holds the audio metadata columns of a short, which are null when the audio file was not parsed
+type nullMetadata struct {
+	durationMs sql.NullInt64
+	sampleRate sql.NullInt64
+	channels   sql.NullInt64
+	bitrate    sql.NullInt64
+	codec      sql.NullString
+}
+
+func newNullMetadata(metadata *model.AudioMetadata) (m nullMetadata) {
+	if metadata == nil {
+		return
+	}
+	return nullMetadata{
+		durationMs: sql.NullInt64{Int64: int64(math.Round(metadata.Duration * 1000)), Valid: true},
+		sampleRate: sql.NullInt64{Int64: int64(metadata.SampleRate), Valid: true},
+		channels:   sql.NullInt64{Int64: int64(metadata.Channels), Valid: true},
+		bitrate:    sql.NullInt64{Int64: int64(metadata.Bitrate), Valid: true},
+		codec:      sql.NullString{String: metadata.Codec, Valid: true},
+	}
+}
+
+func (m nullMetadata) toModel() *model.AudioMetadata {
+	if !m.codec.Valid {
+		return nil
+	}
+	return &model.AudioMetadata{
+		Duration:   float64(m.durationMs.Int64) / 1000,
+		SampleRate: int(m.sampleRate.Int64),
+		Channels:   int(m.channels.Int64),
+		Bitrate:    int(m.bitrate.Int64),
+		Codec:      m.codec.String,
+	}
+}
+
+// peakScale maps peaks from -1 to 1 to the smallint columns they are stored in
+const peakScale = 32767
+
+func findWaveform(ctx context.Context, tx *sql.Tx, shortID string) (waveform *model.Waveform, err error) {
+	var (
+		status     string
+		resolution int
+		minPeaks   pq.Int64Array
+		maxPeaks   pq.Int64Array
+	)
+	query := "SELECT " +
+		"status, " +
+		"resolution, " +
+		"min_peaks, " +
+		"max_peaks " +
+		"FROM waveforms " +
+		"WHERE short_id = $1"
+
+	row := tx.QueryRowContext(ctx, query, shortID)
+	err = row.Scan(&status, &resolution, &minPeaks, &maxPeaks)
+	if err == sql.ErrNoRows {
+		// no waveform was requested for the short
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.Waveform{
+		Status:     model.WaveformStatus(status),
+		Resolution: resolution,
+		Min:        fromPeakColumn(minPeaks),
+		Max:        fromPeakColumn(maxPeaks),
+	}, nil
+}
+
+func requestWaveform(ctx context.Context, tx *sql.Tx, shortID string) (err error) {
+	query := "INSERT INTO " +
+		"waveforms( " +
+		"short_id, " +
+		"status " +
+		") VALUES (" +
+		"$1, " +
+		"$2 " +
+		") " +
+		"ON CONFLICT (short_id) DO UPDATE SET " +
+		"status = EXCLUDED.status, " +
+		"resolution = 0, " +
+		"min_peaks = '{}', " +
+		"max_peaks = '{}', " +
+		"error = NULL"
+
+	_, err = tx.ExecContext(ctx, query, shortID, model.WaveformStatusPending.String())
+	return
+}
+
+func claimWaveforms(ctx context.Context, tx *sql.Tx, staleBefore time.Time, limit uint16) (jobs []*WaveformJob, err error) {
+	// claimed rows are locked and skipped by concurrent claims, so that every waveform is generated once
+	query := "UPDATE " +
+		"waveforms AS w " +
+		"SET " +
+		"status = $1 " +
+		"FROM audio_shorts AS a " +
+		"WHERE a.id = w.short_id AND w.short_id IN (" +
+		"SELECT short_id FROM waveforms " +
+		"WHERE status = $2 OR (status = $1 AND updated_at < $3) " +
+		"ORDER BY updated_at " +
+		"LIMIT $4 " +
+		"FOR UPDATE SKIP LOCKED" +
+		") " +
+		"RETURNING w.short_id, a.audio_file"
+
+	rows, err := tx.QueryContext(ctx, query, model.WaveformStatusProcessing.String(), model.WaveformStatusPending.String(), staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		job := &WaveformJob{}
+		err = rows.Scan(&job.ShortID, &job.AudioFile)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func completeWaveform(ctx context.Context, tx *sql.Tx, shortID string, min, max []float64) (err error) {
+	query := "UPDATE " +
+		"waveforms " +
+		"SET " +
+		"status = $1, " +
+		"resolution = $2, " +
+		"min_peaks = $3, " +
+		"max_peaks = $4, " +
+		"error = NULL " +
+		"WHERE short_id = $5 AND status = $6"
+
+	_, err = tx.ExecContext(ctx, query, model.WaveformStatusReady.String(), len(min), toPeakColumn(min), toPeakColumn(max),
+		shortID, model.WaveformStatusProcessing.String())
+	return
+}
+
+func failWaveform(ctx context.Context, tx *sql.Tx, shortID string, status model.WaveformStatus, reason string) (err error) {
+	query := "UPDATE " +
+		"waveforms " +
+		"SET " +
+		"status = $1, " +
+		"error = $2 " +
+		"WHERE short_id = $3 AND status = $4"
+
+	_, err = tx.ExecContext(ctx, query, status.String(), reason, shortID, model.WaveformStatusProcessing.String())
+	return
+}
+
+func findHLSPackage(ctx context.Context, tx *sql.Tx, shortID string) (pkg *HLSPackage, err error) {
+	var (
+		status      string
+		playlistURL sql.NullString
+		segments    int
+	)
+	query := "SELECT " +
+		"status, " +
+		"playlist_url, " +
+		"segments " +
+		"FROM hls_packages " +
+		"WHERE short_id = $1"
+
+	row := tx.QueryRowContext(ctx, query, shortID)
+	err = row.Scan(&status, &playlistURL, &segments)
+	if err == sql.ErrNoRows {
+		// no package was requested for the short
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &HLSPackage{
+		Status:      HLSStatus(status),
+		PlaylistURL: playlistURL.String,
+		Segments:    segments,
+	}, nil
+}
+
+func requestHLSPackage(ctx context.Context, tx *sql.Tx, shortID string) (err error) {
+	query := "INSERT INTO " +
+		"hls_packages( " +
+		"short_id, " +
+		"status " +
+		") VALUES (" +
+		"$1, " +
+		"$2 " +
+		") " +
+		"ON CONFLICT (short_id) DO UPDATE SET " +
+		"status = EXCLUDED.status, " +
+		"playlist_url = NULL, " +
+		"segments = 0, " +
+		"error = NULL"
+
+	_, err = tx.ExecContext(ctx, query, shortID, HLSStatusPending.String())
+	return
+}
+
+func claimHLSPackages(ctx context.Context, tx *sql.Tx, staleBefore time.Time, limit uint16) (jobs []*HLSJob, err error) {
+	// claimed rows are locked and skipped by concurrent claims, so that every package is segmented once
+	query := "UPDATE " +
+		"hls_packages AS h " +
+		"SET " +
+		"status = $1 " +
+		"FROM audio_shorts AS a " +
+		"WHERE a.id = h.short_id AND h.short_id IN (" +
+		"SELECT short_id FROM hls_packages " +
+		"WHERE status = $2 OR (status = $1 AND updated_at < $3) " +
+		"ORDER BY updated_at " +
+		"LIMIT $4 " +
+		"FOR UPDATE SKIP LOCKED" +
+		") " +
+		"RETURNING h.short_id, a.audio_file"
+
+	rows, err := tx.QueryContext(ctx, query, HLSStatusProcessing.String(), HLSStatusPending.String(), staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		job := &HLSJob{}
+		err = rows.Scan(&job.ShortID, &job.AudioFile)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func completeHLSPackage(ctx context.Context, tx *sql.Tx, shortID string, playlistURL string, segments int) (err error) {
+	query := "UPDATE " +
+		"hls_packages " +
+		"SET " +
+		"status = $1, " +
+		"playlist_url = $2, " +
+		"segments = $3, " +
+		"error = NULL " +
+		"WHERE short_id = $4 AND status = $5"
+
+	_, err = tx.ExecContext(ctx, query, HLSStatusReady.String(), playlistURL, segments, shortID, HLSStatusProcessing.String())
+	return
+}
+
+func failHLSPackage(ctx context.Context, tx *sql.Tx, shortID string, status HLSStatus, reason string) (err error) {
+	query := "UPDATE " +
+		"hls_packages " +
+		"SET " +
+		"status = $1, " +
+		"error = $2 " +
+		"WHERE short_id = $3 AND status = $4"
+
+	_, err = tx.ExecContext(ctx, query, status.String(), reason, shortID, HLSStatusProcessing.String())
+	return
+}
+
+func findLoudness(ctx context.Context, tx *sql.Tx, shortID string) (loudness *model.Loudness, err error) {
+	var integrated, truePeak sql.NullFloat64
+	loudness = &model.Loudness{}
+	query := "SELECT " +
+		"integrated, " +
+		"true_peak, " +
+		"silence_ratio, " +
+		"mostly_silent, " +
+		"replay_gain " +
+		"FROM loudness " +
+		"WHERE short_id = $1 AND status = $2"
+
+	row := tx.QueryRowContext(ctx, query, shortID, LoudnessStatusReady.String())
+	err = row.Scan(&integrated, &truePeak, &loudness.SilenceRatio, &loudness.MostlySilent, &loudness.ReplayGain)
+	if err == sql.ErrNoRows {
+		// the loudness of the short was not measured yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if integrated.Valid {
+		loudness.Integrated = &integrated.Float64
+	}
+	if truePeak.Valid {
+		loudness.TruePeak = &truePeak.Float64
+	}
+	return loudness, nil
+}
+
+func requestLoudness(ctx context.Context, tx *sql.Tx, shortID string) (err error) {
+	query := "INSERT INTO " +
+		"loudness( " +
+		"short_id, " +
+		"status " +
+		") VALUES (" +
+		"$1, " +
+		"$2 " +
+		") " +
+		"ON CONFLICT (short_id) DO UPDATE SET " +
+		"status = EXCLUDED.status, " +
+		"integrated = NULL, " +
+		"true_peak = NULL, " +
+		"silence_ratio = 0, " +
+		"mostly_silent = false, " +
+		"replay_gain = 0, " +
+		"error = NULL"
+
+	_, err = tx.ExecContext(ctx, query, shortID, LoudnessStatusPending.String())
+	return
+}
+
+func claimLoudness(ctx context.Context, tx *sql.Tx, staleBefore time.Time, limit uint16) (jobs []*LoudnessJob, err error) {
+	// claimed rows are locked and skipped by concurrent claims, so that every short is measured once
+	query := "UPDATE " +
+		"loudness AS l " +
+		"SET " +
+		"status = $1 " +
+		"FROM audio_shorts AS a " +
+		"WHERE a.id = l.short_id AND l.short_id IN (" +
+		"SELECT short_id FROM loudness " +
+		"WHERE status = $2 OR (status = $1 AND updated_at < $3) " +
+		"ORDER BY updated_at " +
+		"LIMIT $4 " +
+		"FOR UPDATE SKIP LOCKED" +
+		") " +
+		"RETURNING l.short_id, a.audio_file"
+
+	rows, err := tx.QueryContext(ctx, query, LoudnessStatusProcessing.String(), LoudnessStatusPending.String(), staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		job := &LoudnessJob{}
+		err = rows.Scan(&job.ShortID, &job.AudioFile)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func completeLoudness(ctx context.Context, tx *sql.Tx, shortID string, loudness *model.Loudness) (err error) {
+	query := "UPDATE " +
+		"loudness " +
+		"SET " +
+		"status = $1, " +
+		"integrated = $2, " +
+		"true_peak = $3, " +
+		"silence_ratio = $4, " +
+		"mostly_silent = $5, " +
+		"replay_gain = $6, " +
+		"error = NULL " +
+		"WHERE short_id = $7 AND status = $8"
+
+	_, err = tx.ExecContext(ctx, query, LoudnessStatusReady.String(), loudness.Integrated, loudness.TruePeak,
+		loudness.SilenceRatio, loudness.MostlySilent, loudness.ReplayGain, shortID, LoudnessStatusProcessing.String())
+	return
+}
+
+func failLoudness(ctx context.Context, tx *sql.Tx, shortID string, status LoudnessStatus, reason string) (err error) {
+	query := "UPDATE " +
+		"loudness " +
+		"SET " +
+		"status = $1, " +
+		"error = $2 " +
+		"WHERE short_id = $3 AND status = $4"
+
+	_, err = tx.ExecContext(ctx, query, status.String(), reason, shortID, LoudnessStatusProcessing.String())
+	return
+}
+
+func toPeakColumn(peaks []float64) pq.Int64Array {
+	column := make(pq.Int64Array, len(peaks))
+	for i, peak := range peaks {
+		column[i] = int64(math.Round(peak * peakScale))
+	}
+	return column
+}
+
+func fromPeakColumn(column pq.Int64Array) []float64 {
+	peaks := make([]float64, len(column))
+	for i, value := range column {
+		peaks[i] = float64(value) / peakScale
+	}
+	return peaks
+}
+
+func findFingerprint(ctx context.Context, tx *sql.Tx, shortID string) (fingerprint *Fingerprint, err error) {
+	fingerprint = &Fingerprint{ShortID: shortID}
+	query := "SELECT " +
+		"sha256, " +
+		"acoustic, " +
+		"flagged " +
+		"FROM audio_fingerprints " +
+		"WHERE short_id = $1"
+
+	row := tx.QueryRowContext(ctx, query, shortID)
+	err = row.Scan(&fingerprint.SHA256, &fingerprint.Acoustic, &fingerprint.Flagged)
+	if err == sql.ErrNoRows {
+		// the audio file of the short was not fingerprinted
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fingerprint, nil
+}
+
+func saveFingerprint(ctx context.Context, tx *sql.Tx, fingerprint *Fingerprint) (err error) {
+	query := "INSERT INTO " +
+		"audio_fingerprints( " +
+		"short_id, " +
+		"sha256, " +
+		"acoustic, " +
+		"flagged " +
+		") VALUES (" +
+		"$1, " +
+		"$2, " +
+		"$3, " +
+		"$4 " +
+		") " +
+		"ON CONFLICT (short_id) DO UPDATE SET " +
+		"sha256 = EXCLUDED.sha256, " +
+		"acoustic = EXCLUDED.acoustic, " +
+		"flagged = EXCLUDED.flagged"
+
+	_, err = tx.ExecContext(ctx, query, fingerprint.ShortID, fingerprint.SHA256, fingerprint.Acoustic, fingerprint.Flagged)
+	return
+}
+
+func findCandidateFingerprints(ctx context.Context, tx *sql.Tx, sha256 string, minLength, maxLength int, creatorID, shortID string) (fingerprints []*Fingerprint, err error) {
+	b := newQueryBuilder("SELECT " +
+		"f.short_id, " +
+		"f.sha256, " +
+		"f.acoustic, " +
+		"f.flagged " +
+		"FROM audio_fingerprints AS f " +
+		"INNER JOIN audio_shorts AS a ON a.id = f.short_id")
+	b.where("(f.sha256 = " + b.arg(sha256) + " OR octet_length(f.acoustic) BETWEEN " + b.arg(minLength) + " AND " + b.arg(maxLength) + ")")
+	if creatorID != "" {
+		b.where("a.creator_id <> " + b.arg(creatorID))
+	}
+	if shortID != "" {
+		b.where("f.short_id <> " + b.arg(shortID))
+	}
+	b.order("f.short_id")
+	query, args := b.build()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		fingerprint := &Fingerprint{}
+		err = rows.Scan(&fingerprint.ShortID, &fingerprint.SHA256, &fingerprint.Acoustic, &fingerprint.Flagged)
+		if err != nil {
+			return nil, err
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	return fingerprints, rows.Err()
+}
+
+func findTranscript(ctx context.Context, tx *sql.Tx, shortID string) (transcript *model.Transcript, err error) {
+	var (
+		starts pq.Int64Array
+		ends   pq.Int64Array
+		texts  pq.StringArray
+	)
+	query := "SELECT " +
+		"cue_starts, " +
+		"cue_ends, " +
+		"cue_texts " +
+		"FROM transcripts " +
+		"WHERE short_id = $1"
+
+	row := tx.QueryRowContext(ctx, query, shortID)
+	err = row.Scan(&starts, &ends, &texts)
+	if err == sql.ErrNoRows {
+		// no transcript was set for the short
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	transcript = &model.Transcript{Text: strings.Join(texts, "\n"), Cues: make([]*model.CaptionCue, len(texts))}
+	for i, text := range texts {
+		transcript.Cues[i] = &model.CaptionCue{
+			Start: float64(starts[i]) / 1000,
+			End:   float64(ends[i]) / 1000,
+			Text:  text,
+		}
+	}
+	return transcript, nil
+}
+
+func saveTranscript(ctx context.Context, tx *sql.Tx, shortID string, cues []*model.CaptionCue) (err error) {
+	starts := make(pq.Int64Array, len(cues))
+	ends := make(pq.Int64Array, len(cues))
+	texts := make(pq.StringArray, len(cues))
+	for i, cue := range cues {
+		starts[i] = int64(math.Round(cue.Start * 1000))
+		ends[i] = int64(math.Round(cue.End * 1000))
+		texts[i] = cue.Text
+	}
+	query := "INSERT INTO " +
+		"transcripts( " +
+		"short_id, " +
+		"cue_starts, " +
+		"cue_ends, " +
+		"cue_texts " +
+		") VALUES (" +
+		"$1, " +
+		"$2, " +
+		"$3, " +
+		"$4 " +
+		") " +
+		"ON CONFLICT (short_id) DO UPDATE SET " +
+		"cue_starts = EXCLUDED.cue_starts, " +
+		"cue_ends = EXCLUDED.cue_ends, " +
+		"cue_texts = EXCLUDED.cue_texts"
+
+	_, err = tx.ExecContext(ctx, query, shortID, starts, ends, texts)
+	return
+}
+
+func deleteTranscript(ctx context.Context, tx *sql.Tx, shortID string) (err error) {
+	query := "DELETE FROM " +
+		"transcripts " +
+		"WHERE short_id = $1"
+
+	_, err = tx.ExecContext(ctx, query, shortID)
+	return
+}
+
+func findChapters(ctx context.Context, tx *sql.Tx, shortID string) (chapters []*model.Chapter, err error) {
+	query := "SELECT " +
+		"start_ms, " +
+		"end_ms, " +
+		"title, " +
+		"image_url " +
+		"FROM chapters " +
+		"WHERE short_id = $1 " +
+		"ORDER BY position"
+
+	rows, err := tx.QueryContext(ctx, query, shortID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chapters = []*model.Chapter{}
+	for rows.Next() {
+		var (
+			start, end int64
+			chapter    model.Chapter
+		)
+		err = rows.Scan(&start, &end, &chapter.Title, &chapter.ImageURL)
+		if err != nil {
+			return nil, err
+		}
+		chapter.Start = float64(start) / 1000
+		chapter.End = float64(end) / 1000
+		chapters = append(chapters, &chapter)
+	}
+	return chapters, rows.Err()
+}
+
+func saveChapters(ctx context.Context, tx *sql.Tx, shortID string, chapters []*model.Chapter) (err error) {
+	err = deleteChapters(ctx, tx, shortID)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO " +
+		"chapters( " +
+		"short_id, " +
+		"position, " +
+		"start_ms, " +
+		"end_ms, " +
+		"title, " +
+		"image_url " +
+		") VALUES (" +
+		"$1, " +
+		"$2, " +
+		"$3, " +
+		"$4, " +
+		"$5, " +
+		"$6 " +
+		")"
+
+	for i, chapter := range chapters {
+		start := int64(math.Round(chapter.Start * 1000))
+		end := int64(math.Round(chapter.End * 1000))
+		_, err = tx.ExecContext(ctx, query, shortID, i, start, end, chapter.Title, chapter.ImageURL)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteChapters(ctx context.Context, tx *sql.Tx, shortID string) (err error) {
+	query := "DELETE FROM " +
+		"chapters " +
+		"WHERE short_id = $1"
+
+	_, err = tx.ExecContext(ctx, query, shortID)
+	return
+}
+
+func findImages(ctx context.Context, tx *sql.Tx, owner ImageOwner, ownerID string) (images []*model.Image, err error) {
+	query := "SELECT " +
+		"size, " +
+		"url, " +
+		"width, " +
+		"height, " +
+		"content_type " +
+		"FROM " + owner.table + " " +
+		"WHERE " + owner.column + " = $1 " +
+		"ORDER BY size"
+
+	rows, err := tx.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images = []*model.Image{}
+	for rows.Next() {
+		var image model.Image
+		err = rows.Scan(&image.Size, &image.URL, &image.Width, &image.Height, &image.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, &image)
+	}
+	return images, rows.Err()
+}
+
+func saveImages(ctx context.Context, tx *sql.Tx, owner ImageOwner, ownerID string, images []*model.Image) (err error) {
+	query := "INSERT INTO " +
+		owner.table + "( " +
+		owner.column + ", " +
+		"size, " +
+		"url, " +
+		"width, " +
+		"height, " +
+		"content_type " +
+		") VALUES (" +
+		"$1, " +
+		"$2, " +
+		"$3, " +
+		"$4, " +
+		"$5, " +
+		"$6 " +
+		")"
+
+	for _, image := range images {
+		_, err = tx.ExecContext(ctx, query, ownerID, image.Size, image.URL, image.Width, image.Height, image.ContentType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteImages drops the image of the owner and returns the URLs of its sizes
+func deleteImages(ctx context.Context, tx *sql.Tx, owner ImageOwner, ownerID string) (urls []string, err error) {
+	query := "DELETE FROM " +
+		owner.table + " " +
+		"WHERE " + owner.column + " = $1 " +
+		"RETURNING url"
+
+	rows, err := tx.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url string
+		err = rows.Scan(&url)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+func findTags(ctx context.Context, tx *sql.Tx, shortID string) (tags []string, err error) {
+	query := "SELECT " +
+		"t.name " +
+		"FROM audio_short_tags AS st " +
+		"JOIN tags AS t ON t.id = st.tag_id " +
+		"WHERE st.short_id = $1 " +
+		"ORDER BY t.name"
+
+	rows, err := tx.QueryContext(ctx, query, shortID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags = []string{}
+	for rows.Next() {
+		var tag string
+		err = rows.Scan(&tag)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func saveTags(ctx context.Context, tx *sql.Tx, shortID string, tags []string) (err error) {
+	query := "DELETE FROM " +
+		"audio_short_tags " +
+		"WHERE short_id = $1"
+
+	_, err = tx.ExecContext(ctx, query, shortID)
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+
+	// tags are shared by shorts, so only the ones never used before are created
+	query = "INSERT INTO " +
+		"tags(name) " +
+		"SELECT unnest($1::text[]) " +
+		"ON CONFLICT (name) DO NOTHING"
+
+	_, err = tx.ExecContext(ctx, query, pq.Array(tags))
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO " +
+		"audio_short_tags(short_id, tag_id) " +
+		"SELECT $1, id " +
+		"FROM tags " +
+		"WHERE name = ANY($2::text[])"
+
+	_, err = tx.ExecContext(ctx, query, shortID, pq.Array(tags))
+	return err
+}
+
+func findAllTags(ctx context.Context, tx *sql.Tx, limit uint16, after *Cursor) (edges []*model.TagEdge, err error) {
+	edges = make([]*model.TagEdge, 0, limit) // set cap at limit
+	var (
+		id         string
+		name       string
+		usageCount int
+	)
+	query := "SELECT " +
+		"t.id, " +
+		"t.name, " +
+		"COUNT(*) " +
+		"FROM tags AS t " +
+		"JOIN audio_short_tags AS st ON st.tag_id = t.id " +
+		"JOIN audio_shorts AS a ON a.id = st.short_id " +
+		"WHERE a.status = $2 " +
+		"GROUP BY t.id "
+	args := []interface{}{limit, model.StatusActive.String()}
+	if after != nil {
+		// keyset pagination; most used entries first
+		query += "HAVING (COUNT(*), t.id) < ($3::int, $4::int) "
+		args = append(args, after.Value, after.ID)
+	}
+	query += "ORDER BY COUNT(*) DESC, t.id DESC " +
+		"LIMIT $1"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = rows.Close()
+	}()
+
+	for rows.Next() {
+		err = rows.Scan(&id, &name, &usageCount)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.TagEdge{
+			Cursor: EncodeCursor(Cursor{Key: TagsOrderKey, Value: strconv.Itoa(usageCount), ID: id}),
+			Node:   &model.Tag{Name: name, UsageCount: usageCount},
+		})
+	}
+	return
+}
+
+func countTags(ctx context.Context, tx *sql.Tx) (count int, err error) {
+	query := "SELECT " +
+		"COUNT(DISTINCT st.tag_id) " +
+		"FROM audio_short_tags AS st " +
+		"JOIN audio_shorts AS a ON a.id = st.short_id " +
+		"WHERE a.status = $1"
+
+	row := tx.QueryRowContext(ctx, query, model.StatusActive.String())
+	err = row.Scan(&count)
+	return
+}
+
+func autocompleteTags(ctx context.Context, tx *sql.Tx, prefix string, limit uint16) (tags []*model.Tag, err error) {
+	query := "SELECT " +
+		"t.name, " +
+		"COUNT(*) " +
+		"FROM tags AS t " +
+		"JOIN audio_short_tags AS st ON st.tag_id = t.id " +
+		"JOIN audio_shorts AS a ON a.id = st.short_id " +
+		"WHERE t.name LIKE $1 AND a.status = $2 " +
+		"GROUP BY t.id " +
+		"ORDER BY COUNT(*) DESC, t.name " +
+		"LIMIT $3"
+
+	rows, err := tx.QueryContext(ctx, query, escapeLike(prefix)+"%", model.StatusActive.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags = []*model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+		err = rows.Scan(&tag.Name, &tag.UsageCount)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
+
+func findCategories(ctx context.Context, tx *sql.Tx, includeArchived bool) (categories []*model.Category, err error) {
+	query := "SELECT " +
+		"id, " +
+		"slug, " +
+		"name, " +
+		"position, " +
+		"archived " +
+		"FROM categories "
+	if !includeArchived {
+		query += "WHERE NOT archived "
+	}
+	query += "ORDER BY position, id"
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories = []*model.Category{}
+	for rows.Next() {
+		var category model.Category
+		err = rows.Scan(&category.ID, &category.Slug, &category.Name, &category.Position, &category.Archived)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, &category)
+	}
+	return categories, rows.Err()
+}
+
+func findCategoryByID(ctx context.Context, tx *sql.Tx, id string) (category *model.Category, err error) {
+	category = &model.Category{ID: id}
+	query := "SELECT " +
+		"slug, " +
+		"name, " +
+		"position, " +
+		"archived " +
+		"FROM categories " +
+		"WHERE id = $1"
+
+	row := tx.QueryRowContext(ctx, query, id)
+	err = row.Scan(&category.Slug, &category.Name, &category.Position, &category.Archived)
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func findCategoryNames(ctx context.Context, tx *sql.Tx, id string) (names []*model.LocalizedName, err error) {
+	query := "SELECT " +
+		"locale, " +
+		"name " +
+		"FROM category_names " +
+		"WHERE category_id = $1 " +
+		"ORDER BY locale"
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names = []*model.LocalizedName{}
+	for rows.Next() {
+		var name model.LocalizedName
+		err = rows.Scan(&name.Locale, &name.Name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, &name)
+	}
+	return names, rows.Err()
+}
+
+func createCategory(ctx context.Context, tx *sql.Tx, input *model.CategoryInput) (id string, err error) {
+	query := "INSERT INTO " +
+		"categories( " +
+		"slug, " +
+		"name, " +
+		"position " +
+		") SELECT " +
+		"$1, " +
+		"$2, " +
+		"COALESCE(MAX(position), 0) + 1 " + // after the other categories
+		"FROM categories " +
+		"RETURNING id"
+
+	row := tx.QueryRowContext(ctx, query, input.Slug, input.Name)
+	err = row.Scan(&id)
+	return
+}
+
+func renameCategory(ctx context.Context, tx *sql.Tx, id string, name string) (err error) {
+	query := "UPDATE " +
+		"categories " +
+		"SET " +
+		"name = $1 " +
+		"WHERE id = $2"
+
+	_, err = tx.ExecContext(ctx, query, name, id)
+	return
+}
+
+func saveCategoryNames(ctx context.Context, tx *sql.Tx, id string, names []*model.LocalizedNameInput) (err error) {
+	query := "DELETE FROM " +
+		"category_names " +
+		"WHERE category_id = $1"
+
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO " +
+		"category_names( " +
+		"category_id, " +
+		"locale, " +
+		"name " +
+		") VALUES (" +
+		"$1, " +
+		"$2, " +
+		"$3 " +
+		")"
+
+	for _, name := range names {
+		_, err = tx.ExecContext(ctx, query, id, name.Locale, name.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setCategoryArchived(ctx context.Context, tx *sql.Tx, id string, archived bool) (err error) {
+	query := "UPDATE " +
+		"categories " +
+		"SET " +
+		"archived = $1 " +
+		"WHERE id = $2"
+
+	_, err = tx.ExecContext(ctx, query, archived, id)
+	return
+}
+
+func reorderCategories(ctx context.Context, tx *sql.Tx, ids []string) (err error) {
+	// every category is moved to the position of its ID in the list, starting at 1
+	query := "UPDATE " +
+		"categories AS g " +
+		"SET " +
+		"position = o.position " +
+		"FROM unnest($1::int[]) WITH ORDINALITY AS o(id, position) " +
+		"WHERE g.id = o.id"
+
+	_, err = tx.ExecContext(ctx, query, pq.Array(ids))
 	return
 }
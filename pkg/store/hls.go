@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=hls.go -destination=hls_mock.go -package=store HLSStore
+
+// HLSStatus is the status of the HLS package of a short, which goes through the same statuses as waveforms
+type HLSStatus string
+
+const (
+	HLSStatusPending     HLSStatus = "pending"
+	HLSStatusProcessing  HLSStatus = "processing"
+	HLSStatusReady       HLSStatus = "ready"
+	HLSStatusFailed      HLSStatus = "failed"
+	HLSStatusUnsupported HLSStatus = "unsupported"
+)
+
+// HLSStore is the repository for the HLS packages of shorts, a playlist and its segments in the blob store, which
+// are packaged asynchronously: a package is requested as pending, claimed by a packager as processing, then completed
+// as ready or failed
+type (
+	HLSStore interface {
+		// Get returns the HLS package of the short, or nil when none was requested
+		Get(ctx context.Context, shortID string) (pkg *HLSPackage, err error)
+		// Request marks the HLS package of the short as pending
+		Request(ctx context.Context, shortID string) (err error)
+		// Claim marks up to limit pending packages as processing, along with those left processing since before
+		// staleBefore, claiming the oldest first
+		Claim(ctx context.Context, staleBefore time.Time, limit uint16) (jobs []*HLSJob, err error)
+		// Complete stores the URL of the playlist of a processing package and marks it as ready; packages dropped in
+		// the meantime, as the audio file of their short changed, are left alone
+		Complete(ctx context.Context, shortID string, playlistURL string, segments int) (err error)
+		// Fail marks a processing package as failed or unsupported, for the reason
+		Fail(ctx context.Context, shortID string, status HLSStatus, reason string) (err error)
+	}
+
+	// HLSPackage is the HLS package of a short; the playlist URL is only set once it is ready
+	HLSPackage struct {
+		Status      HLSStatus
+		PlaylistURL string
+		Segments    int
+	}
+
+	// HLSJob is a claimed HLS package, to be segmented from the audio file of its short
+	HLSJob struct {
+		ShortID   string
+		AudioFile string
+	}
+
+	hlsStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewHLSStore(db *sql.DB) (HLSStore, error) {
+	return &hlsStore{
+		db: db,
+	}, nil
+}
+
+func (s HLSStatus) String() string {
+	return string(s)
+}
+
+func (s *hlsStore) Get(ctx context.Context, shortID string) (pkg *HLSPackage, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	pkg, err = findHLSPackage(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *hlsStore) Request(ctx context.Context, shortID string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = requestHLSPackage(ctx, tx, shortID)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCreateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *hlsStore) Claim(ctx context.Context, staleBefore time.Time, limit uint16) (jobs []*HLSJob, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	jobs, err = claimHLSPackages(ctx, tx, staleBefore, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *hlsStore) Complete(ctx context.Context, shortID string, playlistURL string, segments int) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = completeHLSPackage(ctx, tx, shortID, playlistURL, segments)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *hlsStore) Fail(ctx context.Context, shortID string, status HLSStatus, reason string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = failHLSPackage(ctx, tx, shortID, status, reason)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
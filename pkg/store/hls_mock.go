@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: hls.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHLSStore is a mock of HLSStore interface.
+type MockHLSStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockHLSStoreMockRecorder
+}
+
+// MockHLSStoreMockRecorder is the mock recorder for MockHLSStore.
+type MockHLSStoreMockRecorder struct {
+	mock *MockHLSStore
+}
+
+// NewMockHLSStore creates a new mock instance.
+func NewMockHLSStore(ctrl *gomock.Controller) *MockHLSStore {
+	mock := &MockHLSStore{ctrl: ctrl}
+	mock.recorder = &MockHLSStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHLSStore) EXPECT() *MockHLSStoreMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockHLSStore) Claim(ctx context.Context, staleBefore time.Time, limit uint16) ([]*HLSJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, staleBefore, limit)
+	ret0, _ := ret[0].([]*HLSJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockHLSStoreMockRecorder) Claim(ctx, staleBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockHLSStore)(nil).Claim), ctx, staleBefore, limit)
+}
+
+// Complete mocks base method.
+func (m *MockHLSStore) Complete(ctx context.Context, shortID, playlistURL string, segments int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, shortID, playlistURL, segments)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockHLSStoreMockRecorder) Complete(ctx, shortID, playlistURL, segments interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockHLSStore)(nil).Complete), ctx, shortID, playlistURL, segments)
+}
+
+// Fail mocks base method.
+func (m *MockHLSStore) Fail(ctx context.Context, shortID string, status HLSStatus, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, shortID, status, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockHLSStoreMockRecorder) Fail(ctx, shortID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockHLSStore)(nil).Fail), ctx, shortID, status, reason)
+}
+
+// Get mocks base method.
+func (m *MockHLSStore) Get(ctx context.Context, shortID string) (*HLSPackage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].(*HLSPackage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockHLSStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockHLSStore)(nil).Get), ctx, shortID)
+}
+
+// Request mocks base method.
+func (m *MockHLSStore) Request(ctx context.Context, shortID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", ctx, shortID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockHLSStoreMockRecorder) Request(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockHLSStore)(nil).Request), ctx, shortID)
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHLSStore_Get(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewHLSStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT status, playlist_url, segments FROM hls_packages WHERE short_id = $1")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "playlist_url", "segments"}).
+				AddRow(HLSStatusReady, "http://localhost:8080/files/hls/1/index.m3u8", 3))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &HLSPackage{Status: HLSStatusReady, PlaylistURL: "http://localhost:8080/files/hls/1/index.m3u8", Segments: 3}, resp)
+	})
+
+	t.Run("happy path - pending", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "playlist_url", "segments"}).
+				AddRow(HLSStatusPending, nil, 0))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &HLSPackage{Status: HLSStatusPending}, resp)
+	})
+
+	t.Run("happy path - not requested", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "playlist_url", "segments"}))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestHLSStore_Request(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewHLSStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("INSERT INTO hls_packages( short_id, status ) VALUES ($1, $2 ) ON CONFLICT (short_id) DO UPDATE SET status = EXCLUDED.status, playlist_url = NULL, segments = 0, error = NULL")).
+			WithArgs(shortID, HLSStatusPending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Request(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestHLSStore_Claim(t *testing.T) {
+	staleBefore := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewHLSStore(db)
+	assert.NoError(t, err)
+
+	claimQuery := regexp.QuoteMeta("UPDATE hls_packages AS h SET status = $1 FROM audio_shorts AS a WHERE a.id = h.short_id AND h.short_id IN (SELECT short_id FROM hls_packages WHERE status = $2 OR (status = $1 AND updated_at < $3) ORDER BY updated_at LIMIT $4 FOR UPDATE SKIP LOCKED) RETURNING h.short_id, a.audio_file")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(claimQuery).
+			WithArgs(HLSStatusProcessing, HLSStatusPending, staleBefore, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"short_id", "audio_file"}).
+				AddRow("1", "http://localhost:8080/files/shorts/abc.mp3")).RowsWillBeClosed()
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Claim(ctx, staleBefore, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*HLSJob{{ShortID: "1", AudioFile: "http://localhost:8080/files/shorts/abc.mp3"}}, resp)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(claimQuery).
+			WithArgs(HLSStatusProcessing, HLSStatusPending, staleBefore, 5).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Claim(ctx, staleBefore, 5)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestHLSStore_Complete(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewHLSStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE hls_packages SET status = $1, playlist_url = $2, segments = $3, error = NULL WHERE short_id = $4 AND status = $5")).
+			WithArgs(HLSStatusReady, "http://localhost:8080/files/hls/1/index.m3u8", 3, shortID, HLSStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Complete(ctx, shortID, "http://localhost:8080/files/hls/1/index.m3u8", 3)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestHLSStore_Fail(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewHLSStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE hls_packages SET status = $1, error = $2 WHERE short_id = $3 AND status = $4")).
+			WithArgs(HLSStatusUnsupported, "segmenting audio/wav", shortID, HLSStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Fail(ctx, shortID, HLSStatusUnsupported, "segmenting audio/wav")
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
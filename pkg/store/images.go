@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=images.go -destination=images_mock.go -package=store ImagesStore
+
+// ImageOwner tells apart the images of shorts, their cover art, from those of creators, their avatars, which are
+// kept in tables of the same shape and in the blob store under prefixes of their own
+type ImageOwner struct {
+	table     string
+	column    string
+	keyPrefix string
+}
+
+var (
+	// CoverArt owns the cover art of shorts, by short ID
+	CoverArt = ImageOwner{table: "cover_art", column: "short_id", keyPrefix: "covers/"}
+	// Avatar owns the avatars of creators, by creator ID
+	Avatar = ImageOwner{table: "avatars", column: "creator_id", keyPrefix: "avatars/"}
+)
+
+// KeyPrefix returns the prefix of the blob keys of the images of the owner with the ID, e.g. "covers/1/", or of the
+// images of every owner when the ID is empty
+func (o ImageOwner) KeyPrefix(ownerID string) string {
+	if ownerID == "" {
+		return o.keyPrefix
+	}
+	return o.keyPrefix + ownerID + "/"
+}
+
+// ImagesStore is the repository for the images of shorts and creators, the original image and its thumbnails, one
+// per size
+type (
+	ImagesStore interface {
+		// Get returns the sizes of the image of the owner, empty when none was uploaded
+		Get(ctx context.Context, owner ImageOwner, ownerID string) (images []*model.Image, err error)
+		// Save replaces the image of the owner with the sizes, and returns the URLs of the sizes it replaced
+		Save(ctx context.Context, owner ImageOwner, ownerID string, images []*model.Image) (replaced []string, err error)
+		// Delete drops the image of the owner, if any, and returns the URLs of its sizes
+		Delete(ctx context.Context, owner ImageOwner, ownerID string) (deleted []string, err error)
+	}
+
+	imagesStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewImagesStore(db *sql.DB) (ImagesStore, error) {
+	return &imagesStore{
+		db: db,
+	}, nil
+}
+
+func (s *imagesStore) Get(ctx context.Context, owner ImageOwner, ownerID string) (images []*model.Image, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	images, err = findImages(ctx, tx, owner, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" "+owner.column+":"+ownerID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *imagesStore) Save(ctx context.Context, owner ImageOwner, ownerID string, images []*model.Image) (replaced []string, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	replaced, err = deleteImages(ctx, tx, owner, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" "+owner.column+":"+ownerID)
+	}
+	err = saveImages(ctx, tx, owner, ownerID, images)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" "+owner.column+":"+ownerID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *imagesStore) Delete(ctx context.Context, owner ImageOwner, ownerID string) (deleted []string, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	deleted, err = deleteImages(ctx, tx, owner, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageDeleteFailed+" "+owner.column+":"+ownerID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: images.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockImagesStore is a mock of ImagesStore interface.
+type MockImagesStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockImagesStoreMockRecorder
+}
+
+// MockImagesStoreMockRecorder is the mock recorder for MockImagesStore.
+type MockImagesStoreMockRecorder struct {
+	mock *MockImagesStore
+}
+
+// NewMockImagesStore creates a new mock instance.
+func NewMockImagesStore(ctrl *gomock.Controller) *MockImagesStore {
+	mock := &MockImagesStore{ctrl: ctrl}
+	mock.recorder = &MockImagesStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImagesStore) EXPECT() *MockImagesStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockImagesStore) Delete(ctx context.Context, owner ImageOwner, ownerID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, owner, ownerID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockImagesStoreMockRecorder) Delete(ctx, owner, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockImagesStore)(nil).Delete), ctx, owner, ownerID)
+}
+
+// Get mocks base method.
+func (m *MockImagesStore) Get(ctx context.Context, owner ImageOwner, ownerID string) ([]*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, owner, ownerID)
+	ret0, _ := ret[0].([]*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockImagesStoreMockRecorder) Get(ctx, owner, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockImagesStore)(nil).Get), ctx, owner, ownerID)
+}
+
+// Save mocks base method.
+func (m *MockImagesStore) Save(ctx context.Context, owner ImageOwner, ownerID string, images []*model.Image) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, owner, ownerID, images)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockImagesStoreMockRecorder) Save(ctx, owner, ownerID, images interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockImagesStore)(nil).Save), ctx, owner, ownerID, images)
+}
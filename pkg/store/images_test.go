@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImagesStore_Get(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewImagesStore(db)
+	assert.NoError(t, err)
+
+	columns := []string{"size", "url", "width", "height", "content_type"}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT size, url, width, height, content_type FROM cover_art WHERE short_id = $1 ORDER BY size")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("small", "http://localhost/covers/1/a-small.png", 64, 32, "image/png").
+				AddRow("original", "http://localhost/covers/1/a.png", 400, 200, "image/png"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, CoverArt, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Image{
+			{Size: model.ImageSizeSmall, URL: "http://localhost/covers/1/a-small.png", Width: 64, Height: 32, ContentType: "image/png"},
+			{Size: model.ImageSizeOriginal, URL: "http://localhost/covers/1/a.png", Width: 400, Height: 200, ContentType: "image/png"},
+		}, resp)
+	})
+
+	t.Run("happy path - avatar not set", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT size, url, width, height, content_type FROM avatars WHERE creator_id = $1 ORDER BY size")).
+			WithArgs("2").
+			WillReturnRows(sqlmock.NewRows(columns))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, Avatar, "2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Image{}, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT size, url, width, height, content_type FROM cover_art")).
+			WithArgs("1").
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, CoverArt, "1")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestImagesStore_Save(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewImagesStore(db)
+	assert.NoError(t, err)
+
+	deleteQuery := regexp.QuoteMeta("DELETE FROM avatars WHERE creator_id = $1 RETURNING url")
+	insertQuery := regexp.QuoteMeta("INSERT INTO avatars( creator_id, size, url, width, height, content_type ) VALUES ($1, $2, $3, $4, $5, $6 )")
+	images := []*model.Image{
+		{Size: model.ImageSizeSmall, URL: "http://localhost/avatars/1/b-small.jpg", Width: 64, Height: 64, ContentType: "image/jpeg"},
+		{Size: model.ImageSizeOriginal, URL: "http://localhost/avatars/1/b.jpg", Width: 100, Height: 100, ContentType: "image/jpeg"},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(deleteQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"url"}).AddRow("http://localhost/avatars/1/a.webp"))
+		sqlMock.ExpectExec(insertQuery).
+			WithArgs("1", "small", "http://localhost/avatars/1/b-small.jpg", 64, 64, "image/jpeg").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(insertQuery).
+			WithArgs("1", "original", "http://localhost/avatars/1/b.jpg", 100, 100, "image/jpeg").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		replaced, err := store.Save(ctx, Avatar, "1", images)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"http://localhost/avatars/1/a.webp"}, replaced)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(deleteQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"url"}))
+		sqlMock.ExpectExec(insertQuery).
+			WithArgs("1", "small", sqlmock.AnyArg(), 64, 64, "image/jpeg").
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		replaced, err := store.Save(ctx, Avatar, "1", images)
+
+		assert.Error(t, err)
+		assert.Nil(t, replaced)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestImagesStore_Delete(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewImagesStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("DELETE FROM cover_art WHERE short_id = $1 RETURNING url")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"url"}).AddRow("http://localhost/covers/1/a.png").AddRow("http://localhost/covers/1/a-small.png"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		deleted, err := store.Delete(ctx, CoverArt, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"http://localhost/covers/1/a.png", "http://localhost/covers/1/a-small.png"}, deleted)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=loudness.go -destination=loudness_mock.go -package=store LoudnessStore
+
+// LoudnessStatus is the status of the loudness analysis of a short, which goes through the same statuses as waveforms
+type LoudnessStatus string
+
+const (
+	LoudnessStatusPending     LoudnessStatus = "pending"
+	LoudnessStatusProcessing  LoudnessStatus = "processing"
+	LoudnessStatusReady       LoudnessStatus = "ready"
+	LoudnessStatusFailed      LoudnessStatus = "failed"
+	LoudnessStatusUnsupported LoudnessStatus = "unsupported"
+)
+
+// LoudnessStore is the repository for the loudness of shorts, which is measured asynchronously: an analysis is
+// requested as pending, claimed by an analyzer as processing, then completed as ready or failed
+type (
+	LoudnessStore interface {
+		// Get returns the loudness of the short once it is measured, or nil
+		Get(ctx context.Context, shortID string) (loudness *model.Loudness, err error)
+		// Request marks the loudness analysis of the short as pending, dropping any previous measures
+		Request(ctx context.Context, shortID string) (err error)
+		// Claim marks up to limit pending analyses as processing, along with those left processing since before
+		// staleBefore, claiming the oldest first
+		Claim(ctx context.Context, staleBefore time.Time, limit uint16) (jobs []*LoudnessJob, err error)
+		// Complete stores the loudness of a processing analysis and marks it as ready; analyses dropped in the
+		// meantime, as the audio file of their short changed, are left alone
+		Complete(ctx context.Context, shortID string, loudness *model.Loudness) (err error)
+		// Fail marks a processing analysis as failed or unsupported, for the reason
+		Fail(ctx context.Context, shortID string, status LoudnessStatus, reason string) (err error)
+	}
+
+	// LoudnessJob is a claimed loudness analysis, to be measured from the audio file of its short
+	LoudnessJob struct {
+		ShortID   string
+		AudioFile string
+	}
+
+	loudnessStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewLoudnessStore(db *sql.DB) (LoudnessStore, error) {
+	return &loudnessStore{
+		db: db,
+	}, nil
+}
+
+func (s LoudnessStatus) String() string {
+	return string(s)
+}
+
+func (s *loudnessStore) Get(ctx context.Context, shortID string) (loudness *model.Loudness, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	loudness, err = findLoudness(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *loudnessStore) Request(ctx context.Context, shortID string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = requestLoudness(ctx, tx, shortID)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCreateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *loudnessStore) Claim(ctx context.Context, staleBefore time.Time, limit uint16) (jobs []*LoudnessJob, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	jobs, err = claimLoudness(ctx, tx, staleBefore, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *loudnessStore) Complete(ctx context.Context, shortID string, loudness *model.Loudness) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = completeLoudness(ctx, tx, shortID, loudness)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *loudnessStore) Fail(ctx context.Context, shortID string, status LoudnessStatus, reason string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = failLoudness(ctx, tx, shortID, status, reason)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: loudness.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockLoudnessStore is a mock of LoudnessStore interface.
+type MockLoudnessStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoudnessStoreMockRecorder
+}
+
+// MockLoudnessStoreMockRecorder is the mock recorder for MockLoudnessStore.
+type MockLoudnessStoreMockRecorder struct {
+	mock *MockLoudnessStore
+}
+
+// NewMockLoudnessStore creates a new mock instance.
+func NewMockLoudnessStore(ctrl *gomock.Controller) *MockLoudnessStore {
+	mock := &MockLoudnessStore{ctrl: ctrl}
+	mock.recorder = &MockLoudnessStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoudnessStore) EXPECT() *MockLoudnessStoreMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockLoudnessStore) Claim(ctx context.Context, staleBefore time.Time, limit uint16) ([]*LoudnessJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, staleBefore, limit)
+	ret0, _ := ret[0].([]*LoudnessJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockLoudnessStoreMockRecorder) Claim(ctx, staleBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockLoudnessStore)(nil).Claim), ctx, staleBefore, limit)
+}
+
+// Complete mocks base method.
+func (m *MockLoudnessStore) Complete(ctx context.Context, shortID string, loudness *model.Loudness) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, shortID, loudness)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockLoudnessStoreMockRecorder) Complete(ctx, shortID, loudness interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockLoudnessStore)(nil).Complete), ctx, shortID, loudness)
+}
+
+// Fail mocks base method.
+func (m *MockLoudnessStore) Fail(ctx context.Context, shortID string, status LoudnessStatus, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, shortID, status, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockLoudnessStoreMockRecorder) Fail(ctx, shortID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockLoudnessStore)(nil).Fail), ctx, shortID, status, reason)
+}
+
+// Get mocks base method.
+func (m *MockLoudnessStore) Get(ctx context.Context, shortID string) (*model.Loudness, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].(*model.Loudness)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockLoudnessStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLoudnessStore)(nil).Get), ctx, shortID)
+}
+
+// Request mocks base method.
+func (m *MockLoudnessStore) Request(ctx context.Context, shortID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", ctx, shortID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockLoudnessStoreMockRecorder) Request(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockLoudnessStore)(nil).Request), ctx, shortID)
+}
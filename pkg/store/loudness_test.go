@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoudnessStore_Get(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewLoudnessStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT integrated, true_peak, silence_ratio, mostly_silent, replay_gain FROM loudness WHERE short_id = $1 AND status = $2")
+	columns := []string{"integrated", "true_peak", "silence_ratio", "mostly_silent", "replay_gain"}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID, LoudnessStatusReady).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(-23.0, -3.5, 0.1, false, 2.5))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		integrated, truePeak := -23.0, -3.5
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Loudness{Integrated: &integrated, TruePeak: &truePeak, SilenceRatio: 0.1, ReplayGain: 2.5}, resp)
+	})
+
+	t.Run("happy path - silence", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID, LoudnessStatusReady).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(nil, nil, 1.0, true, 0.0))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Loudness{SilenceRatio: 1, MostlySilent: true}, resp)
+	})
+
+	t.Run("happy path - not measured", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID, LoudnessStatusReady).
+			WillReturnRows(sqlmock.NewRows(columns))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID, LoudnessStatusReady).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestLoudnessStore_Request(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewLoudnessStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("INSERT INTO loudness( short_id, status ) VALUES ($1, $2 ) ON CONFLICT (short_id) DO UPDATE SET status = EXCLUDED.status, integrated = NULL, true_peak = NULL, silence_ratio = 0, mostly_silent = false, replay_gain = 0, error = NULL")).
+			WithArgs(shortID, LoudnessStatusPending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Request(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestLoudnessStore_Claim(t *testing.T) {
+	staleBefore := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewLoudnessStore(db)
+	assert.NoError(t, err)
+
+	claimQuery := regexp.QuoteMeta("UPDATE loudness AS l SET status = $1 FROM audio_shorts AS a WHERE a.id = l.short_id AND l.short_id IN (SELECT short_id FROM loudness WHERE status = $2 OR (status = $1 AND updated_at < $3) ORDER BY updated_at LIMIT $4 FOR UPDATE SKIP LOCKED) RETURNING l.short_id, a.audio_file")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(claimQuery).
+			WithArgs(LoudnessStatusProcessing, LoudnessStatusPending, staleBefore, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"short_id", "audio_file"}).
+				AddRow("1", "http://localhost:8080/files/shorts/abc.wav")).RowsWillBeClosed()
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Claim(ctx, staleBefore, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*LoudnessJob{{ShortID: "1", AudioFile: "http://localhost:8080/files/shorts/abc.wav"}}, resp)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(claimQuery).
+			WithArgs(LoudnessStatusProcessing, LoudnessStatusPending, staleBefore, 10).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Claim(ctx, staleBefore, 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestLoudnessStore_Complete(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewLoudnessStore(db)
+	assert.NoError(t, err)
+
+	completeQuery := regexp.QuoteMeta("UPDATE loudness SET status = $1, integrated = $2, true_peak = $3, silence_ratio = $4, mostly_silent = $5, replay_gain = $6, error = NULL WHERE short_id = $7 AND status = $8")
+
+	t.Run("happy path", func(t *testing.T) {
+		integrated, truePeak := -23.0, -3.5
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(completeQuery).
+			WithArgs(LoudnessStatusReady, integrated, truePeak, 0.1, false, 2.5, shortID, LoudnessStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Complete(ctx, shortID, &model.Loudness{Integrated: &integrated, TruePeak: &truePeak, SilenceRatio: 0.1, ReplayGain: 2.5})
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("happy path - silence", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(completeQuery).
+			WithArgs(LoudnessStatusReady, nil, nil, 1.0, true, 0.0, shortID, LoudnessStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Complete(ctx, shortID, &model.Loudness{SilenceRatio: 1, MostlySilent: true})
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestLoudnessStore_Fail(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewLoudnessStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE loudness SET status = $1, error = $2 WHERE short_id = $3 AND status = $4")).
+			WithArgs(LoudnessStatusUnsupported, "decoding audio/mpeg", shortID, LoudnessStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Fail(ctx, shortID, LoudnessStatusUnsupported, "decoding audio/mpeg")
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// queryBuilder assembles a SELECT statement with positional arguments, so that user input is never
+// interpolated into the SQL string; only trusted fragments may be passed as conditions and order terms
+type queryBuilder struct {
+	selects    string
+	conditions []string
+	orderBy    []string
+	limit      string
+	args       []interface{}
+}
+
+// newQueryBuilder starts a statement with the given SELECT ... FROM ... clause
+func newQueryBuilder(selects string) *queryBuilder {
+	return &queryBuilder{selects: selects}
+}
+
+// arg registers an argument and returns its placeholder
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// where adds a condition, joined to the others with AND
+func (b *queryBuilder) where(condition string) *queryBuilder {
+	b.conditions = append(b.conditions, condition)
+	return b
+}
+
+// order adds ORDER BY terms
+func (b *queryBuilder) order(terms ...string) *queryBuilder {
+	b.orderBy = append(b.orderBy, terms...)
+	return b
+}
+
+// withLimit sets the LIMIT argument
+func (b *queryBuilder) withLimit(limit interface{}) *queryBuilder {
+	b.limit = b.arg(limit)
+	return b
+}
+
+// build returns the query and its arguments
+func (b *queryBuilder) build() (string, []interface{}) {
+	query := b.selects
+	if len(b.conditions) > 0 {
+		query += " WHERE " + strings.Join(b.conditions, " AND ")
+	}
+	if len(b.orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(b.orderBy, ", ")
+	}
+	if b.limit != "" {
+		query += " LIMIT " + b.limit
+	}
+	return query, b.args
+}
+
+// escapeLike escapes the LIKE wildcards in s, so it is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// prefixTSQuery turns free text into a tsquery matching every word as a prefix, e.g. "covid new" becomes
+// "covid:* & new:*"; all other characters are dropped so that the result is always valid tsquery syntax
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
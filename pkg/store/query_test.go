@@ -0,0 +1,40 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		b := newQueryBuilder("SELECT a.id FROM audio_shorts AS a")
+		b.where("a.title = " + b.arg("abc")).
+			where("a.status = " + b.arg("active")).
+			order("a.id ASC").
+			withLimit(10)
+		query, args := b.build()
+
+		assert.Equal(t, "SELECT a.id FROM audio_shorts AS a WHERE a.title = $1 AND a.status = $2 ORDER BY a.id ASC LIMIT $3", query)
+		assert.Equal(t, []interface{}{"abc", "active", 10}, args)
+	})
+
+	t.Run("happy path - no clauses", func(t *testing.T) {
+		query, args := newQueryBuilder("SELECT COUNT(*) FROM audio_shorts AS a").build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM audio_shorts AS a", query)
+		assert.Empty(t, args)
+	})
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\%`, escapeLike("50%"))
+	assert.Equal(t, `a\_b`, escapeLike("a_b"))
+	assert.Equal(t, `a\\b`, escapeLike(`a\b`))
+}
+
+func TestPrefixTSQuery(t *testing.T) {
+	assert.Equal(t, "covid:* & new:*", prefixTSQuery("covid new"))
+	assert.Equal(t, "it:* & s:* & a:* & b:*", prefixTSQuery("it's (a) | !b & "))
+	assert.Equal(t, "", prefixTSQuery(" :* "))
+}
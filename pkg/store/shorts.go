@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"sync"
+	"time"
 
 	"github.com/nooble/task/audio-short-api/pkg/api/model"
 	"github.com/nooble/task/audio-short-api/pkg/logging"
@@ -12,21 +13,38 @@ import (
 
 //go:generate mockgen -source=shorts.go -destination=shorts_mock.go -package=store AudioShortsStore
 
-// AudioShortsStore is the repository for audio shorts
+// AudioShortsStore is the repository for audio shorts; writes given an expected version fail when the entry has a
+// different one
 type (
 	AudioShortsStore interface {
 		// GetByID returns the entry corresponding to the given ID
 		GetByID(ctx context.Context, id string) (short *model.AudioShort, err error)
-		// GetAll returns the entries given the page and limit
-		GetAll(ctx context.Context, page, limit uint16) (shorts []*model.AudioShort, err error)
-		// Create inserts a new entry into the table
-		Create(ctx context.Context, input *model.AudioShortInput) (short *model.AudioShort, err error)
-		// Update updates the entry
-		Update(ctx context.Context, id string, input *model.AudioShortInput) (short *model.AudioShort, err error)
+		// GetByIDs returns the entries corresponding to the given IDs in one query, in no particular order; IDs without
+		// an entry are left out
+		GetByIDs(ctx context.Context, ids []string) (shorts []*model.AudioShort, err error)
+		// GetAll returns up to first entries matching the filter after the given cursor, newest first unless ordered otherwise;
+		// only active entries are returned, unless other statuses are included
+		GetAll(ctx context.Context, first uint16, after *Cursor, filter *model.AudioShortFilter, orderBy *model.AudioShortOrder, includeStatuses []model.Status) (shorts *model.AudioShortConnection, err error)
+		// Search returns up to first active entries matching the text after the given cursor, most relevant first
+		Search(ctx context.Context, text string, first uint16, after *Cursor) (results *model.AudioShortSearchConnection, err error)
+		// Create inserts a new entry into the table, with the metadata of its audio file if known; the creator must be active
+		// and the category not archived
+		Create(ctx context.Context, input *model.AudioShortInput, metadata *model.AudioMetadata) (short *model.AudioShort, err error)
+		// Update updates the entry, and the metadata of its audio file if given; the creator must be active, and the
+		// category not archived unless the entry is already in it
+		Update(ctx context.Context, id string, input *model.AudioShortInput, metadata *model.AudioMetadata, expectedVersion *int) (short *model.AudioShort, err error)
+		// Patch updates only the given fields of the entry, and the metadata of its audio file if given; the creator,
+		// new or current, must be active, and a new category not archived unless the entry is already in it
+		Patch(ctx context.Context, id string, patch *model.AudioShortPatch, metadata *model.AudioMetadata, expectedVersion *int) (short *model.AudioShort, err error)
 		// Delete updates the status to 'deleted'
-		Delete(ctx context.Context, id string) (short *model.AudioShort, err error)
+		Delete(ctx context.Context, id string, expectedVersion *int) (short *model.AudioShort, err error)
+		// Restore updates the status of a deleted entry back to 'active'; the creator must be active
+		Restore(ctx context.Context, id string, expectedVersion *int) (short *model.AudioShort, err error)
 		// HardDelete removes the entry completely
-		HardDelete(ctx context.Context, id string) (short *model.AudioShort, err error)
+		HardDelete(ctx context.Context, id string, expectedVersion *int) (short *model.AudioShort, err error)
+		// Purge hard deletes up to limit entries, with IDs after afterID, that were deleted before the given time;
+		// in a dry run the entries are only returned
+		Purge(ctx context.Context, deletedBefore time.Time, afterID string, limit uint16, dryRun bool) (shorts []*model.AudioShort, err error)
 	}
 
 	shortsStore struct {
@@ -47,12 +65,14 @@ func (s *shortsStore) GetByID(ctx context.Context, id string) (short *model.Audi
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -72,18 +92,20 @@ func (s *shortsStore) GetByID(ctx context.Context, id string) (short *model.Audi
 	return
 }
 
-func (s *shortsStore) GetAll(ctx context.Context, page, limit uint16) (shorts []*model.AudioShort, err error) {
+func (s *shortsStore) GetByIDs(ctx context.Context, ids []string) (shorts []*model.AudioShort, err error) {
 	s.Lock()
 	defer s.Unlock()
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -91,7 +113,7 @@ func (s *shortsStore) GetAll(ctx context.Context, page, limit uint16) (shorts []
 		}
 	}()
 
-	shorts, err = findAllShorts(ctx, tx, page, limit)
+	shorts, err = findAllByIDs(ctx, tx, ids)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrorMessageFindFailed)
 	}
@@ -103,18 +125,20 @@ func (s *shortsStore) GetAll(ctx context.Context, page, limit uint16) (shorts []
 	return
 }
 
-func (s *shortsStore) Create(ctx context.Context, input *model.AudioShortInput) (short *model.AudioShort, err error) {
+func (s *shortsStore) GetAll(ctx context.Context, first uint16, after *Cursor, filter *model.AudioShortFilter, orderBy *model.AudioShortOrder, includeStatuses []model.Status) (shorts *model.AudioShortConnection, err error) {
 	s.Lock()
 	defer s.Unlock()
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -122,7 +146,117 @@ func (s *shortsStore) Create(ctx context.Context, input *model.AudioShortInput)
 		}
 	}()
 
-	err = createOne(ctx, tx, input)
+	// fetch one extra entry to know if there is a next page
+	edges, err := findAllShorts(ctx, tx, first+1, after, filter, orderBy, includeStatuses)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+	count, err := countShorts(ctx, tx, filter, includeStatuses)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCountFailed)
+	}
+
+	shorts = &model.AudioShortConnection{
+		PageInfo:   &model.PageInfo{},
+		TotalCount: count,
+	}
+	if len(edges) > int(first) {
+		edges = edges[:first]
+		shorts.PageInfo.HasNextPage = true
+	}
+	if len(edges) > 0 {
+		shorts.PageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+	shorts.Edges = edges
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *shortsStore) Search(ctx context.Context, text string, first uint16, after *Cursor) (results *model.AudioShortSearchConnection, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	// fetch one extra entry to know if there is a next page
+	edges, err := searchShorts(ctx, tx, text, first+1, after)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+	count, err := countSearchShorts(ctx, tx, text)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCountFailed)
+	}
+
+	results = &model.AudioShortSearchConnection{
+		PageInfo:   &model.PageInfo{},
+		TotalCount: count,
+	}
+	if len(edges) > int(first) {
+		edges = edges[:first]
+		results.PageInfo.HasNextPage = true
+	}
+	if len(edges) > 0 {
+		results.PageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+	results.Edges = edges
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *shortsStore) Create(ctx context.Context, input *model.AudioShortInput, metadata *model.AudioMetadata) (short *model.AudioShort, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = checkCreatorActive(ctx, tx, input.Creator.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCreateFailed)
+	}
+	err = checkCategoryActive(ctx, tx, input.Category, "")
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCreateFailed)
+	}
+	err = createOne(ctx, tx, input, metadata)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrorMessageCreateFailed)
 	}
@@ -138,18 +272,71 @@ func (s *shortsStore) Create(ctx context.Context, input *model.AudioShortInput)
 	return
 }
 
-func (s *shortsStore) Update(ctx context.Context, id string, input *model.AudioShortInput) (short *model.AudioShort, err error) {
+func (s *shortsStore) Update(ctx context.Context, id string, input *model.AudioShortInput, metadata *model.AudioMetadata, expectedVersion *int) (short *model.AudioShort, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	if expectedVersion != nil {
+		err = checkVersion(ctx, tx, id, *expectedVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+		}
+	}
+	err = checkCreatorActive(ctx, tx, input.Creator.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	err = checkCategoryActive(ctx, tx, input.Category, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	err = updateOne(ctx, tx, id, input, metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	short, err = findOneByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *shortsStore) Patch(ctx context.Context, id string, patch *model.AudioShortPatch, metadata *model.AudioMetadata, expectedVersion *int) (short *model.AudioShort, err error) {
 	s.Lock()
 	defer s.Unlock()
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -157,7 +344,34 @@ func (s *shortsStore) Update(ctx context.Context, id string, input *model.AudioS
 		}
 	}()
 
-	err = updateOne(ctx, tx, id, input)
+	if expectedVersion != nil {
+		err = checkVersion(ctx, tx, id, *expectedVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+		}
+	}
+	// without a new creator, the current one must still be active
+	creatorID := ""
+	if patch.Creator != nil {
+		creatorID = patch.Creator.ID
+	} else {
+		short, err = findOneByID(ctx, tx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+		}
+		creatorID = short.Creator.ID
+	}
+	err = checkCreatorActive(ctx, tx, creatorID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	if patch.Category != nil {
+		err = checkCategoryActive(ctx, tx, *patch.Category, id)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+		}
+	}
+	err = patchOne(ctx, tx, id, patch, metadata)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
 	}
@@ -173,19 +387,21 @@ func (s *shortsStore) Update(ctx context.Context, id string, input *model.AudioS
 	return
 }
 
-func (s *shortsStore) Delete(ctx context.Context, id string) (short *model.AudioShort, err error) {
+func (s *shortsStore) Delete(ctx context.Context, id string, expectedVersion *int) (short *model.AudioShort, err error) {
 	s.Lock()
 	defer s.Unlock()
 
 	short = &model.AudioShort{}
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -193,6 +409,12 @@ func (s *shortsStore) Delete(ctx context.Context, id string) (short *model.Audio
 		}
 	}()
 
+	if expectedVersion != nil {
+		err = checkVersion(ctx, tx, id, *expectedVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageDeleteFailed+" ID:"+id)
+		}
+	}
 	err = softDeleteOne(ctx, tx, id)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrorMessageDeleteFailed+" ID:"+id)
@@ -209,19 +431,75 @@ func (s *shortsStore) Delete(ctx context.Context, id string) (short *model.Audio
 	return
 }
 
-func (s *shortsStore) HardDelete(ctx context.Context, id string) (short *model.AudioShort, err error) {
+func (s *shortsStore) Restore(ctx context.Context, id string, expectedVersion *int) (short *model.AudioShort, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	if expectedVersion != nil {
+		err = checkVersion(ctx, tx, id, *expectedVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+		}
+	}
+	short, err = findOneByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+	if short.Status != model.StatusDeleted {
+		return nil, &NotDeletedError{ID: id, Status: short.Status}
+	}
+	err = checkCreatorActive(ctx, tx, short.Creator.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	err = restoreOne(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed+" ID:"+id)
+	}
+	short, err = findOneByID(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *shortsStore) HardDelete(ctx context.Context, id string, expectedVersion *int) (short *model.AudioShort, err error) {
 	s.Lock()
 	defer s.Unlock()
 
 	short = &model.AudioShort{}
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, ErrorMessageTransactionFailed)
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
 	}
 
 	defer func() {
 		// evaluated when function returns
 		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
 			err := tx.Rollback()
 			if err != nil {
 				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
@@ -229,6 +507,12 @@ func (s *shortsStore) HardDelete(ctx context.Context, id string) (short *model.A
 		}
 	}()
 
+	if expectedVersion != nil {
+		err = checkVersion(ctx, tx, id, *expectedVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrorMessageDeleteFailed+" ID:"+id)
+		}
+	}
 	short, err = findOneByID(ctx, tx, id)
 	if err != nil {
 		return nil, errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id)
@@ -244,3 +528,44 @@ func (s *shortsStore) HardDelete(ctx context.Context, id string) (short *model.A
 	}
 	return
 }
+
+func (s *shortsStore) Purge(ctx context.Context, deletedBefore time.Time, afterID string, limit uint16, dryRun bool) (shorts []*model.AudioShort, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	shorts, err = findPurgeable(ctx, tx, deletedBefore, afterID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+	if !dryRun {
+		for _, short := range shorts {
+			err = hardDeleteOne(ctx, tx, short.ID)
+			if err != nil {
+				return nil, errors.Wrap(err, ErrorMessageDeleteFailed+" ID:"+short.ID)
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
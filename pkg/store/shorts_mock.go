@@ -7,6 +7,7 @@ package store
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/nooble/task/audio-short-api/pkg/api/model"
@@ -36,48 +37,48 @@ func (m *MockAudioShortsStore) EXPECT() *MockAudioShortsStoreMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockAudioShortsStore) Create(ctx context.Context, input *model.AudioShortInput) (*model.AudioShort, error) {
+func (m *MockAudioShortsStore) Create(ctx context.Context, input *model.AudioShortInput, metadata *model.AudioMetadata) (*model.AudioShort, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, input)
+	ret := m.ctrl.Call(m, "Create", ctx, input, metadata)
 	ret0, _ := ret[0].(*model.AudioShort)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockAudioShortsStoreMockRecorder) Create(ctx, input interface{}) *gomock.Call {
+func (mr *MockAudioShortsStoreMockRecorder) Create(ctx, input, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAudioShortsStore)(nil).Create), ctx, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAudioShortsStore)(nil).Create), ctx, input, metadata)
 }
 
 // Delete mocks base method.
-func (m *MockAudioShortsStore) Delete(ctx context.Context, id string) (*model.AudioShort, error) {
+func (m *MockAudioShortsStore) Delete(ctx context.Context, id string, expectedVersion *int) (*model.AudioShort, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, expectedVersion)
 	ret0, _ := ret[0].(*model.AudioShort)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockAudioShortsStoreMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockAudioShortsStoreMockRecorder) Delete(ctx, id, expectedVersion interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAudioShortsStore)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAudioShortsStore)(nil).Delete), ctx, id, expectedVersion)
 }
 
 // GetAll mocks base method.
-func (m *MockAudioShortsStore) GetAll(ctx context.Context, page, limit uint16) ([]*model.AudioShort, error) {
+func (m *MockAudioShortsStore) GetAll(ctx context.Context, first uint16, after *Cursor, filter *model.AudioShortFilter, orderBy *model.AudioShortOrder, includeStatuses []model.Status) (*model.AudioShortConnection, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx, page, limit)
-	ret0, _ := ret[0].([]*model.AudioShort)
+	ret := m.ctrl.Call(m, "GetAll", ctx, first, after, filter, orderBy, includeStatuses)
+	ret0, _ := ret[0].(*model.AudioShortConnection)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockAudioShortsStoreMockRecorder) GetAll(ctx, page, limit interface{}) *gomock.Call {
+func (mr *MockAudioShortsStoreMockRecorder) GetAll(ctx, first, after, filter, orderBy, includeStatuses interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAudioShortsStore)(nil).GetAll), ctx, page, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAudioShortsStore)(nil).GetAll), ctx, first, after, filter, orderBy, includeStatuses)
 }
 
 // GetByID mocks base method.
@@ -95,32 +96,107 @@ func (mr *MockAudioShortsStoreMockRecorder) GetByID(ctx, id interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockAudioShortsStore)(nil).GetByID), ctx, id)
 }
 
+// GetByIDs mocks base method.
+func (m *MockAudioShortsStore) GetByIDs(ctx context.Context, ids []string) ([]*model.AudioShort, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*model.AudioShort)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockAudioShortsStoreMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockAudioShortsStore)(nil).GetByIDs), ctx, ids)
+}
+
 // HardDelete mocks base method.
-func (m *MockAudioShortsStore) HardDelete(ctx context.Context, id string) (*model.AudioShort, error) {
+func (m *MockAudioShortsStore) HardDelete(ctx context.Context, id string, expectedVersion *int) (*model.AudioShort, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HardDelete", ctx, id)
+	ret := m.ctrl.Call(m, "HardDelete", ctx, id, expectedVersion)
 	ret0, _ := ret[0].(*model.AudioShort)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // HardDelete indicates an expected call of HardDelete.
-func (mr *MockAudioShortsStoreMockRecorder) HardDelete(ctx, id interface{}) *gomock.Call {
+func (mr *MockAudioShortsStoreMockRecorder) HardDelete(ctx, id, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDelete", reflect.TypeOf((*MockAudioShortsStore)(nil).HardDelete), ctx, id, expectedVersion)
+}
+
+// Patch mocks base method.
+func (m *MockAudioShortsStore) Patch(ctx context.Context, id string, patch *model.AudioShortPatch, metadata *model.AudioMetadata, expectedVersion *int) (*model.AudioShort, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, patch, metadata, expectedVersion)
+	ret0, _ := ret[0].(*model.AudioShort)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockAudioShortsStoreMockRecorder) Patch(ctx, id, patch, metadata, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockAudioShortsStore)(nil).Patch), ctx, id, patch, metadata, expectedVersion)
+}
+
+// Purge mocks base method.
+func (m *MockAudioShortsStore) Purge(ctx context.Context, deletedBefore time.Time, afterID string, limit uint16, dryRun bool) ([]*model.AudioShort, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, deletedBefore, afterID, limit, dryRun)
+	ret0, _ := ret[0].([]*model.AudioShort)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockAudioShortsStoreMockRecorder) Purge(ctx, deletedBefore, afterID, limit, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockAudioShortsStore)(nil).Purge), ctx, deletedBefore, afterID, limit, dryRun)
+}
+
+// Restore mocks base method.
+func (m *MockAudioShortsStore) Restore(ctx context.Context, id string, expectedVersion *int) (*model.AudioShort, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, expectedVersion)
+	ret0, _ := ret[0].(*model.AudioShort)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockAudioShortsStoreMockRecorder) Restore(ctx, id, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockAudioShortsStore)(nil).Restore), ctx, id, expectedVersion)
+}
+
+// Search mocks base method.
+func (m *MockAudioShortsStore) Search(ctx context.Context, text string, first uint16, after *Cursor) (*model.AudioShortSearchConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, text, first, after)
+	ret0, _ := ret[0].(*model.AudioShortSearchConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockAudioShortsStoreMockRecorder) Search(ctx, text, first, after interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDelete", reflect.TypeOf((*MockAudioShortsStore)(nil).HardDelete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockAudioShortsStore)(nil).Search), ctx, text, first, after)
 }
 
 // Update mocks base method.
-func (m *MockAudioShortsStore) Update(ctx context.Context, id string, input *model.AudioShortInput) (*model.AudioShort, error) {
+func (m *MockAudioShortsStore) Update(ctx context.Context, id string, input *model.AudioShortInput, metadata *model.AudioMetadata, expectedVersion *int) (*model.AudioShort, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, input)
+	ret := m.ctrl.Call(m, "Update", ctx, id, input, metadata, expectedVersion)
 	ret0, _ := ret[0].(*model.AudioShort)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockAudioShortsStoreMockRecorder) Update(ctx, id, input interface{}) *gomock.Call {
+func (mr *MockAudioShortsStoreMockRecorder) Update(ctx, id, input, metadata, expectedVersion interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAudioShortsStore)(nil).Update), ctx, id, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAudioShortsStore)(nil).Update), ctx, id, input, metadata, expectedVersion)
 }
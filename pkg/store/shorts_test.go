@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/nooble/task/audio-short-api/pkg/api/model"
 	"github.com/nooble/task/audio-short-api/pkg/logging"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func TestShortsStore_GetByID(t *testing.T) {
@@ -18,7 +20,7 @@ func TestShortsStore_GetByID(t *testing.T) {
 		title       = "abc"
 		description = "abcs"
 		status      = model.StatusActive
-		category    = model.CategoryNews
+		category    = "news"
 		audioFile   = "a"
 		creatorID   = "1"
 		name        = "hi"
@@ -32,10 +34,10 @@ func TestShortsStore_GetByID(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.id = $1")).
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
 			WithArgs(ID).
-			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "audio_file", "id", "name", "email"}).
-				AddRow(title, description, status, category, audioFile, creatorID, name, email)).RowsWillBeClosed()
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, 2500, 44100, 2, 128000, "mp3", creatorID, name, email, "jackfrost", model.CreatorStatusActive)).RowsWillBeClosed()
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
@@ -46,12 +48,14 @@ func TestShortsStore_GetByID(t *testing.T) {
 		assert.Equal(t, title, resp.Title)
 		assert.Equal(t, name, resp.Creator.Name)
 		assert.Equal(t, email, resp.Creator.Email)
+		assert.Equal(t, &model.Category{ID: "1", Slug: category, Name: "News", Position: 1}, resp.Category)
+		assert.Equal(t, &model.AudioMetadata{Duration: 2.5, SampleRate: 44100, Channels: 2, Bitrate: 128000, Codec: "mp3"}, resp.Metadata)
 	})
 
 	t.Run("sad path - no rows", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.id = $1")).
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
 			WithArgs(ID).
 			WillReturnError(sql.ErrNoRows)
 		sqlMock.ExpectRollback()
@@ -59,6 +63,52 @@ func TestShortsStore_GetByID(t *testing.T) {
 		ctx := logging.NewContext(context.Background())
 		resp, err := store.GetByID(ctx, ID)
 
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestShortsStore_GetByIDs(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewShortsStore(db)
+	assert.NoError(t, err)
+	query := regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = ANY($1::int[])")
+	columns := []string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(query).
+			WithArgs(pq.Array([]string{"1", "2", "3"})).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "abc", "abcs", model.StatusActive, "news", "1", "News", 1, false, "a", 1, 2500, 44100, 2, 128000, "mp3", "1", "hi", "mockemail@gmail.com", "jackfrost", model.CreatorStatusActive).
+				AddRow("3", "def", "defs", model.StatusDeleted, "news", "1", "News", 1, false, "b", 2, nil, nil, nil, nil, nil, "2", "ho", "other@gmail.com", "jackie", model.CreatorStatusBanned)).RowsWillBeClosed()
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetByIDs(ctx, []string{"1", "2", "3"})
+
+		assert.NoError(t, err)
+		assert.Len(t, resp, 2)
+		assert.Equal(t, "1", resp[0].ID)
+		assert.Equal(t, &model.AudioMetadata{Duration: 2.5, SampleRate: 44100, Channels: 2, Bitrate: 128000, Codec: "mp3"}, resp[0].Metadata)
+		assert.Equal(t, "3", resp[1].ID)
+		assert.Equal(t, model.StatusDeleted, resp[1].Status)
+		assert.Nil(t, resp[1].Metadata)
+		assert.Equal(t, model.CreatorStatusBanned, resp[1].Creator.Status)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(query).
+			WithArgs(pq.Array([]string{"1"})).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetByIDs(ctx, []string{"1"})
+
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
@@ -70,8 +120,9 @@ func TestShortsStore_GetAll(t *testing.T) {
 		title       = "abc"
 		description = "abcs"
 		status      = model.StatusActive
-		category    = model.CategoryNews
+		category    = "news"
 		audioFile   = "a"
+		createdAt   = "2021-01-02 03:04:05.123456+00"
 		creatorID   = "1"
 		name        = "hi"
 		email       = "mockemail@gmail.com"
@@ -84,33 +135,181 @@ func TestShortsStore_GetAll(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id ORDER BY a.id ASC LIMIT $1 OFFSET $2")).
-			WithArgs(1, 0).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "audio_file", "id", "name", "email"}).
-				AddRow(ID, title, description, status, category, audioFile, creatorID, name, email)).RowsWillBeClosed()
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, a.created_at::text, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.status = ANY($1::audio_shorts_status[]) ORDER BY a.created_at DESC, a.id DESC LIMIT $2")).
+			WithArgs(pq.Array([]string{"active"}), 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "created_at", "id", "name", "email", "username", "status"}).
+				AddRow(ID, title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, createdAt, creatorID, name, email, "jackfrost", model.CreatorStatusActive).
+				AddRow("2", title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, createdAt, creatorID, name, email, "jackfrost", model.CreatorStatusActive)).RowsWillBeClosed()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM audio_shorts AS a WHERE a.status = ANY($1::audio_shorts_status[])")).
+			WithArgs(pq.Array([]string{"active"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.GetAll(ctx, 0, 1)
+		resp, err := store.GetAll(ctx, 1, nil, nil, nil, nil)
 
 		assert.NoError(t, err)
-		assert.Equal(t, 1, len(resp))
-		assert.Equal(t, ID, resp[0].ID)
-		assert.Equal(t, title, resp[0].Title)
-		assert.Equal(t, name, resp[0].Creator.Name)
-		assert.Equal(t, email, resp[0].Creator.Email)
+		assert.Equal(t, 1, len(resp.Edges))
+		assert.Equal(t, 2, resp.TotalCount)
+		assert.True(t, resp.PageInfo.HasNextPage)
+		assert.Equal(t, EncodeCursor(Cursor{Key: "created_at_desc", Value: createdAt, ID: ID}), *resp.PageInfo.EndCursor)
+		assert.Equal(t, ID, resp.Edges[0].Node.ID)
+		assert.Equal(t, title, resp.Edges[0].Node.Title)
+		assert.Equal(t, name, resp.Edges[0].Node.Creator.Name)
+		assert.Equal(t, email, resp.Edges[0].Node.Creator.Email)
+	})
+
+	t.Run("happy path - after cursor", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, a.created_at::text, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.status = ANY($1::audio_shorts_status[]) AND (a.created_at, a.id) < ($2::timestamptz, $3::int) ORDER BY a.created_at DESC, a.id DESC LIMIT $4")).
+			WithArgs(pq.Array([]string{"active"}), createdAt, ID, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "created_at", "id", "name", "email", "username", "status"}).
+				AddRow("2", title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, createdAt, creatorID, name, email, "jackfrost", model.CreatorStatusActive)).RowsWillBeClosed()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM audio_shorts AS a WHERE a.status = ANY($1::audio_shorts_status[])")).
+			WithArgs(pq.Array([]string{"active"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, &Cursor{Key: "created_at_desc", Value: createdAt, ID: ID}, nil, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(resp.Edges))
+		assert.False(t, resp.PageInfo.HasNextPage)
+		assert.Equal(t, "2", resp.Edges[0].Node.ID)
+	})
+
+	t.Run("happy path - filter and order", func(t *testing.T) {
+		titleContains := "50%"
+		duplicateFlagged := false
+		mostlySilent := true
+		filter := &model.AudioShortFilter{
+			Categories:       []string{"news"},
+			CreatorIds:       []string{"1", "3"},
+			TitleContains:    &titleContains,
+			DuplicateFlagged: &duplicateFlagged,
+			MostlySilent:     &mostlySilent,
+			Tags:             []string{"covid", "health"},
+		}
+		orderBy := &model.AudioShortOrder{
+			Field:     model.AudioShortOrderFieldTitle,
+			Direction: model.OrderDirectionAsc,
+		}
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, a.title::text, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.category = ANY($1::text[]) AND a.creator_id = ANY($2::int[]) AND a.title ILIKE $3 AND NOT EXISTS (SELECT 1 FROM audio_fingerprints AS f WHERE f.short_id = a.id AND f.flagged) AND EXISTS (SELECT 1 FROM loudness AS l WHERE l.short_id = a.id AND l.mostly_silent) AND EXISTS (SELECT 1 FROM audio_short_tags AS st JOIN tags AS t ON t.id = st.tag_id WHERE st.short_id = a.id AND t.name = ANY($4::text[])) AND a.status = ANY($5::audio_shorts_status[]) ORDER BY a.title ASC, a.id ASC LIMIT $6")).
+			WithArgs(pq.Array([]string{"news"}), pq.Array([]string{"1", "3"}), `%50\%%`, pq.Array([]string{"covid", "health"}), pq.Array([]string{"active", "deleted"}), 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "title", "id", "name", "email", "username", "status"}).
+				AddRow(ID, title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, title, creatorID, name, email, "jackfrost", model.CreatorStatusActive)).RowsWillBeClosed()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM audio_shorts AS a WHERE a.category = ANY($1::text[]) AND a.creator_id = ANY($2::int[]) AND a.title ILIKE $3 AND NOT EXISTS (SELECT 1 FROM audio_fingerprints AS f WHERE f.short_id = a.id AND f.flagged) AND EXISTS (SELECT 1 FROM loudness AS l WHERE l.short_id = a.id AND l.mostly_silent) AND EXISTS (SELECT 1 FROM audio_short_tags AS st JOIN tags AS t ON t.id = st.tag_id WHERE st.short_id = a.id AND t.name = ANY($4::text[])) AND a.status = ANY($5::audio_shorts_status[])")).
+			WithArgs(pq.Array([]string{"news"}), pq.Array([]string{"1", "3"}), `%50\%%`, pq.Array([]string{"covid", "health"}), pq.Array([]string{"active", "deleted"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, nil, filter, orderBy, []model.Status{model.StatusDeleted, model.StatusActive})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(resp.Edges))
+		assert.Equal(t, 1, resp.TotalCount)
+		assert.Equal(t, EncodeCursor(Cursor{Key: "title_asc", Value: title, ID: ID}), resp.Edges[0].Cursor)
 	})
 
 	t.Run("sad path - no rows", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id ORDER BY a.id ASC LIMIT $1 OFFSET $2")).
-			WithArgs(1, 0).
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, a.created_at::text, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.status = ANY($1::audio_shorts_status[]) ORDER BY a.created_at DESC, a.id DESC LIMIT $2")).
+			WithArgs(pq.Array([]string{"active"}), 2).
 			WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, nil, nil, nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestShortsStore_Search(t *testing.T) {
+	var (
+		ID          = "1"
+		title       = "covid news"
+		description = "abcs"
+		status      = model.StatusActive
+		category    = "news"
+		audioFile   = "a"
+		rank        = 0.6079271
+		creatorID   = "1"
+		name        = "hi"
+		email       = "mockemail@gmail.com"
+		options     = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2"
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewShortsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, ts_rank(a.search_vector, q), ts_headline('english', a.title, q, $2), ts_headline('english', a.description, q, $2), c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g,to_tsquery('english', $1) AS q WHERE c.id = a.creator_id AND g.slug = a.category AND a.search_vector @@ q AND a.status = ANY($3::audio_shorts_status[]) ORDER BY ts_rank(a.search_vector, q) DESC, a.id DESC LIMIT $4")).
+			WithArgs("covid:* & ne:*", options, pq.Array([]string{"active"}), 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "ts_rank", "ts_headline", "ts_headline", "id", "name", "email", "username", "status"}).
+				AddRow(ID, title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, rank, "<mark>covid</mark> <mark>news</mark>", description, creatorID, name, email, "jackfrost", model.CreatorStatusActive)).RowsWillBeClosed()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM audio_shorts AS a,to_tsquery('english', $1) AS q WHERE a.search_vector @@ q AND a.status = ANY($2::audio_shorts_status[])")).
+			WithArgs("covid:* & ne:*", pq.Array([]string{"active"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Search(ctx, "covid ne", 1, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(resp.Edges))
+		assert.Equal(t, 1, resp.TotalCount)
+		assert.False(t, resp.PageInfo.HasNextPage)
+		assert.Equal(t, ID, resp.Edges[0].Node.ID)
+		assert.Equal(t, rank, resp.Edges[0].Rank)
+		assert.Equal(t, "<mark>covid</mark> <mark>news</mark>", resp.Edges[0].TitleHighlight)
+		assert.Equal(t, EncodeCursor(Cursor{Key: SearchOrderKey, Value: "0.6079271", ID: ID}), resp.Edges[0].Cursor)
+	})
+
+	t.Run("happy path - after cursor", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, ts_rank(a.search_vector, q), ts_headline('english', a.title, q, $2), ts_headline('english', a.description, q, $2), c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g,to_tsquery('english', $1) AS q WHERE c.id = a.creator_id AND g.slug = a.category AND a.search_vector @@ q AND a.status = ANY($3::audio_shorts_status[]) AND (ts_rank(a.search_vector, q), a.id) < ($4::real, $5::int) ORDER BY ts_rank(a.search_vector, q) DESC, a.id DESC LIMIT $6")).
+			WithArgs("covid:*", options, pq.Array([]string{"active"}), "0.6079271", "2", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "ts_rank", "ts_headline", "ts_headline", "id", "name", "email", "username", "status"})).RowsWillBeClosed()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT COUNT(*) FROM audio_shorts AS a,to_tsquery('english', $1) AS q WHERE a.search_vector @@ q AND a.status = ANY($2::audio_shorts_status[])")).
+			WithArgs("covid:*", pq.Array([]string{"active"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.GetAll(ctx, 0, 1)
+		resp, err := store.Search(ctx, "covid", 1, &Cursor{Key: SearchOrderKey, Value: "0.6079271", ID: "2"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Edges)
+		assert.Nil(t, resp.PageInfo.EndCursor)
+	})
+
+	t.Run("sad path - failed search", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, ts_rank(a.search_vector, q), ts_headline('english', a.title, q, $2), ts_headline('english', a.description, q, $2), c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g,to_tsquery('english', $1) AS q WHERE c.id = a.creator_id AND g.slug = a.category AND a.search_vector @@ q AND a.status = ANY($3::audio_shorts_status[]) ORDER BY ts_rank(a.search_vector, q) DESC, a.id DESC LIMIT $4")).
+			WithArgs("covid:*", options, pq.Array([]string{"active"}), 2).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Search(ctx, "covid", 1, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
@@ -122,7 +321,7 @@ func TestShortsStore_Create(t *testing.T) {
 		ID          = "1"
 		title       = "abc"
 		description = "abcs"
-		category    = model.CategoryNews
+		category    = "news"
 		status      = model.StatusActive
 		audioFile   = "a"
 		creatorID   = "1"
@@ -134,6 +333,8 @@ func TestShortsStore_Create(t *testing.T) {
 	store, err := NewShortsStore(db)
 	assert.NoError(t, err)
 
+	categoryQuery := "SELECT g.archived, EXISTS (SELECT 1 FROM audio_shorts AS a WHERE a.id = $2 AND a.category = g.slug) FROM categories AS g WHERE g.slug = $1 FOR SHARE"
+
 	input := &model.AudioShortInput{
 		Title:       title,
 		Description: description,
@@ -141,24 +342,34 @@ func TestShortsStore_Create(t *testing.T) {
 		AudioFile:   audioFile,
 		Creator:     &model.CreatorInput{ID: creatorID},
 	}
+	metadata := &model.AudioMetadata{Duration: 2.5, SampleRate: 44100, Channels: 2, Bitrate: 128000, Codec: "mp3"}
 
 	t.Run("happy path", func(t *testing.T) {
 		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, false))
 		sqlMock.ExpectExec(
-			regexp.QuoteMeta("INSERT INTO audio_shorts( title, description, status, category, audio_file, creator_id ) VALUES ($1, $2, $3, $4, $5, $6 )")).
-			WithArgs(title, description, status, category, audioFile, creatorID).
+			regexp.QuoteMeta("INSERT INTO audio_shorts( title, description, status, category, audio_file, creator_id, duration_ms, sample_rate, channels, bitrate, codec ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11 )")).
+			WithArgs(title, description, status, category, audioFile, creatorID, 2500, 44100, 2, 128000, "mp3").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, a.audio_file, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.title = $1 AND a.creator_id = $2")).
+			regexp.QuoteMeta("SELECT a.id, a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.title = $1 AND a.creator_id = $2")).
 			WithArgs(title, creatorID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "audio_file", "name", "email"}).
-				AddRow(ID, title, description, status, category, audioFile, name, email))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "name", "email", "username", "status"}).
+				AddRow(ID, title, description, status, category, "1", "News", 1, false, audioFile, 1, 2500, 44100, 2, 128000, "mp3", name, email, "jackfrost", model.CreatorStatusActive))
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.Create(ctx, input)
+		resp, err := store.Create(ctx, input, metadata)
 
 		assert.NoError(t, err)
+		assert.Equal(t, metadata, resp.Metadata)
 		assert.Equal(t, ID, resp.ID)
 		assert.Equal(t, title, resp.Title)
 		assert.Equal(t, name, resp.Creator.Name)
@@ -167,18 +378,125 @@ func TestShortsStore_Create(t *testing.T) {
 
 	t.Run("sad path - failed insert", func(t *testing.T) {
 		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, false))
 		sqlMock.ExpectExec(
-			regexp.QuoteMeta("INSERT INTO audio_shorts( title, description, status, category, audio_file, creator_id ) VALUES ($1, $2, $3, $4, $5, $6 )")).
-			WithArgs(title, description, status, category, audioFile, creatorID).
+			regexp.QuoteMeta("INSERT INTO audio_shorts( title, description, status, category, audio_file, creator_id, duration_ms, sample_rate, channels, bitrate, codec ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11 )")).
+			WithArgs(title, description, status, category, audioFile, creatorID, nil, nil, nil, nil, nil).
 			WillReturnError(errors.New("some error"))
 		sqlMock.ExpectRollback()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.Create(ctx, input)
+		resp, err := store.Create(ctx, input, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
+
+	t.Run("sad path - creator not active", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusBanned))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input, nil)
+
+		var notActive *CreatorNotActiveError
+		assert.True(t, errors.As(err, &notActive))
+		assert.Equal(t, model.CreatorStatusBanned, notActive.Status)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - missing creator", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input, nil)
+
+		var invalidReference *InvalidReferenceError
+		assert.True(t, errors.As(err, &invalidReference))
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - category archived", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(true, false))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input, nil)
+
+		var archived *CategoryArchivedError
+		assert.True(t, errors.As(err, &archived))
+		assert.Equal(t, category, archived.Slug)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - missing category", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, nil).
+			WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input, nil)
+
+		var invalidReference *InvalidReferenceError
+		assert.True(t, errors.As(err, &invalidReference))
+		assert.Equal(t, "fk_category", invalidReference.Constraint)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - duplicate title", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, false))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("INSERT INTO audio_shorts( title, description, status, category, audio_file, creator_id, duration_ms, sample_rate, channels, bitrate, codec ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11 )")).
+			WithArgs(title, description, status, category, audioFile, creatorID, nil, nil, nil, nil, nil).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "audio_shorts_title_creator_id_key"})
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Create(ctx, input, nil)
+
+		var conflict *ConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Nil(t, resp)
+	})
 }
 
 func TestShortsStore_Update(t *testing.T) {
@@ -187,7 +505,7 @@ func TestShortsStore_Update(t *testing.T) {
 		title       = "abc"
 		description = "abcs"
 		status      = model.StatusActive
-		category    = model.CategoryNews
+		category    = "news"
 		audioFile   = "a"
 		creatorID   = "1"
 		name        = "hi"
@@ -198,6 +516,8 @@ func TestShortsStore_Update(t *testing.T) {
 	store, err := NewShortsStore(db)
 	assert.NoError(t, err)
 
+	categoryQuery := "SELECT g.archived, EXISTS (SELECT 1 FROM audio_shorts AS a WHERE a.id = $2 AND a.category = g.slug) FROM categories AS g WHERE g.slug = $1 FOR SHARE"
+
 	input := &model.AudioShortInput{
 		Title:       title,
 		Description: description,
@@ -208,19 +528,27 @@ func TestShortsStore_Update(t *testing.T) {
 
 	t.Run("happy path", func(t *testing.T) {
 		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, true))
 		sqlMock.ExpectExec(
 			regexp.QuoteMeta("UPDATE audio_shorts SET title = $1, description = $2, category = $3, audio_file = $4, creator_id = $5 WHERE id = $6")).
 			WithArgs(title, description, category, audioFile, creatorID, ID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.id = $1")).
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
 			WithArgs(ID).
-			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "audio_file", "id", "name", "email"}).
-				AddRow(title, description, status, category, audioFile, creatorID, name, email))
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive))
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.Update(ctx, ID, input)
+		resp, err := store.Update(ctx, ID, input, nil, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, ID, resp.ID)
@@ -229,8 +557,96 @@ func TestShortsStore_Update(t *testing.T) {
 		assert.Equal(t, email, resp.Creator.Email)
 	})
 
+	t.Run("happy path - with metadata", func(t *testing.T) {
+		metadata := &model.AudioMetadata{Duration: 2.5, SampleRate: 44100, Channels: 2, Bitrate: 128000, Codec: "mp3"}
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, true))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET title = $1, description = $2, category = $3, audio_file = $4, creator_id = $5, duration_ms = $6, sample_rate = $7, channels = $8, bitrate = $9, codec = $10 WHERE id = $11")).
+			WithArgs(title, description, category, audioFile, creatorID, 2500, 44100, 2, 128000, "mp3", ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 2, 2500, 44100, 2, 128000, "mp3", creatorID, name, email, "jackfrost", model.CreatorStatusActive))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Update(ctx, ID, input, metadata, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, metadata, resp.Metadata)
+	})
+
+	t.Run("happy path - expected version", func(t *testing.T) {
+		version := 2
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT version FROM audio_shorts WHERE id = $1 FOR UPDATE")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, true))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET title = $1, description = $2, category = $3, audio_file = $4, creator_id = $5 WHERE id = $6")).
+			WithArgs(title, description, category, audioFile, creatorID, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, version+1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Update(ctx, ID, input, nil, &version)
+
+		assert.NoError(t, err)
+		assert.Equal(t, version+1, resp.Version)
+	})
+
+	t.Run("sad path - version conflict", func(t *testing.T) {
+		version := 2
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT version FROM audio_shorts WHERE id = $1 FOR UPDATE")).
+			WithArgs(ID).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version + 1))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Update(ctx, ID, input, nil, &version)
+
+		var conflict *VersionConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Equal(t, version+1, conflict.Actual)
+		assert.Nil(t, resp)
+	})
+
 	t.Run("sad path - failed update", func(t *testing.T) {
 		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, true))
 		sqlMock.ExpectExec(
 			regexp.QuoteMeta("UPDATE audio_shorts SET title = $1, description = $2, category = $3, audio_file = $4, creator_id = $5 WHERE id = $6")).
 			WithArgs(title, description, category, audioFile, creatorID, ID).
@@ -238,11 +654,197 @@ func TestShortsStore_Update(t *testing.T) {
 		sqlMock.ExpectRollback()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.Update(ctx, ID, input)
+		resp, err := store.Update(ctx, ID, input, nil, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
+
+	t.Run("sad path - creator not active", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusSuspended))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Update(ctx, ID, input, nil, nil)
+
+		var notActive *CreatorNotActiveError
+		assert.True(t, errors.As(err, &notActive))
+		assert.Equal(t, model.CreatorStatusSuspended, notActive.Status)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestShortsStore_Patch(t *testing.T) {
+	var (
+		ID          = "1"
+		title       = "abc"
+		description = "abcs"
+		status      = model.StatusActive
+		category    = "news"
+		audioFile   = "a"
+		creatorID   = "1"
+		name        = "hi"
+		email       = "mockemail@gmail.com"
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewShortsStore(db)
+	assert.NoError(t, err)
+
+	categoryQuery := "SELECT g.archived, EXISTS (SELECT 1 FROM audio_shorts AS a WHERE a.id = $2 AND a.category = g.slug) FROM categories AS g WHERE g.slug = $1 FOR SHARE"
+
+	findQuery := regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")
+	findRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+			AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive)
+	}
+
+	t.Run("happy path - title only", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET title = $1 WHERE id = $2")).
+			WithArgs(title, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{Title: &title}, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ID, resp.ID)
+		assert.Equal(t, title, resp.Title)
+		assert.Equal(t, description, resp.Description)
+	})
+
+	t.Run("happy path - category and creator", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(false, true))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET category = $1, creator_id = $2 WHERE id = $3")).
+			WithArgs(category, creatorID, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{Category: &category, Creator: &model.CreatorInput{ID: creatorID}}, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, category, resp.Category.Slug)
+	})
+
+	t.Run("happy path - archived category kept", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(true, true))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET category = $1, creator_id = $2 WHERE id = $3")).
+			WithArgs(category, creatorID, ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{Category: &category, Creator: &model.CreatorInput{ID: creatorID}}, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, category, resp.Category.Slug)
+	})
+
+	t.Run("sad path - category archived", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta(categoryQuery)).
+			WithArgs(category, ID).
+			WillReturnRows(sqlmock.NewRows([]string{"archived", "exists"}).AddRow(true, false))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{Category: &category, Creator: &model.CreatorInput{ID: creatorID}}, nil, nil)
+
+		var archived *CategoryArchivedError
+		assert.True(t, errors.As(err, &archived))
+		assert.Nil(t, resp)
+	})
+
+	t.Run("happy path - audio file with metadata", func(t *testing.T) {
+		metadata := &model.AudioMetadata{Duration: 2.5, SampleRate: 44100, Channels: 2, Bitrate: 128000, Codec: "mp3"}
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET audio_file = $1, duration_ms = $2, sample_rate = $3, channels = $4, bitrate = $5, codec = $6 WHERE id = $7")).
+			WithArgs(audioFile, 2500, 44100, 2, 128000, "mp3", ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{AudioFile: &audioFile}, metadata, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ID, resp.ID)
+	})
+
+	t.Run("sad path - no rows", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{Title: &title}, nil, nil)
+
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - creator not active", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows())
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusSuspended))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Patch(ctx, ID, &model.AudioShortPatch{Description: &description}, nil, nil)
+
+		var notActive *CreatorNotActiveError
+		assert.True(t, errors.As(err, &notActive))
+		assert.Nil(t, resp)
+	})
 }
 
 func TestShortsStore_Delete(t *testing.T) {
@@ -250,7 +852,7 @@ func TestShortsStore_Delete(t *testing.T) {
 		ID          = "1"
 		title       = "abc"
 		description = "abcs"
-		category    = model.CategoryNews
+		category    = "news"
 		status      = model.StatusDeleted
 		audioFile   = "a"
 		creatorID   = "1"
@@ -269,14 +871,14 @@ func TestShortsStore_Delete(t *testing.T) {
 			WithArgs(status, ID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.id = $1")).
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
 			WithArgs(ID).
-			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "audio_file", "id", "name", "email"}).
-				AddRow(title, description, status, category, audioFile, creatorID, name, email))
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive))
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.Delete(ctx, ID)
+		resp, err := store.Delete(ctx, ID, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, ID, resp.ID)
@@ -294,11 +896,104 @@ func TestShortsStore_Delete(t *testing.T) {
 		sqlMock.ExpectRollback()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.Delete(ctx, ID)
+		resp, err := store.Delete(ctx, ID, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
+
+	t.Run("sad path - missing entry", func(t *testing.T) {
+		version := 1
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT version FROM audio_shorts WHERE id = $1 FOR UPDATE")).
+			WithArgs(ID).
+			WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Delete(ctx, ID, &version)
+
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestShortsStore_Restore(t *testing.T) {
+	var (
+		ID          = "1"
+		title       = "abc"
+		description = "abcs"
+		category    = "news"
+		audioFile   = "a"
+		creatorID   = "1"
+		name        = "hi"
+		email       = "mockemail@gmail.com"
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewShortsStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")
+	findRows := func(status model.Status) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+			AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive)
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows(model.StatusDeleted))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusActive))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE audio_shorts SET status = $1 WHERE id = $2 AND status = $3")).
+			WithArgs(model.StatusActive, ID, model.StatusDeleted).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows(model.StatusActive))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Restore(ctx, ID, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ID, resp.ID)
+		assert.Equal(t, model.StatusActive, resp.Status)
+	})
+
+	t.Run("sad path - banned", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows(model.StatusBanned))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Restore(ctx, ID, nil)
+
+		var notDeleted *NotDeletedError
+		assert.True(t, errors.As(err, &notDeleted))
+		assert.Equal(t, model.StatusBanned, notDeleted.Status)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - creator not active", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).WithArgs(ID).WillReturnRows(findRows(model.StatusDeleted))
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT status FROM creators WHERE id = $1 FOR SHARE")).
+			WithArgs(creatorID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CreatorStatusBanned))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Restore(ctx, ID, nil)
+
+		var notActive *CreatorNotActiveError
+		assert.True(t, errors.As(err, &notActive))
+		assert.Nil(t, resp)
+	})
 }
 
 func TestShortsStore_HardDelete(t *testing.T) {
@@ -307,7 +1002,7 @@ func TestShortsStore_HardDelete(t *testing.T) {
 		title       = "abc"
 		description = "abcs"
 		status      = model.StatusActive
-		category    = model.CategoryNews
+		category    = "news"
 		audioFile   = "a"
 		creatorID   = "1"
 		name        = "hi"
@@ -321,10 +1016,10 @@ func TestShortsStore_HardDelete(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.id = $1")).
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
 			WithArgs(ID).
-			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "audio_file", "id", "name", "email"}).
-				AddRow(title, description, status, category, audioFile, creatorID, name, email))
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive))
 		sqlMock.ExpectExec(
 			regexp.QuoteMeta("DELETE FROM audio_shorts WHERE id = $1")).
 			WithArgs(ID).
@@ -332,7 +1027,7 @@ func TestShortsStore_HardDelete(t *testing.T) {
 		sqlMock.ExpectCommit()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.HardDelete(ctx, ID)
+		resp, err := store.HardDelete(ctx, ID, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, ID, resp.ID)
@@ -344,10 +1039,10 @@ func TestShortsStore_HardDelete(t *testing.T) {
 	t.Run("sad path - failed delete", func(t *testing.T) {
 		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(
-			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, a.audio_file, c.id, c.name, c.email FROM audio_shorts AS a,creators AS c WHERE c.id = a.creator_id AND a.id = $1")).
+			regexp.QuoteMeta("SELECT a.title, a.description, a.status, a.category, g.id, g.name, g.position, g.archived, a.audio_file, a.version, a.duration_ms, a.sample_rate, a.channels, a.bitrate, a.codec, c.id, c.name, c.email, c.username, c.status FROM audio_shorts AS a,creators AS c,categories AS g WHERE c.id = a.creator_id AND g.slug = a.category AND a.id = $1")).
 			WithArgs(ID).
-			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "audio_file", "id", "name", "email"}).
-				AddRow(title, description, status, category, audioFile, creatorID, name, email))
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "status", "category", "id", "name", "position", "archived", "audio_file", "version", "duration_ms", "sample_rate", "channels", "bitrate", "codec", "id", "name", "email", "username", "status"}).
+				AddRow(title, description, status, category, "1", "News", 1, false, audioFile, 1, nil, nil, nil, nil, nil, creatorID, name, email, "jackfrost", model.CreatorStatusActive))
 		sqlMock.ExpectExec(
 			regexp.QuoteMeta("DELETE FROM audio_shorts WHERE id = $1")).
 			WithArgs(ID).
@@ -355,7 +1050,85 @@ func TestShortsStore_HardDelete(t *testing.T) {
 		sqlMock.ExpectRollback()
 
 		ctx := logging.NewContext(context.Background())
-		resp, err := store.HardDelete(ctx, ID)
+		resp, err := store.HardDelete(ctx, ID, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestShortsStore_Purge(t *testing.T) {
+	var (
+		title         = "abc"
+		audioFile     = "http://localhost:8080/files/shorts/abc.mp3"
+		creatorID     = "1"
+		deletedBefore = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewShortsStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT id, title, audio_file, creator_id FROM audio_shorts WHERE status = $1 AND deleted_at < $2 AND id > $3::int ORDER BY id LIMIT $4 FOR UPDATE")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(model.StatusDeleted, deletedBefore, "0", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "audio_file", "creator_id"}).
+				AddRow("3", title, audioFile, creatorID).
+				AddRow("7", title, audioFile, creatorID)).RowsWillBeClosed()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("DELETE FROM audio_shorts WHERE id = $1")).
+			WithArgs("3").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("DELETE FROM audio_shorts WHERE id = $1")).
+			WithArgs("7").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Purge(ctx, deletedBefore, "0", 2, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(resp))
+		assert.Equal(t, "7", resp[1].ID)
+		assert.Equal(t, audioFile, resp[1].AudioFile)
+		assert.Equal(t, creatorID, resp[1].Creator.ID)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("happy path - dry run", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(model.StatusDeleted, deletedBefore, "3", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "audio_file", "creator_id"}).
+				AddRow("7", title, audioFile, creatorID)).RowsWillBeClosed()
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Purge(ctx, deletedBefore, "3", 2, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(resp))
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - failed delete", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(model.StatusDeleted, deletedBefore, "0", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "audio_file", "creator_id"}).
+				AddRow("3", title, audioFile, creatorID))
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("DELETE FROM audio_shorts WHERE id = $1")).
+			WithArgs("3").
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Purge(ctx, deletedBefore, "0", 2, false)
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=tags.go -destination=tags_mock.go -package=store TagsStore
+
+// TagsStore is the repository for the tags of shorts; tags are shared by the shorts they are set on, and only the
+// active shorts count towards their usage
+type (
+	TagsStore interface {
+		// Get returns the tags of the short in alphabetical order, empty when none were set
+		Get(ctx context.Context, shortID string) (tags []string, err error)
+		// Set replaces the tags of the short, creating the ones never used before
+		Set(ctx context.Context, shortID string, tags []string) (err error)
+		// GetAll returns up to first tags of active shorts after the given cursor, most used first
+		GetAll(ctx context.Context, first uint16, after *Cursor) (tags *model.TagConnection, err error)
+		// Autocomplete returns up to first tags of active shorts starting with the prefix, most used first
+		Autocomplete(ctx context.Context, prefix string, first uint16) (tags []*model.Tag, err error)
+	}
+
+	tagsStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewTagsStore(db *sql.DB) (TagsStore, error) {
+	return &tagsStore{
+		db: db,
+	}, nil
+}
+
+func (s *tagsStore) Get(ctx context.Context, shortID string) (tags []string, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	tags, err = findTags(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *tagsStore) Set(ctx context.Context, shortID string, tags []string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = saveTags(ctx, tx, shortID, tags)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *tagsStore) GetAll(ctx context.Context, first uint16, after *Cursor) (tags *model.TagConnection, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	// fetch one extra entry to know if there is a next page
+	edges, err := findAllTags(ctx, tx, first+1, after)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed)
+	}
+	count, err := countTags(ctx, tx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCountFailed)
+	}
+
+	tags = &model.TagConnection{
+		PageInfo:   &model.PageInfo{},
+		TotalCount: count,
+	}
+	if len(edges) > int(first) {
+		edges = edges[:first]
+		tags.PageInfo.HasNextPage = true
+	}
+	if len(edges) > 0 {
+		tags.PageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+	tags.Edges = edges
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *tagsStore) Autocomplete(ctx context.Context, prefix string, first uint16) (tags []*model.Tag, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	tags, err = autocompleteTags(ctx, tx, prefix, first)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" prefix:"+prefix)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tags.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockTagsStore is a mock of TagsStore interface.
+type MockTagsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagsStoreMockRecorder
+}
+
+// MockTagsStoreMockRecorder is the mock recorder for MockTagsStore.
+type MockTagsStoreMockRecorder struct {
+	mock *MockTagsStore
+}
+
+// NewMockTagsStore creates a new mock instance.
+func NewMockTagsStore(ctrl *gomock.Controller) *MockTagsStore {
+	mock := &MockTagsStore{ctrl: ctrl}
+	mock.recorder = &MockTagsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagsStore) EXPECT() *MockTagsStoreMockRecorder {
+	return m.recorder
+}
+
+// Autocomplete mocks base method.
+func (m *MockTagsStore) Autocomplete(ctx context.Context, prefix string, first uint16) ([]*model.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Autocomplete", ctx, prefix, first)
+	ret0, _ := ret[0].([]*model.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Autocomplete indicates an expected call of Autocomplete.
+func (mr *MockTagsStoreMockRecorder) Autocomplete(ctx, prefix, first interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Autocomplete", reflect.TypeOf((*MockTagsStore)(nil).Autocomplete), ctx, prefix, first)
+}
+
+// Get mocks base method.
+func (m *MockTagsStore) Get(ctx context.Context, shortID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTagsStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTagsStore)(nil).Get), ctx, shortID)
+}
+
+// GetAll mocks base method.
+func (m *MockTagsStore) GetAll(ctx context.Context, first uint16, after *Cursor) (*model.TagConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, first, after)
+	ret0, _ := ret[0].(*model.TagConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockTagsStoreMockRecorder) GetAll(ctx, first, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTagsStore)(nil).GetAll), ctx, first, after)
+}
+
+// Set mocks base method.
+func (m *MockTagsStore) Set(ctx context.Context, shortID string, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, shortID, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockTagsStoreMockRecorder) Set(ctx, shortID, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockTagsStore)(nil).Set), ctx, shortID, tags)
+}
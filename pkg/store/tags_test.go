@@ -0,0 +1,230 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagsStore_Get(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTagsStore(db)
+	assert.NoError(t, err)
+
+	query := "SELECT t.name FROM audio_short_tags AS st JOIN tags AS t ON t.id = st.tag_id WHERE st.short_id = $1 ORDER BY t.name"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("covid").AddRow("health"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"covid", "health"}, resp)
+	})
+
+	t.Run("happy path - no tags", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{}, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("1").
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, "1")
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestTagsStore_Set(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTagsStore(db)
+	assert.NoError(t, err)
+
+	tags := []string{"covid", "health"}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM audio_short_tags WHERE short_id = $1")).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO tags(name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING")).
+			WithArgs(pq.Array(tags)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO audio_short_tags(short_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2::text[])")).
+			WithArgs("1", pq.Array(tags)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Set(ctx, "1", tags)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("happy path - clear", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM audio_short_tags WHERE short_id = $1")).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Set(ctx, "1", []string{})
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM audio_short_tags WHERE short_id = $1")).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO tags(name)")).
+			WithArgs(pq.Array(tags)).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Set(ctx, "1", tags)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTagsStore_GetAll(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTagsStore(db)
+	assert.NoError(t, err)
+
+	count := "SELECT COUNT(DISTINCT st.tag_id) FROM audio_short_tags AS st JOIN audio_shorts AS a ON a.id = st.short_id WHERE a.status = $1"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT t.id, t.name, COUNT(*) FROM tags AS t JOIN audio_short_tags AS st ON st.tag_id = t.id JOIN audio_shorts AS a ON a.id = st.short_id WHERE a.status = $2 GROUP BY t.id ORDER BY COUNT(*) DESC, t.id DESC LIMIT $1")).
+			WithArgs(2, model.StatusActive.String()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count"}).
+				AddRow("3", "covid", 5).
+				AddRow("1", "health", 2)).RowsWillBeClosed()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(count)).
+			WithArgs(model.StatusActive.String()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, resp.TotalCount)
+		assert.True(t, resp.PageInfo.HasNextPage)
+		assert.Equal(t, []*model.TagEdge{{
+			Cursor: EncodeCursor(Cursor{Key: TagsOrderKey, Value: "5", ID: "3"}),
+			Node:   &model.Tag{Name: "covid", UsageCount: 5},
+		}}, resp.Edges)
+		assert.Equal(t, &resp.Edges[0].Cursor, resp.PageInfo.EndCursor)
+	})
+
+	t.Run("happy path - after cursor", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(
+			regexp.QuoteMeta("SELECT t.id, t.name, COUNT(*) FROM tags AS t JOIN audio_short_tags AS st ON st.tag_id = t.id JOIN audio_shorts AS a ON a.id = st.short_id WHERE a.status = $2 GROUP BY t.id HAVING (COUNT(*), t.id) < ($3::int, $4::int) ORDER BY COUNT(*) DESC, t.id DESC LIMIT $1")).
+			WithArgs(2, model.StatusActive.String(), "5", "3").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count"}).
+				AddRow("1", "health", 2)).RowsWillBeClosed()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(count)).
+			WithArgs(model.StatusActive.String()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, &Cursor{Key: TagsOrderKey, Value: "5", ID: "3"})
+
+		assert.NoError(t, err)
+		assert.False(t, resp.PageInfo.HasNextPage)
+		assert.Equal(t, "health", resp.Edges[0].Node.Name)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT t.id, t.name, COUNT(*) FROM tags AS t")).
+			WithArgs(2, model.StatusActive.String()).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.GetAll(ctx, 1, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestTagsStore_Autocomplete(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTagsStore(db)
+	assert.NoError(t, err)
+
+	query := "SELECT t.name, COUNT(*) FROM tags AS t JOIN audio_short_tags AS st ON st.tag_id = t.id JOIN audio_shorts AS a ON a.id = st.short_id WHERE t.name LIKE $1 AND a.status = $2 GROUP BY t.id ORDER BY COUNT(*) DESC, t.name LIMIT $3"
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(`co\_%`, model.StatusActive.String(), 10).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).AddRow("co_op", 3))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Autocomplete(ctx, "co_", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Tag{{Name: "co_op", UsageCount: 3}}, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("co%", model.StatusActive.String(), 10).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Autocomplete(ctx, "co", 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=transcripts.go -destination=transcripts_mock.go -package=store TranscriptsStore
+
+// TranscriptsStore is the repository for the transcripts of shorts, timed caption cues kept to the millisecond
+type (
+	TranscriptsStore interface {
+		// Get returns the transcript of the short, or nil when none was set
+		Get(ctx context.Context, shortID string) (transcript *model.Transcript, err error)
+		// Save replaces the transcript of the short with the cues
+		Save(ctx context.Context, shortID string, cues []*model.CaptionCue) (err error)
+		// Delete drops the transcript of the short, if any
+		Delete(ctx context.Context, shortID string) (err error)
+	}
+
+	transcriptsStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewTranscriptsStore(db *sql.DB) (TranscriptsStore, error) {
+	return &transcriptsStore{
+		db: db,
+	}, nil
+}
+
+func (s *transcriptsStore) Get(ctx context.Context, shortID string) (transcript *model.Transcript, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	transcript, err = findTranscript(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *transcriptsStore) Save(ctx context.Context, shortID string, cues []*model.CaptionCue) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = saveTranscript(ctx, tx, shortID, cues)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *transcriptsStore) Delete(ctx context.Context, shortID string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = deleteTranscript(ctx, tx, shortID)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageDeleteFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
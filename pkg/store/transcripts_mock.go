@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transcripts.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockTranscriptsStore is a mock of TranscriptsStore interface.
+type MockTranscriptsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTranscriptsStoreMockRecorder
+}
+
+// MockTranscriptsStoreMockRecorder is the mock recorder for MockTranscriptsStore.
+type MockTranscriptsStoreMockRecorder struct {
+	mock *MockTranscriptsStore
+}
+
+// NewMockTranscriptsStore creates a new mock instance.
+func NewMockTranscriptsStore(ctrl *gomock.Controller) *MockTranscriptsStore {
+	mock := &MockTranscriptsStore{ctrl: ctrl}
+	mock.recorder = &MockTranscriptsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTranscriptsStore) EXPECT() *MockTranscriptsStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockTranscriptsStore) Delete(ctx context.Context, shortID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, shortID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTranscriptsStoreMockRecorder) Delete(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTranscriptsStore)(nil).Delete), ctx, shortID)
+}
+
+// Get mocks base method.
+func (m *MockTranscriptsStore) Get(ctx context.Context, shortID string) (*model.Transcript, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].(*model.Transcript)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTranscriptsStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTranscriptsStore)(nil).Get), ctx, shortID)
+}
+
+// Save mocks base method.
+func (m *MockTranscriptsStore) Save(ctx context.Context, shortID string, cues []*model.CaptionCue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, shortID, cues)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockTranscriptsStoreMockRecorder) Save(ctx, shortID, cues interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockTranscriptsStore)(nil).Save), ctx, shortID, cues)
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscriptsStore_Get(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTranscriptsStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT cue_starts, cue_ends, cue_texts FROM transcripts WHERE short_id = $1")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"cue_starts", "cue_ends", "cue_texts"}).
+				AddRow("{0,1500}", "{1500,3000}", "{Hello,\"Two\nlines\"}"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Transcript{
+			Text: "Hello\nTwo\nlines",
+			Cues: []*model.CaptionCue{
+				{Start: 0, End: 1.5, Text: "Hello"},
+				{Start: 1.5, End: 3, Text: "Two\nlines"},
+			},
+		}, resp)
+	})
+
+	t.Run("happy path - not set", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"cue_starts", "cue_ends", "cue_texts"}))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestTranscriptsStore_Save(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTranscriptsStore(db)
+	assert.NoError(t, err)
+
+	saveQuery := regexp.QuoteMeta("INSERT INTO transcripts( short_id, cue_starts, cue_ends, cue_texts ) VALUES ($1, $2, $3, $4 ) ON CONFLICT (short_id) DO UPDATE SET cue_starts = EXCLUDED.cue_starts, cue_ends = EXCLUDED.cue_ends, cue_texts = EXCLUDED.cue_texts")
+	cues := []*model.CaptionCue{
+		{Start: 0, End: 1.5, Text: "Hello"},
+		{Start: 1.5, End: 3.0004, Text: "Two\nlines"},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(saveQuery).
+			WithArgs(shortID, "{0,1500}", "{1500,3000}", "{\"Hello\",\"Two\nlines\"}").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Save(ctx, shortID, cues)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(saveQuery).
+			WithArgs(shortID, "{0,1500}", "{1500,3000}", sqlmock.AnyArg()).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Save(ctx, shortID, cues)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTranscriptsStore_Delete(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewTranscriptsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta("DELETE FROM transcripts WHERE short_id = $1")).
+			WithArgs(shortID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Delete(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
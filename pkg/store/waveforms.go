@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=waveforms.go -destination=waveforms_mock.go -package=store WaveformsStore
+
+// WaveformsStore is the repository for the waveforms of shorts, which are generated asynchronously: a waveform is
+// requested as pending, claimed by a generator as processing, then completed as ready or failed
+type (
+	WaveformsStore interface {
+		// Get returns the waveform of the short, or nil when none was requested
+		Get(ctx context.Context, shortID string) (waveform *model.Waveform, err error)
+		// Request marks the waveform of the short as pending, dropping any previous peaks
+		Request(ctx context.Context, shortID string) (err error)
+		// Claim marks up to limit pending waveforms as processing, along with those left processing since before
+		// staleBefore, e.g. by a generator that stopped, claiming the oldest first
+		Claim(ctx context.Context, staleBefore time.Time, limit uint16) (jobs []*WaveformJob, err error)
+		// Complete stores the peaks of a processing waveform and marks it as ready; waveforms dropped in the meantime,
+		// as the audio file of their short changed, are left alone
+		Complete(ctx context.Context, shortID string, min, max []float64) (err error)
+		// Fail marks a processing waveform as failed or unsupported, for the reason
+		Fail(ctx context.Context, shortID string, status model.WaveformStatus, reason string) (err error)
+	}
+
+	// WaveformJob is a claimed waveform, to be generated from the audio file of its short
+	WaveformJob struct {
+		ShortID   string
+		AudioFile string
+	}
+
+	waveformsStore struct {
+		db *sql.DB
+		sync.RWMutex
+	}
+)
+
+func NewWaveformsStore(db *sql.DB) (WaveformsStore, error) {
+	return &waveformsStore{
+		db: db,
+	}, nil
+}
+
+func (s *waveformsStore) Get(ctx context.Context, shortID string) (waveform *model.Waveform, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	waveform, err = findWaveform(ctx, tx, shortID)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageFindFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *waveformsStore) Request(ctx context.Context, shortID string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = requestWaveform(ctx, tx, shortID)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCreateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *waveformsStore) Claim(ctx context.Context, staleBefore time.Time, limit uint16) (jobs []*WaveformJob, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	jobs, err = claimWaveforms(ctx, tx, staleBefore, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageUpdateFailed)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *waveformsStore) Complete(ctx context.Context, shortID string, min, max []float64) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = completeWaveform(ctx, tx, shortID, min, max)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
+
+func (s *waveformsStore) Fail(ctx context.Context, shortID string, status model.WaveformStatus, reason string) (err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return classifyError(errors.Wrap(err, ErrorMessageTransactionFailed))
+	}
+
+	defer func() {
+		// evaluated when function returns
+		if err != nil {
+			// typed errors let callers tell failures apart
+			err = classifyError(err)
+			err := tx.Rollback()
+			if err != nil {
+				logging.WithContext(ctx).Error(ErrorMessageRollbackFailed)
+			}
+		}
+	}()
+
+	err = failWaveform(ctx, tx, shortID, status, reason)
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageUpdateFailed+" short ID:"+shortID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, ErrorMessageCommitFailed)
+	}
+	return
+}
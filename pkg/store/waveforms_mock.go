@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: waveforms.go
+
+// Package store is a generated GoMock package.
+package store
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/nooble/task/audio-short-api/pkg/api/model"
+)
+
+// MockWaveformsStore is a mock of WaveformsStore interface.
+type MockWaveformsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWaveformsStoreMockRecorder
+}
+
+// MockWaveformsStoreMockRecorder is the mock recorder for MockWaveformsStore.
+type MockWaveformsStoreMockRecorder struct {
+	mock *MockWaveformsStore
+}
+
+// NewMockWaveformsStore creates a new mock instance.
+func NewMockWaveformsStore(ctrl *gomock.Controller) *MockWaveformsStore {
+	mock := &MockWaveformsStore{ctrl: ctrl}
+	mock.recorder = &MockWaveformsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWaveformsStore) EXPECT() *MockWaveformsStoreMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockWaveformsStore) Claim(ctx context.Context, staleBefore time.Time, limit uint16) ([]*WaveformJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, staleBefore, limit)
+	ret0, _ := ret[0].([]*WaveformJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockWaveformsStoreMockRecorder) Claim(ctx, staleBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockWaveformsStore)(nil).Claim), ctx, staleBefore, limit)
+}
+
+// Complete mocks base method.
+func (m *MockWaveformsStore) Complete(ctx context.Context, shortID string, min, max []float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, shortID, min, max)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockWaveformsStoreMockRecorder) Complete(ctx, shortID, min, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockWaveformsStore)(nil).Complete), ctx, shortID, min, max)
+}
+
+// Fail mocks base method.
+func (m *MockWaveformsStore) Fail(ctx context.Context, shortID string, status model.WaveformStatus, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, shortID, status, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockWaveformsStoreMockRecorder) Fail(ctx, shortID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockWaveformsStore)(nil).Fail), ctx, shortID, status, reason)
+}
+
+// Get mocks base method.
+func (m *MockWaveformsStore) Get(ctx context.Context, shortID string) (*model.Waveform, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, shortID)
+	ret0, _ := ret[0].(*model.Waveform)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWaveformsStoreMockRecorder) Get(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWaveformsStore)(nil).Get), ctx, shortID)
+}
+
+// Request mocks base method.
+func (m *MockWaveformsStore) Request(ctx context.Context, shortID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", ctx, shortID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockWaveformsStoreMockRecorder) Request(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockWaveformsStore)(nil).Request), ctx, shortID)
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaveformsStore_Get(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewWaveformsStore(db)
+	assert.NoError(t, err)
+
+	findQuery := regexp.QuoteMeta("SELECT status, resolution, min_peaks, max_peaks FROM waveforms WHERE short_id = $1")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "resolution", "min_peaks", "max_peaks"}).
+				AddRow(model.WaveformStatusReady, 2, "{-32767,0}", "{16384,32767}"))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.WaveformStatusReady, resp.Status)
+		assert.Equal(t, 2, resp.Resolution)
+		assert.Equal(t, []float64{-1, 0}, resp.Min)
+		assert.Equal(t, []float64{16384.0 / 32767, 1}, resp.Max)
+	})
+
+	t.Run("happy path - not requested", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "resolution", "min_peaks", "max_peaks"}))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(findQuery).
+			WithArgs(shortID).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Get(ctx, shortID)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestWaveformsStore_Request(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewWaveformsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("INSERT INTO waveforms( short_id, status ) VALUES ($1, $2 ) ON CONFLICT (short_id) DO UPDATE SET status = EXCLUDED.status, resolution = 0, min_peaks = '{}', max_peaks = '{}', error = NULL")).
+			WithArgs(shortID, model.WaveformStatusPending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Request(ctx, shortID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestWaveformsStore_Claim(t *testing.T) {
+	staleBefore := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewWaveformsStore(db)
+	assert.NoError(t, err)
+
+	claimQuery := regexp.QuoteMeta("UPDATE waveforms AS w SET status = $1 FROM audio_shorts AS a WHERE a.id = w.short_id AND w.short_id IN (SELECT short_id FROM waveforms WHERE status = $2 OR (status = $1 AND updated_at < $3) ORDER BY updated_at LIMIT $4 FOR UPDATE SKIP LOCKED) RETURNING w.short_id, a.audio_file")
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(claimQuery).
+			WithArgs(model.WaveformStatusProcessing, model.WaveformStatusPending, staleBefore, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"short_id", "audio_file"}).
+				AddRow("1", "http://localhost:8080/files/shorts/abc.wav").
+				AddRow("2", "http://localhost:8080/files/shorts/def.wav")).RowsWillBeClosed()
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Claim(ctx, staleBefore, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*WaveformJob{
+			{ShortID: "1", AudioFile: "http://localhost:8080/files/shorts/abc.wav"},
+			{ShortID: "2", AudioFile: "http://localhost:8080/files/shorts/def.wav"},
+		}, resp)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("sad path - error", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(claimQuery).
+			WithArgs(model.WaveformStatusProcessing, model.WaveformStatusPending, staleBefore, 10).
+			WillReturnError(errors.New("some error"))
+		sqlMock.ExpectRollback()
+
+		ctx := logging.NewContext(context.Background())
+		resp, err := store.Claim(ctx, staleBefore, 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestWaveformsStore_Complete(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewWaveformsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE waveforms SET status = $1, resolution = $2, min_peaks = $3, max_peaks = $4, error = NULL WHERE short_id = $5 AND status = $6")).
+			WithArgs(model.WaveformStatusReady, 2, "{-32767,0}", "{16384,32767}", shortID, model.WaveformStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Complete(ctx, shortID, []float64{-1, 0}, []float64{0.5, 1})
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestWaveformsStore_Fail(t *testing.T) {
+	shortID := "1"
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	store, err := NewWaveformsStore(db)
+	assert.NoError(t, err)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(
+			regexp.QuoteMeta("UPDATE waveforms SET status = $1, error = $2 WHERE short_id = $3 AND status = $4")).
+			WithArgs(model.WaveformStatusUnsupported, "decoding audio/mpeg", shortID, model.WaveformStatusProcessing).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+
+		ctx := logging.NewContext(context.Background())
+		err := store.Fail(ctx, shortID, model.WaveformStatusUnsupported, "decoding audio/mpeg")
+
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
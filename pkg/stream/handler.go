@@ -0,0 +1,219 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/hls"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/playback"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageNoBlobStore    = "Streaming needs a blob store"
+	ErrorMessageFindFailed     = "Failed to find the short to stream"
+	ErrorMessageOpenFailed     = "Failed to open the audio file to stream"
+	ErrorMessageNotInBlobStore = "Audio file is not in the blob store"
+	ErrorMessageNotServed      = "Audio of banned or deleted shorts is not served"
+	ErrorMessageInvalidURL     = "Playback URL is not valid"
+	ErrorMessageNotPackaged    = "Short is not packaged as HLS"
+)
+
+// Handler streams the audio files of shorts from the blob store at /{id}, to be mounted under a prefix such as
+// /audio/, and the files of their HLS packages at /{id}/hls/{name}. Range requests, ETag and Last-Modified validators
+// are supported; the audio of banned or deleted shorts, or of banned creators, is refused, so that takedowns apply to
+// every listener at once
+type Handler struct {
+	shortsStore store.AudioShortsStore
+	blobStore   storage.BlobStore
+	signer      *playback.Signer
+	hlsStore    store.HLSStore
+}
+
+// Option sets an optional dependency of the handler
+type Option func(h *Handler)
+
+// WithSigner makes the handler only serve URLs the signer signed for the listener of the request
+func WithSigner(signer *playback.Signer) Option {
+	return func(h *Handler) {
+		h.signer = signer
+	}
+}
+
+// WithHLS makes the handler serve the HLS packages of the store once they are ready
+func WithHLS(hlsStore store.HLSStore) Option {
+	return func(h *Handler) {
+		h.hlsStore = hlsStore
+	}
+}
+
+func New(shortsStore store.AudioShortsStore, blobStore storage.BlobStore, opts ...Option) (*Handler, error) {
+	if blobStore == nil {
+		return nil, errors.New(ErrorMessageNoBlobStore)
+	}
+	h := &Handler{
+		shortsStore: shortsStore,
+		blobStore:   blobStore,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := logging.NewContext(r.Context())
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	id := parts[0]
+	logging.WithContext(ctx).Info("Stream Audio Short With ID " + id)
+	if _, err := strconv.ParseUint(id, 10, 63); err != nil || (len(parts) != 1 && (len(parts) != 3 || parts[1] != "hls")) {
+		http.NotFound(w, r)
+		return
+	}
+	short, ok := h.find(ctx, w, r, id)
+	if !ok {
+		return
+	}
+	if len(parts) == 3 {
+		h.serveHLS(ctx, w, r, id, parts[2])
+		return
+	}
+
+	key, ok := h.blobStore.Key(short.AudioFile)
+	if !ok {
+		logging.WithContext(ctx).Error(ErrorMessageNotInBlobStore + " ID:" + id)
+		http.NotFound(w, r)
+		return
+	}
+	h.serveBlob(ctx, w, r, id, key)
+}
+
+// find verifies the signature of the request and returns the short, unless it must not be served; otherwise the
+// error is written and false is returned
+func (h *Handler) find(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) (*model.AudioShort, bool) {
+	if h.signer != nil {
+		err := h.signer.Verify(id, r.URL.Query(), playback.Listener(r))
+		if err != nil {
+			logging.WithContext(ctx).Info(errors.Wrap(err, ErrorMessageInvalidURL+" ID:"+id).Error())
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return nil, false
+		}
+	}
+
+	short, err := h.shortsStore.GetByID(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id).Error())
+		writeStoreError(w, r, err)
+		return nil, false
+	}
+	if short.Status != model.StatusActive || short.Creator.Status == model.CreatorStatusBanned {
+		logging.WithContext(ctx).Info(ErrorMessageNotServed + " ID:" + id + " status:" + short.Status.String())
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return nil, false
+	}
+	return short, true
+}
+
+// serveHLS serves the file of the ready HLS package of the short with the name; signed playlists are rewritten so that
+// their segments carry the signature of the playlist, which covers the whole short
+func (h *Handler) serveHLS(ctx context.Context, w http.ResponseWriter, r *http.Request, id, name string) {
+	key, ok := hls.Key(id, name)
+	if !ok || h.hlsStore == nil {
+		http.NotFound(w, r)
+		return
+	}
+	pkg, err := h.hlsStore.Get(ctx, id)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageFindFailed+" ID:"+id).Error())
+		writeStoreError(w, r, err)
+		return
+	}
+	if pkg == nil || pkg.Status != store.HLSStatusReady {
+		logging.WithContext(ctx).Info(ErrorMessageNotPackaged + " ID:" + id)
+		http.NotFound(w, r)
+		return
+	}
+	if name != hls.PlaylistName || h.signer == nil {
+		h.serveBlob(ctx, w, r, id, key)
+		return
+	}
+
+	body, info, err := h.blobStore.Get(ctx, key, 0, -1)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageOpenFailed+" ID:"+id).Error())
+		writeBlobError(w, r, err)
+		return
+	}
+	defer body.Close()
+	playlist, err := ioutil.ReadAll(body)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageOpenFailed+" ID:"+id).Error())
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	lines := strings.Split(string(playlist), "\n")
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines[i] = line + "?" + r.URL.RawQuery
+		}
+	}
+	w.Header().Set("Content-Type", hls.ContentTypePlaylist)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, "", info.LastModified, bytes.NewReader([]byte(strings.Join(lines, "\n"))))
+}
+
+// serveBlob serves the blob of the key with its validators
+func (h *Handler) serveBlob(ctx context.Context, w http.ResponseWriter, r *http.Request, id, key string) {
+	body, err := storage.NewReadSeeker(ctx, h.blobStore, key)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageOpenFailed+" ID:"+id).Error())
+		writeBlobError(w, r, err)
+		return
+	}
+	defer body.Close()
+
+	info := body.Info()
+	w.Header().Set("Content-Type", info.ContentType)
+	if info.ETag != "" {
+		w.Header().Set("ETag", info.ETag)
+	}
+	// listeners revalidate on every play, which is cheap with the validators, so that takedowns are never cached
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, "", info.LastModified, body)
+}
+
+// writeStoreError writes the status of a store error
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	var notFound *store.NotFoundError
+	var unavailable *store.UnavailableError
+	switch {
+	case errors.As(err, &notFound):
+		http.NotFound(w, r)
+	case errors.As(err, &unavailable):
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// writeBlobError writes the status of a blob store error
+func writeBlobError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Cause(err) == storage.ErrNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+}
@@ -0,0 +1,315 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/playback"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	h, err := New(nil, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, h)
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	content := "ID3\x04\x00\x00\x00\x00\x00\x00" + strings.Repeat("audio", 20)
+	url, err := blobStore.Put(context.Background(), "shorts/abc.mp3", strings.NewReader(content), "audio/mpeg")
+	assert.NoError(t, err)
+	h, err := New(mockStore, blobStore)
+	assert.NoError(t, err)
+	srv := http.StripPrefix("/audio/", h)
+
+	short := func(status model.Status, creatorStatus model.CreatorStatus, audioFile string) *model.AudioShort {
+		return &model.AudioShort{
+			ID:        "1",
+			Status:    status,
+			AudioFile: audioFile,
+			Creator:   &model.Creator{ID: "1", Status: creatorStatus},
+		}
+	}
+	serve := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short(model.StatusActive, model.CreatorStatusActive, url), nil)
+
+		rec := serve(http.MethodGet, "/audio/1", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, content, rec.Body.String())
+		assert.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+	})
+
+	t.Run("happy path - range", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short(model.StatusActive, model.CreatorStatusSuspended, url), nil)
+
+		rec := serve(http.MethodGet, "/audio/1", http.Header{"Range": {"bytes=10-19"}})
+
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, content[10:20], rec.Body.String())
+		assert.Equal(t, "bytes 10-19/110", rec.Header().Get("Content-Range"))
+		assert.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("happy path - not modified", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short(model.StatusActive, model.CreatorStatusActive, url), nil).Times(2)
+		etag := serve(http.MethodHead, "/audio/1", nil).Header().Get("ETag")
+
+		rec := serve(http.MethodGet, "/audio/1", http.Header{"If-None-Match": {etag}})
+
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("sad path - range not satisfiable", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short(model.StatusActive, model.CreatorStatusActive, url), nil)
+
+		rec := serve(http.MethodGet, "/audio/1", http.Header{"Range": {"bytes=200-"}})
+
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+	})
+
+	t.Run("sad path - refused", func(t *testing.T) {
+		for _, s := range []*model.AudioShort{
+			short(model.StatusBanned, model.CreatorStatusActive, url),
+			short(model.StatusDeleted, model.CreatorStatusActive, url),
+			short(model.StatusActive, model.CreatorStatusBanned, url),
+		} {
+			mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(s, nil)
+
+			rec := serve(http.MethodGet, "/audio/1", nil)
+
+			assert.Equal(t, http.StatusGone, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "audio")
+		}
+	})
+
+	t.Run("sad path - not found", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "2").Return(nil, &store.NotFoundError{Err: errors.New("some error")})
+
+		rec := serve(http.MethodGet, "/audio/2", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("sad path - invalid ID", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/audio/abc", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("sad path - audio file not in blob store", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short(model.StatusActive, model.CreatorStatusActive, "https://example.com/a.mp3"), nil)
+
+		rec := serve(http.MethodGet, "/audio/1", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("sad path - missing blob", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short(model.StatusActive, model.CreatorStatusActive, "http://localhost:8080/files/shorts/def.mp3"), nil)
+
+		rec := serve(http.MethodGet, "/audio/1", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("sad path - store unavailable", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(nil, &store.UnavailableError{Err: errors.New("some error")})
+
+		rec := serve(http.MethodGet, "/audio/1", nil)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("sad path - method not allowed", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/audio/1", nil)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+	})
+}
+
+func TestHandler_ServeHTTP_Signed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	url, err := blobStore.Put(context.Background(), "shorts/abc.mp3", strings.NewReader("ID3\x04\x00\x00\x00\x00\x00\x00 audio"), "audio/mpeg")
+	assert.NoError(t, err)
+	keys, err := playback.ParseKeys([]string{"1:secret"})
+	assert.NoError(t, err)
+	signer, err := playback.NewSigner("http://localhost:8080/audio", keys, time.Hour, true)
+	assert.NoError(t, err)
+	h, err := New(mockStore, blobStore, WithSigner(signer))
+	assert.NoError(t, err)
+	srv := http.StripPrefix("/audio/", h)
+
+	serve := func(target, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(&model.AudioShort{
+			ID:        "1",
+			Status:    model.StatusActive,
+			AudioFile: url,
+			Creator:   &model.Creator{ID: "1", Status: model.CreatorStatusActive},
+		}, nil)
+
+		rec := serve(signer.URL("1", "10.0.0.1"), "10.0.0.1:1234")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("sad path - other listener", func(t *testing.T) {
+		rec := serve(signer.URL("1", "10.0.0.1"), "10.0.0.2:1234")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("sad path - unsigned", func(t *testing.T) {
+		rec := serve("/audio/1", "10.0.0.1:1234")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestHandler_ServeHTTP_HLS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockAudioShortsStore(ctrl)
+	mockHLS := store.NewMockHLSStore(ctrl)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	playlist := "#EXTM3U\n#EXTINF:6.000,\nsegment0.mp3\n#EXTINF:2.000,\nsegment1.mp3\n#EXT-X-ENDLIST\n"
+	playlistURL, err := blobStore.Put(context.Background(), "hls/1/index.m3u8", strings.NewReader(playlist), "application/vnd.apple.mpegurl")
+	assert.NoError(t, err)
+	_, err = blobStore.Put(context.Background(), "hls/1/segment1.mp3", strings.NewReader("segment"), "audio/mpeg")
+	assert.NoError(t, err)
+	keys, err := playback.ParseKeys([]string{"1:secret"})
+	assert.NoError(t, err)
+	signer, err := playback.NewSigner("http://localhost:8080/audio", keys, time.Hour, false)
+	assert.NoError(t, err)
+
+	short := &model.AudioShort{
+		ID:        "1",
+		Status:    model.StatusActive,
+		AudioFile: "http://localhost:8080/files/shorts/abc.mp3",
+		Creator:   &model.Creator{ID: "1", Status: model.CreatorStatusActive},
+	}
+	ready := &store.HLSPackage{Status: store.HLSStatusReady, PlaylistURL: playlistURL, Segments: 2}
+	serve := func(h *Handler, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		http.StripPrefix("/audio/", h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	h, err := New(mockStore, blobStore, WithHLS(mockHLS))
+	assert.NoError(t, err)
+
+	t.Run("happy path - playlist", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(ready, nil)
+
+		rec := serve(h, "/audio/1/hls/index.m3u8")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, playlist, rec.Body.String())
+		assert.Equal(t, "application/vnd.apple.mpegurl", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("happy path - segment", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(ready, nil)
+
+		rec := serve(h, "/audio/1/hls/segment1.mp3")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "segment", rec.Body.String())
+		assert.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("happy path - signed playlist", func(t *testing.T) {
+		signed, err := New(mockStore, blobStore, WithHLS(mockHLS), WithSigner(signer))
+		assert.NoError(t, err)
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(ready, nil)
+		target := signer.HLSURL("1", "index.m3u8", "")
+		query := target[strings.Index(target, "?"):]
+
+		rec := serve(signed, target)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "#EXTM3U\n#EXTINF:6.000,\nsegment0.mp3"+query+"\n#EXTINF:2.000,\nsegment1.mp3"+query+"\n#EXT-X-ENDLIST\n", rec.Body.String())
+		assert.Equal(t, "application/vnd.apple.mpegurl", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("sad path - not ready", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+		mockHLS.EXPECT().Get(gomock.Any(), "1").Return(&store.HLSPackage{Status: store.HLSStatusProcessing}, nil)
+
+		rec := serve(h, "/audio/1/hls/index.m3u8")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("sad path - refused", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(&model.AudioShort{
+			ID:      "1",
+			Status:  model.StatusBanned,
+			Creator: &model.Creator{ID: "1", Status: model.CreatorStatusActive},
+		}, nil)
+
+		rec := serve(h, "/audio/1/hls/segment1.mp3")
+
+		assert.Equal(t, http.StatusGone, rec.Code)
+	})
+
+	t.Run("sad path - invalid name", func(t *testing.T) {
+		mockStore.EXPECT().GetByID(gomock.Any(), "1").Return(short, nil)
+
+		rec := serve(h, "/audio/1/hls/segment.wav")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("sad path - unsigned", func(t *testing.T) {
+		signed, err := New(mockStore, blobStore, WithHLS(mockHLS), WithSigner(signer))
+		assert.NoError(t, err)
+
+		rec := serve(signed, "/audio/1/hls/segment1.mp3")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
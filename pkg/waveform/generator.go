@@ -0,0 +1,140 @@
+package waveform
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/audio"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrorMessageInvalidConfig  = "Waveform resolution, interval, batch size and timeout must be positive"
+	ErrorMessageClaimFailed    = "Failed to claim pending waveforms"
+	ErrorMessageGenerateFailed = "Failed to generate waveform"
+	ErrorMessageSaveFailed     = "Failed to save waveform"
+	ErrorMessageNotInBlobStore = "Audio file is not kept in the blob store"
+	ErrorMessageNoBlobStore    = "No blob store to read audio files from"
+)
+
+// Generator periodically claims the pending waveforms of shorts and generates their peaks from their audio files in
+// the blob store, so that uploads do not wait for the audio to be decoded
+type Generator struct {
+	waveformsStore store.WaveformsStore
+	blobStore      storage.BlobStore
+	resolution     int
+	interval       time.Duration
+	batchSize      uint16
+	timeout        time.Duration
+	now            func() time.Time
+}
+
+// New returns a generator of the waveforms of the store, reading audio files from the blob store
+func New(waveformsStore store.WaveformsStore, blobStore storage.BlobStore, cfg *config.Config) (*Generator, error) {
+	if cfg.Waveform.Resolution <= 0 || cfg.Waveform.Interval <= 0 || cfg.Waveform.BatchSize == 0 || cfg.Waveform.Timeout <= 0 {
+		return nil, errors.New(ErrorMessageInvalidConfig)
+	}
+	return &Generator{
+		waveformsStore: waveformsStore,
+		blobStore:      blobStore,
+		resolution:     cfg.Waveform.Resolution,
+		interval:       cfg.Waveform.Interval,
+		batchSize:      cfg.Waveform.BatchSize,
+		timeout:        cfg.Waveform.Timeout,
+		now:            time.Now,
+	}, nil
+}
+
+// Run generates the pending waveforms every interval until the context is done
+func (g *Generator) Run(ctx context.Context) {
+	ctx = logging.NewContext(ctx)
+	logging.WithContext(ctx).Info("Waveform generator started, resolution " + strconv.Itoa(g.resolution) + ", interval " + g.interval.String())
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logging.WithContext(ctx).Info("Waveform generator stopped")
+			return
+		case <-ticker.C:
+			generated, err := g.Generate(ctx)
+			if err != nil {
+				logging.WithContext(ctx).Error(err.Error())
+			}
+			if generated > 0 {
+				logging.WithContext(ctx).Info("Generated " + strconv.Itoa(generated) + " waveforms")
+			}
+		}
+	}
+}
+
+// Generate claims the pending waveforms batch by batch, along with those left processing for longer than the timeout,
+// and generates them; it returns the number of waveforms that were claimed
+func (g *Generator) Generate(ctx context.Context) (generated int, err error) {
+	for {
+		jobs, err := g.waveformsStore.Claim(ctx, g.now().Add(-g.timeout), g.batchSize)
+		if err != nil {
+			return generated, errors.Wrap(err, ErrorMessageClaimFailed)
+		}
+		for _, job := range jobs {
+			g.generate(ctx, job)
+		}
+		generated += len(jobs)
+		if len(jobs) < int(g.batchSize) {
+			return generated, nil
+		}
+	}
+}
+
+// generate decodes the audio file of the job into peaks and saves them, or marks the waveform as failed, or as
+// unsupported when the audio cannot be decoded
+func (g *Generator) generate(ctx context.Context, job *store.WaveformJob) {
+	// claims older than the timeout are taken over, so the generation must not outlive it
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	peaks, err := g.peaks(ctx, job.AudioFile)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageGenerateFailed+" short ID:"+job.ShortID).Error())
+		status := model.WaveformStatusFailed
+		if errors.Cause(err) == audio.ErrUnsupported {
+			status = model.WaveformStatusUnsupported
+		}
+		err = g.waveformsStore.Fail(ctx, job.ShortID, status, err.Error())
+		if err != nil {
+			logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageSaveFailed+" short ID:"+job.ShortID).Error())
+		}
+		return
+	}
+	min := make([]float64, len(peaks))
+	max := make([]float64, len(peaks))
+	for i, peak := range peaks {
+		min[i], max[i] = peak.Min, peak.Max
+	}
+	err = g.waveformsStore.Complete(ctx, job.ShortID, min, max)
+	if err != nil {
+		logging.WithContext(ctx).Error(errors.Wrap(err, ErrorMessageSaveFailed+" short ID:"+job.ShortID).Error())
+	}
+}
+
+// peaks decodes the audio file at the URL, which must be kept in the blob store
+func (g *Generator) peaks(ctx context.Context, url string) ([]audio.Peak, error) {
+	if g.blobStore == nil {
+		return nil, errors.New(ErrorMessageNoBlobStore)
+	}
+	key, ok := g.blobStore.Key(url)
+	if !ok {
+		return nil, errors.New(ErrorMessageNotInBlobStore)
+	}
+	reader, err := storage.NewReaderAt(ctx, g.blobStore, key)
+	if err != nil {
+		return nil, err
+	}
+	return audio.Peaks(reader, reader.Size(), g.resolution)
+}
@@ -0,0 +1,156 @@
+package waveform
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nooble/task/audio-short-api/pkg/api/model"
+	"github.com/nooble/task/audio-short-api/pkg/config"
+	"github.com/nooble/task/audio-short-api/pkg/logging"
+	"github.com/nooble/task/audio-short-api/pkg/storage"
+	"github.com/nooble/task/audio-short-api/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Waveform.Resolution = 2
+	cfg.Waveform.Interval = time.Second
+	cfg.Waveform.BatchSize = 2
+	cfg.Waveform.Timeout = time.Minute
+	return cfg
+}
+
+// newWAV returns a mono 16 bit PCM WAVE file of the samples
+func newWAV(samples ...int16) []byte {
+	data := &bytes.Buffer{}
+	for _, sample := range samples {
+		_ = binary.Write(data, binary.LittleEndian, sample)
+	}
+	header := &bytes.Buffer{}
+	header.WriteString("RIFF")
+	_ = binary.Write(header, binary.LittleEndian, uint32(36+data.Len()))
+	header.WriteString("WAVEfmt ")
+	_ = binary.Write(header, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(header, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(header, binary.LittleEndian, []uint32{8000, 16000})
+	_ = binary.Write(header, binary.LittleEndian, []uint16{2, 16})
+	header.WriteString("data")
+	_ = binary.Write(header, binary.LittleEndian, uint32(data.Len()))
+	return append(header.Bytes(), data.Bytes()...)
+}
+
+// newMP3 returns an MP3 file of 128 kbit/s, 44.1 kHz stereo MPEG-1 layer III frames of a tone of about 785 Hz at a
+// quarter of full scale: every granule codes the 21st spectral line at 1 with a global gain of 202
+func newMP3(frames int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	// side information: 25 bits of main data per granule and channel, coded with count1 table B
+	copy(frame[4:], []byte{
+		0x00, 0x00, 0x00, 0x19, 0x00, 0x65, 0x00, 0x00, 0x00, 0x02, 0x03, 0x20, 0x0C, 0xA0, 0x00, 0x00,
+		0x00, 0x40, 0x64, 0x01, 0x94, 0x00, 0x00, 0x00, 0x08, 0x0C, 0x80, 0x32, 0x80, 0x00, 0x00, 0x01,
+	})
+	// main data: five silent quadruples, then the quadruple of the line and its sign, four times
+	copy(frame[36:], []byte{0xFF, 0xFF, 0xF7, 0x7F, 0xFF, 0xFB, 0xBF, 0xFF, 0xFD, 0xDF, 0xFF, 0xFE, 0xE0})
+	return bytes.Repeat(frame, frames)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		g, err := New(nil, nil, newConfig())
+
+		assert.NoError(t, err)
+		assert.NotNil(t, g)
+	})
+
+	t.Run("sad path - no resolution", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Waveform.Resolution = 0
+		g, err := New(nil, nil, cfg)
+
+		assert.Error(t, err)
+		assert.Nil(t, g)
+	})
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := store.NewMockWaveformsStore(ctrl)
+	now := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	staleBefore := now.Add(-time.Minute)
+	blobStore, err := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/files")
+	assert.NoError(t, err)
+	ctx := logging.NewContext(context.Background())
+
+	wav, err := blobStore.Put(ctx, "shorts/1.wav", bytes.NewReader(newWAV(16384, -16384, 32767, 0)), "audio/wav")
+	assert.NoError(t, err)
+	mp3, err := blobStore.Put(ctx, "shorts/2.mp3", bytes.NewReader(newMP3(10)), "audio/mpeg")
+	assert.NoError(t, err)
+	corrupt, err := blobStore.Put(ctx, "shorts/3.wav", bytes.NewReader(newWAV(1, 2, 3)[:40]), "audio/wav")
+	assert.NoError(t, err)
+	flac, err := blobStore.Put(ctx, "shorts/4.flac", strings.NewReader("fLaC"+strings.Repeat("\x00", 100)), "audio/flac")
+	assert.NoError(t, err)
+
+	g, err := New(mockStore, blobStore, newConfig())
+	assert.NoError(t, err)
+	g.now = func() time.Time { return now }
+
+	t.Run("happy path - batches", func(t *testing.T) {
+		gomock.InOrder(
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.WaveformJob{
+				{ShortID: "1", AudioFile: wav},
+				{ShortID: "2", AudioFile: mp3},
+			}, nil),
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.WaveformJob{
+				{ShortID: "3", AudioFile: corrupt},
+				{ShortID: "4", AudioFile: flac},
+			}, nil),
+			mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return(nil, nil),
+		)
+		mockStore.EXPECT().Complete(gomock.Any(), "1", []float64{-0.5, 0}, []float64{0.5, 32767.0 / 32768})
+		mockStore.EXPECT().Complete(gomock.Any(), "2", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, shortID string, min, max []float64) error {
+				assert.Len(t, min, 2)
+				assert.Len(t, max, 2)
+				for i := range min {
+					assert.InDelta(t, -0.25, min[i], 0.025)
+					assert.InDelta(t, 0.25, max[i], 0.025)
+				}
+				return nil
+			})
+		mockStore.EXPECT().Fail(gomock.Any(), "3", model.WaveformStatusFailed, gomock.Any())
+		mockStore.EXPECT().Fail(gomock.Any(), "4", model.WaveformStatusUnsupported, gomock.Any())
+
+		generated, err := g.Generate(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, generated)
+	})
+
+	t.Run("happy path - external audio file", func(t *testing.T) {
+		mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return([]*store.WaveformJob{
+			{ShortID: "5", AudioFile: "https://example.com/5.wav"},
+		}, nil)
+		mockStore.EXPECT().Fail(gomock.Any(), "5", model.WaveformStatusFailed, ErrorMessageNotInBlobStore)
+
+		generated, err := g.Generate(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, generated)
+	})
+
+	t.Run("sad path - claim fails", func(t *testing.T) {
+		mockStore.EXPECT().Claim(gomock.Any(), staleBefore, uint16(2)).Return(nil, errors.New("some error"))
+
+		generated, err := g.Generate(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, generated)
+	})
+}